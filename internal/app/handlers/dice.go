@@ -2,31 +2,37 @@ package handlers
 
 import (
 	"net/http"
-	"strconv"
 
 	"github.com/gin-gonic/gin"
 	"github.com/luizdequeiroz/rpg-backend/internal/app/interfaces"
+	"github.com/luizdequeiroz/rpg-backend/internal/app/middleware"
 	"github.com/luizdequeiroz/rpg-backend/internal/app/models"
 	"github.com/luizdequeiroz/rpg-backend/internal/app/services"
+	"github.com/luizdequeiroz/rpg-backend/pkg/roll"
 )
 
 type DiceHandler struct {
 	diceService         *services.DiceService
 	playerSheetService  *services.PlayerSheetService
+	fairnessService     *services.RollFairnessService
+	contestService      *services.RollContestService
 	notificationService interfaces.NotificationService
 }
 
-func NewDiceHandler(diceService *services.DiceService, playerSheetService *services.PlayerSheetService, notificationService interfaces.NotificationService) *DiceHandler {
+func NewDiceHandler(diceService *services.DiceService, playerSheetService *services.PlayerSheetService, fairnessService *services.RollFairnessService, contestService *services.RollContestService, notificationService interfaces.NotificationService) *DiceHandler {
 	return &DiceHandler{
 		diceService:         diceService,
 		playerSheetService:  playerSheetService,
+		fairnessService:     fairnessService,
+		contestService:      contestService,
 		notificationService: notificationService,
 	}
 }
 
 // RollDice executa uma rolagem de dados
 // @Summary Rolar dados
-// @Description Executa uma rolagem de dados com expressão personalizada
+// @Description Executa uma rolagem de dados com expressão personalizada. Com expressions ou a sintaxe de repetição
+// @Description (e.g., "6x 4d6kh3"), executa um lote de rolagens independentes e retorna models.DiceBatchResponse
 // @Tags dice
 // @Accept json
 // @Produce json
@@ -47,7 +53,7 @@ func (h *DiceHandler) RollDice(c *gin.Context) {
 		return
 	}
 
-	userID, exists := c.Get("userID")
+	userID, _, exists := middleware.GetUserFromContext(c)
 	if !exists {
 		c.JSON(http.StatusUnauthorized, models.ErrorResponse{
 			Error:   "Não autorizado",
@@ -56,7 +62,13 @@ func (h *DiceHandler) RollDice(c *gin.Context) {
 		return
 	}
 
-	result, err := h.diceService.RollDice(req.Expression, userID.(int))
+	var result interface{}
+	var err error
+	if roll.IsBatch(req.Expression, req.Expressions) {
+		result, err = h.diceService.RollBatch(req, userID)
+	} else {
+		result, err = h.diceService.RollDice(req, userID)
+	}
 	if err != nil {
 		c.JSON(http.StatusBadRequest, models.ErrorResponse{
 			Error:   "Erro na rolagem",
@@ -68,9 +80,78 @@ func (h *DiceHandler) RollDice(c *gin.Context) {
 	c.JSON(http.StatusOK, result)
 }
 
+// AnalyzeDice calcula a distribuição de probabilidade de uma expressão
+// @Summary Analisar probabilidades
+// @Description Retorna mínimo, máximo, média, desvio padrão, histograma e P(total >= dificuldade) de uma expressão. Usa cálculo exato quando viável e Monte Carlo, com limite de erro declarado, para explosões e expressões muito grandes; o Monte Carlo usa menos amostras quando cada uma pode rolar muitos dados, e expressões pesadas demais ou com totais espalhados demais são rejeitadas
+// @Tags dice
+// @Accept json
+// @Produce json
+// @Param request body models.DiceAnalysisRequest true "Expressão a analisar"
+// @Security ApiKeyAuth
+// @Success 200 {object} models.DiceAnalysisResponse
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 401 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Router /api/v1/dice/analyze [post]
+func (h *DiceHandler) AnalyzeDice(c *gin.Context) {
+	var req models.DiceAnalysisRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error:   "Dados inválidos",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	userID, _, exists := middleware.GetUserFromContext(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, models.ErrorResponse{
+			Error:   "Não autorizado",
+			Message: "Token inválido",
+		})
+		return
+	}
+
+	// Dados personalizados do template e da mesa da ficha, se informada
+	var options *roll.RollOptions
+	if req.SheetID != "" {
+		sheet, err := h.playerSheetService.GetByID(req.SheetID, userID)
+		if err != nil {
+			c.JSON(http.StatusNotFound, models.ErrorResponse{
+				Error:   "Ficha não encontrada",
+				Message: "Ficha não existe ou não pertence ao usuário",
+			})
+			return
+		}
+
+		options, err = h.playerSheetService.RollOptions(sheet.TableID, sheet.TemplateID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+				Error:   "Erro interno",
+				Message: err.Error(),
+			})
+			return
+		}
+	}
+
+	result, err := h.diceService.AnalyzeExpression(req.Expression, req.Difficulty, options)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error:   "Erro na análise",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
 // RollWithSheet executa uma rolagem usando dados da ficha do personagem
 // @Summary Rolar dados com ficha
-// @Description Executa uma rolagem usando atributos da ficha do personagem
+// @Description Executa uma rolagem usando atributos da ficha do personagem. Cada referência {campo.aninhado} da expressão
+// @Description é resolvida com a ficha e aceita cálculos (e.g., 1d20+floor(({abilities.str}-10)/2)); a resposta traz a
+// @Description expressão resolvida e, em result_details, a original e o valor de cada referência. Como em /rolls, os itens
+// @Description equipados alteram os campos da ficha e somam seus modificadores, assim como as condições ativas da ficha
 // @Tags dice
 // @Accept json
 // @Produce json
@@ -92,7 +173,7 @@ func (h *DiceHandler) RollWithSheet(c *gin.Context) {
 		return
 	}
 
-	userID, exists := c.Get("userID")
+	userID, userEmail, exists := middleware.GetUserFromContext(c)
 	if !exists {
 		c.JSON(http.StatusUnauthorized, models.ErrorResponse{
 			Error:   "Não autorizado",
@@ -102,7 +183,7 @@ func (h *DiceHandler) RollWithSheet(c *gin.Context) {
 	}
 
 	// Verificar se a ficha existe e pertence ao usuário
-	sheet, err := h.playerSheetService.GetByID(req.SheetID, userID.(int))
+	sheet, err := h.playerSheetService.GetByID(req.SheetID, userID)
 	if err != nil {
 		c.JSON(http.StatusNotFound, models.ErrorResponse{
 			Error:   "Ficha não encontrada",
@@ -111,7 +192,26 @@ func (h *DiceHandler) RollWithSheet(c *gin.Context) {
 		return
 	}
 
-	result, err := h.diceService.RollWithSheet(req.Expression, req.AttributeField, sheet)
+	// Visibilidade da rolagem na mesa; o mestre vê as rolagens "gm" e "blind" dos jogadores
+	gmID, err := h.playerSheetService.TableOwner(sheet.TableID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Error:   "Erro interno",
+			Message: err.Error(),
+		})
+		return
+	}
+	req.Visibility, err = services.NormalizeRollVisibility(req.Visibility, sheet.OwnerID == gmID)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error:   "Dados inválidos",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	// Mesmo caminho das rolagens da ficha: definições de rolagem, itens equipados e condições
+	result, err := h.playerSheetService.RollWithSheet(req, sheet)
 	if err != nil {
 		c.JSON(http.StatusBadRequest, models.ErrorResponse{
 			Error:   "Erro na rolagem",
@@ -120,34 +220,56 @@ func (h *DiceHandler) RollWithSheet(c *gin.Context) {
 		return
 	}
 
+	redacted := result.Redacted()
+
 	// Notificar via WebSocket se há serviço de notificação configurado
 	if h.notificationService != nil {
-		userEmail, _ := c.Get("userEmail")
 		h.notificationService.NotifyRollPerformed(
 			sheet.TableID,
-			userID.(int),
-			userEmail.(string),
-			result,
+			userID,
+			userEmail,
+			RollRecipients(result.Visibility, result.UserID, gmID, result, redacted),
 		)
 	}
 
+	if _, full := models.RollAccess(result.Visibility, result.UserID, userID, gmID); !full {
+		c.JSON(http.StatusOK, redacted)
+		return
+	}
 	c.JSON(http.StatusOK, result)
 }
 
 // GetHistory recupera histórico de rolagens
 // @Summary Histórico de rolagens
-// @Description Recupera histórico de rolagens do usuário
+// @Description Recupera histórico de rolagens do usuário em todas as mesas, com filtros e ordenação. Filtros pelo resultado ignoram as rolagens "blind", cujo resultado o usuário não vê
 // @Tags dice
 // @Produce json
+// @Param table_id query string false "ID da mesa"
+// @Param sheet_id query string false "ID da ficha"
+// @Param from query string false "Início do período, inclusivo (RFC3339 ou AAAA-MM-DD)"
+// @Param to query string false "Fim do período, exclusivo; uma data sem hora inclui o dia inteiro"
+// @Param field_name query string false "Campo da ficha rolado"
+// @Param expression query string false "Trecho da expressão"
+// @Param critical query bool false "Apenas críticos (true) ou não críticos (false)"
+// @Param fumble query bool false "Apenas falhas críticas (true) ou não (false)"
+// @Param success query bool false "Resultado do teste contra a dificuldade"
+// @Param outcome query string false "Resultado nomeado do teste"
+// @Param label query string false "Trecho do rótulo"
+// @Param tag query []string false "Tag que a rolagem deve ter, com ou sem #; repita para exigir várias" collectionFormat(multi)
+// @Param min_result query int false "Resultado mínimo"
+// @Param max_result query int false "Resultado máximo"
+// @Param sort query string false "Ordenação: created_at ou result_value" default(created_at)
+// @Param order query string false "Ordem: asc ou desc" default(desc)
 // @Param page query int false "Página" default(1)
 // @Param limit query int false "Limite por página" default(10)
 // @Security ApiKeyAuth
 // @Success 200 {object} models.DiceHistoryResponse
+// @Failure 400 {object} models.ErrorResponse
 // @Failure 401 {object} models.ErrorResponse
 // @Failure 500 {object} models.ErrorResponse
 // @Router /api/v1/dice/history [get]
 func (h *DiceHandler) GetHistory(c *gin.Context) {
-	userID, exists := c.Get("userID")
+	userID, _, exists := middleware.GetUserFromContext(c)
 	if !exists {
 		c.JSON(http.StatusUnauthorized, models.ErrorResponse{
 			Error:   "Não autorizado",
@@ -156,17 +278,25 @@ func (h *DiceHandler) GetHistory(c *gin.Context) {
 		return
 	}
 
-	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
-	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "10"))
-
-	if page < 1 {
-		page = 1
+	var query models.RollHistoryQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error:   "Filtros inválidos",
+			Message: err.Error(),
+		})
+		return
 	}
-	if limit < 1 || limit > 100 {
-		limit = 10
+	filter, sort, err := query.Filter()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error:   "Filtros inválidos",
+			Message: err.Error(),
+		})
+		return
 	}
+	page, limit := query.Pagination(10)
 
-	rolls, total, err := h.diceService.GetUserHistory(userID.(int), page, limit)
+	rolls, total, err := h.diceService.GetUserHistory(userID, filter, sort, page, limit)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
 			Error:   "Erro interno",
@@ -176,10 +306,230 @@ func (h *DiceHandler) GetHistory(c *gin.Context) {
 	}
 
 	c.JSON(http.StatusOK, gin.H{
-		"message": "Histórico de rolagens",
-		"rolls":   rolls,
-		"total":   total,
-		"page":    page,
-		"limit":   limit,
+		"message":     "Histórico de rolagens",
+		"rolls":       rolls,
+		"total":       total,
+		"page":        page,
+		"limit":       limit,
+		"total_pages": (total + limit - 1) / limit,
 	})
 }
+
+// GetSeed retorna o compromisso da semente usada nas próximas rolagens
+// @Summary Semente ativa
+// @Description Retorna o hash (compromisso) da semente do servidor usada nas próximas rolagens do usuário e o próximo nonce
+// @Tags dice
+// @Produce json
+// @Security ApiKeyAuth
+// @Success 200 {object} models.RollSeedResponse
+// @Failure 401 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Router /api/v1/dice/seed [get]
+func (h *DiceHandler) GetSeed(c *gin.Context) {
+	userID, _, exists := middleware.GetUserFromContext(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, models.ErrorResponse{
+			Error:   "Não autorizado",
+			Message: "Token inválido",
+		})
+		return
+	}
+
+	seed, err := h.fairnessService.GetActiveSeed(userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Error:   "Erro interno",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, seed)
+}
+
+// VerifyRoll verifica publicamente uma rolagem
+// @Summary Verificar rolagem
+// @Description Revela a semente do servidor usada na rolagem e recalcula os dados a partir dela. A semente revelada deixa de ser usada em novas rolagens. Apenas rolagens públicas podem ser verificadas; rolagens ocultas usam outra semente, nunca revelada, e sementes anteriores que também cobrem rolagens ocultas não são reveladas. Os dados são recalculados com as regras de rolagem gravadas com a rolagem (rules), mesmo que o template ou a mesa tenham mudado depois
+// @Tags dice
+// @Produce json
+// @Param rollID path string true "ID da rolagem"
+// @Success 200 {object} models.RollVerificationResponse
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 403 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Failure 409 {object} models.ErrorResponse "Semente também usada em rolagens ocultas"
+// @Failure 500 {object} models.ErrorResponse
+// @Router /api/v1/dice/verify/{rollID} [get]
+func (h *DiceHandler) VerifyRoll(c *gin.Context) {
+	rollRecord, err := h.fairnessService.GetRoll(c.Param("rollID"))
+	if err != nil {
+		status := http.StatusInternalServerError
+		switch err.Error() {
+		case "rolagem não encontrada":
+			status = http.StatusNotFound
+		case "rolagem não pública":
+			status = http.StatusForbidden
+		}
+		c.JSON(status, models.ErrorResponse{
+			Error:   "Erro ao verificar rolagem",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	// Definições atuais da ficha, usadas apenas por rolagens anteriores às regras gravadas com a rolagem
+	options, err := h.playerSheetService.RollOptionsForSheet(rollRecord.SheetID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Error:   "Erro interno",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	verification, err := h.fairnessService.Verify(rollRecord, options)
+	if err != nil {
+		status := http.StatusInternalServerError
+		switch err.Error() {
+		case "rolagem sem dados de verificação":
+			status = http.StatusBadRequest
+		case "a semente desta rolagem também cobre rolagens ocultas e não pode ser revelada":
+			status = http.StatusConflict
+		}
+		c.JSON(status, models.ErrorResponse{
+			Error:   "Erro ao verificar rolagem",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, verification)
+}
+
+// ContestRoll executa uma rolagem resistida entre duas fichas
+// @Summary Rolagem resistida
+// @Description Rola as fichas do atacante e do defensor, cada uma com as definições de rolagem do seu template e da mesa, e decide o vencedor pela regra de desempate (defender, attacker ou none)
+// @Tags dice
+// @Accept json
+// @Produce json
+// @Param request body models.CreateRollContestRequest true "Lados da disputa"
+// @Security ApiKeyAuth
+// @Success 200 {object} models.RollContestResponse
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 401 {object} models.ErrorResponse
+// @Failure 403 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Router /api/v1/dice/contest [post]
+func (h *DiceHandler) ContestRoll(c *gin.Context) {
+	var req models.CreateRollContestRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error:   "Dados inválidos",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	userID, userEmail, exists := middleware.GetUserFromContext(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, models.ErrorResponse{
+			Error:   "Não autorizado",
+			Message: "Token inválido",
+		})
+		return
+	}
+
+	contest, err := h.contestService.Create(req, userID)
+	if err != nil {
+		status := http.StatusBadRequest
+		switch err.Error() {
+		case "ficha não encontrada":
+			status = http.StatusNotFound
+		case "acesso negado à mesa":
+			status = http.StatusForbidden
+		}
+		c.JSON(status, models.ErrorResponse{
+			Error:   "Erro na disputa",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	gmID, err := h.playerSheetService.TableOwner(contest.TableID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Error:   "Erro interno",
+			Message: err.Error(),
+		})
+		return
+	}
+	visibility := contest.Attacker.Visibility
+	redacted := contest.Redacted()
+
+	// Uma única notificação com as duas rolagens e o vencedor
+	if h.notificationService != nil {
+		h.notificationService.NotifyRollContested(contest.TableID, userID, userEmail, RollRecipients(visibility, userID, gmID, contest, redacted))
+	}
+
+	if _, full := models.RollAccess(visibility, userID, userID, gmID); !full {
+		c.JSON(http.StatusOK, redacted)
+		return
+	}
+	c.JSON(http.StatusOK, contest)
+}
+
+// GetContest busca uma rolagem resistida
+// @Summary Buscar rolagem resistida
+// @Description Retorna a disputa com as rolagens do atacante e do defensor
+// @Tags dice
+// @Produce json
+// @Param contestID path string true "ID da disputa"
+// @Security ApiKeyAuth
+// @Success 200 {object} models.RollContestResponse
+// @Failure 401 {object} models.ErrorResponse
+// @Failure 403 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Router /api/v1/dice/contest/{contestID} [get]
+func (h *DiceHandler) GetContest(c *gin.Context) {
+	userID, _, exists := middleware.GetUserFromContext(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, models.ErrorResponse{
+			Error:   "Não autorizado",
+			Message: "Token inválido",
+		})
+		return
+	}
+
+	contest, err := h.contestService.GetByID(c.Param("contestID"), userID)
+	if err != nil {
+		status := http.StatusInternalServerError
+		switch err.Error() {
+		case "disputa não encontrada":
+			status = http.StatusNotFound
+		case "acesso negado à mesa":
+			status = http.StatusForbidden
+		}
+		c.JSON(status, models.ErrorResponse{
+			Error:   "Erro ao buscar disputa",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, contest)
+}
+
+// RollRecipients escolhe, para cada destinatário da mesa, a rolagem completa, a rolagem sem resultado ou nada
+func RollRecipients(visibility string, rollerID, gmID int, full, redacted interface{}) interfaces.RecipientData {
+	return func(userID int) (interface{}, bool) {
+		visible, seesResult := models.RollAccess(visibility, rollerID, userID, gmID)
+		if !visible {
+			return nil, false
+		}
+		if !seesResult {
+			return redacted, true
+		}
+		return full, true
+	}
+}
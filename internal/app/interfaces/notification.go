@@ -1,5 +1,8 @@
 package interfaces
 
+// RecipientData retorna os dados de uma notificação para o usuário destinatário, ou false se ele não deve recebê-la
+type RecipientData func(userID int) (interface{}, bool)
+
 // NotificationService define interface para notificações em tempo real
 type NotificationService interface {
 	// Notificações de convites
@@ -11,10 +14,19 @@ type NotificationService interface {
 	NotifySheetCreated(tableID string, userID int, userEmail string, sheetData interface{})
 	NotifySheetUpdated(tableID string, userID int, userEmail string, sheetData interface{})
 	NotifySheetDeleted(tableID string, userID int, userEmail string, sheetData interface{})
+	NotifySheetDelta(tableID string, userID int, userEmail string, deltaData interface{})
 
-	// Notificações de rolagens
-	NotifyRollPerformed(tableID string, userID int, userEmail string, rollData interface{})
+	// Notificações de rolagens, filtradas por destinatário conforme a visibilidade
+	NotifyRollPerformed(tableID string, userID int, userEmail string, rollData RecipientData)
+	NotifyRollContested(tableID string, userID int, userEmail string, contestData RecipientData)
+	NotifyRollBatch(tableID string, userID int, userEmail string, batchData RecipientData)
+	NotifyRollVoided(tableID string, userID int, userEmail string, rollData RecipientData)
+	NotifyRollReaction(tableID string, userID int, userEmail string, reactionData RecipientData)
 
 	// Notificações de mesa
 	NotifyTableUpdated(tableID string, userID int, userEmail string, tableData interface{})
+	NotifyEncounterUpdated(tableID string, userID int, userEmail string, encounterData interface{})
+	NotifyConditionUpdated(tableID string, userID int, userEmail string, conditionData interface{})
+	NotifyInventoryUpdated(tableID string, userID int, userEmail string, inventoryData interface{})
+	NotifySheetRested(tableID string, userID int, userEmail string, restData interface{})
 }
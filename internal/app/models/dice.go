@@ -1,34 +1,106 @@
 package models
 
 import (
+	"encoding/json"
 	"time"
+
+	"github.com/google/uuid"
 )
 
 // DiceRollRequest representa uma solicitação de rolagem de dados
 type DiceRollRequest struct {
-	Expression string `json:"expression" binding:"required" example:"1d20+3"`
-	Comment    string `json:"comment,omitempty" example:"Teste de Força"`
+	Expression  string   `json:"expression" binding:"required_without=Expressions" example:"1d20+3"`
+	Expressions []string `json:"expressions,omitempty" binding:"omitempty,max=20,dive,max=200"` // Lote: cada expressão é rolada de forma independente
+	Comment     string   `json:"comment,omitempty" example:"Teste de Força"`                    // Obsoleto: use label
+	ClientSeed  string   `json:"client_seed,omitempty" binding:"omitempty,max=64" example:"minha-semente"`
+	RollCheck
+	RollLabel
 }
 
 // DiceRollWithSheetRequest representa uma rolagem usando dados da ficha
 type DiceRollWithSheetRequest struct {
 	SheetID        string `json:"sheet_id" binding:"required" example:"1"`
-	Expression     string `json:"expression" binding:"required" example:"1d20+{strength}"`
-	AttributeField string `json:"attribute_field,omitempty" example:"strength"`
-	Comment        string `json:"comment,omitempty" example:"Teste de Força com modificador da ficha"`
+	Expression     string `json:"expression" binding:"required" example:"1d20+{abilities.dex_mod}+{proficiency}"`
+	AttributeField string `json:"attribute_field,omitempty" example:"strength"`                        // Obsoleto: todas as referências da expressão são resolvidas
+	Comment        string `json:"comment,omitempty" example:"Teste de Força com modificador da ficha"` // Obsoleto: use label
+	ClientSeed     string `json:"client_seed,omitempty" binding:"omitempty,max=64" example:"minha-semente"`
+	Visibility     string `json:"visibility,omitempty" example:"public"`
+	RollCheck
+	RollLabel
 }
 
 // DiceRollResponse representa o resultado de uma rolagem
 type DiceRollResponse struct {
-	ID         string    `json:"id" example:"550e8400-e29b-41d4-a716-446655440000"`
-	Expression string    `json:"expression" example:"1d20+3"`
-	Result     int       `json:"result" example:"18"`
-	Details    string    `json:"details" example:"[15] + 3 = 18"`
-	IsCritical bool      `json:"is_critical" example:"false"`
-	IsFumble   bool      `json:"is_fumble" example:"false"`
-	SheetID    *string   `json:"sheet_id,omitempty" example:"550e8400-e29b-41d4-a716-446655440000"`
-	UserID     int       `json:"user_id" example:"1"`
-	CreatedAt  time.Time `json:"created_at" example:"2024-01-01T10:00:00Z"`
+	ID            string         `json:"id" example:"550e8400-e29b-41d4-a716-446655440000"`
+	Expression    string         `json:"expression" example:"1d20+3"`
+	Result        int            `json:"result" example:"18"`
+	Details       string         `json:"details" example:"[15] +3 = 18"`
+	ResultDetails *RollDetails   `json:"result_details,omitempty"`
+	IsCritical    bool           `json:"is_critical" example:"false"`
+	IsFumble      bool           `json:"is_fumble" example:"false"`
+	IsBotch       bool           `json:"is_botch" example:"false"`
+	Successes     *int           `json:"successes,omitempty" example:"3"`
+	Failures      *int           `json:"failures,omitempty" example:"1"`
+	Outcome       string         `json:"outcome,omitempty" example:"sucesso parcial"`
+	Success       *bool          `json:"success,omitempty" example:"true"`
+	Difficulty    *int           `json:"difficulty,omitempty" example:"15"`
+	Visibility    string         `json:"visibility" example:"public"`
+	Hidden        bool           `json:"hidden,omitempty" example:"false"`
+	Fairness      *RollFairness  `json:"fairness,omitempty"`
+	Void          *RollVoid      `json:"void,omitempty"` // Presente em rolagens anuladas
+	Label         string         `json:"label,omitempty" example:"Ataque furtivo no guarda"`
+	Tags          []string       `json:"tags,omitempty" example:"attack,stealth"`
+	Reactions     []RollReaction `json:"reactions,omitempty"`
+	SheetID       *string        `json:"sheet_id,omitempty" example:"550e8400-e29b-41d4-a716-446655440000"`
+	TableID       *string        `json:"table_id,omitempty" example:"550e8400-e29b-41d4-a716-446655440000"`
+	UserID        int            `json:"user_id" example:"1"`
+	CreatedAt     time.Time      `json:"created_at" example:"2024-01-01T10:00:00Z"`
+}
+
+// Redacted retorna uma cópia da rolagem sem o resultado, para quem não pode vê-lo (e.g., rolagens "blind")
+func (r DiceRollResponse) Redacted() DiceRollResponse {
+	r.Result = 0
+	r.Details = ""
+	r.ResultDetails = nil
+	r.IsCritical = false
+	r.IsFumble = false
+	r.IsBotch = false
+	r.Successes = nil
+	r.Failures = nil
+	r.Outcome = ""
+	r.Success = nil
+	r.Fairness = nil // Semente do cliente e nonce permitiriam recalcular o resultado
+	r.Hidden = true
+	return r
+}
+
+// DiceAnalysisRequest representa uma solicitação de análise de probabilidade
+type DiceAnalysisRequest struct {
+	Expression string `json:"expression" binding:"required" example:"3d6+2"`
+	Difficulty *int   `json:"difficulty,omitempty" example:"14"`
+	SheetID    string `json:"sheet_id,omitempty" example:"550e8400-e29b-41d4-a716-446655440000"` // Ficha cujos dados personalizados (template e mesa) ficam disponíveis
+}
+
+// DiceAnalysisResponse representa a distribuição de probabilidade do total de uma expressão
+type DiceAnalysisResponse struct {
+	Expression    string               `json:"expression" example:"3d6+2"`
+	Method        string               `json:"method" example:"exact"`            // "exact" ou "monte_carlo"
+	Samples       int                  `json:"samples,omitempty" example:"50000"` // Amostras do Monte Carlo
+	ErrorBound    float64              `json:"error_bound" example:"0"`           // Erro máximo das probabilidades com 95% de confiança
+	Min           int                  `json:"min" example:"5"`
+	Max           int                  `json:"max" example:"20"`
+	Mean          float64              `json:"mean" example:"12.5"`
+	StdDev        float64              `json:"std_dev" example:"2.958"`
+	Histogram     []DistributionBucket `json:"histogram"`
+	Difficulty    *int                 `json:"difficulty,omitempty" example:"14"`
+	SuccessChance *float64             `json:"success_chance,omitempty" example:"0.375"` // P(total >= difficulty)
+}
+
+// DistributionBucket representa a probabilidade de um total
+type DistributionBucket struct {
+	Value       int     `json:"value" example:"12"`
+	Probability float64 `json:"probability" example:"0.125"`
+	AtLeast     float64 `json:"at_least" example:"0.5"` // P(total >= value)
 }
 
 // DiceHistoryResponse representa o histórico de rolagens
@@ -39,3 +111,144 @@ type DiceHistoryResponse struct {
 	Limit      int                `json:"limit" example:"10"`
 	TotalPages int                `json:"total_pages" example:"5"`
 }
+
+// CustomDie representa um dado personalizado registrado na mesa ou no template
+// (e.g., dados narrativos do Genesys)
+type CustomDie struct {
+	Name  string    `json:"name" example:"boost"`
+	Faces []DieFace `json:"faces"`
+}
+
+// DieFace representa uma face de dado personalizado, com valor numérico e/ou símbolos
+type DieFace struct {
+	Value   int      `json:"value,omitempty" example:"1"`
+	Symbols []string `json:"symbols,omitempty" example:"success,advantage"`
+}
+
+// RollRules representa as regras de rolagem declaradas por uma mesa ou template
+type RollRules struct {
+	CustomDice []CustomDie `json:"custom_dice,omitempty"`
+	Critical   *CritRule   `json:"critical,omitempty"` // Sem regra, vale o 20 natural em 1d20
+	Fumble     *CritRule   `json:"fumble,omitempty"`   // Sem regra, vale o 1 natural em 1d20
+
+	// Tabelas de resultados por nome; "default" é usada quando a rolagem informa apenas a dificuldade
+	Outcomes map[string]OutcomeTable `json:"outcomes,omitempty"`
+}
+
+// CritRule define a faixa de valores naturais de um termo de dados que conta como crítico ou falha crítica
+// (e.g., {"dice": "1d20", "min": 19} para Crítico Aprimorado, {"dice": "d%", "max": 5} para 01-05)
+type CritRule struct {
+	Dice string `json:"dice" example:"1d20"`        // Termo avaliado pela quantidade de dados mantidos e lados (e.g., "1d20", "2d6", "d%")
+	Min  *int   `json:"min,omitempty" example:"19"` // Soma natural mínima, inclusiva
+	Max  *int   `json:"max,omitempty" example:"20"` // Soma natural máxima, inclusiva
+}
+
+// Bases de comparação das faixas de resultado
+const (
+	OutcomeBasisTotal   = "total"   // Total da rolagem (e.g., PbtA: 6-, 7-9, 10+)
+	OutcomeBasisMargin  = "margin"  // Total menos a dificuldade (e.g., PF2e: ±10)
+	OutcomeBasisPercent = "percent" // Total em porcentagem da dificuldade, para rolagens "abaixo de" (e.g., CoC: 20/50/100)
+)
+
+// OutcomeTable representa faixas nomeadas de resultado de um teste, da melhor para a pior
+type OutcomeTable struct {
+	Basis          string        `json:"basis,omitempty" example:"margin"`          // "total" (padrão), "margin" ou "percent"
+	StepOnCritical bool          `json:"step_on_critical,omitempty" example:"true"` // Crítico sobe uma faixa e falha crítica desce uma (e.g., 20/1 naturais no PF2e)
+	Bands          []OutcomeBand `json:"bands"`
+}
+
+// OutcomeBand representa uma faixa de resultado; a primeira faixa que contém o valor é escolhida
+type OutcomeBand struct {
+	Name    string `json:"name" example:"sucesso parcial"`
+	Min     *int   `json:"min,omitempty" example:"7"` // Limite inferior inclusivo; vazio = sem limite
+	Max     *int   `json:"max,omitempty" example:"9"` // Limite superior inclusivo; vazio = sem limite
+	Success bool   `json:"success" example:"true"`    // Se a faixa conta como sucesso
+}
+
+// RollCheck representa a dificuldade e a tabela de resultados pedidas em uma rolagem
+type RollCheck struct {
+	Difficulty   *int          `json:"difficulty,omitempty" example:"15"`
+	OutcomeTable string        `json:"outcome_table,omitempty" example:"move"` // Nome de uma tabela declarada no template ou na mesa
+	Outcomes     *OutcomeTable `json:"outcomes,omitempty"`                     // Tabela informada na própria rolagem
+}
+
+// RollOutcome representa o resultado nomeado de um teste
+type RollOutcome struct {
+	Name       string `json:"name" example:"sucesso parcial"`
+	Success    bool   `json:"success" example:"true"`
+	Difficulty *int   `json:"difficulty,omitempty" example:"15"`
+}
+
+// ParseRollRules extrai as regras de rolagem de um JSON (settings da mesa ou definition do template)
+func ParseRollRules(data string) (*RollRules, error) {
+	rules := &RollRules{}
+	if data == "" {
+		return rules, nil
+	}
+	if err := json.Unmarshal([]byte(data), rules); err != nil {
+		return nil, err
+	}
+	return rules, nil
+}
+
+// RollSeed representa a semente secreta do servidor usada nas rolagens verificáveis de um usuário
+type RollSeed struct {
+	ID             string     `db:"id"`
+	UserID         int        `db:"user_id"`
+	ServerSeed     string     `db:"server_seed"`
+	ServerSeedHash string     `db:"server_seed_hash"`
+	NextNonce      int        `db:"next_nonce"`
+	Hidden         bool       `db:"hidden"` // Usada apenas em rolagens ocultas (gm, blind e self), nunca revelada
+	RevealedAt     *time.Time `db:"revealed_at"`
+	CreatedAt      time.Time  `db:"created_at"`
+}
+
+// NewRollSeed cria uma nova semente ativa para as rolagens públicas ou ocultas do usuário
+func NewRollSeed(userID int, hidden bool, serverSeed, serverSeedHash string) *RollSeed {
+	return &RollSeed{
+		ID:             uuid.New().String(),
+		UserID:         userID,
+		Hidden:         hidden,
+		ServerSeed:     serverSeed,
+		ServerSeedHash: serverSeedHash,
+		CreatedAt:      time.Now(),
+	}
+}
+
+// RollFairness representa os dados publicados para verificação de uma rolagem
+type RollFairness struct {
+	ServerSeedHash string `json:"server_seed_hash" example:"2cf24dba5fb0a30e26e83b2ac5b9e29e1b161e5c1fa7425e73043362938b9824"`
+	ClientSeed     string `json:"client_seed" example:"minha-semente"`
+	Nonce          int    `json:"nonce" example:"0"`
+}
+
+// RollRuleSnapshot representa as regras usadas em uma rolagem verificável, gravadas com ela para que a verificação
+// a recalcule com as mesmas regras mesmo depois que o mestre alterar o template ou a mesa. Das tabelas de
+// resultados, guarda apenas a usada no teste
+type RollRuleSnapshot struct {
+	RollRules
+	Check *RollCheck `json:"check,omitempty"`
+}
+
+// RollSeedResponse representa o compromisso da semente ativa do usuário
+type RollSeedResponse struct {
+	ServerSeedHash string    `json:"server_seed_hash" example:"2cf24dba5fb0a30e26e83b2ac5b9e29e1b161e5c1fa7425e73043362938b9824"`
+	NextNonce      int       `json:"next_nonce" example:"0"`
+	CreatedAt      time.Time `json:"created_at" example:"2024-01-01T10:00:00Z"`
+}
+
+// RollVerificationResponse representa a verificação pública de uma rolagem
+type RollVerificationResponse struct {
+	RollID         string            `json:"roll_id" example:"550e8400-e29b-41d4-a716-446655440000"`
+	Expression     string            `json:"expression" example:"1d20+3"`
+	ServerSeed     string            `json:"server_seed" example:"9f86d081884c7d659a2feaa0c55ad015a3bf4f1b2b0b822cd15d6c15b0f00a08"`
+	ServerSeedHash string            `json:"server_seed_hash" example:"2cf24dba5fb0a30e26e83b2ac5b9e29e1b161e5c1fa7425e73043362938b9824"`
+	ClientSeed     string            `json:"client_seed" example:"minha-semente"`
+	Nonce          int               `json:"nonce" example:"0"`
+	HashMatches    bool              `json:"hash_matches" example:"true"` // Se a semente revelada corresponde ao compromisso publicado
+	DiceMatch      bool              `json:"dice_match" example:"true"`   // Se os dados recalculados são iguais aos salvos
+	Verified       bool              `json:"verified" example:"true"`
+	Stored         *RollDetails      `json:"stored"`
+	Recomputed     *RollDetails      `json:"recomputed"`
+	Rules          *RollRuleSnapshot `json:"rules,omitempty"` // Regras gravadas com a rolagem e usadas no recálculo
+}
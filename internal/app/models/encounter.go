@@ -0,0 +1,227 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Estados de um encontro
+const (
+	EncounterStatusPreparing = "preparing" // Montando participantes e iniciativas
+	EncounterStatusActive    = "active"    // Em combate: rodada e turno correndo
+	EncounterStatusEnded     = "ended"
+)
+
+// Regras de desempate da iniciativa; em qualquer regra, persistindo o empate decide o maior modificador
+// e, por fim, um sorteio feito ao definir a iniciativa
+const (
+	EncounterTieBreakModifier     = "modifier"      // Maior modificador de iniciativa (e.g., D&D)
+	EncounterTieBreakPlayersFirst = "players_first" // Jogadores antes dos NPCs
+	EncounterTieBreakNPCsFirst    = "npcs_first"    // NPCs antes dos jogadores (e.g., PF2e)
+)
+
+// Tipos de participante
+const (
+	ParticipantKindPlayer = "player"
+	ParticipantKindNPC    = "npc"
+)
+
+// Estados de um participante na ordem de turnos
+const (
+	ParticipantStatusActive  = "active"
+	ParticipantStatusDelayed = "delayed" // Adiou o turno: é pulado até agir
+	ParticipantStatusReady   = "ready"   // Preparou uma ação para um gatilho; expira no próximo turno dele
+)
+
+// Ações de um encontro, enviadas nas notificações
+const (
+	EncounterActionCreated            = "created"
+	EncounterActionUpdated            = "updated"
+	EncounterActionDeleted            = "deleted"
+	EncounterActionParticipantAdded   = "participant_added"
+	EncounterActionParticipantRemoved = "participant_removed"
+	EncounterActionInitiative         = "initiative"
+	EncounterActionStarted            = "started"
+	EncounterActionNext               = "next"
+	EncounterActionPrevious           = "previous"
+	EncounterActionDelay              = "delay"
+	EncounterActionReady              = "ready"
+	EncounterActionAct                = "act"
+	EncounterActionEnded              = "ended"
+)
+
+// Encounter representa um encontro de combate de uma mesa
+type Encounter struct {
+	ID                   string    `json:"id" db:"id"`
+	TableID              string    `json:"table_id" db:"table_id"`
+	Name                 string    `json:"name" db:"name"`
+	Status               string    `json:"status" db:"status"`
+	TieBreak             string    `json:"tie_break" db:"tie_break"`
+	Round                int       `json:"round" db:"round"`
+	TickedRound          int       `json:"-" db:"ticked_round"` // Última rodada já descontada das condições das fichas
+	CurrentParticipantID *string   `json:"current_participant_id" db:"current_participant_id"`
+	Version              int       `json:"version" db:"version"`
+	CreatedBy            int       `json:"created_by" db:"created_by"`
+	CreatedAt            time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt            time.Time `json:"updated_at" db:"updated_at"`
+}
+
+// EncounterParticipant representa uma ficha de jogador ou NPC no encontro
+type EncounterParticipant struct {
+	ID                 string    `json:"id" db:"id"`
+	EncounterID        string    `json:"encounter_id" db:"encounter_id"`
+	SheetID            *string   `json:"sheet_id" db:"sheet_id"`
+	UserID             int       `json:"user_id" db:"user_id"` // Quem controla: dono da ficha ou mestre
+	Name               string    `json:"name" db:"name"`
+	Kind               string    `json:"kind" db:"kind"`
+	Initiative         *int      `json:"initiative" db:"initiative"`
+	InitiativeModifier int       `json:"initiative_modifier" db:"initiative_modifier"`
+	Tiebreaker         int       `json:"-" db:"tiebreaker"`
+	RollID             *string   `json:"roll_id" db:"roll_id"`
+	Position           int       `json:"position" db:"position"`
+	Status             string    `json:"status" db:"status"`
+	ReadyTrigger       *string   `json:"ready_trigger" db:"ready_trigger"`
+	CreatedAt          time.Time `json:"created_at" db:"created_at"`
+}
+
+// CreateEncounterRequest representa dados para criação de encontro
+type CreateEncounterRequest struct {
+	Name     string `json:"name" binding:"required,max=100" example:"Emboscada na estrada"`
+	TieBreak string `json:"tie_break,omitempty" example:"modifier"` // modifier (padrão), players_first ou npcs_first
+}
+
+// UpdateEncounterRequest representa dados para atualização de encontro
+type UpdateEncounterRequest struct {
+	Name     *string `json:"name,omitempty" binding:"omitempty,max=100" example:"Emboscada na estrada"`
+	TieBreak *string `json:"tie_break,omitempty" example:"npcs_first"`
+}
+
+// AddParticipantRequest representa a entrada de uma ficha (sheet_id) ou de um NPC (name) no encontro
+type AddParticipantRequest struct {
+	SheetID    string `json:"sheet_id,omitempty" example:"550e8400-e29b-41d4-a716-446655440000"`
+	Name       string `json:"name,omitempty" binding:"omitempty,max=100" example:"Goblin 1"` // Padrão: nome da ficha
+	Initiative *int   `json:"initiative,omitempty" example:"14"`                             // Iniciativa informada manualmente
+	Modifier   *int   `json:"modifier,omitempty" example:"2"`
+}
+
+// InitiativeRequest representa a iniciativa de um participante: informada (value) ou rolada com uma
+// expressão ou campo da ficha (e.g., "1d20+{abilities.dex_mod}")
+type InitiativeRequest struct {
+	Value      *int   `json:"value,omitempty" example:"17"`
+	Modifier   *int   `json:"modifier,omitempty" example:"3"` // Padrão ao rolar: o modificador da expressão
+	Expression string `json:"expression,omitempty" binding:"omitempty,max=200" example:"1d20+{abilities.dex_mod}"`
+	FieldName  string `json:"field_name,omitempty" binding:"omitempty,max=100" example:"initiative"`
+	ClientSeed string `json:"client_seed,omitempty" binding:"omitempty,max=64" example:"minha-semente"`
+	Visibility string `json:"visibility,omitempty" example:"public"`
+}
+
+// ReadyRequest representa a ação preparada de um participante e o gatilho dela
+type ReadyRequest struct {
+	Trigger string `json:"trigger" binding:"required,max=300" example:"Ataco quem passar pela porta"`
+}
+
+// EncounterResponse representa o encontro com os participantes na ordem dos turnos
+type EncounterResponse struct {
+	ID                   string                         `json:"id"`
+	TableID              string                         `json:"table_id"`
+	Name                 string                         `json:"name" example:"Emboscada na estrada"`
+	Status               string                         `json:"status" example:"active"`
+	TieBreak             string                         `json:"tie_break" example:"modifier"`
+	Round                int                            `json:"round" example:"2"`
+	CurrentParticipantID *string                        `json:"current_participant_id"`
+	Version              int                            `json:"version" example:"7"`
+	CreatedBy            int                            `json:"created_by"`
+	Participants         []*EncounterParticipantSummary `json:"participants"`
+	CreatedAt            time.Time                      `json:"created_at"`
+	UpdatedAt            time.Time                      `json:"updated_at"`
+}
+
+// EncounterParticipantSummary representa um participante na ordem dos turnos
+type EncounterParticipantSummary struct {
+	ID                 string  `json:"id"`
+	SheetID            *string `json:"sheet_id,omitempty"`
+	UserID             int     `json:"user_id"`
+	Name               string  `json:"name" example:"Goblin 1"`
+	Kind               string  `json:"kind" example:"npc"`
+	Initiative         *int    `json:"initiative" example:"14"`
+	InitiativeModifier int     `json:"initiative_modifier" example:"2"`
+	RollID             *string `json:"roll_id,omitempty"`
+	Position           int     `json:"position" example:"0"`
+	Status             string  `json:"status" example:"active"`
+	ReadyTrigger       *string `json:"ready_trigger,omitempty"`
+	Current            bool    `json:"current" example:"false"`
+}
+
+// EncounterEvent representa uma alteração do encontro notificada à mesa
+type EncounterEvent struct {
+	Action        string             `json:"action" example:"next"`
+	ParticipantID *string            `json:"participant_id,omitempty"`
+	Encounter     *EncounterResponse `json:"encounter"`
+
+	ExpiredConditions *ConditionEvent `json:"-"` // Condições das fichas que expiraram com a nova rodada
+}
+
+// NewEncounter cria um novo encontro na mesa
+func NewEncounter(tableID string, createdBy int, name, tieBreak string) *Encounter {
+	now := time.Now()
+	return &Encounter{
+		ID:        uuid.New().String(),
+		TableID:   tableID,
+		Name:      name,
+		Status:    EncounterStatusPreparing,
+		TieBreak:  tieBreak,
+		CreatedBy: createdBy,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+}
+
+// NewEncounterParticipant cria um participante do encontro, sem posição na ordem dos turnos
+func NewEncounterParticipant(encounterID string, sheetID *string, userID int, name, kind string) *EncounterParticipant {
+	return &EncounterParticipant{
+		ID:          uuid.New().String(),
+		EncounterID: encounterID,
+		SheetID:     sheetID,
+		UserID:      userID,
+		Name:        name,
+		Kind:        kind,
+		Status:      ParticipantStatusActive,
+		CreatedAt:   time.Now(),
+	}
+}
+
+// ToResponse converte o encontro para a resposta da API, com os participantes já ordenados
+func (e *Encounter) ToResponse(participants []*EncounterParticipant) *EncounterResponse {
+	response := &EncounterResponse{
+		ID:                   e.ID,
+		TableID:              e.TableID,
+		Name:                 e.Name,
+		Status:               e.Status,
+		TieBreak:             e.TieBreak,
+		Round:                e.Round,
+		CurrentParticipantID: e.CurrentParticipantID,
+		Version:              e.Version,
+		CreatedBy:            e.CreatedBy,
+		Participants:         make([]*EncounterParticipantSummary, len(participants)),
+		CreatedAt:            e.CreatedAt,
+		UpdatedAt:            e.UpdatedAt,
+	}
+	for i, participant := range participants {
+		response.Participants[i] = &EncounterParticipantSummary{
+			ID:                 participant.ID,
+			SheetID:            participant.SheetID,
+			UserID:             participant.UserID,
+			Name:               participant.Name,
+			Kind:               participant.Kind,
+			Initiative:         participant.Initiative,
+			InitiativeModifier: participant.InitiativeModifier,
+			RollID:             participant.RollID,
+			Position:           participant.Position,
+			Status:             participant.Status,
+			ReadyTrigger:       participant.ReadyTrigger,
+			Current:            e.CurrentParticipantID != nil && *e.CurrentParticipantID == participant.ID,
+		}
+	}
+	return response
+}
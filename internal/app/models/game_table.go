@@ -1,6 +1,7 @@
 package models
 
 import (
+	"encoding/json"
 	"time"
 
 	"github.com/google/uuid"
@@ -12,10 +13,16 @@ type GameTable struct {
 	Name      string    `json:"name" db:"name" validate:"required,min=3,max=100"`
 	System    string    `json:"system" db:"system" validate:"required,min=2,max=50"`
 	OwnerID   int       `json:"owner_id" db:"owner_id"`
+	Settings  string    `json:"-" db:"settings"` // JSON como string no banco
 	CreatedAt time.Time `json:"created_at" db:"created_at"`
 	UpdatedAt time.Time `json:"updated_at" db:"updated_at"`
 }
 
+// TableSettings representa as configurações da mesa
+type TableSettings struct {
+	RollRules
+}
+
 // Invite representa um convite para uma mesa de jogo
 type Invite struct {
 	ID        string    `json:"id" db:"id"`
@@ -31,14 +38,16 @@ type Invite struct {
 
 // CreateGameTableRequest para criação de mesa
 type CreateGameTableRequest struct {
-	Name   string `json:"name" validate:"required,min=3,max=100"`
-	System string `json:"system" validate:"required,min=2,max=50"`
+	Name     string         `json:"name" validate:"required,min=3,max=100"`
+	System   string         `json:"system" validate:"required,min=2,max=50"`
+	Settings *TableSettings `json:"settings,omitempty"`
 }
 
 // UpdateGameTableRequest para atualização de mesa
 type UpdateGameTableRequest struct {
-	Name   string `json:"name,omitempty" validate:"omitempty,min=3,max=100"`
-	System string `json:"system,omitempty" validate:"omitempty,min=2,max=50"`
+	Name     string         `json:"name,omitempty" validate:"omitempty,min=3,max=100"`
+	System   string         `json:"system,omitempty" validate:"omitempty,min=2,max=50"`
+	Settings *TableSettings `json:"settings,omitempty"`
 }
 
 // GameTableResponse resposta detalhada da mesa com invites
@@ -48,6 +57,7 @@ type GameTableResponse struct {
 	System    string           `json:"system"`
 	OwnerID   int              `json:"owner_id"`
 	Owner     *UserResponse    `json:"owner,omitempty"`
+	Settings  *TableSettings   `json:"settings,omitempty"`
 	Invites   []*InviteDetails `json:"invites,omitempty"`
 	CreatedAt time.Time        `json:"created_at"`
 	UpdatedAt time.Time        `json:"updated_at"`
@@ -117,16 +127,31 @@ type GameTableValidationError struct {
 // NewGameTable cria uma nova mesa com UUID
 func NewGameTable(req CreateGameTableRequest, ownerID int) *GameTable {
 	now := time.Now()
+	settingsJSON := []byte("{}")
+	if req.Settings != nil {
+		settingsJSON, _ = json.Marshal(req.Settings)
+	}
+
 	return &GameTable{
 		ID:        uuid.New().String(),
 		Name:      req.Name,
 		System:    req.System,
 		OwnerID:   ownerID,
+		Settings:  string(settingsJSON),
 		CreatedAt: now,
 		UpdatedAt: now,
 	}
 }
 
+// GetSettings retorna as configurações da mesa
+func (gt *GameTable) GetSettings() *TableSettings {
+	var settings TableSettings
+	if gt.Settings != "" {
+		json.Unmarshal([]byte(gt.Settings), &settings)
+	}
+	return &settings
+}
+
 // NewInvite cria um novo convite com UUID
 func NewInvite(tableID string, inviterID, inviteeID int) *Invite {
 	now := time.Now()
@@ -155,6 +180,7 @@ func (gt *GameTable) ToResponse() *GameTableResponse {
 		Name:      gt.Name,
 		System:    gt.System,
 		OwnerID:   gt.OwnerID,
+		Settings:  gt.GetSettings(),
 		CreatedAt: gt.CreatedAt,
 		UpdatedAt: gt.UpdatedAt,
 	}
@@ -181,5 +207,9 @@ func (gt *GameTable) Update(req UpdateGameTableRequest) {
 	if req.System != "" {
 		gt.System = req.System
 	}
+	if req.Settings != nil {
+		settingsJSON, _ := json.Marshal(req.Settings)
+		gt.Settings = string(settingsJSON)
+	}
 	gt.UpdatedAt = time.Now()
 }
@@ -81,38 +81,160 @@ type Roll struct {
 	ResultValue   int       `json:"result_value" db:"result_value"`
 	ResultDetails string    `json:"-" db:"result_details"` // JSON como string
 	Success       *bool     `json:"success" db:"success"`
+	Successes     *int      `json:"successes" db:"successes"`
+	Failures      *int      `json:"failures" db:"failures"`
+	Outcome       *string   `json:"outcome" db:"outcome"`
+	Difficulty    *int      `json:"difficulty" db:"difficulty"`
+	Visibility    string    `json:"visibility" db:"visibility"`
 	CreatedAt     time.Time `json:"created_at" db:"created_at"`
+
+	// Dados de verificação (commit-reveal); nulos em rolagens anteriores
+	SeedID         *string `json:"-" db:"seed_id"`
+	ServerSeedHash *string `json:"server_seed_hash" db:"server_seed_hash"`
+	ClientSeed     *string `json:"client_seed" db:"client_seed"`
+	Nonce          *int    `json:"nonce" db:"nonce"`
+	Rules          *string `json:"-" db:"rules"` // JSON de RollRuleSnapshot
+
+	// Disputa da qual a rolagem faz parte, se houver
+	ContestID *string `json:"contest_id" db:"contest_id"`
+
+	// Grupo de rolagens executadas juntas (e.g., passos de uma macro) e macro de origem
+	BatchID *string `json:"batch_id" db:"batch_id"`
+	MacroID *string `json:"macro_id" db:"macro_id"`
+
+	// Anulação pelo mestre ou por quem rolou; a rolagem continua no histórico
+	VoidedAt   *time.Time `json:"voided_at" db:"voided_at"`
+	VoidedBy   *int       `json:"voided_by" db:"voided_by"`
+	VoidReason *string    `json:"void_reason" db:"void_reason"`
+
+	// Rótulo livre e tags da rolagem (e.g., "#attack")
+	Label *string `json:"label" db:"label"`
+	Tags  *string `json:"-" db:"tags"` // JSON como string
 }
 
 // RollDetails representa detalhes da rolagem
 type RollDetails struct {
-	Dice     []int `json:"dice"`     // Valores individuais dos dados
-	Modifier int   `json:"modifier"` // Modificador aplicado
-	Total    int   `json:"total"`    // Resultado final
-	Critical bool  `json:"critical"` // Se foi crítico
-	Fumble   bool  `json:"fumble"`   // Se foi fumble
+	Dice     []int      `json:"dice"`            // Valores individuais dos dados
+	Modifier int        `json:"modifier"`        // Modificador aplicado
+	Total    int        `json:"total"`           // Resultado final
+	Critical bool       `json:"critical"`        // Se foi crítico
+	Fumble   bool       `json:"fumble"`          // Se foi fumble
+	Terms    []RollTerm `json:"terms,omitempty"` // Detalhamento por termo da expressão
+
+	// Paradas de sucesso (e.g., "8d10>=8")
+	Successes *int `json:"successes,omitempty"` // Sucessos obtidos, antes dos cancelamentos
+	Failures  *int `json:"failures,omitempty"`  // Falhas obtidas
+	Botch     bool `json:"botch,omitempty"`     // Se a rolagem foi uma falha crítica (nenhum sucesso e ao menos uma falha)
+
+	Symbols map[string]int `json:"symbols,omitempty"` // Contagem de símbolos de dados personalizados
+
+	Outcome *RollOutcome `json:"outcome,omitempty"` // Resultado nomeado do teste, quando há dificuldade ou tabela de resultados
+
+	// Referências a campos da ficha (e.g., "1d20+{abilities.dex_mod}")
+	SourceExpression string           `json:"source_expression,omitempty"` // Expressão antes de resolver as referências
+	References       []SheetReference `json:"references,omitempty"`        // Valor usado para cada referência
+
+	Conditions []AppliedCondition `json:"conditions,omitempty"` // Modificadores de condições da ficha somados à expressão
+	Items      []AppliedItem      `json:"items,omitempty"`      // Modificadores de itens equipados somados à expressão
+}
+
+// SheetReference representa um campo da ficha referenciado em uma expressão e o trecho que o substituiu
+type SheetReference struct {
+	Field string `json:"field" example:"abilities.dex_mod"`
+	Value string `json:"value" example:"3"`
+}
+
+// Tipos de termo de uma rolagem
+const (
+	RollTermDice     = "dice"
+	RollTermPool     = "pool"
+	RollTermConstant = "constant"
+)
+
+// RollTerm representa o resultado de um termo da expressão (dados ou constante)
+type RollTerm struct {
+	Type       string         `json:"type"`                // "dice", "pool" ou "constant"
+	Expression string         `json:"expression"`          // Termo original (e.g., "2d6" ou "4")
+	Sides      int            `json:"sides,omitempty"`     // Lados do dado (apenas termos de dados)
+	Dice       []DieResult    `json:"dice,omitempty"`      // Dados do termo, na ordem em que foram rolados
+	Successes  int            `json:"successes,omitempty"` // Sucessos do termo (apenas paradas)
+	Failures   int            `json:"failures,omitempty"`  // Falhas do termo (apenas paradas)
+	Symbols    map[string]int `json:"symbols,omitempty"`   // Contagem de símbolos dos dados mantidos (apenas dados personalizados)
+	Value      int            `json:"value"`               // Valor do termo (soma dos dados mantidos ou sucessos líquidos)
+}
+
+// DieResult representa um dado individual dentro de um termo
+type DieResult struct {
+	Value    int   `json:"value"`              // Valor final do dado
+	Rerolls  []int `json:"rerolls,omitempty"`  // Valores descartados por rerrolagem, em ordem
+	Rolls    []int `json:"rolls,omitempty"`    // Rolagens somadas em um dado composto ("!!"), em ordem
+	Exploded bool  `json:"exploded,omitempty"` // Se o dado explodiu
+	Extra    bool  `json:"extra,omitempty"`    // Se o dado foi gerado pela explosão do anterior
+	Dropped  bool  `json:"dropped,omitempty"`  // Se foi descartado por keep/drop
+	Success  bool  `json:"success,omitempty"`  // Se contou como sucesso (apenas paradas)
+	Failure  bool  `json:"failure,omitempty"`  // Se contou como falha (apenas paradas)
+
+	Tens    []int    `json:"tens,omitempty"`    // Dezenas roladas em um dado percentual, incluindo bônus/penalidade
+	Symbols []string `json:"symbols,omitempty"` // Símbolos da face sorteada (apenas dados personalizados)
+}
+
+// IsDice indica se o termo é de dados (soma ou parada de sucessos)
+func (t RollTerm) IsDice() bool {
+	return t.Type == RollTermDice || t.Type == RollTermPool
+}
+
+// KeptValues retorna os valores dos dados mantidos no termo
+func (t RollTerm) KeptValues() []int {
+	var values []int
+	for _, die := range t.Dice {
+		if !die.Dropped {
+			values = append(values, die.Value)
+		}
+	}
+	return values
 }
 
 // RollResponse representa resposta da rolagem
 type RollResponse struct {
-	ID            string        `json:"id"`
-	SheetID       *string       `json:"sheet_id"`
-	TableID       *string       `json:"table_id"`
-	UserID        int           `json:"user_id"`
-	Expression    string        `json:"expression"`
-	FieldName     *string       `json:"field_name"`
-	ResultValue   int           `json:"result_value"`
-	ResultDetails *RollDetails  `json:"result_details"`
-	Success       *bool         `json:"success"`
-	User          *UserResponse `json:"user,omitempty"`
-	CreatedAt     time.Time     `json:"created_at"`
+	ID            string         `json:"id"`
+	SheetID       *string        `json:"sheet_id"`
+	TableID       *string        `json:"table_id"`
+	UserID        int            `json:"user_id"`
+	Expression    string         `json:"expression"`
+	FieldName     *string        `json:"field_name"`
+	ResultValue   int            `json:"result_value"`
+	ResultDetails *RollDetails   `json:"result_details"`
+	Success       *bool          `json:"success"`
+	Successes     *int           `json:"successes,omitempty"`
+	Failures      *int           `json:"failures,omitempty"`
+	Outcome       *string        `json:"outcome,omitempty"`
+	Difficulty    *int           `json:"difficulty,omitempty"`
+	Visibility    string         `json:"visibility"`
+	Hidden        bool           `json:"hidden,omitempty"`
+	Fairness      *RollFairness  `json:"fairness,omitempty"`
+	ContestID     *string        `json:"contest_id,omitempty"`
+	BatchID       *string        `json:"batch_id,omitempty"`
+	MacroID       *string        `json:"macro_id,omitempty"`
+	Void          *RollVoid      `json:"void,omitempty"` // Presente em rolagens anuladas
+	Label         *string        `json:"label,omitempty"`
+	Tags          []string       `json:"tags,omitempty"`
+	Reactions     []RollReaction `json:"reactions,omitempty"`
+	User          *UserResponse  `json:"user,omitempty"`
+	CreatedAt     time.Time      `json:"created_at"`
 }
 
 // CreateRollRequest representa dados para rolagem
 type CreateRollRequest struct {
 	SheetID    string `json:"sheet_id" validate:"required,uuid"`
 	Expression string `json:"expression,omitempty" validate:"omitempty,max=200"`
-	FieldName  string `json:"field_name,omitempty" validate:"omitempty,max=100"`
+	// Lote: cada expressão é rolada de forma independente (e.g., ["1d20+5", "1d8+3"]); a expressão única aceita "6x 4d6kh3"
+	Expressions []string `json:"expressions,omitempty" validate:"omitempty,max=20,dive,max=200"`
+	FieldName   string   `json:"field_name,omitempty" validate:"omitempty,max=100"`
+	ClientSeed  string   `json:"client_seed,omitempty" validate:"omitempty,max=64"`
+	Visibility  string   `json:"visibility,omitempty" validate:"omitempty,oneof=public gm blind self"`
+	Macro       string   `json:"macro,omitempty" validate:"omitempty,max=100"`
+	RollCheck
+	RollLabel
 }
 
 // PlayerSheetValidationError representa erro de validação
@@ -162,10 +284,53 @@ func NewRoll(sheetID, tableID string, userID int, expression string, fieldName *
 		UserID:     userID,
 		Expression: expression,
 		FieldName:  fieldName,
+		Visibility: RollVisibilityPublic,
 		CreatedAt:  time.Now(),
 	}
 }
 
+// SetFairness registra os dados de verificação usados na rolagem
+func (r *Roll) SetFairness(seedID string, fairness *RollFairness) {
+	r.SeedID = &seedID
+	r.ServerSeedHash = &fairness.ServerSeedHash
+	r.ClientSeed = &fairness.ClientSeed
+	r.Nonce = &fairness.Nonce
+}
+
+// Fairness retorna os dados de verificação da rolagem, ou nil em rolagens anteriores
+func (r *Roll) Fairness() *RollFairness {
+	if r.ServerSeedHash == nil || r.ClientSeed == nil || r.Nonce == nil {
+		return nil
+	}
+	return &RollFairness{
+		ServerSeedHash: *r.ServerSeedHash,
+		ClientSeed:     *r.ClientSeed,
+		Nonce:          *r.Nonce,
+	}
+}
+
+// SetRules grava as regras usadas na rolagem verificável
+func (r *Roll) SetRules(rules *RollRuleSnapshot) {
+	r.Rules = nil
+	if rules != nil {
+		rulesJSON, _ := json.Marshal(rules)
+		value := string(rulesJSON)
+		r.Rules = &value
+	}
+}
+
+// RuleSnapshot retorna as regras usadas na rolagem, ou nil em rolagens anteriores
+func (r *Roll) RuleSnapshot() *RollRuleSnapshot {
+	if r.Rules == nil {
+		return nil
+	}
+	var rules RollRuleSnapshot
+	if err := json.Unmarshal([]byte(*r.Rules), &rules); err != nil {
+		return nil
+	}
+	return &rules
+}
+
 // ToResponse converte PlayerSheet para resposta
 func (ps *PlayerSheet) ToResponse() *PlayerSheetResponse {
 	var data PlayerSheetData
@@ -213,12 +378,35 @@ func (ps *PlayerSheet) Update(req UpdatePlayerSheetRequest) {
 	ps.UpdatedAt = time.Now()
 }
 
-// ToResponse converte Roll para resposta
-func (r *Roll) ToResponse() *RollResponse {
+// SetDetails registra o resultado estruturado da rolagem
+func (r *Roll) SetDetails(details *RollDetails) {
+	detailsJSON, _ := json.Marshal(details)
+	r.ResultDetails = string(detailsJSON)
+	r.ResultValue = details.Total
+	r.Successes = details.Successes
+	r.Failures = details.Failures
+
+	r.Success, r.Outcome, r.Difficulty = nil, nil, nil
+	if details.Outcome != nil {
+		success := details.Outcome.Success
+		r.Success = &success
+		r.Outcome = &details.Outcome.Name
+		r.Difficulty = details.Outcome.Difficulty
+	}
+}
+
+// Details retorna o resultado estruturado da rolagem
+func (r *Roll) Details() *RollDetails {
 	var details *RollDetails
 	if r.ResultDetails != "" {
 		json.Unmarshal([]byte(r.ResultDetails), &details)
 	}
+	return details
+}
+
+// ToResponse converte Roll para resposta
+func (r *Roll) ToResponse() *RollResponse {
+	details := r.Details()
 
 	return &RollResponse{
 		ID:            r.ID,
@@ -230,6 +418,32 @@ func (r *Roll) ToResponse() *RollResponse {
 		ResultValue:   r.ResultValue,
 		ResultDetails: details,
 		Success:       r.Success,
+		Successes:     r.Successes,
+		Failures:      r.Failures,
+		Outcome:       r.Outcome,
+		Difficulty:    r.Difficulty,
+		Visibility:    r.Visibility,
+		Fairness:      r.Fairness(),
+		ContestID:     r.ContestID,
+		BatchID:       r.BatchID,
+		MacroID:       r.MacroID,
+		Void:          r.Void(),
+		Label:         r.Label,
+		Tags:          r.TagList(),
 		CreatedAt:     r.CreatedAt,
 	}
 }
+
+// Redacted retorna uma cópia da rolagem sem o resultado, para quem não pode vê-lo (e.g., rolagens "blind")
+func (r *RollResponse) Redacted() *RollResponse {
+	redacted := *r
+	redacted.ResultValue = 0
+	redacted.ResultDetails = nil
+	redacted.Success = nil
+	redacted.Successes = nil
+	redacted.Failures = nil
+	redacted.Outcome = nil
+	redacted.Fairness = nil // Semente do cliente e nonce permitiriam recalcular o resultado
+	redacted.Hidden = true
+	return &redacted
+}
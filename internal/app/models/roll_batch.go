@@ -0,0 +1,51 @@
+package models
+
+// RollBatchResponse representa as rolagens de ficha feitas em uma única requisição (lista ou "6x 4d6kh3").
+// Cada rolagem é independente; as que falham trazem o erro e não são salvas
+type RollBatchResponse struct {
+	BatchID    string            `json:"batch_id"`
+	SheetID    string            `json:"sheet_id"`
+	TableID    string            `json:"table_id"`
+	UserID     int               `json:"user_id"`
+	Visibility string            `json:"visibility" example:"public"`
+	Rolls      []RollBatchResult `json:"rolls"`
+	Failed     int               `json:"failed" example:"0"`
+	Hidden     bool              `json:"hidden,omitempty"`
+}
+
+// RollBatchResult representa uma rolagem do lote ou o erro que a impediu
+type RollBatchResult struct {
+	Index      int           `json:"index" example:"0"`
+	Expression string        `json:"expression" example:"4d6kh3"`
+	Roll       *RollResponse `json:"roll,omitempty"`
+	Error      string        `json:"error,omitempty"`
+}
+
+// Redacted retorna uma cópia do lote sem os resultados, para quem não pode vê-los
+func (r *RollBatchResponse) Redacted() *RollBatchResponse {
+	redacted := *r
+	redacted.Rolls = make([]RollBatchResult, len(r.Rolls))
+	for i, result := range r.Rolls {
+		if result.Roll != nil {
+			result.Roll = result.Roll.Redacted()
+		}
+		redacted.Rolls[i] = result
+	}
+	redacted.Hidden = true
+	return &redacted
+}
+
+// DiceBatchResponse representa as rolagens livres feitas em uma única requisição a /dice/roll
+type DiceBatchResponse struct {
+	BatchID string            `json:"batch_id"`
+	Rolls   []DiceBatchResult `json:"rolls"`
+	Failed  int               `json:"failed" example:"0"`
+}
+
+// DiceBatchResult representa uma rolagem livre do lote ou o erro que a impediu
+type DiceBatchResult struct {
+	Index      int               `json:"index" example:"0"`
+	Expression string            `json:"expression" example:"4d6kh3"`
+	Roll       *DiceRollResponse `json:"roll,omitempty"`
+	Error      string            `json:"error,omitempty"`
+}
@@ -0,0 +1,125 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Lados de uma disputa e resultado de empate
+const (
+	ContestAttacker = "attacker"
+	ContestDefender = "defender"
+	ContestTie      = "tie"
+)
+
+// Regras de desempate: vence o defensor, vence o atacante ou a disputa termina empatada
+const (
+	ContestTieRuleDefender = "defender"
+	ContestTieRuleAttacker = "attacker"
+	ContestTieRuleNone     = "none"
+)
+
+// RollContest representa uma rolagem resistida entre duas fichas da mesma mesa
+type RollContest struct {
+	ID              string    `json:"id" db:"id"`
+	TableID         string    `json:"table_id" db:"table_id"`
+	UserID          int       `json:"user_id" db:"user_id"`
+	AttackerSheetID string    `json:"attacker_sheet_id" db:"attacker_sheet_id"`
+	DefenderSheetID string    `json:"defender_sheet_id" db:"defender_sheet_id"`
+	AttackerRollID  string    `json:"attacker_roll_id" db:"attacker_roll_id"`
+	DefenderRollID  string    `json:"defender_roll_id" db:"defender_roll_id"`
+	TieRule         string    `json:"tie_rule" db:"tie_rule"`
+	Winner          string    `json:"winner" db:"winner"`
+	Margin          int       `json:"margin" db:"margin"`
+	CreatedAt       time.Time `json:"created_at" db:"created_at"`
+}
+
+// NewRollContest cria uma disputa entre as rolagens informadas e liga as rolagens a ela
+func NewRollContest(tableID string, userID int, attacker, defender *Roll, tieRule string) *RollContest {
+	contest := &RollContest{
+		ID:             uuid.New().String(),
+		TableID:        tableID,
+		UserID:         userID,
+		AttackerRollID: attacker.ID,
+		DefenderRollID: defender.ID,
+		TieRule:        tieRule,
+		CreatedAt:      time.Now(),
+	}
+	if attacker.SheetID != nil {
+		contest.AttackerSheetID = *attacker.SheetID
+	}
+	if defender.SheetID != nil {
+		contest.DefenderSheetID = *defender.SheetID
+	}
+
+	attacker.ContestID = &contest.ID
+	defender.ContestID = &contest.ID
+	return contest
+}
+
+// ContestantRequest representa um dos lados da disputa: a ficha e a expressão ou campo rolado
+type ContestantRequest struct {
+	SheetID    string `json:"sheet_id" binding:"required" example:"550e8400-e29b-41d4-a716-446655440000"`
+	Expression string `json:"expression,omitempty" binding:"omitempty,max=200" example:"1d20+5"`
+	FieldName  string `json:"field_name,omitempty" binding:"omitempty,max=100" example:"skills.athletics"`
+}
+
+// CreateRollContestRequest representa uma rolagem resistida entre duas fichas
+type CreateRollContestRequest struct {
+	Attacker   ContestantRequest `json:"attacker" binding:"required"`
+	Defender   ContestantRequest `json:"defender" binding:"required"`
+	TieRule    string            `json:"tie_rule,omitempty" example:"defender"`
+	Visibility string            `json:"visibility,omitempty" example:"public"`
+	ClientSeed string            `json:"client_seed,omitempty" binding:"omitempty,max=64" example:"minha-semente"`
+}
+
+// RollContestResponse representa o resultado de uma rolagem resistida
+type RollContestResponse struct {
+	ID            string        `json:"id"`
+	TableID       string        `json:"table_id"`
+	UserID        int           `json:"user_id"`
+	TieRule       string        `json:"tie_rule" example:"defender"`
+	Winner        string        `json:"winner" example:"attacker"`
+	WinnerSheetID *string       `json:"winner_sheet_id"`
+	Margin        int           `json:"margin" example:"3"`
+	Attacker      *RollResponse `json:"attacker"`
+	Defender      *RollResponse `json:"defender"`
+	Hidden        bool          `json:"hidden,omitempty"`
+	CreatedAt     time.Time     `json:"created_at"`
+}
+
+// ToResponse converte a disputa e suas rolagens para a resposta da API
+func (c *RollContest) ToResponse(attacker, defender *RollResponse) *RollContestResponse {
+	response := &RollContestResponse{
+		ID:        c.ID,
+		TableID:   c.TableID,
+		UserID:    c.UserID,
+		TieRule:   c.TieRule,
+		Winner:    c.Winner,
+		Margin:    c.Margin,
+		Attacker:  attacker,
+		Defender:  defender,
+		CreatedAt: c.CreatedAt,
+	}
+
+	switch c.Winner {
+	case ContestAttacker:
+		response.WinnerSheetID = &c.AttackerSheetID
+	case ContestDefender:
+		response.WinnerSheetID = &c.DefenderSheetID
+	}
+	return response
+}
+
+// Redacted retorna uma cópia da disputa sem resultados nem vencedor, para quem não pode vê-los
+func (r *RollContestResponse) Redacted() *RollContestResponse {
+	redacted := *r
+	redacted.Winner = ""
+	redacted.WinnerSheetID = nil
+	redacted.Margin = 0
+	redacted.Attacker = r.Attacker.Redacted()
+	redacted.Defender = r.Defender.Redacted()
+	redacted.Hidden = true
+	return &redacted
+}
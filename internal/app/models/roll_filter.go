@@ -0,0 +1,204 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Campos de ordenação do histórico de rolagens
+const (
+	RollSortCreatedAt = "created_at"
+	RollSortResult    = "result_value"
+)
+
+// RollFilter delimita as rolagens de uma busca ou relatório; From é inclusivo e To, exclusivo
+type RollFilter struct {
+	UserID  *int
+	SheetID *string
+	TableID *string
+	From    *time.Time
+	To      *time.Time
+
+	FieldName  *string // Campo da ficha rolado, exato
+	Expression *string // Trecho da expressão rolada
+	Critical   *bool
+	Fumble     *bool
+	Success    *bool
+	Outcome    *string  // Resultado nomeado do teste (e.g., "sucesso parcial")
+	Voided     *bool    // Apenas anuladas (true) ou apenas válidas (false)
+	Label      *string  // Trecho do rótulo
+	Tags       []string // Tags normalizadas; a rolagem deve ter todas
+	MinResult  *int
+	MaxResult  *int
+}
+
+// HasResultFilters informa se o filtro depende do resultado das rolagens; nesse caso rolagens
+// cujo resultado o usuário não pode ver ficam de fora, para que o filtro não o revele
+func (f RollFilter) HasResultFilters() bool {
+	return f.Critical != nil || f.Fumble != nil || f.Success != nil || f.Outcome != nil ||
+		f.MinResult != nil || f.MaxResult != nil
+}
+
+// RollSort define a ordenação do histórico de rolagens
+type RollSort struct {
+	Field      string
+	Descending bool
+}
+
+// RollHistoryQuery representa os filtros, a ordenação e a paginação do histórico de rolagens, lidos da query string
+type RollHistoryQuery struct {
+	From       string   `form:"from"`
+	To         string   `form:"to"`
+	UserID     *int     `form:"user_id"`
+	SheetID    string   `form:"sheet_id"`
+	TableID    string   `form:"table_id"`
+	FieldName  string   `form:"field_name" binding:"omitempty,max=100"`
+	Expression string   `form:"expression" binding:"omitempty,max=200"`
+	Critical   *bool    `form:"critical"`
+	Fumble     *bool    `form:"fumble"`
+	Success    *bool    `form:"success"`
+	Outcome    string   `form:"outcome" binding:"omitempty,max=50"`
+	Voided     *bool    `form:"voided"`
+	Label      string   `form:"label" binding:"omitempty,max=200"`
+	Tags       []string `form:"tag" binding:"omitempty,max=10,dive,max=32"`
+	MinResult  *int     `form:"min_result"`
+	MaxResult  *int     `form:"max_result"`
+	Sort       string   `form:"sort"`
+	Order      string   `form:"order"`
+	Page       int      `form:"page"`
+	Limit      int      `form:"limit"`
+}
+
+// Filter converte os parâmetros da busca em filtro e ordenação
+func (q RollHistoryQuery) Filter() (RollFilter, RollSort, error) {
+	from, to, err := ParseRollPeriod(q.From, q.To)
+	if err != nil {
+		return RollFilter{}, RollSort{}, err
+	}
+	if q.MinResult != nil && q.MaxResult != nil && *q.MinResult > *q.MaxResult {
+		return RollFilter{}, RollSort{}, errors.New("min_result deve ser menor ou igual a max_result")
+	}
+	var tags []string
+	for _, tag := range q.Tags {
+		normalized, err := NormalizeRollTag(tag)
+		if err != nil {
+			return RollFilter{}, RollSort{}, err
+		}
+		tags = append(tags, normalized)
+	}
+
+	filter := RollFilter{
+		UserID:     q.UserID,
+		SheetID:    optionalString(q.SheetID),
+		TableID:    optionalString(q.TableID),
+		From:       from,
+		To:         to,
+		FieldName:  optionalString(q.FieldName),
+		Expression: optionalString(q.Expression),
+		Critical:   q.Critical,
+		Fumble:     q.Fumble,
+		Success:    q.Success,
+		Outcome:    optionalString(q.Outcome),
+		Voided:     q.Voided,
+		Label:      optionalString(q.Label),
+		Tags:       tags,
+		MinResult:  q.MinResult,
+		MaxResult:  q.MaxResult,
+	}
+
+	sort := RollSort{Field: RollSortCreatedAt, Descending: true}
+	switch q.Sort {
+	case "", RollSortCreatedAt:
+	case RollSortResult:
+		sort.Field = RollSortResult
+	default:
+		return RollFilter{}, RollSort{}, fmt.Errorf("ordenação inválida: '%s' (use created_at ou result_value)", q.Sort)
+	}
+	switch strings.ToLower(q.Order) {
+	case "", "desc":
+	case "asc":
+		sort.Descending = false
+	default:
+		return RollFilter{}, RollSort{}, fmt.Errorf("ordem inválida: '%s' (use asc ou desc)", q.Order)
+	}
+
+	return filter, sort, nil
+}
+
+// Pagination retorna página e limite válidos, usando o limite padrão informado
+func (q RollHistoryQuery) Pagination(defaultLimit int) (page, limit int) {
+	page, limit = q.Page, q.Limit
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 || limit > 100 {
+		limit = defaultLimit
+	}
+	return page, limit
+}
+
+// ParseRollPeriod converte os limites de um período (RFC3339 ou AAAA-MM-DD). No fim do período,
+// exclusivo, uma data sem hora vira o início do dia seguinte, para incluir o dia inteiro
+func ParseRollPeriod(from, to string) (*time.Time, *time.Time, error) {
+	start, err := parseRollDate("from", from, false)
+	if err != nil {
+		return nil, nil, err
+	}
+	end, err := parseRollDate("to", to, true)
+	if err != nil {
+		return nil, nil, err
+	}
+	if start != nil && end != nil && !start.Before(*end) {
+		return nil, nil, errors.New("data inicial deve ser anterior à data final")
+	}
+	return start, end, nil
+}
+
+// parseRollDate converte uma data RFC3339 ou AAAA-MM-DD
+func parseRollDate(name, value string, end bool) (*time.Time, error) {
+	if value == "" {
+		return nil, nil
+	}
+
+	if date, err := time.Parse(time.RFC3339, value); err == nil {
+		return &date, nil
+	}
+
+	date, err := time.Parse(time.DateOnly, value)
+	if err != nil {
+		return nil, fmt.Errorf("data inválida em '%s': use RFC3339 ou AAAA-MM-DD", name)
+	}
+	if end {
+		date = date.AddDate(0, 0, 1)
+	}
+	return &date, nil
+}
+
+// optionalString retorna nil para textos vazios
+func optionalString(value string) *string {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return nil
+	}
+	return &value
+}
+
+// RollWithUser representa uma rolagem lida no histórico junto com o e-mail de quem rolou
+type RollWithUser struct {
+	Roll
+	UserEmail *string `db:"user_email"`
+	Reactions *string `db:"reactions"` // JSON: [{"user_id": 2, "emoji": "🔥"}]
+}
+
+// ToResponse converte a rolagem para a resposta da API, incluindo quem rolou
+func (r *RollWithUser) ToResponse() *RollResponse {
+	response := r.Roll.ToResponse()
+	response.Reactions = r.ReactionList()
+	response.User = &UserResponse{ID: r.UserID}
+	if r.UserEmail != nil {
+		response.User.Email = *r.UserEmail
+	}
+	return response
+}
@@ -0,0 +1,63 @@
+package models
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseRollPeriod(t *testing.T) {
+	// Uma data sem hora no fim do período inclui o dia inteiro: o fim, exclusivo, é o início do dia seguinte
+	from, to, err := ParseRollPeriod("2025-08-01", "2025-08-01")
+	require.NoError(t, err)
+	assert.Equal(t, time.Date(2025, 8, 1, 0, 0, 0, 0, time.UTC), *from)
+	assert.Equal(t, time.Date(2025, 8, 2, 0, 0, 0, 0, time.UTC), *to)
+
+	// Datas RFC3339 valem como informadas
+	from, to, err = ParseRollPeriod("2025-08-01T10:00:00Z", "2025-08-01T12:00:00-03:00")
+	require.NoError(t, err)
+	assert.Equal(t, time.Date(2025, 8, 1, 10, 0, 0, 0, time.UTC), from.UTC())
+	assert.Equal(t, time.Date(2025, 8, 1, 15, 0, 0, 0, time.UTC), to.UTC())
+
+	from, to, err = ParseRollPeriod("", "")
+	require.NoError(t, err)
+	assert.Nil(t, from)
+	assert.Nil(t, to)
+
+	_, _, err = ParseRollPeriod("2025-08-02", "2025-08-01")
+	assert.Error(t, err)
+	_, _, err = ParseRollPeriod("2025-08-01T00:00:00Z", "2025-08-01T00:00:00Z")
+	assert.Error(t, err)
+	_, _, err = ParseRollPeriod("01/08/2025", "")
+	assert.Error(t, err)
+}
+
+func TestRollHistoryQueryFilter(t *testing.T) {
+	intPtr := func(value int) *int { return &value }
+
+	filter, sort, err := RollHistoryQuery{MinResult: intPtr(10), MaxResult: intPtr(10)}.Filter()
+	require.NoError(t, err)
+	assert.Equal(t, 10, *filter.MinResult)
+	assert.True(t, filter.HasResultFilters())
+	assert.Equal(t, RollSort{Field: RollSortCreatedAt, Descending: true}, sort)
+
+	_, _, err = RollHistoryQuery{MinResult: intPtr(11), MaxResult: intPtr(10)}.Filter()
+	assert.EqualError(t, err, "min_result deve ser menor ou igual a max_result")
+
+	_, sort, err = RollHistoryQuery{Sort: RollSortResult, Order: "ASC"}.Filter()
+	require.NoError(t, err)
+	assert.Equal(t, RollSort{Field: RollSortResult, Descending: false}, sort)
+
+	_, _, err = RollHistoryQuery{Sort: "result_value; DROP TABLE rolls"}.Filter()
+	assert.Error(t, err)
+	_, _, err = RollHistoryQuery{Order: "up"}.Filter()
+	assert.Error(t, err)
+
+	filter, _, err = RollHistoryQuery{Label: "  ", Tags: []string{"#Stealth"}}.Filter()
+	require.NoError(t, err)
+	assert.Nil(t, filter.Label)
+	assert.Equal(t, []string{"stealth"}, filter.Tags)
+	assert.False(t, filter.HasResultFilters())
+}
@@ -0,0 +1,70 @@
+package models
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Limites das tags de uma rolagem
+const (
+	MaxRollTags      = 10
+	MaxRollTagLength = 32
+)
+
+// labelHashtagPattern encontra as hashtags de um rótulo (e.g., "Ataque furtivo #stealth")
+var labelHashtagPattern = regexp.MustCompile(`(?:^|\s)#([\p{L}\p{N}_-]+)`)
+
+// RollLabel representa o rótulo livre e as tags pedidos em uma rolagem
+type RollLabel struct {
+	Label string   `json:"label,omitempty" binding:"omitempty,max=200" example:"Ataque furtivo no guarda #stealth"`
+	Tags  []string `json:"tags,omitempty" binding:"omitempty,max=10,dive,max=32" example:"attack,stealth"` // Com ou sem "#"; hashtags do rótulo também viram tags
+}
+
+// SetLabel registra o rótulo e as tags já normalizados da rolagem; vazios ficam nulos
+func (r *Roll) SetLabel(label string, tags []string) {
+	r.Label, r.Tags = nil, nil
+	if label != "" {
+		r.Label = &label
+	}
+	if len(tags) > 0 {
+		tagsJSON, _ := json.Marshal(tags)
+		text := string(tagsJSON)
+		r.Tags = &text
+	}
+}
+
+// TagList retorna as tags da rolagem
+func (r *Roll) TagList() []string {
+	var tags []string
+	if r.Tags != nil {
+		json.Unmarshal([]byte(*r.Tags), &tags)
+	}
+	return tags
+}
+
+// NormalizeRollTag normaliza uma tag: sem "#", em minúsculas, com letras, números, "_" e "-"
+func NormalizeRollTag(tag string) (string, error) {
+	normalized := strings.ToLower(strings.TrimPrefix(strings.TrimSpace(tag), "#"))
+	if normalized == "" || utf8.RuneCountInString(normalized) > MaxRollTagLength {
+		return "", fmt.Errorf("tag inválida: '%s' (use de 1 a %d caracteres)", tag, MaxRollTagLength)
+	}
+	for _, r := range normalized {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '_' && r != '-' {
+			return "", fmt.Errorf("tag inválida: '%s' (use letras, números, _ ou -)", tag)
+		}
+	}
+	return normalized, nil
+}
+
+// LabelHashtags retorna as hashtags escritas no rótulo, na ordem em que aparecem
+func LabelHashtags(label string) []string {
+	var tags []string
+	for _, match := range labelHashtagPattern.FindAllStringSubmatch(label, -1) {
+		tags = append(tags, match[1])
+	}
+	return tags
+}
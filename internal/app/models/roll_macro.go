@@ -0,0 +1,121 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// RollMacro representa uma sequência nomeada de rolagens salva pelo usuário ou ligada a uma ficha
+// (e.g., "Ataque com espada longa" = 1d20+{attack_bonus} seguido de 1d8+{str_mod})
+type RollMacro struct {
+	ID          string    `json:"id" db:"id"`
+	UserID      int       `json:"user_id" db:"user_id"`
+	SheetID     *string   `json:"sheet_id" db:"sheet_id"`
+	Name        string    `json:"name" db:"name"`
+	Description string    `json:"description" db:"description"`
+	Steps       string    `json:"-" db:"steps"` // JSON como string no banco
+	CreatedAt   time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at" db:"updated_at"`
+}
+
+// MacroStep representa uma rolagem da macro; a expressão pode referenciar campos da ficha entre chaves
+type MacroStep struct {
+	Label      string `json:"label,omitempty" example:"Dano"`
+	Expression string `json:"expression" example:"1d8+{str_mod}"`
+}
+
+// CreateRollMacroRequest representa dados para criação de macro; com sheet_id a macro pertence à ficha
+type CreateRollMacroRequest struct {
+	Name        string      `json:"name" binding:"required" example:"Ataque com espada longa"`
+	Description string      `json:"description,omitempty" example:"Ataque e dano da espada longa"`
+	SheetID     *string     `json:"sheet_id,omitempty" example:"550e8400-e29b-41d4-a716-446655440000"`
+	Steps       []MacroStep `json:"steps" binding:"required"`
+}
+
+// UpdateRollMacroRequest representa dados para atualização de macro
+type UpdateRollMacroRequest struct {
+	Name        *string     `json:"name,omitempty" example:"Ataque com espada longa"`
+	Description *string     `json:"description,omitempty" example:"Ataque e dano da espada longa"`
+	Steps       []MacroStep `json:"steps,omitempty"`
+}
+
+// RollMacroResponse representa uma macro na API
+type RollMacroResponse struct {
+	ID          string      `json:"id"`
+	UserID      int         `json:"user_id"`
+	SheetID     *string     `json:"sheet_id"`
+	Name        string      `json:"name"`
+	Description string      `json:"description"`
+	Steps       []MacroStep `json:"steps"`
+	CreatedAt   time.Time   `json:"created_at"`
+	UpdatedAt   time.Time   `json:"updated_at"`
+}
+
+// MacroRollResponse representa a execução de uma macro: as rolagens do grupo, na ordem dos passos
+type MacroRollResponse struct {
+	BatchID   string            `json:"batch_id"`
+	MacroID   string            `json:"macro_id"`
+	MacroName string            `json:"macro_name"`
+	SheetID   string            `json:"sheet_id"`
+	Steps     []MacroStepResult `json:"steps"`
+}
+
+// MacroStepResult representa a rolagem de um passo da macro
+type MacroStepResult struct {
+	Label string        `json:"label,omitempty"`
+	Roll  *RollResponse `json:"roll"`
+}
+
+// NewRollMacro cria nova macro
+func NewRollMacro(req CreateRollMacroRequest, userID int) *RollMacro {
+	stepsJSON, _ := json.Marshal(req.Steps)
+
+	return &RollMacro{
+		ID:          uuid.New().String(),
+		UserID:      userID,
+		SheetID:     req.SheetID,
+		Name:        req.Name,
+		Description: req.Description,
+		Steps:       string(stepsJSON),
+		CreatedAt:   time.Now(),
+		UpdatedAt:   time.Now(),
+	}
+}
+
+// GetSteps retorna os passos da macro
+func (m *RollMacro) GetSteps() []MacroStep {
+	var steps []MacroStep
+	json.Unmarshal([]byte(m.Steps), &steps)
+	return steps
+}
+
+// Update atualiza a macro com os dados informados
+func (m *RollMacro) Update(req UpdateRollMacroRequest) {
+	if req.Name != nil {
+		m.Name = *req.Name
+	}
+	if req.Description != nil {
+		m.Description = *req.Description
+	}
+	if req.Steps != nil {
+		stepsJSON, _ := json.Marshal(req.Steps)
+		m.Steps = string(stepsJSON)
+	}
+	m.UpdatedAt = time.Now()
+}
+
+// ToResponse converte para response
+func (m *RollMacro) ToResponse() *RollMacroResponse {
+	return &RollMacroResponse{
+		ID:          m.ID,
+		UserID:      m.UserID,
+		SheetID:     m.SheetID,
+		Name:        m.Name,
+		Description: m.Description,
+		Steps:       m.GetSteps(),
+		CreatedAt:   m.CreatedAt,
+		UpdatedAt:   m.UpdatedAt,
+	}
+}
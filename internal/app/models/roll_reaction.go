@@ -0,0 +1,65 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// RollReactionRecord representa a reação (emoji) de um usuário a uma rolagem
+type RollReactionRecord struct {
+	RollID    string    `json:"roll_id" db:"roll_id"`
+	UserID    int       `json:"user_id" db:"user_id"`
+	Emoji     string    `json:"emoji" db:"emoji"`
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+}
+
+// RollReaction representa as reações com um mesmo emoji a uma rolagem
+type RollReaction struct {
+	Emoji   string `json:"emoji" example:"🔥"`
+	Count   int    `json:"count" example:"2"`
+	UserIDs []int  `json:"user_ids"`
+}
+
+// RollReactionRequest representa o pedido de reação a uma rolagem
+type RollReactionRequest struct {
+	Emoji string `json:"emoji" binding:"required,max=32" example:"🔥"`
+}
+
+// RollReactionsResponse representa as reações de uma rolagem após uma alteração
+type RollReactionsResponse struct {
+	RollID    string         `json:"roll_id" example:"550e8400-e29b-41d4-a716-446655440000"`
+	TableID   *string        `json:"table_id,omitempty" example:"550e8400-e29b-41d4-a716-446655440000"`
+	UserID    int            `json:"user_id" example:"2"` // Quem reagiu
+	Emoji     string         `json:"emoji" example:"🔥"`
+	Added     bool           `json:"added" example:"true"` // true ao reagir, false ao remover a reação
+	Reactions []RollReaction `json:"reactions"`
+}
+
+// SummarizeReactions agrupa as reações por emoji, na ordem da primeira reação com cada um
+func SummarizeReactions(records []RollReactionRecord) []RollReaction {
+	reactions := []RollReaction{}
+	index := make(map[string]int)
+	for _, record := range records {
+		i, ok := index[record.Emoji]
+		if !ok {
+			i = len(reactions)
+			index[record.Emoji] = i
+			reactions = append(reactions, RollReaction{Emoji: record.Emoji, UserIDs: []int{}})
+		}
+		reactions[i].Count++
+		reactions[i].UserIDs = append(reactions[i].UserIDs, record.UserID)
+	}
+	return reactions
+}
+
+// ReactionList retorna as reações lidas junto com a rolagem no histórico
+func (r *RollWithUser) ReactionList() []RollReaction {
+	if r.Reactions == nil {
+		return nil
+	}
+	var records []RollReactionRecord
+	if err := json.Unmarshal([]byte(*r.Reactions), &records); err != nil || len(records) == 0 {
+		return nil
+	}
+	return SummarizeReactions(records)
+}
@@ -0,0 +1,71 @@
+package models
+
+import (
+	"time"
+)
+
+// Escopos dos relatórios de estatísticas de rolagens
+const (
+	RollStatsScopeUser  = "user"
+	RollStatsScopeSheet = "sheet"
+	RollStatsScopeTable = "table"
+)
+
+// RollStatsResponse representa o relatório de estatísticas das rolagens de um usuário, ficha ou mesa
+type RollStatsResponse struct {
+	Scope   string     `json:"scope" example:"table"`
+	ScopeID string     `json:"scope_id" example:"550e8400-e29b-41d4-a716-446655440000"`
+	UserID  *int       `json:"user_id,omitempty"`
+	From    *time.Time `json:"from,omitempty"`
+	To      *time.Time `json:"to,omitempty"`
+
+	Count        int     `json:"count" example:"120"`
+	Average      float64 `json:"average" example:"12.4"` // Média dos resultados de todas as rolagens
+	Criticals    int     `json:"criticals" example:"7"`
+	Fumbles      int     `json:"fumbles" example:"5"`
+	CriticalRate float64 `json:"critical_rate" example:"0.058"`
+	FumbleRate   float64 `json:"fumble_rate" example:"0.042"`
+
+	Luck      *RollLuckStats   `json:"luck,omitempty"` // Nulo quando nenhuma expressão pôde ser analisada
+	Streaks   RollStreaks      `json:"streaks"`
+	TopFields []RollFieldStats `json:"top_fields"`
+	Dice      []DieStats       `json:"dice"`
+}
+
+// RollLuckStats compara os resultados com o valor esperado de cada expressão rolada
+type RollLuckStats struct {
+	Analyzed      int     `json:"analyzed" example:"118"`      // Rolagens cuja expressão teve distribuição calculada
+	Average       float64 `json:"average" example:"12.4"`      // Média dos resultados analisados
+	Expected      float64 `json:"expected" example:"11.9"`     // Média dos valores esperados
+	Delta         float64 `json:"delta" example:"0.5"`         // Diferença entre a média e o esperado
+	Score         float64 `json:"score" example:"0.11"`        // Média dos desvios padronizados (z); positivo é sorte
+	AboveExpected int     `json:"above_expected" example:"63"` // Rolagens acima do esperado
+	BelowExpected int     `json:"below_expected" example:"55"` // Rolagens abaixo do esperado
+}
+
+// RollStreaks representa as maiores sequências de rolagens consecutivas
+type RollStreaks struct {
+	AboveExpected int `json:"above_expected" example:"6"` // Acima do valor esperado
+	BelowExpected int `json:"below_expected" example:"4"` // Abaixo do valor esperado
+	Criticals     int `json:"criticals" example:"2"`
+	Fumbles       int `json:"fumbles" example:"1"`
+}
+
+// RollFieldStats representa as rolagens de um campo da ficha
+type RollFieldStats struct {
+	Field   string  `json:"field" example:"skills.athletics"`
+	Count   int     `json:"count" example:"14"`
+	Average float64 `json:"average" example:"13.2"`
+}
+
+// DieStats representa as faces sorteadas de um tamanho de dado e o teste de aderência à distribuição uniforme
+type DieStats struct {
+	Sides      int      `json:"sides" example:"20"`
+	Count      int      `json:"count" example:"240"` // Dados lançados, incluindo rerrolagens e explosões
+	Faces      []int    `json:"faces"`               // Quantas vezes cada face saiu, a partir da face 1
+	Average    float64  `json:"average" example:"10.9"`
+	Expected   float64  `json:"expected" example:"10.5"`
+	ChiSquare  *float64 `json:"chi_square,omitempty"`       // Nulo com amostra pequena demais para o teste
+	PValue     *float64 `json:"p_value,omitempty"`          // Chance de um dado justo desviar tanto ou mais
+	Suspicious bool     `json:"suspicious" example:"false"` // Desvio improvável demais para um dado justo
+}
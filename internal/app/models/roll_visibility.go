@@ -0,0 +1,31 @@
+package models
+
+// Visibilidade das rolagens de uma mesa; o mestre é o dono da mesa
+const (
+	// RollVisibilityPublic é vista por todos na mesa
+	RollVisibilityPublic = "public"
+	// RollVisibilityGM é vista apenas por quem rolou e pelo mestre (e.g., rolagens secretas do mestre)
+	RollVisibilityGM = "gm"
+	// RollVisibilityBlind é rolada pelo jogador, mas apenas o mestre vê o resultado
+	RollVisibilityBlind = "blind"
+	// RollVisibilitySelf é vista apenas por quem rolou
+	RollVisibilitySelf = "self"
+)
+
+// RollAccess informa se o usuário vê a rolagem e, vendo, se vê o resultado.
+// Em rolagens "blind", quem rolou vê apenas que a rolagem aconteceu
+func RollAccess(visibility string, rollerID, viewerID, gmID int) (visible, full bool) {
+	isGM := viewerID == gmID
+	isRoller := viewerID == rollerID
+
+	switch visibility {
+	case RollVisibilityGM:
+		visible = isRoller || isGM
+		return visible, visible
+	case RollVisibilityBlind:
+		return isRoller || isGM, isGM
+	case RollVisibilitySelf:
+		return isRoller, isRoller
+	}
+	return true, true
+}
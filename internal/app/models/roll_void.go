@@ -0,0 +1,29 @@
+package models
+
+import (
+	"time"
+)
+
+// RollVoid representa a anulação de uma rolagem: quem anulou, quando e por quê
+type RollVoid struct {
+	VoidedAt time.Time `json:"voided_at" example:"2024-01-01T10:02:00Z"`
+	VoidedBy *int      `json:"voided_by" example:"1"`
+	Reason   string    `json:"reason" example:"Clique errado"`
+}
+
+// VoidRollRequest representa o pedido de anulação de uma rolagem
+type VoidRollRequest struct {
+	Reason string `json:"reason" binding:"required,max=500" example:"Clique errado"`
+}
+
+// Void retorna os dados de anulação da rolagem, ou nil se ela vale
+func (r *Roll) Void() *RollVoid {
+	if r.VoidedAt == nil {
+		return nil
+	}
+	void := &RollVoid{VoidedAt: *r.VoidedAt, VoidedBy: r.VoidedBy}
+	if r.VoidReason != nil {
+		void.Reason = *r.VoidReason
+	}
+	return void
+}
@@ -0,0 +1,164 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Tipos de duração de uma condição
+const (
+	ConditionDurationRounds     = "rounds"
+	ConditionDurationMinutes    = "minutes"    // Convertidos em rodadas (ConditionRoundsPerMinute)
+	ConditionDurationUntilSave  = "until_save" // Termina com uma resistência bem-sucedida
+	ConditionDurationIndefinite = "indefinite" // Termina apenas ao ser removida
+)
+
+// ConditionRoundsPerMinute é o número de rodadas de combate em um minuto (rodadas de 6 segundos)
+const ConditionRoundsPerMinute = 10
+
+// Ações sobre as condições, enviadas nas notificações
+const (
+	ConditionActionAdded      = "added"
+	ConditionActionRemoved    = "removed"
+	ConditionActionExpired    = "expired"     // A duração em rodadas acabou
+	ConditionActionSaved      = "saved"       // Resistência bem-sucedida: a condição terminou
+	ConditionActionSaveFailed = "save_failed" // Resistência falhou: a condição continua
+)
+
+// SheetCondition representa uma condição aplicada a uma ficha
+type SheetCondition struct {
+	ID              string    `json:"id" db:"id"`
+	SheetID         string    `json:"sheet_id" db:"sheet_id"`
+	TableID         string    `json:"table_id" db:"table_id"`
+	Name            string    `json:"name" db:"name"`
+	Source          *string   `json:"source" db:"source"`
+	DurationType    string    `json:"duration_type" db:"duration_type"`
+	Duration        *int      `json:"duration" db:"duration"`
+	RemainingRounds *int      `json:"remaining_rounds" db:"remaining_rounds"`
+	SaveDC          *int      `json:"save_dc" db:"save_dc"`
+	Modifiers       *string   `json:"-" db:"modifiers"` // JSON array de ConditionModifier
+	CreatedBy       int       `json:"created_by" db:"created_by"`
+	CreatedAt       time.Time `json:"created_at" db:"created_at"`
+}
+
+// ConditionModifier representa um modificador somado às rolagens da ficha enquanto a condição durar.
+// Sem applies_to vale para todas as rolagens; com ele, para as rolagens com uma dessas tags ou desse campo
+type ConditionModifier struct {
+	Expression string   `json:"expression" binding:"required,max=50" example:"+1d4"`
+	AppliesTo  []string `json:"applies_to,omitempty" binding:"max=10,dive,max=100" example:"attack,save"`
+}
+
+// AppliedCondition representa o modificador de uma condição somado a uma rolagem
+type AppliedCondition struct {
+	ConditionID string `json:"condition_id" example:"550e8400-e29b-41d4-a716-446655440000"`
+	Name        string `json:"name" example:"abençoado"`
+	Expression  string `json:"expression" example:"+1d4"`
+}
+
+// CreateConditionRequest representa dados para aplicar uma condição a uma ficha
+type CreateConditionRequest struct {
+	Name         string              `json:"name" binding:"required,max=50" example:"abençoado"`
+	Source       string              `json:"source,omitempty" binding:"omitempty,max=100" example:"Bênção do clérigo"`
+	DurationType string              `json:"duration_type" binding:"required" example:"rounds"` // rounds, minutes, until_save ou indefinite
+	Duration     *int                `json:"duration,omitempty" example:"10"`                   // Obrigatória em rounds e minutes
+	SaveDC       *int                `json:"save_dc,omitempty" example:"13"`                    // Dificuldade da resistência em until_save
+	Modifiers    []ConditionModifier `json:"modifiers,omitempty" binding:"omitempty,max=5,dive"`
+}
+
+// ConditionSaveRequest representa a resistência rolada contra uma condição until_save; resistências são públicas
+type ConditionSaveRequest struct {
+	Expression string `json:"expression,omitempty" binding:"omitempty,max=200" example:"1d20+{saves.con}"`
+	FieldName  string `json:"field_name,omitempty" binding:"omitempty,max=100" example:"saves.con"`
+	Difficulty *int   `json:"difficulty,omitempty" example:"13"` // Padrão: save_dc da condição
+	ClientSeed string `json:"client_seed,omitempty" binding:"omitempty,max=64" example:"minha-semente"`
+}
+
+// SheetConditionResponse representa uma condição da ficha
+type SheetConditionResponse struct {
+	ID              string              `json:"id"`
+	SheetID         string              `json:"sheet_id"`
+	TableID         string              `json:"table_id"`
+	Name            string              `json:"name" example:"abençoado"`
+	Source          *string             `json:"source,omitempty" example:"Bênção do clérigo"`
+	DurationType    string              `json:"duration_type" example:"rounds"`
+	Duration        *int                `json:"duration,omitempty" example:"10"`
+	RemainingRounds *int                `json:"remaining_rounds,omitempty" example:"7"`
+	SaveDC          *int                `json:"save_dc,omitempty" example:"13"`
+	Modifiers       []ConditionModifier `json:"modifiers"`
+	CreatedBy       int                 `json:"created_by"`
+	CreatedAt       time.Time           `json:"created_at"`
+}
+
+// ConditionEvent representa condições aplicadas, removidas ou encerradas, notificadas à mesa
+type ConditionEvent struct {
+	Action     string                    `json:"action" example:"expired"`
+	Conditions []*SheetConditionResponse `json:"conditions"`
+	Roll       *RollResponse             `json:"roll,omitempty"` // Resistência rolada, em saved e save_failed
+}
+
+// ConditionSaveResponse representa o resultado de uma resistência contra uma condição
+type ConditionSaveResponse struct {
+	Saved     bool                    `json:"saved" example:"true"` // true se a condição terminou
+	Condition *SheetConditionResponse `json:"condition"`
+	Roll      *RollResponse           `json:"roll"`
+}
+
+// NewSheetCondition cria uma condição na ficha
+func NewSheetCondition(sheetID, tableID string, createdBy int, name string) *SheetCondition {
+	return &SheetCondition{
+		ID:        uuid.New().String(),
+		SheetID:   sheetID,
+		TableID:   tableID,
+		Name:      name,
+		CreatedBy: createdBy,
+		CreatedAt: time.Now(),
+	}
+}
+
+// SetModifiers grava os modificadores da condição; sem modificadores a coluna fica nula
+func (c *SheetCondition) SetModifiers(modifiers []ConditionModifier) {
+	c.Modifiers = nil
+	if len(modifiers) > 0 {
+		modifiersJSON, _ := json.Marshal(modifiers)
+		value := string(modifiersJSON)
+		c.Modifiers = &value
+	}
+}
+
+// ModifierList retorna os modificadores da condição
+func (c *SheetCondition) ModifierList() []ConditionModifier {
+	modifiers := []ConditionModifier{}
+	if c.Modifiers != nil {
+		_ = json.Unmarshal([]byte(*c.Modifiers), &modifiers)
+	}
+	return modifiers
+}
+
+// ToResponse converte a condição para a resposta da API
+func (c *SheetCondition) ToResponse() *SheetConditionResponse {
+	return &SheetConditionResponse{
+		ID:              c.ID,
+		SheetID:         c.SheetID,
+		TableID:         c.TableID,
+		Name:            c.Name,
+		Source:          c.Source,
+		DurationType:    c.DurationType,
+		Duration:        c.Duration,
+		RemainingRounds: c.RemainingRounds,
+		SaveDC:          c.SaveDC,
+		Modifiers:       c.ModifierList(),
+		CreatedBy:       c.CreatedBy,
+		CreatedAt:       c.CreatedAt,
+	}
+}
+
+// ConditionResponses converte uma lista de condições para a resposta da API
+func ConditionResponses(conditions []*SheetCondition) []*SheetConditionResponse {
+	responses := make([]*SheetConditionResponse, len(conditions))
+	for i, condition := range conditions {
+		responses[i] = condition.ToResponse()
+	}
+	return responses
+}
@@ -0,0 +1,335 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Situações de uma troca de itens
+const (
+	TradeStatusPending  = "pending"
+	TradeStatusAccepted = "accepted"
+	TradeStatusDeclined = "declined" // Recusada pela ficha que recebeu a proposta
+	TradeStatusCanceled = "canceled" // Cancelada pela ficha que propôs
+)
+
+// Ações sobre o inventário, enviadas nas notificações
+const (
+	InventoryActionAdded   = "added"
+	InventoryActionUpdated = "updated"
+	InventoryActionRemoved = "removed"
+	InventoryActionGiven   = "given"
+	InventoryActionTrade   = "trade" // Proposta, aceita, recusada ou cancelada (ver o status da troca)
+)
+
+// SheetItem representa um item do inventário de uma ficha
+type SheetItem struct {
+	ID          string    `json:"id" db:"id"`
+	SheetID     string    `json:"sheet_id" db:"sheet_id"`
+	TableID     string    `json:"table_id" db:"table_id"`
+	Name        string    `json:"name" db:"name"`
+	Description *string   `json:"description" db:"description"`
+	Quantity    int       `json:"quantity" db:"quantity"`
+	Weight      float64   `json:"weight" db:"weight"` // Peso de uma unidade
+	Equipped    bool      `json:"equipped" db:"equipped"`
+	Modifiers   *string   `json:"-" db:"modifiers"` // JSON array de ConditionModifier
+	Stats       *string   `json:"-" db:"stats"`     // JSON array de ItemStat
+	CreatedBy   int       `json:"created_by" db:"created_by"`
+	CreatedAt   time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at" db:"updated_at"`
+}
+
+// ItemStat altera um campo numérico da ficha enquanto o item está equipado: set substitui o valor (e.g., a armadura
+// define a CA; com vários, vale o maior) e add soma a ele (e.g., escudo +2)
+type ItemStat struct {
+	Path string `json:"path" binding:"required,max=100" example:"ac"`
+	Set  *int   `json:"set,omitempty" example:"16"`
+	Add  *int   `json:"add,omitempty" example:"2"`
+}
+
+// AppliedItem representa o modificador de um item equipado somado a uma rolagem
+type AppliedItem struct {
+	ItemID     string `json:"item_id" example:"550e8400-e29b-41d4-a716-446655440000"`
+	Name       string `json:"name" example:"Espada da Aurora"`
+	Expression string `json:"expression" example:"+1"`
+}
+
+// InventoryRule define a capacidade de carga das fichas do template: fixa ou lida de um campo da ficha
+type InventoryRule struct {
+	Capacity     *float64 `json:"capacity,omitempty" example:"150"`
+	CapacityPath string   `json:"capacity_path,omitempty" example:"carry.capacity"`
+}
+
+// CreateItemRequest representa dados para adicionar um item à ficha
+type CreateItemRequest struct {
+	Name        string              `json:"name" binding:"required,max=100" example:"Espada da Aurora"`
+	Description string              `json:"description,omitempty" binding:"omitempty,max=1000" example:"Brilha ao amanhecer"`
+	Quantity    *int                `json:"quantity,omitempty" example:"1"` // Padrão: 1
+	Weight      float64             `json:"weight,omitempty" example:"3"`
+	Equipped    bool                `json:"equipped,omitempty" example:"true"`
+	Modifiers   []ConditionModifier `json:"modifiers,omitempty" binding:"omitempty,max=5,dive"`
+	Stats       []ItemStat          `json:"stats,omitempty" binding:"omitempty,max=10,dive"`
+}
+
+// UpdateItemRequest representa dados para atualizar um item; campos ausentes não mudam
+type UpdateItemRequest struct {
+	Name        *string              `json:"name,omitempty" binding:"omitempty,max=100" example:"Espada da Aurora +1"`
+	Description *string              `json:"description,omitempty" binding:"omitempty,max=1000"`
+	Quantity    *int                 `json:"quantity,omitempty" example:"2"`
+	Weight      *float64             `json:"weight,omitempty" example:"3"`
+	Equipped    *bool                `json:"equipped,omitempty" example:"false"`
+	Modifiers   *[]ConditionModifier `json:"modifiers,omitempty" binding:"omitempty,max=5,dive"`
+	Stats       *[]ItemStat          `json:"stats,omitempty" binding:"omitempty,max=10,dive"`
+}
+
+// GiveItemRequest representa a entrega de um item a outra ficha da mesa
+type GiveItemRequest struct {
+	SheetID  string `json:"sheet_id" binding:"required,max=36" example:"550e8400-e29b-41d4-a716-446655440000"`
+	Quantity *int   `json:"quantity,omitempty" example:"1"` // Padrão: todas as unidades
+}
+
+// SheetItemResponse representa um item do inventário
+type SheetItemResponse struct {
+	ID          string              `json:"id"`
+	SheetID     string              `json:"sheet_id"`
+	TableID     string              `json:"table_id"`
+	Name        string              `json:"name" example:"Espada da Aurora"`
+	Description *string             `json:"description,omitempty"`
+	Quantity    int                 `json:"quantity" example:"1"`
+	Weight      float64             `json:"weight" example:"3"`
+	TotalWeight float64             `json:"total_weight" example:"3"` // Peso × quantidade
+	Equipped    bool                `json:"equipped" example:"true"`
+	Modifiers   []ConditionModifier `json:"modifiers"`
+	Stats       []ItemStat          `json:"stats"`
+	CreatedBy   int                 `json:"created_by"`
+	CreatedAt   time.Time           `json:"created_at"`
+	UpdatedAt   time.Time           `json:"updated_at"`
+}
+
+// InventoryResponse representa o inventário da ficha com a carga e os campos alterados pelos itens equipados
+type InventoryResponse struct {
+	SheetID     string               `json:"sheet_id"`
+	Items       []*SheetItemResponse `json:"items"`
+	TotalWeight float64              `json:"total_weight" example:"48.5"`
+	Capacity    *float64             `json:"capacity,omitempty" example:"150"` // Declarada no template
+	Encumbered  bool                 `json:"encumbered" example:"false"`       // Carga acima da capacidade
+	Stats       []SheetFieldChange   `json:"stats"`                            // Valor da ficha (old) e com os itens equipados (new)
+}
+
+// TableItem representa um item da mesa com a ficha que o possui
+type TableItem struct {
+	SheetItem
+	SheetName string `db:"sheet_name"`
+	OwnerID   int    `db:"owner_id"`
+}
+
+// TableItemResponse representa um item encontrado na busca de itens da mesa, com a ficha que o possui
+type TableItemResponse struct {
+	*SheetItemResponse
+	SheetName string `json:"sheet_name" example:"Guerreiro"`
+	OwnerID   int    `json:"owner_id"`
+}
+
+// TradeItem representa um item oferecido ou pedido em uma troca
+type TradeItem struct {
+	ItemID   string `json:"item_id" binding:"required,max=36" example:"550e8400-e29b-41d4-a716-446655440000"`
+	Name     string `json:"name,omitempty" example:"Poção de Cura"` // Preenchido pelo servidor
+	Quantity int    `json:"quantity" binding:"required,min=1" example:"2"`
+}
+
+// ItemTrade representa uma proposta de troca de itens entre duas fichas da mesa
+type ItemTrade struct {
+	ID          string     `json:"id" db:"id"`
+	TableID     string     `json:"table_id" db:"table_id"`
+	FromSheetID string     `json:"from_sheet_id" db:"from_sheet_id"`
+	ToSheetID   string     `json:"to_sheet_id" db:"to_sheet_id"`
+	Offered     string     `json:"-" db:"offered"`   // JSON array de TradeItem
+	Requested   string     `json:"-" db:"requested"` // JSON array de TradeItem
+	Status      string     `json:"status" db:"status"`
+	CreatedBy   int        `json:"created_by" db:"created_by"`
+	ResolvedBy  *int       `json:"resolved_by" db:"resolved_by"`
+	CreatedAt   time.Time  `json:"created_at" db:"created_at"`
+	ResolvedAt  *time.Time `json:"resolved_at" db:"resolved_at"`
+}
+
+// CreateTradeRequest representa uma proposta de troca: itens oferecidos pela ficha e pedidos à outra ficha
+type CreateTradeRequest struct {
+	ToSheetID string      `json:"to_sheet_id" binding:"required,max=36" example:"550e8400-e29b-41d4-a716-446655440000"`
+	Offered   []TradeItem `json:"offered,omitempty" binding:"omitempty,max=20,dive"`
+	Requested []TradeItem `json:"requested,omitempty" binding:"omitempty,max=20,dive"`
+}
+
+// ItemTradeResponse representa uma troca de itens
+type ItemTradeResponse struct {
+	ID          string      `json:"id"`
+	TableID     string      `json:"table_id"`
+	FromSheetID string      `json:"from_sheet_id"`
+	ToSheetID   string      `json:"to_sheet_id"`
+	Offered     []TradeItem `json:"offered"`
+	Requested   []TradeItem `json:"requested"`
+	Status      string      `json:"status" example:"pending"`
+	CreatedBy   int         `json:"created_by"`
+	ResolvedBy  *int        `json:"resolved_by,omitempty"`
+	CreatedAt   time.Time   `json:"created_at"`
+	ResolvedAt  *time.Time  `json:"resolved_at,omitempty"`
+}
+
+// ItemMove representa unidades de um item passadas de uma ficha para outra
+type ItemMove struct {
+	ItemID      string
+	FromSheetID string
+	ToSheetID   string
+	Quantity    int
+}
+
+// InventoryEvent representa uma alteração nos inventários da mesa, notificada à mesa
+type InventoryEvent struct {
+	Action  string               `json:"action" example:"given"`
+	TableID string               `json:"table_id"`
+	Items   []*SheetItemResponse `json:"items"`           // Itens no estado final; quantidade 0 indica item que deixou de existir
+	Trade   *ItemTradeResponse   `json:"trade,omitempty"` // Apenas em trade
+}
+
+// NewSheetItem cria um item na ficha
+func NewSheetItem(sheetID, tableID string, createdBy int, name string) *SheetItem {
+	now := time.Now()
+	return &SheetItem{
+		ID:        uuid.New().String(),
+		SheetID:   sheetID,
+		TableID:   tableID,
+		Name:      name,
+		Quantity:  1,
+		CreatedBy: createdBy,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+}
+
+// SetModifiers grava os modificadores de rolagem do item; sem modificadores a coluna fica nula
+func (i *SheetItem) SetModifiers(modifiers []ConditionModifier) {
+	i.Modifiers = nil
+	if len(modifiers) > 0 {
+		modifiersJSON, _ := json.Marshal(modifiers)
+		value := string(modifiersJSON)
+		i.Modifiers = &value
+	}
+}
+
+// ModifierList retorna os modificadores de rolagem do item
+func (i *SheetItem) ModifierList() []ConditionModifier {
+	modifiers := []ConditionModifier{}
+	if i.Modifiers != nil {
+		_ = json.Unmarshal([]byte(*i.Modifiers), &modifiers)
+	}
+	return modifiers
+}
+
+// SetStats grava os campos da ficha alterados pelo item; sem alterações a coluna fica nula
+func (i *SheetItem) SetStats(stats []ItemStat) {
+	i.Stats = nil
+	if len(stats) > 0 {
+		statsJSON, _ := json.Marshal(stats)
+		value := string(statsJSON)
+		i.Stats = &value
+	}
+}
+
+// StatList retorna os campos da ficha alterados pelo item
+func (i *SheetItem) StatList() []ItemStat {
+	stats := []ItemStat{}
+	if i.Stats != nil {
+		_ = json.Unmarshal([]byte(*i.Stats), &stats)
+	}
+	return stats
+}
+
+// ToResponse converte o item para a resposta da API
+func (i *SheetItem) ToResponse() *SheetItemResponse {
+	return &SheetItemResponse{
+		ID:          i.ID,
+		SheetID:     i.SheetID,
+		TableID:     i.TableID,
+		Name:        i.Name,
+		Description: i.Description,
+		Quantity:    i.Quantity,
+		Weight:      i.Weight,
+		TotalWeight: i.Weight * float64(i.Quantity),
+		Equipped:    i.Equipped,
+		Modifiers:   i.ModifierList(),
+		Stats:       i.StatList(),
+		CreatedBy:   i.CreatedBy,
+		CreatedAt:   i.CreatedAt,
+		UpdatedAt:   i.UpdatedAt,
+	}
+}
+
+// ItemResponses converte uma lista de itens para a resposta da API
+func ItemResponses(items []*SheetItem) []*SheetItemResponse {
+	responses := make([]*SheetItemResponse, len(items))
+	for i, item := range items {
+		responses[i] = item.ToResponse()
+	}
+	return responses
+}
+
+// NewItemTrade cria uma proposta de troca pendente
+func NewItemTrade(tableID, fromSheetID, toSheetID string, createdBy int, offered, requested []TradeItem) *ItemTrade {
+	offeredJSON, _ := json.Marshal(offered)
+	requestedJSON, _ := json.Marshal(requested)
+	return &ItemTrade{
+		ID:          uuid.New().String(),
+		TableID:     tableID,
+		FromSheetID: fromSheetID,
+		ToSheetID:   toSheetID,
+		Offered:     string(offeredJSON),
+		Requested:   string(requestedJSON),
+		Status:      TradeStatusPending,
+		CreatedBy:   createdBy,
+		CreatedAt:   time.Now(),
+	}
+}
+
+// OfferedItems retorna os itens oferecidos pela ficha que propôs a troca
+func (t *ItemTrade) OfferedItems() []TradeItem {
+	items := []TradeItem{}
+	_ = json.Unmarshal([]byte(t.Offered), &items)
+	return items
+}
+
+// RequestedItems retorna os itens pedidos à outra ficha
+func (t *ItemTrade) RequestedItems() []TradeItem {
+	items := []TradeItem{}
+	_ = json.Unmarshal([]byte(t.Requested), &items)
+	return items
+}
+
+// Moves retorna as movimentações de itens que efetivam a troca
+func (t *ItemTrade) Moves() []ItemMove {
+	var moves []ItemMove
+	for _, item := range t.OfferedItems() {
+		moves = append(moves, ItemMove{ItemID: item.ItemID, FromSheetID: t.FromSheetID, ToSheetID: t.ToSheetID, Quantity: item.Quantity})
+	}
+	for _, item := range t.RequestedItems() {
+		moves = append(moves, ItemMove{ItemID: item.ItemID, FromSheetID: t.ToSheetID, ToSheetID: t.FromSheetID, Quantity: item.Quantity})
+	}
+	return moves
+}
+
+// ToResponse converte a troca para a resposta da API
+func (t *ItemTrade) ToResponse() *ItemTradeResponse {
+	return &ItemTradeResponse{
+		ID:          t.ID,
+		TableID:     t.TableID,
+		FromSheetID: t.FromSheetID,
+		ToSheetID:   t.ToSheetID,
+		Offered:     t.OfferedItems(),
+		Requested:   t.RequestedItems(),
+		Status:      t.Status,
+		CreatedBy:   t.CreatedBy,
+		ResolvedBy:  t.ResolvedBy,
+		CreatedAt:   t.CreatedAt,
+		ResolvedAt:  t.ResolvedAt,
+	}
+}
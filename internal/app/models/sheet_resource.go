@@ -0,0 +1,96 @@
+package models
+
+import "encoding/json"
+
+// Operações sobre os recursos da ficha, enviadas nas notificações
+const (
+	SheetActionDamage  = "damage"
+	SheetActionHeal    = "heal"
+	SheetActionTempHP  = "temp_hp"
+	SheetActionSpend   = "spend"
+	SheetActionRestore = "restore"
+)
+
+// Defesas da ficha contra um tipo de dano
+const (
+	DamageDefenseResistance    = "resistance"    // Dano pela metade, arredondado para baixo
+	DamageDefenseImmunity      = "immunity"      // Nenhum dano
+	DamageDefenseVulnerability = "vulnerability" // Dano dobrado
+)
+
+// DefaultHealthResource é o recurso de dano, cura e PV temporários quando a requisição não informa outro
+const DefaultHealthResource = "hp"
+
+// SheetRules representa os recursos, as defesas e a capacidade de carga que um template declara nos dados das fichas
+type SheetRules struct {
+	Resources map[string]ResourceRule `json:"resources,omitempty"` // Por nome (e.g., "hp", "ki", "slots_1")
+	Defenses  *DefenseRule            `json:"defenses,omitempty"`
+	Inventory *InventoryRule          `json:"inventory,omitempty"`
+}
+
+// ResourceRule define o campo numérico de um recurso na ficha, seus limites e quando ele é renovado
+// (e.g., {"path": "hp.current", "max_path": "hp.max", "temp_path": "hp.temp", "refresh": "long_rest"})
+type ResourceRule struct {
+	Path           string `json:"path" example:"hp.current"`
+	Min            int    `json:"min,omitempty" example:"0"`              // Padrão: 0
+	Max            *int   `json:"max,omitempty" example:"5"`              // Máximo fixo
+	MaxPath        string `json:"max_path,omitempty" example:"hp.max"`    // Campo da ficha com o máximo
+	TempPath       string `json:"temp_path,omitempty" example:"hp.temp"`  // Campo dos pontos temporários, consumidos antes no dano
+	Refresh        string `json:"refresh,omitempty" example:"short_rest"` // short_rest, long_rest, daily ou manual (padrão)
+	RefreshAmount  *int   `json:"refresh_amount,omitempty" example:"1"`   // Quantidade recuperada no descanso; padrão: até o máximo
+	RefreshPercent *int   `json:"refresh_percent,omitempty" example:"50"` // Porcentagem do máximo recuperada (e.g., metade dos dados de vida), no mínimo 1
+}
+
+// DefenseRule define os campos da ficha com as listas de tipos de dano (e.g., ["fire", "poison"])
+type DefenseRule struct {
+	Resistances     string `json:"resistances,omitempty" example:"defenses.resistances"`
+	Immunities      string `json:"immunities,omitempty" example:"defenses.immunities"`
+	Vulnerabilities string `json:"vulnerabilities,omitempty" example:"defenses.vulnerabilities"`
+}
+
+// DamageRequest representa dano aplicado a um recurso da ficha
+type DamageRequest struct {
+	Resource   string `json:"resource,omitempty" binding:"omitempty,max=50" example:"hp"` // Padrão: hp
+	Amount     int    `json:"amount" binding:"required,min=1,max=100000" example:"12"`
+	DamageType string `json:"damage_type,omitempty" binding:"omitempty,max=50" example:"fire"`
+}
+
+// ResourceAmountRequest representa cura, PV temporários, gasto ou recuperação de um recurso da ficha
+type ResourceAmountRequest struct {
+	Resource string `json:"resource,omitempty" binding:"omitempty,max=50" example:"ki"` // Padrão: hp em cura e PV temporários
+	Amount   *int   `json:"amount,omitempty" binding:"omitempty,min=1,max=100000" example:"2"`
+}
+
+// SheetFieldChange representa a alteração de um campo numérico da ficha
+type SheetFieldChange struct {
+	Path  string `json:"path" example:"hp.current"`
+	Old   int    `json:"old" example:"30"`
+	New   int    `json:"new" example:"18"`
+	Delta int    `json:"delta" example:"-12"`
+}
+
+// SheetDeltaEvent representa uma operação sobre os recursos da ficha e os campos alterados, notificada à mesa
+type SheetDeltaEvent struct {
+	SheetID    string             `json:"sheet_id"`
+	TableID    string             `json:"table_id"`
+	Action     string             `json:"action" example:"damage"`
+	Resource   string             `json:"resource" example:"hp"`
+	Amount     int                `json:"amount" example:"24"`                    // Quantidade pedida
+	Applied    int                `json:"applied" example:"12"`                   // Quantidade após as defesas
+	DamageType string             `json:"damage_type,omitempty" example:"fire"`   // Apenas dano
+	Defense    string             `json:"defense,omitempty" example:"resistance"` // Defesa aplicada ao dano
+	Changes    []SheetFieldChange `json:"changes"`                                // Vazia quando nada mudou (e.g., imunidade)
+	UserID     int                `json:"user_id"`
+}
+
+// ParseSheetRules lê os recursos, as defesas e a capacidade de carga declarados na definition de um template
+func ParseSheetRules(data string) (*SheetRules, error) {
+	rules := &SheetRules{}
+	if data == "" {
+		return rules, nil
+	}
+	if err := json.Unmarshal([]byte(data), rules); err != nil {
+		return nil, err
+	}
+	return rules, nil
+}
@@ -0,0 +1,122 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Tipos de descanso e regras de renovação dos recursos declarados no template
+const (
+	RestShort     = "short_rest" // Renova os recursos short_rest
+	RestLong      = "long_rest"  // Renova os recursos short_rest e long_rest
+	RestDaily     = "daily"      // Novo dia (e.g., ao amanhecer): renova os recursos daily
+	RefreshManual = "manual"     // Renovado apenas pela recuperação manual do recurso
+)
+
+// RestRefreshes indica se o descanso renova um recurso com a regra de renovação informada
+func RestRefreshes(restType, refresh string) bool {
+	switch restType {
+	case RestShort:
+		return refresh == RestShort
+	case RestLong:
+		return refresh == RestShort || refresh == RestLong
+	case RestDaily:
+		return refresh == RestDaily
+	}
+	return false
+}
+
+// SheetRest registra um descanso de uma ficha e os campos renovados por ele
+type SheetRest struct {
+	ID        string    `json:"id" db:"id"`
+	SheetID   string    `json:"sheet_id" db:"sheet_id"`
+	TableID   string    `json:"table_id" db:"table_id"`
+	RestType  string    `json:"rest_type" db:"rest_type"`
+	Changes   string    `json:"-" db:"changes"` // JSON array de RestChange
+	CreatedBy int       `json:"created_by" db:"created_by"`
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+}
+
+// RestChange representa um campo renovado pelo descanso e o recurso a que pertence
+type RestChange struct {
+	Resource string `json:"resource" example:"ki"`
+	SheetFieldChange
+}
+
+// RestRequest representa um descanso de uma ficha ou da mesa inteira
+type RestRequest struct {
+	Type string `json:"type" binding:"required,oneof=short_rest long_rest daily" example:"short_rest"`
+}
+
+// SheetRestResponse representa um descanso de uma ficha na resposta da API
+type SheetRestResponse struct {
+	ID        string       `json:"id"`
+	SheetID   string       `json:"sheet_id"`
+	TableID   string       `json:"table_id"`
+	RestType  string       `json:"rest_type" example:"short_rest"`
+	Changes   []RestChange `json:"changes"` // Vazia quando nada precisava ser renovado
+	CreatedBy int          `json:"created_by"`
+	CreatedAt time.Time    `json:"created_at"`
+}
+
+// RestEvent representa um descanso de uma ou mais fichas da mesa, notificado à mesa
+type RestEvent struct {
+	TableID  string               `json:"table_id"`
+	RestType string               `json:"rest_type" example:"long_rest"`
+	Rests    []*SheetRestResponse `json:"rests"` // Uma por ficha
+	UserID   int                  `json:"user_id"`
+}
+
+// NewSheetRest cria o registro do descanso de uma ficha, ainda sem campos renovados
+func NewSheetRest(sheetID, tableID, restType string, createdBy int) *SheetRest {
+	return &SheetRest{
+		ID:        uuid.New().String(),
+		SheetID:   sheetID,
+		TableID:   tableID,
+		RestType:  restType,
+		Changes:   "[]",
+		CreatedBy: createdBy,
+		CreatedAt: time.Now(),
+	}
+}
+
+// SetChanges grava os campos renovados pelo descanso
+func (r *SheetRest) SetChanges(changes []RestChange) {
+	if len(changes) == 0 {
+		r.Changes = "[]"
+		return
+	}
+	changesJSON, _ := json.Marshal(changes)
+	r.Changes = string(changesJSON)
+}
+
+// ChangeList retorna os campos renovados pelo descanso
+func (r *SheetRest) ChangeList() []RestChange {
+	changes := []RestChange{}
+	_ = json.Unmarshal([]byte(r.Changes), &changes)
+	return changes
+}
+
+// ToResponse converte o descanso para a resposta da API
+func (r *SheetRest) ToResponse() *SheetRestResponse {
+	return &SheetRestResponse{
+		ID:        r.ID,
+		SheetID:   r.SheetID,
+		TableID:   r.TableID,
+		RestType:  r.RestType,
+		Changes:   r.ChangeList(),
+		CreatedBy: r.CreatedBy,
+		CreatedAt: r.CreatedAt,
+	}
+}
+
+// RestResponses converte uma lista de descansos para a resposta da API
+func RestResponses(rests []*SheetRest) []*SheetRestResponse {
+	responses := make([]*SheetRestResponse, len(rests))
+	for i, rest := range rests {
+		responses[i] = rest.ToResponse()
+	}
+	return responses
+}
@@ -0,0 +1,167 @@
+package repositories
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/luizdequeiroz/rpg-backend/internal/app/models"
+)
+
+// EncounterRepository gerencia os encontros de combate e seus participantes
+type EncounterRepository struct {
+	db *sqlx.DB
+}
+
+// NewEncounterRepository cria uma nova instância do repositório
+func NewEncounterRepository(db *sqlx.DB) *EncounterRepository {
+	return &EncounterRepository{db: db}
+}
+
+// Create cria um novo encontro
+func (r *EncounterRepository) Create(encounter *models.Encounter) error {
+	query := `
+		INSERT INTO encounters (id, table_id, name, status, tie_break, round, ticked_round, current_participant_id,
+		                        version, created_by, created_at, updated_at)
+		VALUES (:id, :table_id, :name, :status, :tie_break, :round, :ticked_round, :current_participant_id,
+		        :version, :created_by, :created_at, :updated_at)
+	`
+
+	_, err := r.db.NamedExec(query, encounter)
+	return err
+}
+
+// GetByID busca um encontro por ID
+func (r *EncounterRepository) GetByID(id string) (*models.Encounter, error) {
+	query := `
+		SELECT id, table_id, name, status, tie_break, round, ticked_round, current_participant_id,
+		       version, created_by, created_at, updated_at
+		FROM encounters
+		WHERE id = ?
+	`
+
+	var encounter models.Encounter
+	err := r.db.Get(&encounter, query, id)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &encounter, nil
+}
+
+// GetByTableID lista os encontros da mesa, do mais recente para o mais antigo
+func (r *EncounterRepository) GetByTableID(tableID string) ([]*models.Encounter, error) {
+	query := `
+		SELECT id, table_id, name, status, tie_break, round, ticked_round, current_participant_id,
+		       version, created_by, created_at, updated_at
+		FROM encounters
+		WHERE table_id = ?
+		ORDER BY created_at DESC
+	`
+
+	var encounters []*models.Encounter
+	err := r.db.Select(&encounters, query, tableID)
+	return encounters, err
+}
+
+// Delete remove o encontro e seus participantes
+func (r *EncounterRepository) Delete(id string) error {
+	tx, err := r.db.Beginx()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(`DELETE FROM encounter_participants WHERE encounter_id = ?`, id); err != nil {
+		return err
+	}
+	if _, err := tx.Exec(`DELETE FROM encounters WHERE id = ?`, id); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// GetParticipants lista os participantes do encontro na ordem dos turnos
+func (r *EncounterRepository) GetParticipants(encounterID string) ([]*models.EncounterParticipant, error) {
+	query := `
+		SELECT id, encounter_id, sheet_id, user_id, name, kind, initiative, initiative_modifier,
+		       tiebreaker, roll_id, position, status, ready_trigger, created_at
+		FROM encounter_participants
+		WHERE encounter_id = ?
+		ORDER BY position, created_at, id
+	`
+
+	var participants []*models.EncounterParticipant
+	err := r.db.Select(&participants, query, encounterID)
+	return participants, err
+}
+
+// Save grava o estado do encontro e dos participantes em uma única transação, removendo os participantes
+// informados em removedIDs. Na mesma transação, cada rodada ainda não descontada (ticked_round) desconta uma rodada
+// das condições das fichas do encontro; retorna as condições que expiraram. Retorna false, sem gravar, se o encontro
+// foi alterado desde que foi lido (version)
+func (r *EncounterRepository) Save(encounter *models.Encounter, participants []*models.EncounterParticipant, removedIDs ...string) (bool, []*models.SheetCondition, error) {
+	tx, err := r.db.Beginx()
+	if err != nil {
+		return false, nil, err
+	}
+	defer tx.Rollback()
+
+	// A rodada de início não desconta nada; voltar o turno não devolve as rodadas já descontadas
+	ticked := max(encounter.TickedRound, encounter.Round)
+
+	now := time.Now()
+	result, err := tx.Exec(`
+		UPDATE encounters
+		SET name = ?, status = ?, tie_break = ?, round = ?, ticked_round = ?, current_participant_id = ?,
+		    version = version + 1, updated_at = ?
+		WHERE id = ? AND version = ?
+	`, encounter.Name, encounter.Status, encounter.TieBreak, encounter.Round, ticked, encounter.CurrentParticipantID,
+		now, encounter.ID, encounter.Version)
+	if err != nil {
+		return false, nil, err
+	}
+	if affected, err := result.RowsAffected(); err != nil || affected == 0 {
+		return false, nil, err
+	}
+
+	for _, id := range removedIDs {
+		if _, err := tx.Exec(`DELETE FROM encounter_participants WHERE id = ? AND encounter_id = ?`, id, encounter.ID); err != nil {
+			return false, nil, err
+		}
+	}
+
+	query := `
+		INSERT INTO encounter_participants (id, encounter_id, sheet_id, user_id, name, kind, initiative,
+		                                    initiative_modifier, tiebreaker, roll_id, position, status, ready_trigger, created_at)
+		VALUES (:id, :encounter_id, :sheet_id, :user_id, :name, :kind, :initiative,
+		        :initiative_modifier, :tiebreaker, :roll_id, :position, :status, :ready_trigger, :created_at)
+		ON CONFLICT (id) DO UPDATE SET
+		    name = excluded.name, initiative = excluded.initiative, initiative_modifier = excluded.initiative_modifier,
+		    tiebreaker = excluded.tiebreaker, roll_id = excluded.roll_id, position = excluded.position,
+		    status = excluded.status, ready_trigger = excluded.ready_trigger
+	`
+	for _, participant := range participants {
+		if _, err := tx.NamedExec(query, participant); err != nil {
+			return false, nil, err
+		}
+	}
+
+	var expired []*models.SheetCondition
+	if encounter.TickedRound > 0 && ticked > encounter.TickedRound {
+		if expired, err = tickEncounterConditions(tx, encounter.ID, ticked-encounter.TickedRound); err != nil {
+			return false, nil, err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return false, nil, err
+	}
+	encounter.TickedRound = ticked
+	encounter.Version++
+	encounter.UpdatedAt = now
+	return true, expired, nil
+}
@@ -22,12 +22,12 @@ func NewGameTableRepository(database *db.DB) *GameTableRepository {
 // Create cria uma nova mesa de jogo
 func (r *GameTableRepository) Create(table *models.GameTable) error {
 	query := `
-		INSERT INTO game_tables (id, name, system, owner_id, created_at, updated_at)
-		VALUES (?, ?, ?, ?, ?, ?)
+		INSERT INTO game_tables (id, name, system, owner_id, settings, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?)
 	`
 
 	_, err := r.db.Exec(query,
-		table.ID, table.Name, table.System, table.OwnerID,
+		table.ID, table.Name, table.System, table.OwnerID, table.Settings,
 		table.CreatedAt, table.UpdatedAt,
 	)
 
@@ -39,7 +39,7 @@ func (r *GameTableRepository) GetByID(id string) (*models.GameTable, error) {
 	var table models.GameTable
 
 	query := `
-		SELECT id, name, system, owner_id, created_at, updated_at
+		SELECT id, name, system, owner_id, settings, created_at, updated_at
 		FROM game_tables 
 		WHERE id = ?
 	`
@@ -60,7 +60,7 @@ func (r *GameTableRepository) GetByOwnerID(ownerID int, offset, limit int) ([]*m
 	var tables []*models.GameTable
 
 	query := `
-		SELECT id, name, system, owner_id, created_at, updated_at
+		SELECT id, name, system, owner_id, settings, created_at, updated_at
 		FROM game_tables 
 		WHERE owner_id = ?
 		ORDER BY created_at DESC
@@ -123,11 +123,11 @@ func (r *GameTableRepository) GetTablesForUser(userID int, offset, limit int) ([
 func (r *GameTableRepository) Update(table *models.GameTable) error {
 	query := `
 		UPDATE game_tables 
-		SET name = ?, system = ?, updated_at = ?
+		SET name = ?, system = ?, settings = ?, updated_at = ?
 		WHERE id = ?
 	`
 
-	result, err := r.db.Exec(query, table.Name, table.System, table.UpdatedAt, table.ID)
+	result, err := r.db.Exec(query, table.Name, table.System, table.Settings, table.UpdatedAt, table.ID)
 	if err != nil {
 		return err
 	}
@@ -4,6 +4,7 @@ import (
 	"database/sql"
 	"encoding/json"
 	"fmt"
+	"time"
 
 	"github.com/jmoiron/sqlx"
 	"github.com/luizdequeiroz/rpg-backend/internal/app/models"
@@ -51,6 +52,20 @@ func (r *PlayerSheetRepository) GetByID(id string) (*models.PlayerSheet, error)
 	return &sheet, err
 }
 
+// GetAllByTableID busca todas as fichas da mesa com os dados, da mais antiga para a mais recente
+func (r *PlayerSheetRepository) GetAllByTableID(tableID string) ([]*models.PlayerSheet, error) {
+	query := `
+		SELECT id, table_id, template_id, owner_id, name, data, created_at, updated_at
+		FROM player_sheets 
+		WHERE table_id = ?
+		ORDER BY created_at, id
+	`
+
+	var sheets []*models.PlayerSheet
+	err := r.db.Select(&sheets, query, tableID)
+	return sheets, err
+}
+
 // GetByTableID lista fichas por mesa
 func (r *PlayerSheetRepository) GetByTableID(tableID string, offset, limit int) ([]*models.PlayerSheetListResponse, error) {
 	// Primeiro buscar apenas as fichas
@@ -183,6 +198,23 @@ func (r *PlayerSheetRepository) Update(sheet *models.PlayerSheet) error {
 	return err
 }
 
+// UpdateData grava os dados da ficha apenas se eles não mudaram desde a leitura (previousData).
+// Retorna false, sem gravar, se outra alteração chegou antes
+func (r *PlayerSheetRepository) UpdateData(id, previousData, data string, updatedAt time.Time) (bool, error) {
+	query := `
+		UPDATE player_sheets 
+		SET data = ?, updated_at = ?
+		WHERE id = ? AND data = ?
+	`
+
+	result, err := r.db.Exec(query, data, updatedAt, id, previousData)
+	if err != nil {
+		return false, err
+	}
+	affected, err := result.RowsAffected()
+	return affected > 0, err
+}
+
 // Delete remove ficha
 func (r *PlayerSheetRepository) Delete(id string) error {
 	query := `DELETE FROM player_sheets WHERE id = ?`
@@ -3,6 +3,7 @@ package repositories
 import (
 	"database/sql"
 	"encoding/json"
+	"strings"
 	"time"
 
 	"github.com/jmoiron/sqlx"
@@ -19,48 +20,88 @@ func NewRollRepository(db *sqlx.DB) *RollRepository {
 
 // Create cria uma nova rolagem
 func (r *RollRepository) Create(roll *models.Roll) error {
+	return insertRoll(r.db, roll)
+}
+
+// CreateBatch cria as rolagens de um grupo em uma única transação
+func (r *RollRepository) CreateBatch(rolls []*models.Roll) error {
+	tx, err := r.db.Beginx()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	for _, roll := range rolls {
+		if err := insertRoll(tx, roll); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+// insertRoll insere a rolagem usando o banco ou uma transação
+func insertRoll(db sqlx.Ext, roll *models.Roll) error {
 	query := `
 		INSERT INTO rolls (id, sheet_id, table_id, user_id, expression, field_name, 
-		                  result_value, result_details, success, created_at)
+		                  result_value, result_details, success, successes, failures, outcome, difficulty, visibility, created_at,
+		                  seed_id, server_seed_hash, client_seed, nonce, rules, contest_id, batch_id, macro_id, label, tags)
 		VALUES (:id, :sheet_id, :table_id, :user_id, :expression, :field_name, 
-		        :result_value, :result_details, :success, :created_at)
+		        :result_value, :result_details, :success, :successes, :failures, :outcome, :difficulty, :visibility, :created_at,
+		        :seed_id, :server_seed_hash, :client_seed, :nonce, :rules, :contest_id, :batch_id, :macro_id, :label, :tags)
 	`
 
-	// Preparar detalhes como JSON
-	details := models.RollDetails{
-		Total: roll.ResultValue,
+	// Preparar detalhes como JSON quando o chamador não informou o detalhamento
+	if roll.ResultDetails == "" {
+		details := models.RollDetails{
+			Total: roll.ResultValue,
+		}
+		detailsJSON, _ := json.Marshal(details)
+		roll.ResultDetails = string(detailsJSON)
 	}
-	detailsJSON, _ := json.Marshal(details)
-	roll.ResultDetails = string(detailsJSON)
-	roll.CreatedAt = time.Now()
+	// Gravado em UTC: o driver salva a data como texto, comparado por prefixo nos filtros de data. As rolagens
+	// gravadas antes na hora local foram convertidas pela migração normalize_roll_created_at
+	roll.CreatedAt = time.Now().UTC()
 
-	_, err := r.db.NamedExec(query, roll)
+	_, err := sqlx.NamedExec(db, query, roll)
 	return err
 }
 
-// GetByUserID recupera rolagens por usuário com paginação
-func (r *RollRepository) GetByUserID(userID, limit, offset int) ([]models.Roll, error) {
+// GetByID busca uma rolagem por ID
+func (r *RollRepository) GetByID(id string) (*models.Roll, error) {
 	query := `
 		SELECT id, sheet_id, table_id, user_id, expression, field_name, 
-		       result_value, result_details, success, created_at
+		       result_value, result_details, success, successes, failures, outcome, difficulty, visibility, created_at,
+		       seed_id, server_seed_hash, client_seed, nonce, rules, contest_id, batch_id, macro_id,
+		       voided_at, voided_by, void_reason, label, tags
 		FROM rolls 
-		WHERE user_id = ? 
-		ORDER BY created_at DESC 
-		LIMIT ? OFFSET ?
+		WHERE id = ?
 	`
 
-	var rolls []models.Roll
-	err := r.db.Select(&rolls, query, userID, limit, offset)
-	return rolls, err
+	var roll models.Roll
+	err := r.db.Get(&roll, query, id)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &roll, nil
 }
 
-// CountByUserID conta rolagens do usuário
-func (r *RollRepository) CountByUserID(userID int) (int, error) {
-	query := `SELECT COUNT(*) FROM rolls WHERE user_id = ?`
+// Void marca a rolagem como anulada; retorna false se ela já estava anulada
+func (r *RollRepository) Void(id string, voidedBy int, reason string) (bool, error) {
+	query := `
+		UPDATE rolls SET voided_at = ?, voided_by = ?, void_reason = ?
+		WHERE id = ? AND voided_at IS NULL
+	`
 
-	var count int
-	err := r.db.Get(&count, query, userID)
-	return count, err
+	result, err := r.db.Exec(query, time.Now().UTC(), voidedBy, reason, id)
+	if err != nil {
+		return false, err
+	}
+	affected, err := result.RowsAffected()
+	return affected > 0, err
 }
 
 // UpdateSheetID atualiza o sheet_id de uma rolagem
@@ -74,7 +115,9 @@ func (r *RollRepository) UpdateSheetID(rollID, sheetID string) error {
 func (r *RollRepository) GetBySheetID(sheetID string) ([]models.Roll, error) {
 	query := `
 		SELECT id, sheet_id, table_id, user_id, expression, field_name, 
-		       result_value, result_details, success, created_at
+		       result_value, result_details, success, successes, failures, outcome, difficulty, visibility, created_at,
+		       seed_id, server_seed_hash, client_seed, nonce, rules, contest_id, batch_id, macro_id,
+		       voided_at, voided_by, void_reason, label, tags
 		FROM rolls 
 		WHERE sheet_id = ? 
 		ORDER BY created_at DESC
@@ -85,102 +128,170 @@ func (r *RollRepository) GetBySheetID(sheetID string) ([]models.Roll, error) {
 	return rolls, err
 }
 
-// GetByTableID lista rolagens de uma mesa
-func (r *RollRepository) GetByTableID(tableID string, offset, limit int) ([]*models.RollResponse, error) {
-	query := `
-		SELECT 
-			r.id, r.sheet_id, r.table_id, r.user_id, r.expression, r.field_name,
-			r.result_value, r.result_details, r.success, r.created_at,
-			u.id as "user.id", u.email as "user.email"
-		FROM rolls r
-		LEFT JOIN users u ON r.user_id = u.id
-		WHERE r.table_id = ?
-		ORDER BY r.created_at DESC
-		LIMIT ? OFFSET ?
-	`
+// visibleRollsFilter restringe as rolagens às que o usuário pode ver: públicas, as próprias e,
+// para o mestre, as rolagens "gm" e "blind" dos jogadores
+const visibleRollsFilter = `(r.visibility = 'public' OR r.user_id = ? OR (? AND r.visibility IN ('gm', 'blind')))`
 
-	rows, err := r.db.Query(query, tableID, limit, offset)
-	if err != nil {
-		return nil, err
-	}
-	defer rows.Close()
+// fullRollsFilter restringe as rolagens às que o usuário pode ver com resultado: públicas, as próprias
+// exceto "blind" e, para o mestre, as rolagens "gm" e "blind" dos jogadores
+const fullRollsFilter = `(r.visibility = 'public' OR (r.user_id = ? AND r.visibility != 'blind') OR (? AND r.visibility IN ('gm', 'blind')))`
 
-	var rolls []*models.RollResponse
+// rollTimeFormat é o prefixo de created_at comparado nos filtros de data
+const rollTimeFormat = "2006-01-02 15:04:05"
 
-	for rows.Next() {
-		var roll models.RollResponse
-		var user models.UserResponse
-		var detailsJSON sql.NullString
+// rollColumns são as colunas de uma rolagem lidas nas buscas
+const rollColumns = `r.id, r.sheet_id, r.table_id, r.user_id, r.expression, r.field_name,
+		r.result_value, r.result_details, r.success, r.successes, r.failures, r.outcome, r.difficulty, r.visibility, r.created_at,
+		r.seed_id, r.server_seed_hash, r.client_seed, r.nonce, r.rules, r.contest_id, r.batch_id, r.macro_id,
+		r.voided_at, r.voided_by, r.void_reason, r.label, r.tags`
 
-		err := rows.Scan(
-			&roll.ID, &roll.SheetID, &roll.TableID, &roll.UserID, &roll.Expression, &roll.FieldName,
-			&roll.ResultValue, &detailsJSON, &roll.Success, &roll.CreatedAt,
-			&user.ID, &user.Email,
-		)
-		if err != nil {
-			return nil, err
-		}
+// rollReactionsColumn lê as reações de cada rolagem como JSON, na ordem em que foram feitas
+const rollReactionsColumn = `(SELECT json_group_array(json_object('user_id', rr.user_id, 'emoji', rr.emoji))
+		FROM (SELECT user_id, emoji FROM roll_reactions WHERE roll_id = r.id ORDER BY created_at, rowid) rr) AS reactions`
 
-		// Parse details JSON
-		if detailsJSON.Valid {
-			var details models.RollDetails
-			json.Unmarshal([]byte(detailsJSON.String), &details)
-			roll.ResultDetails = &details
-		}
+// rollSortColumns mapeia os campos de ordenação para as colunas da tabela
+var rollSortColumns = map[string]string{
+	models.RollSortCreatedAt: "r.created_at",
+	models.RollSortResult:    "r.result_value",
+}
 
-		roll.User = &user
-		rolls = append(rolls, &roll)
-	}
+// Search lista as rolagens do filtro visíveis ao usuário, com o e-mail de quem rolou e as reações.
+// Filtros pelo resultado consideram apenas as rolagens cujo resultado o usuário pode ver
+func (r *RollRepository) Search(filter models.RollFilter, sort models.RollSort, viewerID int, viewerIsGM bool, offset, limit int) ([]models.RollWithUser, error) {
+	where, args := rollSearchConditions(filter, viewerID, viewerIsGM)
 
-	return rolls, nil
-}
+	direction := "ASC"
+	if sort.Descending {
+		direction = "DESC"
+	}
+	column, ok := rollSortColumns[sort.Field]
+	if !ok {
+		column = rollSortColumns[models.RollSortCreatedAt]
+	}
 
-// GetBySheetIDWithPagination lista rolagens de uma ficha com paginação
-func (r *RollRepository) GetBySheetIDWithPagination(sheetID string, offset, limit int) ([]*models.RollResponse, error) {
 	query := `
-		SELECT 
-			r.id, r.sheet_id, r.table_id, r.user_id, r.expression, r.field_name,
-			r.result_value, r.result_details, r.success, r.created_at,
-			u.id as "user.id", u.email as "user.email"
+		SELECT ` + rollColumns + `, u.email AS user_email, ` + rollReactionsColumn + `
 		FROM rolls r
 		LEFT JOIN users u ON r.user_id = u.id
-		WHERE r.sheet_id = ?
-		ORDER BY r.created_at DESC
+		WHERE ` + where + `
+		ORDER BY ` + column + ` ` + direction + `, r.created_at ` + direction + `, r.id
 		LIMIT ? OFFSET ?
 	`
 
-	rows, err := r.db.Query(query, sheetID, limit, offset)
-	if err != nil {
-		return nil, err
-	}
-	defer rows.Close()
+	var rolls []models.RollWithUser
+	err := r.db.Select(&rolls, query, append(args, limit, offset)...)
+	return rolls, err
+}
 
-	var rolls []*models.RollResponse
+// Count conta as rolagens do filtro visíveis ao usuário, com as mesmas regras de Search
+func (r *RollRepository) Count(filter models.RollFilter, viewerID int, viewerIsGM bool) (int, error) {
+	where, args := rollSearchConditions(filter, viewerID, viewerIsGM)
+	query := `SELECT COUNT(*) FROM rolls r WHERE ` + where
 
-	for rows.Next() {
-		var roll models.RollResponse
-		var user models.UserResponse
-		var detailsJSON sql.NullString
+	var count int
+	err := r.db.Get(&count, query, args...)
+	return count, err
+}
 
-		err := rows.Scan(
-			&roll.ID, &roll.SheetID, &roll.TableID, &roll.UserID, &roll.Expression, &roll.FieldName,
-			&roll.ResultValue, &detailsJSON, &roll.Success, &roll.CreatedAt,
-			&user.ID, &user.Email,
-		)
-		if err != nil {
-			return nil, err
-		}
+// GetForStats lista, da mais antiga para a mais recente, as rolagens válidas do filtro cujo resultado o
+// usuário pode ver; rolagens anuladas ficam de fora
+func (r *RollRepository) GetForStats(filter models.RollFilter, viewerID int, viewerIsGM bool) ([]models.Roll, error) {
+	conditions, args := rollFilterConditions(filter)
+	conditions = append([]string{fullRollsFilter, "r.voided_at IS NULL"}, conditions...)
+	args = append([]interface{}{viewerID, viewerIsGM}, args...)
 
-		// Parse details JSON
-		if detailsJSON.Valid {
-			var details models.RollDetails
-			json.Unmarshal([]byte(detailsJSON.String), &details)
-			roll.ResultDetails = &details
-		}
+	query := `
+		SELECT ` + rollColumns + `
+		FROM rolls r
+		WHERE ` + strings.Join(conditions, " AND ") + `
+		ORDER BY r.created_at ASC
+	`
+
+	var rolls []models.Roll
+	err := r.db.Select(&rolls, query, args...)
+	return rolls, err
+}
+
+// rollSearchConditions combina a regra de visibilidade com as condições do filtro
+func rollSearchConditions(filter models.RollFilter, viewerID int, viewerIsGM bool) (string, []interface{}) {
+	visibility := visibleRollsFilter
+	if filter.HasResultFilters() {
+		visibility = fullRollsFilter
+	}
+
+	conditions, args := rollFilterConditions(filter)
+	conditions = append([]string{visibility}, conditions...)
+	args = append([]interface{}{viewerID, viewerIsGM}, args...)
+	return strings.Join(conditions, " AND "), args
+}
 
-		roll.User = &user
-		rolls = append(rolls, &roll)
+// rollFilterConditions monta as condições do filtro; os valores vão sempre como parâmetros
+func rollFilterConditions(filter models.RollFilter) ([]string, []interface{}) {
+	var conditions []string
+	var args []interface{}
+	add := func(condition string, value interface{}) {
+		conditions = append(conditions, condition)
+		args = append(args, value)
 	}
 
-	return rolls, nil
+	if filter.UserID != nil {
+		add("r.user_id = ?", *filter.UserID)
+	}
+	if filter.SheetID != nil {
+		add("r.sheet_id = ?", *filter.SheetID)
+	}
+	if filter.TableID != nil {
+		add("r.table_id = ?", *filter.TableID)
+	}
+	if filter.From != nil {
+		add("r.created_at >= ?", filter.From.UTC().Format(rollTimeFormat))
+	}
+	if filter.To != nil {
+		add("r.created_at < ?", filter.To.UTC().Format(rollTimeFormat))
+	}
+	if filter.FieldName != nil {
+		add("r.field_name = ?", *filter.FieldName)
+	}
+	if filter.Expression != nil {
+		add(`r.expression LIKE ? ESCAPE '\'`, likePattern(*filter.Expression))
+	}
+	if filter.Critical != nil {
+		add("COALESCE(json_extract(r.result_details, '$.critical'), 0) = ?", *filter.Critical)
+	}
+	if filter.Fumble != nil {
+		add("COALESCE(json_extract(r.result_details, '$.fumble'), 0) = ?", *filter.Fumble)
+	}
+	if filter.Success != nil {
+		add("r.success = ?", *filter.Success)
+	}
+	if filter.Outcome != nil {
+		add("r.outcome = ?", *filter.Outcome)
+	}
+	if filter.Voided != nil {
+		if *filter.Voided {
+			conditions = append(conditions, "r.voided_at IS NOT NULL")
+		} else {
+			conditions = append(conditions, "r.voided_at IS NULL")
+		}
+	}
+	if filter.Label != nil {
+		add(`r.label LIKE ? ESCAPE '\'`, likePattern(*filter.Label))
+	}
+	for _, tag := range filter.Tags {
+		add("EXISTS (SELECT 1 FROM json_each(r.tags) WHERE json_each.value = ?)", tag)
+	}
+	if filter.MinResult != nil {
+		add("r.result_value >= ?", *filter.MinResult)
+	}
+	if filter.MaxResult != nil {
+		add("r.result_value <= ?", *filter.MaxResult)
+	}
+
+	return conditions, args
+}
+
+// likePattern monta o padrão LIKE que busca o texto em qualquer posição, escapando os curingas
+func likePattern(text string) string {
+	escaped := strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(text)
+	return "%" + escaped + "%"
 }
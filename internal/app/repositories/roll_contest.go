@@ -0,0 +1,65 @@
+package repositories
+
+import (
+	"database/sql"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/luizdequeiroz/rpg-backend/internal/app/models"
+)
+
+// RollContestRepository gerencia as rolagens resistidas entre fichas
+type RollContestRepository struct {
+	db *sqlx.DB
+}
+
+// NewRollContestRepository cria uma nova instância do repositório
+func NewRollContestRepository(db *sqlx.DB) *RollContestRepository {
+	return &RollContestRepository{db: db}
+}
+
+// Create salva a disputa e as duas rolagens em uma única transação
+func (r *RollContestRepository) Create(contest *models.RollContest, attacker, defender *models.Roll) error {
+	query := `
+		INSERT INTO roll_contests (id, table_id, user_id, attacker_sheet_id, defender_sheet_id,
+		                           attacker_roll_id, defender_roll_id, tie_rule, winner, margin, created_at)
+		VALUES (:id, :table_id, :user_id, :attacker_sheet_id, :defender_sheet_id,
+		        :attacker_roll_id, :defender_roll_id, :tie_rule, :winner, :margin, :created_at)
+	`
+
+	tx, err := r.db.Beginx()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.NamedExec(query, contest); err != nil {
+		return err
+	}
+	for _, roll := range []*models.Roll{attacker, defender} {
+		if err := insertRoll(tx, roll); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+// GetByID busca uma disputa por ID
+func (r *RollContestRepository) GetByID(id string) (*models.RollContest, error) {
+	query := `
+		SELECT id, table_id, user_id, attacker_sheet_id, defender_sheet_id,
+		       attacker_roll_id, defender_roll_id, tie_rule, winner, margin, created_at
+		FROM roll_contests
+		WHERE id = ?
+	`
+
+	var contest models.RollContest
+	err := r.db.Get(&contest, query, id)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &contest, nil
+}
@@ -0,0 +1,122 @@
+package repositories
+
+import (
+	"database/sql"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/luizdequeiroz/rpg-backend/internal/app/models"
+)
+
+// RollMacroRepository gerencia as macros de rolagem
+type RollMacroRepository struct {
+	db *sqlx.DB
+}
+
+// NewRollMacroRepository cria uma nova instância do repositório
+func NewRollMacroRepository(db *sqlx.DB) *RollMacroRepository {
+	return &RollMacroRepository{db: db}
+}
+
+// Create cria uma nova macro
+func (r *RollMacroRepository) Create(macro *models.RollMacro) error {
+	query := `
+		INSERT INTO roll_macros (id, user_id, sheet_id, name, description, steps, created_at, updated_at)
+		VALUES (:id, :user_id, :sheet_id, :name, :description, :steps, :created_at, :updated_at)
+	`
+
+	_, err := r.db.NamedExec(query, macro)
+	return err
+}
+
+// GetByID busca uma macro por ID
+func (r *RollMacroRepository) GetByID(id string) (*models.RollMacro, error) {
+	query := `
+		SELECT id, user_id, sheet_id, name, description, steps, created_at, updated_at
+		FROM roll_macros
+		WHERE id = ?
+	`
+
+	return r.get(query, id)
+}
+
+// GetBySheetAndName busca uma macro da ficha pelo nome
+func (r *RollMacroRepository) GetBySheetAndName(sheetID, name string) (*models.RollMacro, error) {
+	query := `
+		SELECT id, user_id, sheet_id, name, description, steps, created_at, updated_at
+		FROM roll_macros
+		WHERE sheet_id = ? AND name = ?
+	`
+
+	return r.get(query, sheetID, name)
+}
+
+// GetByUserAndName busca uma macro do usuário (sem ficha) pelo nome
+func (r *RollMacroRepository) GetByUserAndName(userID int, name string) (*models.RollMacro, error) {
+	query := `
+		SELECT id, user_id, sheet_id, name, description, steps, created_at, updated_at
+		FROM roll_macros
+		WHERE user_id = ? AND sheet_id IS NULL AND name = ?
+	`
+
+	return r.get(query, userID, name)
+}
+
+// GetByUserID lista as macros do usuário (sem ficha)
+func (r *RollMacroRepository) GetByUserID(userID int) ([]*models.RollMacro, error) {
+	query := `
+		SELECT id, user_id, sheet_id, name, description, steps, created_at, updated_at
+		FROM roll_macros
+		WHERE user_id = ? AND sheet_id IS NULL
+		ORDER BY name
+	`
+
+	var macros []*models.RollMacro
+	err := r.db.Select(&macros, query, userID)
+	return macros, err
+}
+
+// GetBySheetID lista as macros da ficha
+func (r *RollMacroRepository) GetBySheetID(sheetID string) ([]*models.RollMacro, error) {
+	query := `
+		SELECT id, user_id, sheet_id, name, description, steps, created_at, updated_at
+		FROM roll_macros
+		WHERE sheet_id = ?
+		ORDER BY name
+	`
+
+	var macros []*models.RollMacro
+	err := r.db.Select(&macros, query, sheetID)
+	return macros, err
+}
+
+// Update atualiza uma macro
+func (r *RollMacroRepository) Update(macro *models.RollMacro) error {
+	query := `
+		UPDATE roll_macros
+		SET name = :name, description = :description, steps = :steps, updated_at = :updated_at
+		WHERE id = :id
+	`
+
+	_, err := r.db.NamedExec(query, macro)
+	return err
+}
+
+// Delete remove uma macro
+func (r *RollMacroRepository) Delete(id string) error {
+	query := `DELETE FROM roll_macros WHERE id = ?`
+	_, err := r.db.Exec(query, id)
+	return err
+}
+
+// get busca uma única macro; retorna nil se não existir
+func (r *RollMacroRepository) get(query string, args ...interface{}) (*models.RollMacro, error) {
+	var macro models.RollMacro
+	err := r.db.Get(&macro, query, args...)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &macro, nil
+}
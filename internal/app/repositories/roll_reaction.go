@@ -0,0 +1,68 @@
+package repositories
+
+import (
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/luizdequeiroz/rpg-backend/internal/app/models"
+)
+
+// RollReactionRepository gerencia as reações (emoji) às rolagens
+type RollReactionRepository struct {
+	db *sqlx.DB
+}
+
+// NewRollReactionRepository cria uma nova instância do repositório
+func NewRollReactionRepository(db *sqlx.DB) *RollReactionRepository {
+	return &RollReactionRepository{db: db}
+}
+
+// Add registra a reação do usuário; retorna false se ele já tinha reagido com o mesmo emoji
+func (r *RollReactionRepository) Add(rollID string, userID int, emoji string) (bool, error) {
+	query := `
+		INSERT OR IGNORE INTO roll_reactions (roll_id, user_id, emoji, created_at)
+		VALUES (?, ?, ?, ?)
+	`
+
+	result, err := r.db.Exec(query, rollID, userID, emoji, time.Now().UTC())
+	if err != nil {
+		return false, err
+	}
+	affected, err := result.RowsAffected()
+	return affected > 0, err
+}
+
+// Remove remove a reação do usuário; retorna false se ela não existia
+func (r *RollReactionRepository) Remove(rollID string, userID int, emoji string) (bool, error) {
+	query := `DELETE FROM roll_reactions WHERE roll_id = ? AND user_id = ? AND emoji = ?`
+
+	result, err := r.db.Exec(query, rollID, userID, emoji)
+	if err != nil {
+		return false, err
+	}
+	affected, err := result.RowsAffected()
+	return affected > 0, err
+}
+
+// CountByUser conta os emojis com que o usuário reagiu à rolagem
+func (r *RollReactionRepository) CountByUser(rollID string, userID int) (int, error) {
+	query := `SELECT COUNT(*) FROM roll_reactions WHERE roll_id = ? AND user_id = ?`
+
+	var count int
+	err := r.db.Get(&count, query, rollID, userID)
+	return count, err
+}
+
+// GetByRollID lista as reações da rolagem na ordem em que foram feitas
+func (r *RollReactionRepository) GetByRollID(rollID string) ([]models.RollReactionRecord, error) {
+	query := `
+		SELECT roll_id, user_id, emoji, created_at
+		FROM roll_reactions
+		WHERE roll_id = ?
+		ORDER BY created_at, rowid
+	`
+
+	var reactions []models.RollReactionRecord
+	err := r.db.Select(&reactions, query, rollID)
+	return reactions, err
+}
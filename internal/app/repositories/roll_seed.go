@@ -0,0 +1,101 @@
+package repositories
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/luizdequeiroz/rpg-backend/internal/app/models"
+)
+
+// RollSeedRepository gerencia as sementes do servidor das rolagens verificáveis
+type RollSeedRepository struct {
+	db *sqlx.DB
+}
+
+// NewRollSeedRepository cria uma nova instância do repositório
+func NewRollSeedRepository(db *sqlx.DB) *RollSeedRepository {
+	return &RollSeedRepository{db: db}
+}
+
+// Create salva uma nova semente
+func (r *RollSeedRepository) Create(seed *models.RollSeed) error {
+	query := `
+		INSERT INTO roll_seeds (id, user_id, server_seed, server_seed_hash, next_nonce, hidden, revealed_at, created_at)
+		VALUES (:id, :user_id, :server_seed, :server_seed_hash, :next_nonce, :hidden, :revealed_at, :created_at)
+	`
+
+	_, err := r.db.NamedExec(query, seed)
+	return err
+}
+
+// GetByID busca uma semente por ID
+func (r *RollSeedRepository) GetByID(id string) (*models.RollSeed, error) {
+	query := `
+		SELECT id, user_id, server_seed, server_seed_hash, next_nonce, hidden, revealed_at, created_at
+		FROM roll_seeds
+		WHERE id = ?
+	`
+
+	var seed models.RollSeed
+	err := r.db.Get(&seed, query, id)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &seed, nil
+}
+
+// GetActiveByUserID busca a semente ainda não revelada das rolagens públicas ou ocultas do usuário
+func (r *RollSeedRepository) GetActiveByUserID(userID int, hidden bool) (*models.RollSeed, error) {
+	query := `
+		SELECT id, user_id, server_seed, server_seed_hash, next_nonce, hidden, revealed_at, created_at
+		FROM roll_seeds
+		WHERE user_id = ? AND hidden = ? AND revealed_at IS NULL
+		ORDER BY created_at DESC
+		LIMIT 1
+	`
+
+	var seed models.RollSeed
+	err := r.db.Get(&seed, query, userID, hidden)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &seed, nil
+}
+
+// ReserveNonce reserva atomicamente o próximo nonce de uma semente ainda não revelada.
+// Retorna sql.ErrNoRows se a semente já foi revelada
+func (r *RollSeedRepository) ReserveNonce(id string) (int, error) {
+	query := `
+		UPDATE roll_seeds
+		SET next_nonce = next_nonce + 1
+		WHERE id = ? AND revealed_at IS NULL
+		RETURNING next_nonce - 1
+	`
+
+	var nonce int
+	err := r.db.Get(&nonce, query, id)
+	return nonce, err
+}
+
+// Reveal marca a semente como revelada, encerrando seu uso em novas rolagens
+func (r *RollSeedRepository) Reveal(id string) error {
+	query := `UPDATE roll_seeds SET revealed_at = ? WHERE id = ? AND revealed_at IS NULL`
+	_, err := r.db.Exec(query, time.Now(), id)
+	return err
+}
+
+// CoversHiddenRolls verifica se a semente foi usada em alguma rolagem que não é pública
+func (r *RollSeedRepository) CoversHiddenRolls(id string) (bool, error) {
+	query := `SELECT EXISTS (SELECT 1 FROM rolls WHERE seed_id = ? AND visibility != ?)`
+
+	var covers bool
+	err := r.db.Get(&covers, query, id, models.RollVisibilityPublic)
+	return covers, err
+}
@@ -0,0 +1,114 @@
+package repositories
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/luizdequeiroz/rpg-backend/internal/app/models"
+	"github.com/luizdequeiroz/rpg-backend/pkg/db"
+)
+
+// newTestRollRepository cria o repositório sobre um banco em memória, exclusivo do teste, com todas as migrações
+func newTestRollRepository(t *testing.T) *RollRepository {
+	name := strings.NewReplacer("/", "_", " ", "_").Replace(t.Name())
+	database, err := db.NewDBWithDSN("file:" + name + "?mode=memory&cache=shared")
+	require.NoError(t, err)
+	t.Cleanup(func() { database.Close() })
+
+	require.NoError(t, db.NewMigrationManager(database, "../../../migrations").Up())
+	return NewRollRepository(database.DB)
+}
+
+// newTestRoll salva uma rolagem da mesa "table" com o resultado, a visibilidade e o rótulo informados
+func newTestRoll(t *testing.T, repo *RollRepository, userID, result int, visibility, label string) *models.Roll {
+	rollRecord := models.NewRoll("sheet", "table", userID, "1d20", nil)
+	rollRecord.ResultValue = result
+	rollRecord.Visibility = visibility
+	rollRecord.SetLabel(label, nil)
+	require.NoError(t, repo.Create(rollRecord))
+	return rollRecord
+}
+
+// searchIDs retorna os IDs das rolagens do filtro visíveis ao usuário, conferindo a contagem
+func searchIDs(t *testing.T, repo *RollRepository, filter models.RollFilter, viewerID int, viewerIsGM bool) []string {
+	rolls, err := repo.Search(filter, models.RollSort{Field: models.RollSortCreatedAt}, viewerID, viewerIsGM, 0, 100)
+	require.NoError(t, err)
+
+	count, err := repo.Count(filter, viewerID, viewerIsGM)
+	require.NoError(t, err)
+	assert.Equal(t, len(rolls), count)
+
+	ids := make([]string, len(rolls))
+	for i, rollRecord := range rolls {
+		ids[i] = rollRecord.ID
+	}
+	return ids
+}
+
+func TestLikePattern(t *testing.T) {
+	assert.Equal(t, `%furtivo%`, likePattern("furtivo"))
+	assert.Equal(t, `%100\%%`, likePattern("100%"))
+	assert.Equal(t, `%golpe\_baixo%`, likePattern("golpe_baixo"))
+	assert.Equal(t, `%C:\\x%`, likePattern(`C:\x`))
+}
+
+func TestSearchLabelEscapesWildcards(t *testing.T) {
+	repo := newTestRollRepository(t)
+	percent := newTestRoll(t, repo, 1, 10, models.RollVisibilityPublic, "100% de dano")
+	newTestRoll(t, repo, 1, 10, models.RollVisibilityPublic, "1000 de dano")
+	underscore := newTestRoll(t, repo, 1, 10, models.RollVisibilityPublic, "golpe_baixo")
+	newTestRoll(t, repo, 1, 10, models.RollVisibilityPublic, "golpeXbaixo")
+	backslash := newTestRoll(t, repo, 1, 10, models.RollVisibilityPublic, `C:\x`)
+	newTestRoll(t, repo, 1, 10, models.RollVisibilityPublic, "C:x")
+
+	search := func(label string) []string {
+		return searchIDs(t, repo, models.RollFilter{Label: &label}, 1, false)
+	}
+	assert.Equal(t, []string{percent.ID}, search("100%"))
+	assert.Equal(t, []string{underscore.ID}, search("golpe_baixo"))
+	assert.Equal(t, []string{backslash.ID}, search(`\`))
+}
+
+func TestSearchDateEndIsExclusive(t *testing.T) {
+	repo := newTestRollRepository(t)
+	lastSecond := newTestRoll(t, repo, 1, 10, models.RollVisibilityPublic, "")
+	nextDay := newTestRoll(t, repo, 1, 10, models.RollVisibilityPublic, "")
+	for id, createdAt := range map[string]time.Time{
+		lastSecond.ID: time.Date(2025, 8, 1, 23, 59, 59, 0, time.UTC),
+		nextDay.ID:    time.Date(2025, 8, 2, 0, 0, 0, 0, time.UTC),
+	} {
+		_, err := repo.db.Exec(`UPDATE rolls SET created_at = ? WHERE id = ?`, createdAt, id)
+		require.NoError(t, err)
+	}
+
+	search := func(from, to string) []string {
+		start, end, err := models.ParseRollPeriod(from, to)
+		require.NoError(t, err)
+		return searchIDs(t, repo, models.RollFilter{From: start, To: end}, 1, false)
+	}
+	// to=2025-08-01 inclui o último segundo do dia e nada do dia seguinte
+	assert.Equal(t, []string{lastSecond.ID}, search("", "2025-08-01"))
+	assert.Equal(t, []string{nextDay.ID}, search("2025-08-02", ""))
+	assert.Equal(t, []string{lastSecond.ID}, search("", "2025-08-02T00:00:00Z"))
+}
+
+func TestSearchResultFiltersHideUnseenResults(t *testing.T) {
+	repo := newTestRollRepository(t)
+	blind := newTestRoll(t, repo, 2, 20, models.RollVisibilityBlind, "")
+	public := newTestRoll(t, repo, 2, 20, models.RollVisibilityPublic, "")
+	gmRoll := newTestRoll(t, repo, 3, 20, models.RollVisibilityGM, "")
+
+	// Quem fez a rolagem "blind" a vê no histórico, mas sem resultado: um filtro de resultado não pode revelá-lo
+	assert.ElementsMatch(t, []string{blind.ID, public.ID}, searchIDs(t, repo, models.RollFilter{}, 2, false))
+	minResult := 15
+	assert.Equal(t, []string{public.ID}, searchIDs(t, repo, models.RollFilter{MinResult: &minResult}, 2, false))
+	critical := false
+	assert.Equal(t, []string{public.ID}, searchIDs(t, repo, models.RollFilter{Critical: &critical}, 2, false))
+
+	// O mestre vê o resultado de todas
+	assert.ElementsMatch(t, []string{blind.ID, public.ID, gmRoll.ID}, searchIDs(t, repo, models.RollFilter{MinResult: &minResult}, 1, true))
+}
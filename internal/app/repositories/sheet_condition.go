@@ -0,0 +1,94 @@
+package repositories
+
+import (
+	"database/sql"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/luizdequeiroz/rpg-backend/internal/app/models"
+)
+
+// sheetConditionColumns são as colunas lidas das condições
+const sheetConditionColumns = `id, sheet_id, table_id, name, source, duration_type, duration, remaining_rounds,
+	       save_dc, modifiers, created_by, created_at`
+
+// SheetConditionRepository gerencia as condições das fichas
+type SheetConditionRepository struct {
+	db *sqlx.DB
+}
+
+// NewSheetConditionRepository cria uma nova instância do repositório
+func NewSheetConditionRepository(db *sqlx.DB) *SheetConditionRepository {
+	return &SheetConditionRepository{db: db}
+}
+
+// Create aplica uma condição a uma ficha
+func (r *SheetConditionRepository) Create(condition *models.SheetCondition) error {
+	query := `
+		INSERT INTO sheet_conditions (id, sheet_id, table_id, name, source, duration_type, duration, remaining_rounds,
+		                              save_dc, modifiers, created_by, created_at)
+		VALUES (:id, :sheet_id, :table_id, :name, :source, :duration_type, :duration, :remaining_rounds,
+		        :save_dc, :modifiers, :created_by, :created_at)
+	`
+
+	_, err := r.db.NamedExec(query, condition)
+	return err
+}
+
+// GetByID busca uma condição por ID
+func (r *SheetConditionRepository) GetByID(id string) (*models.SheetCondition, error) {
+	query := `SELECT ` + sheetConditionColumns + ` FROM sheet_conditions WHERE id = ?`
+
+	var condition models.SheetCondition
+	err := r.db.Get(&condition, query, id)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &condition, nil
+}
+
+// GetBySheetID lista as condições da ficha, da mais antiga para a mais recente
+func (r *SheetConditionRepository) GetBySheetID(sheetID string) ([]*models.SheetCondition, error) {
+	query := `SELECT ` + sheetConditionColumns + ` FROM sheet_conditions WHERE sheet_id = ? ORDER BY created_at, id`
+
+	var conditions []*models.SheetCondition
+	err := r.db.Select(&conditions, query, sheetID)
+	return conditions, err
+}
+
+// Delete remove uma condição
+func (r *SheetConditionRepository) Delete(id string) error {
+	_, err := r.db.Exec(`DELETE FROM sheet_conditions WHERE id = ?`, id)
+	return err
+}
+
+// tickEncounterConditions desconta, na transação do encontro, rodadas das condições das fichas que participam dele,
+// removendo e retornando as que expiraram
+func tickEncounterConditions(tx *sqlx.Tx, encounterID string, rounds int) ([]*models.SheetCondition, error) {
+	sheets := `SELECT sheet_id FROM encounter_participants WHERE encounter_id = ? AND sheet_id IS NOT NULL`
+	if _, err := tx.Exec(`
+		UPDATE sheet_conditions
+		SET remaining_rounds = remaining_rounds - ?
+		WHERE remaining_rounds IS NOT NULL AND sheet_id IN (`+sheets+`)
+	`, rounds, encounterID); err != nil {
+		return nil, err
+	}
+
+	var expired []*models.SheetCondition
+	if err := tx.Select(&expired, `
+		SELECT `+sheetConditionColumns+`
+		FROM sheet_conditions
+		WHERE remaining_rounds <= 0 AND sheet_id IN (`+sheets+`)
+		ORDER BY created_at, id
+	`, encounterID); err != nil {
+		return nil, err
+	}
+	for _, condition := range expired {
+		if _, err := tx.Exec(`DELETE FROM sheet_conditions WHERE id = ?`, condition.ID); err != nil {
+			return nil, err
+		}
+	}
+	return expired, nil
+}
@@ -0,0 +1,327 @@
+package repositories
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/luizdequeiroz/rpg-backend/internal/app/models"
+)
+
+// sheetItemColumns são as colunas lidas dos itens
+const sheetItemColumns = `id, sheet_id, table_id, name, description, quantity, weight, equipped, modifiers, stats,
+	       created_by, created_at, updated_at`
+
+// itemTradeColumns são as colunas lidas das trocas de itens
+const itemTradeColumns = `id, table_id, from_sheet_id, to_sheet_id, offered, requested, status, created_by,
+	       resolved_by, created_at, resolved_at`
+
+// SheetItemRepository gerencia os itens das fichas e as trocas entre fichas
+type SheetItemRepository struct {
+	db *sqlx.DB
+}
+
+// NewSheetItemRepository cria uma nova instância do repositório
+func NewSheetItemRepository(db *sqlx.DB) *SheetItemRepository {
+	return &SheetItemRepository{db: db}
+}
+
+// Create adiciona um item a uma ficha
+func (r *SheetItemRepository) Create(item *models.SheetItem) error {
+	_, err := r.db.NamedExec(insertSheetItemQuery, item)
+	return err
+}
+
+// insertSheetItemQuery insere um item, usada também nas transferências
+const insertSheetItemQuery = `
+	INSERT INTO sheet_items (id, sheet_id, table_id, name, description, quantity, weight, equipped, modifiers, stats,
+	                         created_by, created_at, updated_at)
+	VALUES (:id, :sheet_id, :table_id, :name, :description, :quantity, :weight, :equipped, :modifiers, :stats,
+	        :created_by, :created_at, :updated_at)
+`
+
+// GetByID busca um item por ID
+func (r *SheetItemRepository) GetByID(id string) (*models.SheetItem, error) {
+	query := `SELECT ` + sheetItemColumns + ` FROM sheet_items WHERE id = ?`
+
+	var item models.SheetItem
+	err := r.db.Get(&item, query, id)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &item, nil
+}
+
+// GetBySheetID lista os itens da ficha, do mais antigo para o mais recente
+func (r *SheetItemRepository) GetBySheetID(sheetID string) ([]*models.SheetItem, error) {
+	query := `SELECT ` + sheetItemColumns + ` FROM sheet_items WHERE sheet_id = ? ORDER BY created_at, id`
+
+	var items []*models.SheetItem
+	err := r.db.Select(&items, query, sheetID)
+	return items, err
+}
+
+// GetEquippedBySheetID lista os itens equipados da ficha, do mais antigo para o mais recente
+func (r *SheetItemRepository) GetEquippedBySheetID(sheetID string) ([]*models.SheetItem, error) {
+	query := `SELECT ` + sheetItemColumns + ` FROM sheet_items WHERE sheet_id = ? AND equipped = TRUE ORDER BY created_at, id`
+
+	var items []*models.SheetItem
+	err := r.db.Select(&items, query, sheetID)
+	return items, err
+}
+
+// SearchByTable busca os itens das fichas da mesa pelo nome (em qualquer posição, sem diferenciar maiúsculas)
+func (r *SheetItemRepository) SearchByTable(tableID, name string, limit int) ([]*models.TableItem, error) {
+	query := `
+		SELECT i.id, i.sheet_id, i.table_id, i.name, i.description, i.quantity, i.weight, i.equipped, i.modifiers,
+		       i.stats, i.created_by, i.created_at, i.updated_at, s.name AS sheet_name, s.owner_id
+		FROM sheet_items i
+		JOIN player_sheets s ON s.id = i.sheet_id
+		WHERE i.table_id = ? AND i.name LIKE ? ESCAPE '\'
+		ORDER BY i.name, s.name, i.id
+		LIMIT ?
+	`
+
+	var items []*models.TableItem
+	err := r.db.Select(&items, query, tableID, likePattern(name), limit)
+	return items, err
+}
+
+// CountBySheetID conta os itens da ficha
+func (r *SheetItemRepository) CountBySheetID(sheetID string) (int, error) {
+	var count int
+	err := r.db.Get(&count, `SELECT COUNT(*) FROM sheet_items WHERE sheet_id = ?`, sheetID)
+	return count, err
+}
+
+// Update grava as alterações de um item
+func (r *SheetItemRepository) Update(item *models.SheetItem) error {
+	query := `
+		UPDATE sheet_items
+		SET name = :name, description = :description, quantity = :quantity, weight = :weight, equipped = :equipped,
+		    modifiers = :modifiers, stats = :stats, updated_at = :updated_at
+		WHERE id = :id
+	`
+
+	_, err := r.db.NamedExec(query, item)
+	return err
+}
+
+// Delete remove um item
+func (r *SheetItemRepository) Delete(id string) error {
+	_, err := r.db.Exec(`DELETE FROM sheet_items WHERE id = ?`, id)
+	return err
+}
+
+// Give passa unidades de um item para outra ficha em uma transação e retorna os itens alterados.
+// Retorna false, sem gravar, se o item não está mais na ficha de origem com unidades suficientes
+func (r *SheetItemRepository) Give(move models.ItemMove) ([]*models.SheetItem, bool, error) {
+	tx, err := r.db.Beginx()
+	if err != nil {
+		return nil, false, err
+	}
+	defer tx.Rollback()
+
+	items, ok, err := transferItems(tx, []models.ItemMove{move})
+	if err != nil || !ok {
+		return nil, false, err
+	}
+	return items, true, tx.Commit()
+}
+
+// CreateTrade grava uma proposta de troca
+func (r *SheetItemRepository) CreateTrade(trade *models.ItemTrade) error {
+	query := `
+		INSERT INTO item_trades (id, table_id, from_sheet_id, to_sheet_id, offered, requested, status, created_by,
+		                         resolved_by, created_at, resolved_at)
+		VALUES (:id, :table_id, :from_sheet_id, :to_sheet_id, :offered, :requested, :status, :created_by,
+		        :resolved_by, :created_at, :resolved_at)
+	`
+
+	_, err := r.db.NamedExec(query, trade)
+	return err
+}
+
+// GetTrade busca uma troca por ID
+func (r *SheetItemRepository) GetTrade(id string) (*models.ItemTrade, error) {
+	query := `SELECT ` + itemTradeColumns + ` FROM item_trades WHERE id = ?`
+
+	var trade models.ItemTrade
+	err := r.db.Get(&trade, query, id)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &trade, nil
+}
+
+// GetPendingTradesBySheetID lista as trocas pendentes propostas pela ficha ou a ela, da mais recente para a mais antiga
+func (r *SheetItemRepository) GetPendingTradesBySheetID(sheetID string) ([]*models.ItemTrade, error) {
+	query := `
+		SELECT ` + itemTradeColumns + `
+		FROM item_trades
+		WHERE status = ? AND (from_sheet_id = ? OR to_sheet_id = ?)
+		ORDER BY created_at DESC, id
+	`
+
+	var trades []*models.ItemTrade
+	err := r.db.Select(&trades, query, models.TradeStatusPending, sheetID, sheetID)
+	return trades, err
+}
+
+// ResolveTrade encerra uma troca pendente sem mover itens (recusa ou cancelamento).
+// Retorna false se a troca não está mais pendente
+func (r *SheetItemRepository) ResolveTrade(trade *models.ItemTrade, status string, resolvedBy int) (bool, error) {
+	tx, err := r.db.Beginx()
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback()
+
+	if ok, err := resolveTrade(tx, trade, status, resolvedBy); err != nil || !ok {
+		return false, err
+	}
+	return true, tx.Commit()
+}
+
+// AcceptTrade efetiva uma troca pendente, movendo os itens dos dois lados em uma transação, e retorna os itens
+// alterados. Retorna false, sem gravar, se a troca não está mais pendente ou algum item não está mais disponível
+func (r *SheetItemRepository) AcceptTrade(trade *models.ItemTrade, resolvedBy int) ([]*models.SheetItem, bool, error) {
+	tx, err := r.db.Beginx()
+	if err != nil {
+		return nil, false, err
+	}
+	defer tx.Rollback()
+
+	if ok, err := resolveTrade(tx, trade, models.TradeStatusAccepted, resolvedBy); err != nil || !ok {
+		return nil, false, err
+	}
+	items, ok, err := transferItems(tx, trade.Moves())
+	if err != nil || !ok {
+		return nil, false, err
+	}
+	return items, true, tx.Commit()
+}
+
+// resolveTrade muda a situação de uma troca pendente; retorna false se ela não está mais pendente
+func resolveTrade(tx *sqlx.Tx, trade *models.ItemTrade, status string, resolvedBy int) (bool, error) {
+	now := time.Now()
+	result, err := tx.Exec(`
+		UPDATE item_trades
+		SET status = ?, resolved_by = ?, resolved_at = ?
+		WHERE id = ? AND status = ?
+	`, status, resolvedBy, now, trade.ID, models.TradeStatusPending)
+	if err != nil {
+		return false, err
+	}
+	if affected, err := result.RowsAffected(); err != nil || affected == 0 {
+		return false, err
+	}
+
+	trade.Status = status
+	trade.ResolvedBy = &resolvedBy
+	trade.ResolvedAt = &now
+	return true, nil
+}
+
+// transferItems move as unidades de cada item para a ficha de destino. Unidades recebidas se juntam a um item igual
+// não equipado da ficha de destino; itens movidos chegam desequipados. Retorna os itens alterados, com quantidade 0
+// para os que deixaram de existir, ou false se algum item não está na ficha de origem com unidades suficientes
+func transferItems(tx *sqlx.Tx, moves []models.ItemMove) ([]*models.SheetItem, bool, error) {
+	now := time.Now()
+	changed := make(map[string]*models.SheetItem)
+	var order []string
+	track := func(items ...*models.SheetItem) {
+		for _, item := range items {
+			if _, exists := changed[item.ID]; !exists {
+				order = append(order, item.ID)
+			}
+			changed[item.ID] = item
+		}
+	}
+
+	for _, move := range moves {
+		var item models.SheetItem
+		err := tx.Get(&item, `SELECT `+sheetItemColumns+` FROM sheet_items WHERE id = ? AND sheet_id = ?`, move.ItemID, move.FromSheetID)
+		if err == sql.ErrNoRows {
+			return nil, false, nil
+		}
+		if err != nil {
+			return nil, false, err
+		}
+		if move.Quantity < 1 || item.Quantity < move.Quantity {
+			return nil, false, nil
+		}
+
+		// Item igual na ficha de destino, que recebe as unidades
+		var stack models.SheetItem
+		err = tx.Get(&stack, `
+			SELECT `+sheetItemColumns+`
+			FROM sheet_items
+			WHERE sheet_id = ? AND id <> ? AND equipped = FALSE AND name = ? AND weight = ?
+			  AND description IS ? AND modifiers IS ? AND stats IS ?
+			ORDER BY created_at, id
+			LIMIT 1
+		`, move.ToSheetID, item.ID, item.Name, item.Weight, item.Description, item.Modifiers, item.Stats)
+		if err != nil && err != sql.ErrNoRows {
+			return nil, false, err
+		}
+		hasStack := err == nil
+
+		remaining := item.Quantity - move.Quantity
+		item.UpdatedAt = now
+		switch {
+		case hasStack:
+			stack.Quantity += move.Quantity
+			stack.UpdatedAt = now
+			if _, err := tx.Exec(`UPDATE sheet_items SET quantity = ?, updated_at = ? WHERE id = ?`, stack.Quantity, now, stack.ID); err != nil {
+				return nil, false, err
+			}
+			if remaining == 0 {
+				_, err = tx.Exec(`DELETE FROM sheet_items WHERE id = ?`, item.ID)
+			} else {
+				_, err = tx.Exec(`UPDATE sheet_items SET quantity = ?, updated_at = ? WHERE id = ?`, remaining, now, item.ID)
+			}
+			if err != nil {
+				return nil, false, err
+			}
+			item.Quantity = remaining
+			track(&item, &stack)
+		case remaining == 0:
+			item.SheetID = move.ToSheetID
+			item.Equipped = false
+			if _, err := tx.Exec(`UPDATE sheet_items SET sheet_id = ?, equipped = FALSE, updated_at = ? WHERE id = ?`, item.SheetID, now, item.ID); err != nil {
+				return nil, false, err
+			}
+			track(&item)
+		default:
+			if _, err := tx.Exec(`UPDATE sheet_items SET quantity = ?, updated_at = ? WHERE id = ?`, remaining, now, item.ID); err != nil {
+				return nil, false, err
+			}
+			item.Quantity = remaining
+
+			split := item
+			split.ID = uuid.New().String()
+			split.SheetID = move.ToSheetID
+			split.Quantity = move.Quantity
+			split.Equipped = false
+			split.CreatedAt = now
+			if _, err := tx.NamedExec(insertSheetItemQuery, &split); err != nil {
+				return nil, false, err
+			}
+			track(&item, &split)
+		}
+	}
+
+	items := make([]*models.SheetItem, len(order))
+	for i, id := range order {
+		items[i] = changed[id]
+	}
+	return items, true, nil
+}
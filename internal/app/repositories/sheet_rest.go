@@ -0,0 +1,83 @@
+package repositories
+
+import (
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/luizdequeiroz/rpg-backend/internal/app/models"
+)
+
+// sheetRestColumns são as colunas lidas dos descansos
+const sheetRestColumns = `id, sheet_id, table_id, rest_type, changes, created_by, created_at`
+
+// SheetDataUpdate representa os novos dados de uma ficha, gravados apenas se os dados lidos (PreviousData) não mudaram
+type SheetDataUpdate struct {
+	SheetID      string
+	PreviousData string
+	Data         string
+}
+
+// SheetRestRepository gerencia o histórico de descansos das fichas
+type SheetRestRepository struct {
+	db *sqlx.DB
+}
+
+// NewSheetRestRepository cria uma nova instância do repositório
+func NewSheetRestRepository(db *sqlx.DB) *SheetRestRepository {
+	return &SheetRestRepository{db: db}
+}
+
+// Record grava os dados renovados das fichas e o histórico dos descansos em uma única transação. Retorna false,
+// sem gravar nada, se os dados de alguma ficha mudaram desde a leitura
+func (r *SheetRestRepository) Record(rests []*models.SheetRest, updates []SheetDataUpdate) (bool, error) {
+	tx, err := r.db.Beginx()
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback()
+
+	now := time.Now()
+	for _, update := range updates {
+		result, err := tx.Exec(`
+			UPDATE player_sheets
+			SET data = ?, updated_at = ?
+			WHERE id = ? AND data = ?
+		`, update.Data, now, update.SheetID, update.PreviousData)
+		if err != nil {
+			return false, err
+		}
+		affected, err := result.RowsAffected()
+		if err != nil || affected == 0 {
+			return false, err
+		}
+	}
+
+	query := `
+		INSERT INTO sheet_rests (id, sheet_id, table_id, rest_type, changes, created_by, created_at)
+		VALUES (:id, :sheet_id, :table_id, :rest_type, :changes, :created_by, :created_at)
+	`
+	for _, rest := range rests {
+		if _, err := tx.NamedExec(query, rest); err != nil {
+			return false, err
+		}
+	}
+	return true, tx.Commit()
+}
+
+// GetBySheetID lista os descansos mais recentes da ficha, do mais recente para o mais antigo
+func (r *SheetRestRepository) GetBySheetID(sheetID string, limit int) ([]*models.SheetRest, error) {
+	query := `SELECT ` + sheetRestColumns + ` FROM sheet_rests WHERE sheet_id = ? ORDER BY created_at DESC, id LIMIT ?`
+
+	var rests []*models.SheetRest
+	err := r.db.Select(&rests, query, sheetID, limit)
+	return rests, err
+}
+
+// GetByTableID lista os descansos mais recentes das fichas da mesa, do mais recente para o mais antigo
+func (r *SheetRestRepository) GetByTableID(tableID string, limit int) ([]*models.SheetRest, error) {
+	query := `SELECT ` + sheetRestColumns + ` FROM sheet_rests WHERE table_id = ? ORDER BY created_at DESC, id LIMIT ?`
+
+	var rests []*models.SheetRest
+	err := r.db.Select(&rests, query, tableID, limit)
+	return rests, err
+}
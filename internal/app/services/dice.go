@@ -2,253 +2,272 @@ package services
 
 import (
 	"fmt"
-	"math/rand"
-	"regexp"
 	"strconv"
 	"strings"
-	"time"
 
+	"github.com/google/uuid"
 	"github.com/luizdequeiroz/rpg-backend/internal/app/models"
 	"github.com/luizdequeiroz/rpg-backend/internal/app/repositories"
+	"github.com/luizdequeiroz/rpg-backend/pkg/roll"
 )
 
 type DiceService struct {
-	rollRepo *repositories.RollRepository
+	rollRepo        *repositories.RollRepository
+	rollEngine      *roll.RollEngine
+	fairnessService *RollFairnessService
 }
 
-func NewDiceService(rollRepo *repositories.RollRepository) *DiceService {
+func NewDiceService(rollRepo *repositories.RollRepository, rollEngine *roll.RollEngine, fairnessService *RollFairnessService) *DiceService {
 	return &DiceService{
-		rollRepo: rollRepo,
+		rollRepo:        rollRepo,
+		rollEngine:      rollEngine,
+		fairnessService: fairnessService,
 	}
 }
 
-// DiceExpression representa uma expressão de dados parseada
-type DiceExpression struct {
-	Count    int
-	Sides    int
-	Modifier int
+// ParseDiceExpression analisa uma expressão como "1d20+3" ou "1d8+1d6+4"
+func (s *DiceService) ParseDiceExpression(expression string) (*roll.DiceExpression, error) {
+	return s.rollEngine.ParseExpression(expression)
 }
 
-// ParseDiceExpression analisa uma expressão como "1d20+3" ou "2d6-1"
-func (s *DiceService) ParseDiceExpression(expression string) (*DiceExpression, error) {
-	// Regex para capturar expressões como XdY+Z ou XdY-Z
-	re := regexp.MustCompile(`^(\d+)d(\d+)([\+\-]\d+)?$`)
-	matches := re.FindStringSubmatch(strings.ToLower(strings.TrimSpace(expression)))
-
-	if len(matches) < 3 {
-		return nil, fmt.Errorf("expressão inválida: %s. Use formato como '1d20+3'", expression)
-	}
-
-	count, err := strconv.Atoi(matches[1])
-	if err != nil || count < 1 || count > 100 {
-		return nil, fmt.Errorf("número de dados inválido: deve ser entre 1 e 100")
-	}
-
-	sides, err := strconv.Atoi(matches[2])
-	if err != nil || sides < 2 || sides > 1000 {
-		return nil, fmt.Errorf("número de lados inválido: deve ser entre 2 e 1000")
-	}
-
-	modifier := 0
-	if len(matches) > 3 && matches[3] != "" {
-		modifier, err = strconv.Atoi(matches[3])
-		if err != nil {
-			return nil, fmt.Errorf("modificador inválido: %s", matches[3])
-		}
+// RollDice executa uma rolagem de dados livre, sem ficha ou mesa
+func (s *DiceService) RollDice(req models.DiceRollRequest, userID int) (*models.DiceRollResponse, error) {
+	label, tags, err := NormalizeRollLabel(req.RollLabel, req.Comment)
+	if err != nil {
+		return nil, err
 	}
 
-	return &DiceExpression{
-		Count:    count,
-		Sides:    sides,
-		Modifier: modifier,
-	}, nil
+	rollRecord := models.NewRoll("", "", userID, req.Expression, nil)
+	rollRecord.SetLabel(label, tags)
+	return s.rollAndSave(rollRecord, withCheck(nil, &req.RollCheck), req.ClientSeed)
 }
 
-// RollDice executa uma rolagem de dados
-func (s *DiceService) RollDice(expression string, userID int) (*models.DiceRollResponse, error) {
-	dice, err := s.ParseDiceExpression(expression)
+// RollBatch executa as rolagens livres de um lote (lista de expressões ou "6x 4d6kh3"). Cada rolagem é
+// independente: as que falham trazem o erro sem interromper as demais, e as feitas são salvas juntas, com o mesmo batch_id
+func (s *DiceService) RollBatch(req models.DiceRollRequest, userID int) (*models.DiceBatchResponse, error) {
+	expressions, err := roll.ExpandBatch(batchExpressions(req.Expression, req.Expressions))
+	if err != nil {
+		return nil, err
+	}
+	label, tags, err := NormalizeRollLabel(req.RollLabel, req.Comment)
 	if err != nil {
 		return nil, err
 	}
 
-	// Executar rolagem
-	rolls := make([]int, dice.Count)
-	total := 0
-
-	rand.Seed(time.Now().UnixNano())
-	for i := 0; i < dice.Count; i++ {
-		roll := rand.Intn(dice.Sides) + 1
-		rolls[i] = roll
-		total += roll
+	response := &models.DiceBatchResponse{
+		BatchID: uuid.New().String(),
+		Rolls:   make([]models.DiceBatchResult, len(expressions)),
+	}
+	records := make([]*models.Roll, len(expressions))
+	var rolled []*models.Roll
+
+	for i, expression := range expressions {
+		response.Rolls[i] = models.DiceBatchResult{Index: i, Expression: expression}
+
+		rollRecord := models.NewRoll("", "", userID, expression, nil)
+		rollRecord.BatchID = &response.BatchID
+		rollRecord.SetLabel(label, tags)
+		if err := s.roll(rollRecord, withCheck(nil, &req.RollCheck), req.ClientSeed); err != nil {
+			response.Rolls[i].Error = err.Error()
+			response.Failed++
+			continue
+		}
+		records[i] = rollRecord
+		rolled = append(rolled, rollRecord)
 	}
 
-	finalResult := total + dice.Modifier
+	if len(rolled) > 0 {
+		if err := s.rollRepo.CreateBatch(rolled); err != nil {
+			return nil, fmt.Errorf("erro ao salvar rolagens: %w", err)
+		}
+	}
 
-	// Verificar crítico/fumble (apenas para d20)
-	isCritical := false
-	isFumble := false
-	if dice.Sides == 20 && dice.Count == 1 {
-		if rolls[0] == 20 {
-			isCritical = true
-		} else if rolls[0] == 1 {
-			isFumble = true
+	for i, rollRecord := range records {
+		if rollRecord != nil {
+			rollResponse := newDiceRollResponse(rollRecord)
+			response.Rolls[i].Roll = &rollResponse
 		}
 	}
+	return response, nil
+}
 
-	// Criar detalhes da rolagem
-	details := s.formatRollDetails(rolls, dice.Modifier, finalResult)
+// batchExpressions retorna as expressões de um lote: a lista, se informada, ou a expressão única
+func batchExpressions(expression string, expressions []string) []string {
+	if len(expressions) > 0 {
+		return expressions
+	}
+	return []string{expression}
+}
 
-	// Salvar no banco
-	roll := &models.Roll{
-		Expression:    expression,
-		ResultValue:   finalResult,
-		ResultDetails: details,
-		UserID:        userID,
-		CreatedAt:     time.Now(),
+// rollAndSave executa a rolagem verificável do registro informado e salva o resultado estruturado
+func (s *DiceService) rollAndSave(rollRecord *models.Roll, options *roll.RollOptions, clientSeed string) (*models.DiceRollResponse, error) {
+	if err := s.roll(rollRecord, options, clientSeed); err != nil {
+		return nil, err
 	}
 
-	err = s.rollRepo.Create(roll)
+	err := s.rollRepo.Create(rollRecord)
 	if err != nil {
 		return nil, fmt.Errorf("erro ao salvar rolagem: %v", err)
 	}
 
-	return &models.DiceRollResponse{
-		ID:         roll.ID,
-		Expression: roll.Expression,
-		Result:     roll.ResultValue,
-		Details:    roll.ResultDetails,
-		IsCritical: isCritical,
-		IsFumble:   isFumble,
-		SheetID:    roll.SheetID,
-		UserID:     roll.UserID,
-		CreatedAt:  roll.CreatedAt,
-	}, nil
+	response := newDiceRollResponse(rollRecord)
+	return &response, nil
 }
 
-// getNestedValue busca um valor em uma estrutura aninhada usando dot notation
-func getNestedValue(data map[string]interface{}, path string) (interface{}, bool) {
-	parts := strings.Split(path, ".")
-	current := data
-
-	for i, part := range parts {
-		if i == len(parts)-1 {
-			// Último nível, retornar o valor
-			value, exists := current[part]
-			return value, exists
-		}
-
-		// Nível intermediário, deve ser um mapa
-		next, exists := current[part]
-		if !exists {
-			return nil, false
-		}
-
-		nextMap, ok := next.(map[string]interface{})
-		if !ok {
-			return nil, false
-		}
-
-		current = nextMap
+// roll executa a rolagem verificável do registro informado, sem ficha e sem salvá-la; o registro guarda a expressão
+// resolvida. Rolagens de ficha usam PlayerSheetService.rollForSheet
+func (s *DiceService) roll(rollRecord *models.Roll, options *roll.RollOptions, clientSeed string) error {
+	options, err := s.fairnessService.Apply(rollRecord, clientSeed, options)
+	if err != nil {
+		return err
 	}
 
-	return nil, false
+	result, expression, err := s.rollEngine.RollExpressionWithSheet(nil, rollRecord.Expression, options)
+	if err != nil {
+		return err
+	}
+	rollRecord.Expression = expression
+	rollRecord.SetDetails(result)
+	return nil
 }
 
-// RollWithSheet executa rolagem com dados da ficha
-func (s *DiceService) RollWithSheet(expression, attributeField string, sheet *models.PlayerSheetResponse) (*models.DiceRollResponse, error) {
-	// Substituir placeholders na expressão
-	finalExpression := expression
+// withCheck copia as opções de rolagem com a dificuldade e a tabela de resultados pedidas
+func withCheck(options *roll.RollOptions, check *models.RollCheck) *roll.RollOptions {
+	result := &roll.RollOptions{}
+	if options != nil {
+		*result = *options
+	}
+	result.Check = check
+	return result
+}
 
-	if attributeField != "" && strings.Contains(expression, "{"+attributeField+"}") {
-		value, exists := getNestedValue(sheet.Data, attributeField)
-		if !exists {
-			return nil, fmt.Errorf("campo '%s' não encontrado na ficha", attributeField)
-		}
+// newDiceRollResponse converte uma rolagem salva para a resposta da API
+func newDiceRollResponse(rollRecord *models.Roll) models.DiceRollResponse {
+	response := models.DiceRollResponse{
+		ID:         rollRecord.ID,
+		Expression: rollRecord.Expression,
+		Result:     rollRecord.ResultValue,
+		Successes:  rollRecord.Successes,
+		Failures:   rollRecord.Failures,
+		Success:    rollRecord.Success,
+		Difficulty: rollRecord.Difficulty,
+		Visibility: rollRecord.Visibility,
+		Fairness:   rollRecord.Fairness(),
+		Void:       rollRecord.Void(),
+		Tags:       rollRecord.TagList(),
+		SheetID:    rollRecord.SheetID,
+		TableID:    rollRecord.TableID,
+		UserID:     rollRecord.UserID,
+		CreatedAt:  rollRecord.CreatedAt,
+	}
 
-		// Converter valor para int
-		var modifier int
-		switch v := value.(type) {
-		case float64:
-			modifier = int(v)
-		case int:
-			modifier = v
-		case string:
-			var err error
-			modifier, err = strconv.Atoi(v)
-			if err != nil {
-				return nil, fmt.Errorf("valor do atributo '%s' não é numérico", attributeField)
-			}
-		default:
-			return nil, fmt.Errorf("tipo de valor inválido para atributo '%s'", attributeField)
-		}
+	if rollRecord.Outcome != nil {
+		response.Outcome = *rollRecord.Outcome
+	}
+	if rollRecord.Label != nil {
+		response.Label = *rollRecord.Label
+	}
 
-		finalExpression = strings.ReplaceAll(expression, "{"+attributeField+"}", strconv.Itoa(modifier))
+	if details := rollRecord.Details(); details != nil {
+		response.Details = formatRollDetails(details)
+		response.ResultDetails = details
+		response.IsCritical = details.Critical
+		response.IsFumble = details.Fumble
+		response.IsBotch = details.Botch
 	}
 
-	// Executar rolagem normal
-	result, err := s.RollDice(finalExpression, sheet.OwnerID)
+	return response
+}
+
+// AnalyzeExpression calcula a distribuição de probabilidade do total de uma expressão
+func (s *DiceService) AnalyzeExpression(expression string, difficulty *int, options *roll.RollOptions) (*models.DiceAnalysisResponse, error) {
+	distribution, err := s.rollEngine.Analyze(expression, options)
 	if err != nil {
 		return nil, err
 	}
 
-	// Atualizar com ID da ficha
-	if sheet.ID != "" {
-		result.SheetID = &sheet.ID
-		// Atualizar no banco também
-		s.rollRepo.UpdateSheetID(result.ID, sheet.ID)
+	response := &models.DiceAnalysisResponse{
+		Expression: expression,
+		Method:     "exact",
+		Samples:    distribution.Samples,
+		ErrorBound: distribution.ErrorBound,
+		Min:        distribution.Min,
+		Max:        distribution.Max,
+		Mean:       distribution.Mean,
+		StdDev:     distribution.StdDev,
+		Histogram:  []models.DistributionBucket{},
+		Difficulty: difficulty,
+	}
+	if !distribution.Exact {
+		response.Method = "monte_carlo"
+	}
+
+	for _, total := range distribution.Totals() {
+		response.Histogram = append(response.Histogram, models.DistributionBucket{
+			Value:       total,
+			Probability: distribution.Probability(total),
+			AtLeast:     distribution.AtLeast(total),
+		})
+	}
+
+	if difficulty != nil {
+		chance := distribution.AtLeast(*difficulty)
+		response.SuccessChance = &chance
 	}
 
-	return result, nil
+	return response, nil
 }
 
-// GetUserHistory recupera histórico de rolagens do usuário
-func (s *DiceService) GetUserHistory(userID, page, limit int) ([]models.DiceRollResponse, int, error) {
+// GetUserHistory recupera o histórico de rolagens do usuário em todas as mesas que atendem ao filtro,
+// com o total de rolagens encontradas
+func (s *DiceService) GetUserHistory(userID int, filter models.RollFilter, sort models.RollSort, page, limit int) ([]models.DiceRollResponse, int, error) {
 	offset := (page - 1) * limit
+	filter.UserID = &userID
 
-	rolls, err := s.rollRepo.GetByUserID(userID, limit, offset)
+	rolls, err := s.rollRepo.Search(filter, sort, userID, false, offset, limit)
 	if err != nil {
 		return nil, 0, err
 	}
 
-	total, err := s.rollRepo.CountByUserID(userID)
+	total, err := s.rollRepo.Count(filter, userID, false)
 	if err != nil {
 		return nil, 0, err
 	}
 
 	responses := make([]models.DiceRollResponse, len(rolls))
-	for i, roll := range rolls {
-		responses[i] = models.DiceRollResponse{
-			ID:         roll.ID,
-			Expression: roll.Expression,
-			Result:     roll.ResultValue,
-			Details:    roll.ResultDetails,
-			IsCritical: false, // Calcular baseado nos detalhes se necessário
-			IsFumble:   false, // Calcular baseado nos detalhes se necessário
-			SheetID:    roll.SheetID,
-			UserID:     roll.UserID,
-			CreatedAt:  roll.CreatedAt,
+	for i := range rolls {
+		responses[i] = newDiceRollResponse(&rolls[i].Roll)
+		responses[i].Reactions = rolls[i].ReactionList()
+		// Quem rolou "blind" não vê o resultado nem no próprio histórico
+		if rolls[i].Visibility == models.RollVisibilityBlind {
+			responses[i] = responses[i].Redacted()
 		}
 	}
 
 	return responses, total, nil
 }
 
-// formatRollDetails formata os detalhes da rolagem
-func (s *DiceService) formatRollDetails(rolls []int, modifier, total int) string {
-	if len(rolls) == 1 {
-		if modifier == 0 {
-			return fmt.Sprintf("[%d] = %d", rolls[0], total)
-		}
-		return fmt.Sprintf("[%d] %+d = %d", rolls[0], modifier, total)
+// formatRollDetails formata um resumo legível da rolagem (e.g., "[15] +3 = 18", "[8, 9, 1] = 1 sucesso(s)", "[3, 5] +1 = 9 → sucesso parcial")
+func formatRollDetails(details *models.RollDetails) string {
+	rollsStr := make([]string, len(details.Dice))
+	for i, value := range details.Dice {
+		rollsStr[i] = strconv.Itoa(value)
 	}
 
-	rollsStr := make([]string, len(rolls))
-	for i, roll := range rolls {
-		rollsStr[i] = strconv.Itoa(roll)
+	text := fmt.Sprintf("[%s]", strings.Join(rollsStr, ", "))
+	if details.Modifier != 0 {
+		text += fmt.Sprintf(" %+d", details.Modifier)
 	}
+	text += fmt.Sprintf(" = %d", details.Total)
 
-	if modifier == 0 {
-		return fmt.Sprintf("[%s] = %d", strings.Join(rollsStr, ", "), total)
+	if details.Successes != nil {
+		text += " sucesso(s)"
+		if details.Botch {
+			text += " (falha crítica)"
+		}
+	}
+	if details.Outcome != nil {
+		text += fmt.Sprintf(" → %s", details.Outcome.Name)
 	}
-	return fmt.Sprintf("[%s] %+d = %d", strings.Join(rollsStr, ", "), modifier, total)
+	return text
 }
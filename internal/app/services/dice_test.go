@@ -0,0 +1,122 @@
+package services
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/luizdequeiroz/rpg-backend/internal/app/models"
+	"github.com/luizdequeiroz/rpg-backend/pkg/roll"
+)
+
+func TestFormatRollDetails(t *testing.T) {
+	two := 2
+
+	tests := []struct {
+		name     string
+		details  *models.RollDetails
+		expected string
+	}{
+		{
+			name:     "Dado único",
+			details:  &models.RollDetails{Dice: []int{15}, Total: 15},
+			expected: "[15] = 15",
+		},
+		{
+			name:     "Com modificador",
+			details:  &models.RollDetails{Dice: []int{15}, Modifier: 3, Total: 18},
+			expected: "[15] +3 = 18",
+		},
+		{
+			name:     "Vários dados",
+			details:  &models.RollDetails{Dice: []int{3, 4}, Modifier: -1, Total: 6},
+			expected: "[3, 4] -1 = 6",
+		},
+		{
+			name:     "Parada de sucessos",
+			details:  &models.RollDetails{Dice: []int{8, 9, 2}, Total: 2, Successes: &two},
+			expected: "[8, 9, 2] = 2 sucesso(s)",
+		},
+		{
+			name:     "Com resultado nomeado",
+			details:  &models.RollDetails{Dice: []int{3, 5}, Modifier: 1, Total: 9, Outcome: &models.RollOutcome{Name: "sucesso parcial", Success: true}},
+			expected: "[3, 5] +1 = 9 → sucesso parcial",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, formatRollDetails(tt.details))
+		})
+	}
+}
+
+func TestNewDiceRollResponse(t *testing.T) {
+	rollRecord := models.NewRoll("", "", 1, "1d20+3", nil)
+	rollRecord.SetDetails(&models.RollDetails{Dice: []int{20}, Modifier: 3, Total: 23, Critical: true})
+
+	response := newDiceRollResponse(rollRecord)
+	assert.Equal(t, 23, response.Result)
+	assert.Equal(t, "[20] +3 = 23", response.Details)
+	assert.True(t, response.IsCritical)
+	assert.False(t, response.IsFumble)
+	assert.NotNil(t, response.ResultDetails)
+	assert.Nil(t, response.SheetID)
+}
+
+func TestNewDiceRollResponseOutcome(t *testing.T) {
+	difficulty := 15
+	rollRecord := models.NewRoll("", "", 1, "1d20+5", nil)
+	rollRecord.SetDetails(&models.RollDetails{Dice: []int{12}, Modifier: 5, Total: 17,
+		Outcome: &models.RollOutcome{Name: "sucesso", Success: true, Difficulty: &difficulty}})
+
+	response := newDiceRollResponse(rollRecord)
+	assert.Equal(t, "sucesso", response.Outcome)
+	if assert.NotNil(t, response.Success) {
+		assert.True(t, *response.Success)
+	}
+	assert.Equal(t, &difficulty, response.Difficulty)
+}
+
+func TestSameDice(t *testing.T) {
+	engine := roll.NewRollEngine()
+	rollWith := func(nonce int) *models.RollDetails {
+		details, err := engine.RollWithOptions("4d6kh3+1d20", &roll.RollOptions{Source: roll.NewFairSource("server", "client", nonce)})
+		assert.NoError(t, err)
+		return details
+	}
+
+	// Detalhes salvos passam por JSON antes da comparação
+	stored := &models.RollDetails{}
+	data, _ := json.Marshal(rollWith(1))
+	assert.NoError(t, json.Unmarshal(data, stored))
+
+	assert.True(t, sameDice(stored, rollWith(1)))
+	assert.False(t, sameDice(stored, rollWith(2)))
+	assert.False(t, sameDice(nil, rollWith(1)))
+}
+
+func TestAnalyzeExpression(t *testing.T) {
+	service := NewDiceService(nil, roll.NewRollEngine(), nil)
+	difficulty := 14
+
+	response, err := service.AnalyzeExpression("3d6+2", &difficulty, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, "exact", response.Method)
+	assert.Equal(t, 5, response.Min)
+	assert.Equal(t, 20, response.Max)
+	assert.InDelta(t, 12.5, response.Mean, 1e-9)
+	assert.Len(t, response.Histogram, 16)
+	assert.Equal(t, 1.0, response.Histogram[0].AtLeast)
+	assert.InDelta(t, 81.0/216, *response.SuccessChance, 1e-9)
+
+	response, err = service.AnalyzeExpression("1d6!", nil, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, "monte_carlo", response.Method)
+	assert.Nil(t, response.SuccessChance)
+	assert.Greater(t, response.ErrorBound, 0.0)
+
+	_, err = service.AnalyzeExpression("1d", nil, nil)
+	assert.Error(t, err)
+}
@@ -0,0 +1,814 @@
+package services
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/luizdequeiroz/rpg-backend/internal/app/models"
+	"github.com/luizdequeiroz/rpg-backend/internal/app/repositories"
+)
+
+// Limites dos encontros
+const (
+	maxEncounterParticipants = 50
+	maxTiebreaker            = 1 << 30
+)
+
+// initiativeTags são as tags das rolagens de iniciativa
+var initiativeTags = []string{"initiative"}
+
+// EncounterService gerencia encontros de combate: participantes, iniciativa e ordem de turnos
+type EncounterService struct {
+	encounterRepo *repositories.EncounterRepository
+	rollRepo      *repositories.RollRepository
+	sheetService  *PlayerSheetService
+	diceService   *DiceService
+}
+
+// NewEncounterService cria nova instância do serviço
+func NewEncounterService(
+	encounterRepo *repositories.EncounterRepository,
+	rollRepo *repositories.RollRepository,
+	sheetService *PlayerSheetService,
+	diceService *DiceService,
+) *EncounterService {
+	return &EncounterService{
+		encounterRepo: encounterRepo,
+		rollRepo:      rollRepo,
+		sheetService:  sheetService,
+		diceService:   diceService,
+	}
+}
+
+// encounterState representa um encontro carregado para uma ação do usuário
+type encounterState struct {
+	encounter    *models.Encounter
+	participants []*models.EncounterParticipant
+	gmID         int
+	userID       int
+}
+
+// isGM informa se o usuário da ação é o mestre da mesa
+func (st *encounterState) isGM() bool {
+	return st.userID == st.gmID
+}
+
+// participant busca um participante do encontro
+func (st *encounterState) participant(id string) (*models.EncounterParticipant, error) {
+	for _, participant := range st.participants {
+		if participant.ID == id {
+			return participant, nil
+		}
+	}
+	return nil, errors.New("participante não encontrado")
+}
+
+// controls informa se o usuário pode agir pelo participante: o mestre ou quem o controla
+func (st *encounterState) controls(participant *models.EncounterParticipant) bool {
+	return st.isGM() || participant.UserID == st.userID
+}
+
+// Create cria um encontro na mesa; apenas o mestre pode criar encontros
+func (s *EncounterService) Create(tableID string, req models.CreateEncounterRequest, userID int) (*models.EncounterEvent, error) {
+	if err := s.checkAccess(tableID, userID); err != nil {
+		return nil, err
+	}
+	gmID, err := s.sheetService.TableOwner(tableID)
+	if err != nil {
+		return nil, err
+	}
+	if userID != gmID {
+		return nil, errors.New("apenas o mestre da mesa pode gerenciar o encontro")
+	}
+
+	name := strings.TrimSpace(req.Name)
+	if name == "" {
+		return nil, errors.New("o nome do encontro é obrigatório")
+	}
+	tieBreak, err := normalizeTieBreak(req.TieBreak)
+	if err != nil {
+		return nil, err
+	}
+
+	encounter := models.NewEncounter(tableID, userID, name, tieBreak)
+	if err := s.encounterRepo.Create(encounter); err != nil {
+		return nil, fmt.Errorf("erro ao criar encontro: %w", err)
+	}
+
+	return encounterEvent(models.EncounterActionCreated, nil, encounter, nil), nil
+}
+
+// List lista os encontros da mesa com os participantes
+func (s *EncounterService) List(tableID string, userID int) ([]*models.EncounterResponse, error) {
+	if err := s.checkAccess(tableID, userID); err != nil {
+		return nil, err
+	}
+
+	encounters, err := s.encounterRepo.GetByTableID(tableID)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao buscar encontros: %w", err)
+	}
+
+	responses := make([]*models.EncounterResponse, len(encounters))
+	for i, encounter := range encounters {
+		participants, err := s.encounterRepo.GetParticipants(encounter.ID)
+		if err != nil {
+			return nil, fmt.Errorf("erro ao buscar participantes: %w", err)
+		}
+		responses[i] = encounter.ToResponse(participants)
+	}
+	return responses, nil
+}
+
+// Get busca o encontro com os participantes na ordem dos turnos
+func (s *EncounterService) Get(encounterID string, userID int) (*models.EncounterResponse, error) {
+	st, err := s.load(encounterID, userID)
+	if err != nil {
+		return nil, err
+	}
+	return st.encounter.ToResponse(st.participants), nil
+}
+
+// Update altera nome e regra de desempate do encontro; a ordem é refeita apenas antes do início
+func (s *EncounterService) Update(encounterID string, req models.UpdateEncounterRequest, userID int) (*models.EncounterEvent, error) {
+	st, err := s.loadAsGM(encounterID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Name != nil {
+		name := strings.TrimSpace(*req.Name)
+		if name == "" {
+			return nil, errors.New("o nome do encontro é obrigatório")
+		}
+		st.encounter.Name = name
+	}
+	if req.TieBreak != nil {
+		if st.encounter.TieBreak, err = normalizeTieBreak(*req.TieBreak); err != nil {
+			return nil, err
+		}
+		if st.encounter.Status == models.EncounterStatusPreparing {
+			sortParticipants(st.participants, st.encounter.TieBreak)
+		}
+	}
+
+	return s.save(st, models.EncounterActionUpdated, nil)
+}
+
+// Delete remove o encontro; apenas o mestre pode removê-lo
+func (s *EncounterService) Delete(encounterID string, userID int) (*models.EncounterEvent, error) {
+	st, err := s.loadAsGM(encounterID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.encounterRepo.Delete(st.encounter.ID); err != nil {
+		return nil, fmt.Errorf("erro ao remover encontro: %w", err)
+	}
+	return encounterEvent(models.EncounterActionDeleted, nil, st.encounter, st.participants), nil
+}
+
+// AddParticipant adiciona uma ficha da mesa ou um NPC ao encontro. Jogadores adicionam apenas as próprias fichas;
+// fichas do mestre entram como NPCs. Com a iniciativa informada, o participante já entra na ordem
+func (s *EncounterService) AddParticipant(encounterID string, req models.AddParticipantRequest, userID int) (*models.EncounterEvent, error) {
+	st, err := s.load(encounterID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if st.encounter.Status == models.EncounterStatusEnded {
+		return nil, errors.New("o encontro já terminou")
+	}
+	if len(st.participants) >= maxEncounterParticipants {
+		return nil, fmt.Errorf("o encontro pode ter no máximo %d participantes", maxEncounterParticipants)
+	}
+
+	name := strings.TrimSpace(req.Name)
+	var participant *models.EncounterParticipant
+	if req.SheetID != "" {
+		sheet, err := s.sheetService.sheetForRoll(req.SheetID, userID)
+		if err != nil {
+			return nil, err
+		}
+		if sheet.TableID != st.encounter.TableID {
+			return nil, errors.New("a ficha não pertence à mesa do encontro")
+		}
+		if sheet.OwnerID != userID && !st.isGM() {
+			return nil, errors.New("apenas o dono da ficha ou o mestre da mesa pode adicioná-la")
+		}
+		for _, other := range st.participants {
+			if other.SheetID != nil && *other.SheetID == sheet.ID {
+				return nil, errors.New("a ficha já participa do encontro")
+			}
+		}
+
+		kind := models.ParticipantKindPlayer
+		if sheet.OwnerID == st.gmID {
+			kind = models.ParticipantKindNPC
+		}
+		if name == "" {
+			name = sheet.Name
+		}
+		participant = models.NewEncounterParticipant(st.encounter.ID, &sheet.ID, sheet.OwnerID, name, kind)
+	} else {
+		if !st.isGM() {
+			return nil, errors.New("apenas o mestre da mesa pode adicionar NPCs")
+		}
+		if name == "" {
+			return nil, errors.New("informe sheet_id ou o nome do NPC")
+		}
+		participant = models.NewEncounterParticipant(st.encounter.ID, nil, st.gmID, name, models.ParticipantKindNPC)
+	}
+
+	if req.Modifier != nil {
+		participant.InitiativeModifier = *req.Modifier
+	}
+	if req.Initiative != nil {
+		setInitiative(participant, *req.Initiative)
+	}
+
+	st.participants = append(st.participants, participant)
+	placeParticipant(st.encounter, st.participants, participant)
+	return s.save(st, models.EncounterActionParticipantAdded, &participant.ID)
+}
+
+// RemoveParticipant remove um participante; se for a vez dele, o turno passa ao próximo
+func (s *EncounterService) RemoveParticipant(encounterID, participantID string, userID int) (*models.EncounterEvent, error) {
+	st, err := s.load(encounterID, userID)
+	if err != nil {
+		return nil, err
+	}
+	participant, err := st.participant(participantID)
+	if err != nil {
+		return nil, err
+	}
+	if !st.controls(participant) {
+		return nil, errors.New("apenas o mestre da mesa ou quem controla o participante pode removê-lo")
+	}
+
+	if isCurrent(st.encounter, participant) {
+		// O próprio participante não pode receber o turno de volta
+		participant.Status = models.ParticipantStatusDelayed
+		if err := advanceTurn(st.encounter, st.participants); err != nil {
+			st.encounter.CurrentParticipantID = nil
+		}
+	}
+
+	st.participants = slices.DeleteFunc(st.participants, func(other *models.EncounterParticipant) bool {
+		return other.ID == participant.ID
+	})
+	renumber(st.participants)
+	return s.save(st, models.EncounterActionParticipantRemoved, &participant.ID, participant.ID)
+}
+
+// SetInitiative define a iniciativa do participante: informada ou rolada com pkg/roll. Fichas rolam uma expressão
+// (com referências {campo}) ou um campo; NPCs sem ficha, uma expressão. A rolagem fica no histórico da mesa com a tag "initiative"
+func (s *EncounterService) SetInitiative(encounterID, participantID string, req models.InitiativeRequest, userID int) (*models.EncounterEvent, error) {
+	st, err := s.load(encounterID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if st.encounter.Status == models.EncounterStatusEnded {
+		return nil, errors.New("o encontro já terminou")
+	}
+	participant, err := st.participant(participantID)
+	if err != nil {
+		return nil, err
+	}
+	if !st.controls(participant) {
+		return nil, errors.New("apenas o mestre da mesa ou quem controla o participante pode definir a iniciativa")
+	}
+
+	switch {
+	case req.Value != nil:
+		setInitiative(participant, *req.Value)
+		participant.RollID = nil
+		if req.Modifier != nil {
+			participant.InitiativeModifier = *req.Modifier
+		}
+	case req.Expression != "" || req.FieldName != "":
+		rollRecord, modifier, err := s.rollInitiative(st, participant, req)
+		if err != nil {
+			return nil, err
+		}
+		setInitiative(participant, rollRecord.ResultValue)
+		participant.RollID = &rollRecord.ID
+		participant.InitiativeModifier = modifier
+		if req.Modifier != nil {
+			participant.InitiativeModifier = *req.Modifier
+		}
+	default:
+		return nil, errors.New("informe value, expression ou field_name")
+	}
+
+	placeParticipant(st.encounter, st.participants, participant)
+	return s.save(st, models.EncounterActionInitiative, &participant.ID)
+}
+
+// rollInitiative rola e salva a iniciativa do participante, retornando a rolagem e o modificador da expressão
+func (s *EncounterService) rollInitiative(st *encounterState, participant *models.EncounterParticipant, req models.InitiativeRequest) (*models.Roll, int, error) {
+	label := models.RollLabel{Label: "Iniciativa: " + participant.Name, Tags: initiativeTags}
+
+	var rollRecord *models.Roll
+	var details *models.RollDetails
+	if participant.SheetID != nil {
+		sheet, err := s.sheetService.sheetForRoll(*participant.SheetID, st.userID)
+		if err != nil {
+			return nil, 0, err
+		}
+		rollRecord, details, err = s.sheetService.rollForSheet(sheet, models.CreateRollRequest{
+			SheetID:    sheet.ID,
+			Expression: req.Expression,
+			FieldName:  req.FieldName,
+			ClientSeed: req.ClientSeed,
+			Visibility: req.Visibility,
+			RollLabel:  label,
+		}, st.userID)
+		if err != nil {
+			return nil, 0, err
+		}
+	} else {
+		if req.Expression == "" {
+			return nil, 0, errors.New("NPCs sem ficha rolam a iniciativa com uma expressão")
+		}
+		visibility, err := NormalizeRollVisibility(req.Visibility, st.isGM())
+		if err != nil {
+			return nil, 0, err
+		}
+		options, err := s.sheetService.RollOptions(st.encounter.TableID, 0)
+		if err != nil {
+			return nil, 0, err
+		}
+
+		rollRecord = models.NewRoll("", st.encounter.TableID, st.userID, req.Expression, nil)
+		rollRecord.Visibility = visibility
+		rollRecord.SetLabel(label.Label, label.Tags)
+		if err := s.diceService.roll(rollRecord, options, req.ClientSeed); err != nil {
+			return nil, 0, fmt.Errorf("erro na rolagem: %w", err)
+		}
+		details = rollRecord.Details()
+	}
+
+	if err := s.rollRepo.Create(rollRecord); err != nil {
+		return nil, 0, fmt.Errorf("erro ao salvar rolagem: %w", err)
+	}
+
+	modifier := 0
+	if details != nil {
+		modifier = details.Modifier
+	}
+	return rollRecord, modifier, nil
+}
+
+// Start inicia o combate: ordena os participantes, abre a rodada 1 e passa a vez ao primeiro
+func (s *EncounterService) Start(encounterID string, userID int) (*models.EncounterEvent, error) {
+	st, err := s.loadAsGM(encounterID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if err := startEncounter(st.encounter, st.participants); err != nil {
+		return nil, err
+	}
+	return s.save(st, models.EncounterActionStarted, st.encounter.CurrentParticipantID)
+}
+
+// Next passa a vez ao próximo participante; o mestre ou quem controla o participante da vez pode encerrar o turno
+func (s *EncounterService) Next(encounterID string, userID int) (*models.EncounterEvent, error) {
+	st, err := s.load(encounterID, userID)
+	if err != nil {
+		return nil, err
+	}
+	current, err := st.current()
+	if err != nil {
+		return nil, err
+	}
+	if !st.controls(current) {
+		return nil, errors.New("apenas o mestre da mesa ou o participante da vez pode encerrar o turno")
+	}
+
+	if err := advanceTurn(st.encounter, st.participants); err != nil {
+		return nil, err
+	}
+	return s.save(st, models.EncounterActionNext, st.encounter.CurrentParticipantID)
+}
+
+// Previous devolve a vez ao participante anterior; apenas o mestre pode voltar o turno
+func (s *EncounterService) Previous(encounterID string, userID int) (*models.EncounterEvent, error) {
+	st, err := s.loadAsGM(encounterID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := st.current(); err != nil {
+		return nil, err
+	}
+
+	if err := rewindTurn(st.encounter, st.participants); err != nil {
+		return nil, err
+	}
+	return s.save(st, models.EncounterActionPrevious, st.encounter.CurrentParticipantID)
+}
+
+// Delay adia o turno do participante da vez: ele sai da ordem até agir (Act) e a vez passa ao próximo
+func (s *EncounterService) Delay(encounterID, participantID string, userID int) (*models.EncounterEvent, error) {
+	st, participant, err := s.loadCurrentTurn(encounterID, participantID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	participant.Status = models.ParticipantStatusDelayed
+	participant.ReadyTrigger = nil
+	if err := advanceTurn(st.encounter, st.participants); err != nil {
+		return nil, err
+	}
+	return s.save(st, models.EncounterActionDelay, &participant.ID)
+}
+
+// Ready prepara uma ação do participante da vez para um gatilho e passa a vez ao próximo.
+// A ação expira quando chega de novo a vez dele
+func (s *EncounterService) Ready(encounterID, participantID, trigger string, userID int) (*models.EncounterEvent, error) {
+	trigger = strings.TrimSpace(trigger)
+	if trigger == "" {
+		return nil, errors.New("o gatilho da ação preparada é obrigatório")
+	}
+
+	st, participant, err := s.loadCurrentTurn(encounterID, participantID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	participant.Status = models.ParticipantStatusReady
+	participant.ReadyTrigger = &trigger
+	if err := advanceTurn(st.encounter, st.participants); err != nil {
+		return nil, err
+	}
+	return s.save(st, models.EncounterActionReady, &participant.ID)
+}
+
+// Act faz agir um participante que adiou o turno ou preparou uma ação. Ele passa para a posição
+// imediatamente antes do participante da vez; quem adiou assume a vez, e quem preparou age fora do turno
+func (s *EncounterService) Act(encounterID, participantID string, userID int) (*models.EncounterEvent, error) {
+	st, err := s.load(encounterID, userID)
+	if err != nil {
+		return nil, err
+	}
+	participant, err := st.participant(participantID)
+	if err != nil {
+		return nil, err
+	}
+	if !st.controls(participant) {
+		return nil, errors.New("apenas o mestre da mesa ou quem controla o participante pode agir por ele")
+	}
+	if _, err := st.current(); err != nil {
+		return nil, err
+	}
+
+	if err := actNow(st.encounter, st.participants, participant); err != nil {
+		return nil, err
+	}
+	return s.save(st, models.EncounterActionAct, &participant.ID)
+}
+
+// End encerra o encontro
+func (s *EncounterService) End(encounterID string, userID int) (*models.EncounterEvent, error) {
+	st, err := s.loadAsGM(encounterID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if st.encounter.Status == models.EncounterStatusEnded {
+		return nil, errors.New("o encontro já terminou")
+	}
+
+	st.encounter.Status = models.EncounterStatusEnded
+	st.encounter.CurrentParticipantID = nil
+	return s.save(st, models.EncounterActionEnded, nil)
+}
+
+// current retorna o participante da vez de um encontro em andamento
+func (st *encounterState) current() (*models.EncounterParticipant, error) {
+	if st.encounter.Status != models.EncounterStatusActive {
+		return nil, errors.New("o encontro não está em andamento")
+	}
+	if st.encounter.CurrentParticipantID == nil {
+		return nil, errors.New("nenhum participante está com a vez")
+	}
+	return st.participant(*st.encounter.CurrentParticipantID)
+}
+
+// load busca o encontro e os participantes, verificando o acesso do usuário à mesa
+func (s *EncounterService) load(encounterID string, userID int) (*encounterState, error) {
+	encounter, err := s.encounterRepo.GetByID(encounterID)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao buscar encontro: %w", err)
+	}
+	if encounter == nil {
+		return nil, errors.New("encontro não encontrado")
+	}
+	if err := s.checkAccess(encounter.TableID, userID); err != nil {
+		return nil, err
+	}
+
+	gmID, err := s.sheetService.TableOwner(encounter.TableID)
+	if err != nil {
+		return nil, err
+	}
+	participants, err := s.encounterRepo.GetParticipants(encounter.ID)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao buscar participantes: %w", err)
+	}
+
+	return &encounterState{
+		encounter:    encounter,
+		participants: participants,
+		gmID:         gmID,
+		userID:       userID,
+	}, nil
+}
+
+// loadAsGM busca o encontro para uma ação exclusiva do mestre
+func (s *EncounterService) loadAsGM(encounterID string, userID int) (*encounterState, error) {
+	st, err := s.load(encounterID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if !st.isGM() {
+		return nil, errors.New("apenas o mestre da mesa pode gerenciar o encontro")
+	}
+	return st, nil
+}
+
+// loadCurrentTurn busca o encontro para uma ação do participante da vez (adiar ou preparar)
+func (s *EncounterService) loadCurrentTurn(encounterID, participantID string, userID int) (*encounterState, *models.EncounterParticipant, error) {
+	st, err := s.load(encounterID, userID)
+	if err != nil {
+		return nil, nil, err
+	}
+	participant, err := st.participant(participantID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if !st.controls(participant) {
+		return nil, nil, errors.New("apenas o mestre da mesa ou quem controla o participante pode agir por ele")
+	}
+	current, err := st.current()
+	if err != nil {
+		return nil, nil, err
+	}
+	if current.ID != participant.ID {
+		return nil, nil, errors.New("não é a vez do participante")
+	}
+	return st, participant, nil
+}
+
+// checkAccess verifica o acesso do usuário à mesa
+func (s *EncounterService) checkAccess(tableID string, userID int) error {
+	hasAccess, err := s.sheetService.checkTableAccess(tableID, userID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return errors.New("mesa não encontrada")
+	}
+	if err != nil {
+		return fmt.Errorf("erro ao verificar acesso: %w", err)
+	}
+	if !hasAccess {
+		return errors.New("acesso negado à mesa")
+	}
+	return nil
+}
+
+// save grava o estado do encontro e monta a notificação da ação. Cada rodada nova desconta, uma única vez e na
+// mesma transação, uma rodada das condições das fichas do encontro; voltar o turno não devolve as rodadas
+func (s *EncounterService) save(st *encounterState, action string, participantID *string, removedIDs ...string) (*models.EncounterEvent, error) {
+	saved, expired, err := s.encounterRepo.Save(st.encounter, st.participants, removedIDs...)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao salvar encontro: %w", err)
+	}
+	if !saved {
+		return nil, errors.New("o encontro foi alterado por outra ação; tente novamente")
+	}
+
+	event := encounterEvent(action, participantID, st.encounter, st.participants)
+	event.ExpiredConditions = expiredConditionsEvent(expired)
+	return event, nil
+}
+
+// encounterEvent monta a notificação de uma ação do encontro
+func encounterEvent(action string, participantID *string, encounter *models.Encounter, participants []*models.EncounterParticipant) *models.EncounterEvent {
+	return &models.EncounterEvent{
+		Action:        action,
+		ParticipantID: participantID,
+		Encounter:     encounter.ToResponse(participants),
+	}
+}
+
+// normalizeTieBreak valida a regra de desempate; vazio é o maior modificador
+func normalizeTieBreak(tieBreak string) (string, error) {
+	switch tieBreak {
+	case "":
+		return models.EncounterTieBreakModifier, nil
+	case models.EncounterTieBreakModifier, models.EncounterTieBreakPlayersFirst, models.EncounterTieBreakNPCsFirst:
+		return tieBreak, nil
+	}
+	return "", fmt.Errorf("regra de desempate inválida: '%s' (use modifier, players_first ou npcs_first)", tieBreak)
+}
+
+// setInitiative define a iniciativa e sorteia o desempate final do participante
+func setInitiative(participant *models.EncounterParticipant, initiative int) {
+	participant.Initiative = &initiative
+	participant.Tiebreaker = rand.IntN(maxTiebreaker)
+}
+
+// initiativeBefore informa se a age antes de b: maior iniciativa, depois a regra de desempate, o maior
+// modificador e o sorteio. Participantes sem iniciativa ficam no fim, na ordem de entrada
+func initiativeBefore(a, b *models.EncounterParticipant, tieBreak string) bool {
+	if (a.Initiative == nil) != (b.Initiative == nil) {
+		return a.Initiative != nil
+	}
+	if a.Initiative == nil {
+		return a.CreatedAt.Before(b.CreatedAt)
+	}
+	if *a.Initiative != *b.Initiative {
+		return *a.Initiative > *b.Initiative
+	}
+
+	if a.Kind != b.Kind {
+		switch tieBreak {
+		case models.EncounterTieBreakPlayersFirst:
+			return a.Kind == models.ParticipantKindPlayer
+		case models.EncounterTieBreakNPCsFirst:
+			return a.Kind == models.ParticipantKindNPC
+		}
+	}
+	if a.InitiativeModifier != b.InitiativeModifier {
+		return a.InitiativeModifier > b.InitiativeModifier
+	}
+	if a.Tiebreaker != b.Tiebreaker {
+		return a.Tiebreaker > b.Tiebreaker
+	}
+	return a.CreatedAt.Before(b.CreatedAt)
+}
+
+// sortParticipants ordena todos os participantes pela iniciativa
+func sortParticipants(participants []*models.EncounterParticipant, tieBreak string) {
+	sort.SliceStable(participants, func(i, j int) bool {
+		return initiativeBefore(participants[i], participants[j], tieBreak)
+	})
+	renumber(participants)
+}
+
+// placeParticipant posiciona um participante novo ou com iniciativa alterada. Antes do início a ordem toda é
+// refeita; em combate, apenas ele é reinserido, preservando as mudanças de ordem feitas ao adiar ou preparar ações
+func placeParticipant(encounter *models.Encounter, participants []*models.EncounterParticipant, participant *models.EncounterParticipant) {
+	if encounter.Status == models.EncounterStatusPreparing {
+		sortParticipants(participants, encounter.TieBreak)
+		return
+	}
+
+	others := slices.DeleteFunc(slices.Clone(participants), func(other *models.EncounterParticipant) bool {
+		return other.ID == participant.ID
+	})
+	index := len(others)
+	for i, other := range others {
+		if initiativeBefore(participant, other, encounter.TieBreak) {
+			index = i
+			break
+		}
+	}
+	copy(participants, slices.Insert(others, index, participant))
+	renumber(participants)
+}
+
+// renumber grava a posição de cada participante na ordem atual
+func renumber(participants []*models.EncounterParticipant) {
+	for i, participant := range participants {
+		participant.Position = i
+	}
+}
+
+// isCurrent informa se é a vez do participante
+func isCurrent(encounter *models.Encounter, participant *models.EncounterParticipant) bool {
+	return encounter.CurrentParticipantID != nil && *encounter.CurrentParticipantID == participant.ID
+}
+
+// currentIndex retorna a posição do participante da vez, ou -1
+func currentIndex(encounter *models.Encounter, participants []*models.EncounterParticipant) int {
+	return slices.IndexFunc(participants, func(participant *models.EncounterParticipant) bool {
+		return isCurrent(encounter, participant)
+	})
+}
+
+// startEncounter ordena os participantes, abre a rodada 1 e passa a vez ao primeiro
+func startEncounter(encounter *models.Encounter, participants []*models.EncounterParticipant) error {
+	switch encounter.Status {
+	case models.EncounterStatusActive:
+		return errors.New("o encontro já começou")
+	case models.EncounterStatusEnded:
+		return errors.New("o encontro já terminou")
+	}
+	if len(participants) == 0 {
+		return errors.New("o encontro não tem participantes")
+	}
+
+	var missing []string
+	for _, participant := range participants {
+		if participant.Initiative == nil {
+			missing = append(missing, participant.Name)
+		}
+	}
+	if len(missing) > 0 {
+		return fmt.Errorf("participantes sem iniciativa: %s", strings.Join(missing, ", "))
+	}
+
+	for _, participant := range participants {
+		participant.Status = models.ParticipantStatusActive
+		participant.ReadyTrigger = nil
+	}
+	sortParticipants(participants, encounter.TieBreak)
+
+	encounter.Status = models.EncounterStatusActive
+	encounter.Round = 1
+	encounter.CurrentParticipantID = &participants[0].ID
+	return nil
+}
+
+// advanceTurn passa a vez ao próximo participante que não adiou o turno, abrindo uma nova rodada ao
+// passar do último. A ação preparada de quem recebe a vez expira
+func advanceTurn(encounter *models.Encounter, participants []*models.EncounterParticipant) error {
+	start := currentIndex(encounter, participants)
+	for step := 1; step <= len(participants); step++ {
+		index := start + step
+		wrapped := index >= len(participants)
+		if wrapped {
+			index -= len(participants)
+		}
+		participant := participants[index]
+		if participant.Status == models.ParticipantStatusDelayed {
+			continue
+		}
+
+		if wrapped {
+			encounter.Round++
+		}
+		if participant.Status == models.ParticipantStatusReady {
+			participant.Status = models.ParticipantStatusActive
+			participant.ReadyTrigger = nil
+		}
+		encounter.CurrentParticipantID = &participant.ID
+		return nil
+	}
+	return errors.New("nenhum participante pode agir: todos adiaram o turno")
+}
+
+// rewindTurn devolve a vez ao participante anterior que não adiou o turno, voltando a rodada ao passar do primeiro
+func rewindTurn(encounter *models.Encounter, participants []*models.EncounterParticipant) error {
+	start := currentIndex(encounter, participants)
+	for step := 1; step <= len(participants); step++ {
+		index := start - step
+		wrapped := index < 0
+		if wrapped {
+			index += len(participants)
+		}
+		participant := participants[index]
+		if participant.Status == models.ParticipantStatusDelayed {
+			continue
+		}
+
+		if wrapped {
+			if encounter.Round <= 1 {
+				return errors.New("o encontro já está no primeiro turno")
+			}
+			encounter.Round--
+		}
+		encounter.CurrentParticipantID = &participant.ID
+		return nil
+	}
+	return errors.New("nenhum participante pode agir: todos adiaram o turno")
+}
+
+// actNow move um participante que adiou o turno ou preparou uma ação para imediatamente antes do participante
+// da vez. Quem adiou assume a vez; quem preparou age fora do turno e passa a agir nessa posição nas próximas rodadas
+func actNow(encounter *models.Encounter, participants []*models.EncounterParticipant, participant *models.EncounterParticipant) error {
+	delayed := participant.Status == models.ParticipantStatusDelayed
+	if !delayed && participant.Status != models.ParticipantStatusReady {
+		return errors.New("o participante não adiou o turno nem preparou uma ação")
+	}
+
+	others := slices.DeleteFunc(slices.Clone(participants), func(other *models.EncounterParticipant) bool {
+		return other.ID == participant.ID
+	})
+	index := currentIndex(encounter, others)
+	if index < 0 {
+		return errors.New("nenhum participante está com a vez")
+	}
+	copy(participants, slices.Insert(others, index, participant))
+	renumber(participants)
+
+	participant.Status = models.ParticipantStatusActive
+	participant.ReadyTrigger = nil
+	if delayed {
+		encounter.CurrentParticipantID = &participant.ID
+	}
+	return nil
+}
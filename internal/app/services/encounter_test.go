@@ -0,0 +1,233 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/luizdequeiroz/rpg-backend/internal/app/models"
+	"github.com/luizdequeiroz/rpg-backend/internal/app/repositories"
+)
+
+// newTestEncounter cria um encontro em preparação com participantes de iniciativa definida
+func newTestEncounter(tieBreak string, initiatives map[string]int, names ...string) (*models.Encounter, []*models.EncounterParticipant) {
+	encounter := models.NewEncounter("table", 1, "Teste", tieBreak)
+	participants := make([]*models.EncounterParticipant, len(names))
+	for i, name := range names {
+		participant := models.NewEncounterParticipant(encounter.ID, nil, 1, name, models.ParticipantKindNPC)
+		participant.ID = name
+		participant.CreatedAt = participant.CreatedAt.Add(time.Duration(i) * time.Millisecond)
+		if initiative, ok := initiatives[name]; ok {
+			setInitiative(participant, initiative)
+		}
+		participants[i] = participant
+	}
+	return encounter, participants
+}
+
+func order(participants []*models.EncounterParticipant) []string {
+	names := make([]string, len(participants))
+	for i, participant := range participants {
+		names[i] = participant.Name
+		if participant.Position != i {
+			names[i] += "?"
+		}
+	}
+	return names
+}
+
+func TestInitiativeOrderAndTieBreak(t *testing.T) {
+	encounter, participants := newTestEncounter(models.EncounterTieBreakModifier,
+		map[string]int{"a": 10, "b": 15, "c": 15, "d": 15}, "a", "b", "c", "d", "e")
+	participants[1].InitiativeModifier = 1
+	participants[2].InitiativeModifier = 3
+	participants[3].Kind = models.ParticipantKindPlayer
+	participants[3].InitiativeModifier = 1
+	participants[3].Tiebreaker = participants[1].Tiebreaker + 1
+
+	sortParticipants(participants, encounter.TieBreak)
+	assert.Equal(t, []string{"c", "d", "b", "a", "e"}, order(participants))
+
+	sortParticipants(participants, models.EncounterTieBreakPlayersFirst)
+	assert.Equal(t, []string{"d", "c", "b", "a", "e"}, order(participants))
+
+	sortParticipants(participants, models.EncounterTieBreakNPCsFirst)
+	assert.Equal(t, []string{"c", "b", "d", "a", "e"}, order(participants))
+}
+
+func TestStartEncounter(t *testing.T) {
+	encounter, participants := newTestEncounter(models.EncounterTieBreakModifier, map[string]int{"a": 10}, "a", "b")
+	assert.EqualError(t, startEncounter(encounter, participants), "participantes sem iniciativa: b")
+	assert.EqualError(t, startEncounter(encounter, nil), "o encontro não tem participantes")
+
+	setInitiative(participants[1], 12)
+	assert.NoError(t, startEncounter(encounter, participants))
+	assert.Equal(t, models.EncounterStatusActive, encounter.Status)
+	assert.Equal(t, 1, encounter.Round)
+	assert.Equal(t, "b", *encounter.CurrentParticipantID)
+	assert.EqualError(t, startEncounter(encounter, participants), "o encontro já começou")
+}
+
+func TestAdvanceAndRewindTurn(t *testing.T) {
+	encounter, participants := newTestEncounter(models.EncounterTieBreakModifier,
+		map[string]int{"a": 20, "b": 15, "c": 10}, "a", "b", "c")
+	assert.NoError(t, startEncounter(encounter, participants))
+
+	assert.EqualError(t, rewindTurn(encounter, participants), "o encontro já está no primeiro turno")
+
+	for _, expected := range []string{"b", "c", "a"} {
+		assert.NoError(t, advanceTurn(encounter, participants))
+		assert.Equal(t, expected, *encounter.CurrentParticipantID)
+	}
+	assert.Equal(t, 2, encounter.Round)
+
+	assert.NoError(t, rewindTurn(encounter, participants))
+	assert.Equal(t, "c", *encounter.CurrentParticipantID)
+	assert.Equal(t, 1, encounter.Round)
+}
+
+func TestDelayAndAct(t *testing.T) {
+	encounter, participants := newTestEncounter(models.EncounterTieBreakModifier,
+		map[string]int{"a": 20, "b": 15, "c": 10}, "a", "b", "c")
+	assert.NoError(t, startEncounter(encounter, participants))
+
+	// a adia: a vez passa para b e a é pulado até agir
+	participants[0].Status = models.ParticipantStatusDelayed
+	assert.NoError(t, advanceTurn(encounter, participants))
+	assert.Equal(t, "b", *encounter.CurrentParticipantID)
+	assert.NoError(t, advanceTurn(encounter, participants))
+	assert.NoError(t, advanceTurn(encounter, participants))
+	assert.Equal(t, "b", *encounter.CurrentParticipantID)
+	assert.Equal(t, 2, encounter.Round)
+
+	// a age antes de b e assume a vez, ficando nessa posição
+	assert.NoError(t, actNow(encounter, participants, participants[0]))
+	assert.Equal(t, "a", *encounter.CurrentParticipantID)
+	assert.Equal(t, []string{"a", "b", "c"}, order(participants))
+	assert.Equal(t, models.ParticipantStatusActive, participants[0].Status)
+
+	assert.EqualError(t, actNow(encounter, participants, participants[1]), "o participante não adiou o turno nem preparou uma ação")
+
+	for _, participant := range participants {
+		participant.Status = models.ParticipantStatusDelayed
+	}
+	assert.EqualError(t, advanceTurn(encounter, participants), "nenhum participante pode agir: todos adiaram o turno")
+}
+
+func TestReadyActionAndExpiry(t *testing.T) {
+	encounter, participants := newTestEncounter(models.EncounterTieBreakModifier,
+		map[string]int{"a": 20, "b": 15, "c": 10}, "a", "b", "c")
+	assert.NoError(t, startEncounter(encounter, participants))
+
+	trigger := "quando o goblin entrar"
+	participants[0].Status = models.ParticipantStatusReady
+	participants[0].ReadyTrigger = &trigger
+	assert.NoError(t, advanceTurn(encounter, participants))
+	assert.NoError(t, advanceTurn(encounter, participants))
+	assert.Equal(t, "c", *encounter.CurrentParticipantID)
+
+	// A ação preparada dispara no turno de c: a passa a agir antes de c, sem tirar a vez dele
+	assert.NoError(t, actNow(encounter, participants, participants[0]))
+	assert.Equal(t, "c", *encounter.CurrentParticipantID)
+	assert.Equal(t, []string{"b", "a", "c"}, order(participants))
+	ready := participants[1]
+	assert.Equal(t, models.ParticipantStatusActive, ready.Status)
+	assert.Nil(t, ready.ReadyTrigger)
+
+	// Sem disparar, a ação preparada expira quando a vez volta ao participante
+	ready.Status = models.ParticipantStatusReady
+	ready.ReadyTrigger = &trigger
+	assert.NoError(t, advanceTurn(encounter, participants))
+	assert.Equal(t, "b", *encounter.CurrentParticipantID)
+	assert.NoError(t, advanceTurn(encounter, participants))
+	assert.Equal(t, "a", *encounter.CurrentParticipantID)
+	assert.Equal(t, models.ParticipantStatusActive, ready.Status)
+	assert.Nil(t, ready.ReadyTrigger)
+}
+
+func TestPlaceParticipantDuringCombat(t *testing.T) {
+	encounter, participants := newTestEncounter(models.EncounterTieBreakModifier,
+		map[string]int{"a": 20, "b": 15, "c": 10}, "a", "b", "c")
+	assert.NoError(t, startEncounter(encounter, participants))
+
+	// Ordem alterada por uma ação adiada é preservada ao reinserir outro participante
+	participants[0], participants[1] = participants[1], participants[0]
+	renumber(participants)
+
+	_, extra := newTestEncounter(models.EncounterTieBreakModifier, map[string]int{"d": 12}, "d")
+	participants = append(participants, extra[0])
+	placeParticipant(encounter, participants, extra[0])
+	assert.Equal(t, []string{"b", "a", "d", "c"}, order(participants))
+	assert.Equal(t, "a", *encounter.CurrentParticipantID)
+}
+
+func TestNormalizeTieBreak(t *testing.T) {
+	tieBreak, err := normalizeTieBreak("")
+	assert.NoError(t, err)
+	assert.Equal(t, models.EncounterTieBreakModifier, tieBreak)
+
+	tieBreak, err = normalizeTieBreak(models.EncounterTieBreakNPCsFirst)
+	assert.NoError(t, err)
+	assert.Equal(t, models.EncounterTieBreakNPCsFirst, tieBreak)
+
+	_, err = normalizeTieBreak("dex")
+	assert.Error(t, err)
+}
+
+func TestEncounterTicksEachRoundOnce(t *testing.T) {
+	database := newTestDatabase(t)
+	sheetService := newTestSheetService(database)
+	service := NewEncounterService(repositories.NewEncounterRepository(database.DB), repositories.NewRollRepository(database.DB), sheetService, nil)
+	sheet := newTestSheet(t, database, `{}`)
+
+	condition := newTestCondition("atordoado")
+	condition.DurationType = models.ConditionDurationRounds
+	condition.RemainingRounds = intPtr(3)
+	conditionRepo := repositories.NewSheetConditionRepository(database.DB)
+	require.NoError(t, conditionRepo.Create(condition))
+
+	created, err := service.Create(sheet.TableID, models.CreateEncounterRequest{Name: "Emboscada"}, 1)
+	require.NoError(t, err)
+	encounterID := created.Encounter.ID
+	_, err = service.AddParticipant(encounterID, models.AddParticipantRequest{SheetID: sheet.ID, Initiative: intPtr(15)}, 1)
+	require.NoError(t, err)
+	_, err = service.AddParticipant(encounterID, models.AddParticipantRequest{Name: "Goblin", Initiative: intPtr(10)}, 1)
+	require.NoError(t, err)
+
+	remaining := func() *int {
+		current, err := conditionRepo.GetByID(condition.ID)
+		require.NoError(t, err)
+		if current == nil {
+			return nil
+		}
+		return current.RemainingRounds
+	}
+	step := func(move func(string, int) (*models.EncounterEvent, error), round int) *models.EncounterEvent {
+		event, err := move(encounterID, 1)
+		require.NoError(t, err)
+		require.Equal(t, round, event.Encounter.Round)
+		return event
+	}
+
+	step(service.Start, 1)
+	step(service.Next, 1)
+	step(service.Next, 2)
+	assert.Equal(t, intPtr(2), remaining())
+
+	// Voltar à rodada 1 e avançar de novo até a 2 não desconta a rodada outra vez
+	step(service.Previous, 1)
+	step(service.Next, 2)
+	assert.Equal(t, intPtr(2), remaining())
+
+	step(service.Next, 2)
+	step(service.Next, 3)
+	assert.Equal(t, intPtr(1), remaining())
+
+	step(service.Next, 3)
+	event := step(service.Next, 4)
+	assert.Nil(t, remaining())
+	require.NotNil(t, event.ExpiredConditions)
+	assert.Equal(t, condition.ID, event.ExpiredConditions.Conditions[0].ID)
+}
@@ -7,6 +7,7 @@ import (
 
 	"github.com/luizdequeiroz/rpg-backend/internal/app/models"
 	"github.com/luizdequeiroz/rpg-backend/internal/app/repositories"
+	"github.com/luizdequeiroz/rpg-backend/pkg/roll"
 )
 
 // GameTableService gerencia a lógica de negócio para mesas de jogo
@@ -314,6 +315,8 @@ func (s *GameTableService) ValidateCreateRequest(req models.CreateGameTableReque
 		})
 	}
 
+	errors = append(errors, s.validateSettings(req.Settings)...)
+
 	if req.System == "" {
 		errors = append(errors, models.GameTableValidationError{
 			Field:   "system",
@@ -373,5 +376,42 @@ func (s *GameTableService) ValidateUpdateRequest(req models.UpdateGameTableReque
 		}
 	}
 
+	errors = append(errors, s.validateSettings(req.Settings)...)
+
+	return errors
+}
+
+// validateSettings valida as configurações de rolagem da mesa
+func (s *GameTableService) validateSettings(settings *models.TableSettings) []models.GameTableValidationError {
+	var errors []models.GameTableValidationError
+	if settings == nil {
+		return errors
+	}
+
+	if err := roll.ValidateCustomDice(settings.CustomDice); err != nil {
+		errors = append(errors, models.GameTableValidationError{
+			Field:   "settings.custom_dice",
+			Message: err.Error(),
+		})
+	}
+	if err := roll.ValidateCritRule(settings.Critical); err != nil {
+		errors = append(errors, models.GameTableValidationError{
+			Field:   "settings.critical",
+			Message: err.Error(),
+		})
+	}
+	if err := roll.ValidateCritRule(settings.Fumble); err != nil {
+		errors = append(errors, models.GameTableValidationError{
+			Field:   "settings.fumble",
+			Message: err.Error(),
+		})
+	}
+	if err := roll.ValidateOutcomeTables(settings.Outcomes); err != nil {
+		errors = append(errors, models.GameTableValidationError{
+			Field:   "settings.outcomes",
+			Message: err.Error(),
+		})
+	}
+
 	return errors
 }
@@ -4,18 +4,33 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"slices"
+	"strings"
+	"time"
+	"unicode/utf8"
 
+	"github.com/google/uuid"
 	"github.com/luizdequeiroz/rpg-backend/internal/app/models"
 	"github.com/luizdequeiroz/rpg-backend/internal/app/repositories"
 	"github.com/luizdequeiroz/rpg-backend/pkg/roll"
 )
 
+// rollVoidGracePeriod é o prazo em que quem rolou pode anular a própria rolagem; o mestre pode anular a qualquer momento
+const rollVoidGracePeriod = 5 * time.Minute
+
+// maxRollLabelLength é o tamanho máximo do rótulo de uma rolagem
+const maxRollLabelLength = 200
+
 // PlayerSheetService gerencia lógica de negócio para fichas
 type PlayerSheetService struct {
-	sheetRepo     *repositories.PlayerSheetRepository
-	rollRepo      *repositories.RollRepository
-	gameTableRepo *repositories.GameTableRepository
-	rollEngine    *roll.RollEngine
+	sheetRepo       *repositories.PlayerSheetRepository
+	rollRepo        *repositories.RollRepository
+	gameTableRepo   *repositories.GameTableRepository
+	templateRepo    *repositories.SheetTemplateRepository
+	rollEngine      *roll.RollEngine
+	fairnessService *RollFairnessService
+	conditionRepo   *repositories.SheetConditionRepository
+	itemRepo        *repositories.SheetItemRepository
 }
 
 // NewPlayerSheetService cria nova instância do serviço
@@ -23,12 +38,21 @@ func NewPlayerSheetService(
 	sheetRepo *repositories.PlayerSheetRepository,
 	rollRepo *repositories.RollRepository,
 	gameTableRepo *repositories.GameTableRepository,
+	templateRepo *repositories.SheetTemplateRepository,
+	rollEngine *roll.RollEngine,
+	fairnessService *RollFairnessService,
+	conditionRepo *repositories.SheetConditionRepository,
+	itemRepo *repositories.SheetItemRepository,
 ) *PlayerSheetService {
 	return &PlayerSheetService{
-		sheetRepo:     sheetRepo,
-		rollRepo:      rollRepo,
-		gameTableRepo: gameTableRepo,
-		rollEngine:    roll.NewRollEngine(),
+		sheetRepo:       sheetRepo,
+		rollRepo:        rollRepo,
+		gameTableRepo:   gameTableRepo,
+		templateRepo:    templateRepo,
+		rollEngine:      rollEngine,
+		fairnessService: fairnessService,
+		conditionRepo:   conditionRepo,
+		itemRepo:        itemRepo,
 	}
 }
 
@@ -181,6 +205,142 @@ func (s *PlayerSheetService) Delete(id string, userID int) error {
 
 // CreateRoll executa rolagem de dados
 func (s *PlayerSheetService) CreateRoll(sheetID string, req models.CreateRollRequest, userID int) (*models.RollResponse, error) {
+	sheet, err := s.sheetForRoll(sheetID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	rollRecord, rollDetails, err := s.rollForSheet(sheet, req, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Salvar no banco
+	err = s.rollRepo.Create(rollRecord)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao salvar rolagem: %w", err)
+	}
+
+	// Retornar resposta
+	response := rollRecord.ToResponse()
+	response.ResultDetails = rollDetails
+	response.User = &models.UserResponse{ID: userID}
+
+	// Em rolagens "blind" quem rolou não vê o resultado (o mestre nunca rola "blind", ver NormalizeRollVisibility)
+	if rollRecord.Visibility == models.RollVisibilityBlind {
+		return response.Redacted(), nil
+	}
+	return response, nil
+}
+
+// RollWithSheet executa e salva a rolagem de /dice/roll-with-sheet em nome do dono da ficha, pelo mesmo caminho das
+// rolagens da ficha: as definições de rolagem, os itens equipados e as condições valem também aqui.
+// A visibilidade do pedido deve vir validada por NormalizeRollVisibility
+func (s *PlayerSheetService) RollWithSheet(req models.DiceRollWithSheetRequest, sheet *models.PlayerSheetResponse) (*models.DiceRollResponse, error) {
+	// comment é o rótulo obsoleto, usado apenas sem label
+	label := req.RollLabel
+	if label.Label == "" {
+		label.Label = req.Comment
+	}
+
+	rollRecord, _, err := s.rollForSheet(sheet, models.CreateRollRequest{
+		SheetID:    sheet.ID,
+		Expression: req.Expression,
+		ClientSeed: req.ClientSeed,
+		Visibility: req.Visibility,
+		RollCheck:  req.RollCheck,
+		RollLabel:  label,
+	}, sheet.OwnerID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.rollRepo.Create(rollRecord); err != nil {
+		return nil, fmt.Errorf("erro ao salvar rolagem: %w", err)
+	}
+
+	response := newDiceRollResponse(rollRecord)
+	return &response, nil
+}
+
+// CreateRollBatch executa as rolagens de um lote na ficha (lista de expressões ou "6x 4d6kh3"). Cada rolagem é
+// independente: as que falham trazem o erro sem interromper as demais, e as feitas são salvas juntas, com o mesmo batch_id.
+// Retorna o lote completo; quem o exibe oculta os resultados conforme a visibilidade (ver models.RollAccess)
+func (s *PlayerSheetService) CreateRollBatch(req models.CreateRollRequest, userID int) (*models.RollBatchResponse, error) {
+	expressions, err := roll.ExpandBatch(batchExpressions(req.Expression, req.Expressions))
+	if err != nil {
+		return nil, err
+	}
+
+	sheet, err := s.sheetForRoll(req.SheetID, userID)
+	if err != nil {
+		return nil, err
+	}
+	gmID, err := s.TableOwner(sheet.TableID)
+	if err != nil {
+		return nil, err
+	}
+	visibility, err := NormalizeRollVisibility(req.Visibility, userID == gmID)
+	if err != nil {
+		return nil, err
+	}
+	label, tags, err := NormalizeRollLabel(req.RollLabel, "")
+	if err != nil {
+		return nil, err
+	}
+
+	response := &models.RollBatchResponse{
+		BatchID:    uuid.New().String(),
+		SheetID:    sheet.ID,
+		TableID:    sheet.TableID,
+		UserID:     userID,
+		Visibility: visibility,
+		Rolls:      make([]models.RollBatchResult, len(expressions)),
+	}
+	records := make([]*models.Roll, len(expressions))
+	details := make([]*models.RollDetails, len(expressions))
+	var rolled []*models.Roll
+
+	for i, expression := range expressions {
+		response.Rolls[i] = models.RollBatchResult{Index: i, Expression: expression}
+
+		rollRecord, rollDetails, err := s.rollForSheet(sheet, models.CreateRollRequest{
+			SheetID:    sheet.ID,
+			Expression: expression,
+			ClientSeed: req.ClientSeed,
+			Visibility: visibility,
+			RollCheck:  req.RollCheck,
+			RollLabel:  models.RollLabel{Label: label, Tags: tags},
+		}, userID)
+		if err != nil {
+			response.Rolls[i].Error = err.Error()
+			response.Failed++
+			continue
+		}
+		rollRecord.BatchID = &response.BatchID
+		records[i], details[i] = rollRecord, rollDetails
+		rolled = append(rolled, rollRecord)
+	}
+
+	if len(rolled) > 0 {
+		if err := s.rollRepo.CreateBatch(rolled); err != nil {
+			return nil, fmt.Errorf("erro ao salvar rolagens: %w", err)
+		}
+	}
+
+	for i, rollRecord := range records {
+		if rollRecord != nil {
+			rollResponse := rollRecord.ToResponse()
+			rollResponse.ResultDetails = details[i]
+			rollResponse.User = &models.UserResponse{ID: userID}
+			response.Rolls[i].Roll = rollResponse
+		}
+	}
+	return response, nil
+}
+
+// sheetForRoll busca a ficha a ser rolada e verifica o acesso do usuário à mesa dela
+func (s *PlayerSheetService) sheetForRoll(sheetID string, userID int) (*models.PlayerSheetResponse, error) {
 	// Buscar ficha
 	sheet, err := s.sheetRepo.GetByIDWithDetails(sheetID)
 	if err != nil {
@@ -199,96 +359,364 @@ func (s *PlayerSheetService) CreateRoll(sheetID string, req models.CreateRollReq
 		return nil, errors.New("acesso negado à mesa")
 	}
 
+	return sheet, nil
+}
+
+// rollForSheet executa a rolagem verificável da ficha, sem salvá-la
+func (s *PlayerSheetService) rollForSheet(sheet *models.PlayerSheetResponse, req models.CreateRollRequest, userID int) (*models.Roll, *models.RollDetails, error) {
+	return s.rollSheetStep(sheet, req, userID, true)
+}
+
+// rollSheetStep executa a rolagem verificável da ficha, sem salvá-la. Com untargeted, os modificadores de condições e
+// itens sem applies_to também valem; sem ele, apenas os que têm como alvo uma tag ou o campo da rolagem
+func (s *PlayerSheetService) rollSheetStep(sheet *models.PlayerSheetResponse, req models.CreateRollRequest, userID int, untargeted bool) (*models.Roll, *models.RollDetails, error) {
 	// Validar request
 	if req.Expression == "" && req.FieldName == "" {
-		return nil, errors.New("expression ou field_name é obrigatório")
+		return nil, nil, errors.New("expression ou field_name é obrigatório")
 	}
 
-	var rollDetails *models.RollDetails
-	var expression string
-	var fieldName *string
+	gmID, err := s.TableOwner(sheet.TableID)
+	if err != nil {
+		return nil, nil, err
+	}
+	visibility, err := NormalizeRollVisibility(req.Visibility, userID == gmID)
+	if err != nil {
+		return nil, nil, err
+	}
+	label, tags, err := NormalizeRollLabel(req.RollLabel, "")
+	if err != nil {
+		return nil, nil, err
+	}
 
-	// Executar rolagem
-	if req.Expression != "" {
-		// Rolagem direta por expressão
-		rollDetails, err = s.rollEngine.Roll(req.Expression)
-		expression = req.Expression
-	} else {
-		// Rolagem baseada em campo da ficha
-		rollDetails, expression, err = s.rollEngine.RollFromField(sheet.Data, req.FieldName)
-		fieldName = &req.FieldName
+	// Definições de rolagem do template e da mesa
+	options, err := s.RollOptions(sheet.TableID, sheet.TemplateID)
+	if err != nil {
+		return nil, nil, err
 	}
 
+	options.Check = &req.RollCheck
+
+	// Criar record da rolagem e reservar semente e nonce verificáveis
+	rollRecord := models.NewRoll(sheet.ID, sheet.TableID, userID, req.Expression, nil)
+	rollRecord.Visibility = visibility
+	rollRecord.SetLabel(label, tags)
+	options, err = s.fairnessService.Apply(rollRecord, req.ClientSeed, options)
 	if err != nil {
-		return nil, fmt.Errorf("erro na rolagem: %w", err)
+		return nil, nil, err
 	}
 
-	// Criar record da rolagem
-	rollRecord := models.NewRoll(sheetID, sheet.TableID, userID, expression, fieldName)
-	rollRecord.ResultValue = rollDetails.Total
+	// Itens equipados alteram campos da ficha (e.g., a armadura define a CA) e somam modificadores à rolagem
+	items, err := s.itemRepo.GetEquippedBySheetID(sheet.ID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("erro ao buscar itens da ficha: %w", err)
+	}
+	sheetData, _, err := equippedSheetData(sheet.Data, items)
+	if err != nil {
+		return nil, nil, err
+	}
 
-	// Serializar detalhes
-	detailsJSON, _ := json.Marshal(rollDetails)
-	rollRecord.ResultDetails = string(detailsJSON)
+	// Rolagem direta por expressão ou baseada em campo da ficha
+	expression := req.Expression
+	if expression == "" {
+		expression, err = s.rollEngine.FieldExpression(sheetData, req.FieldName)
+		if err != nil {
+			return nil, nil, fmt.Errorf("erro na rolagem: %w", err)
+		}
+		rollRecord.FieldName = &req.FieldName
+	}
 
-	// Salvar no banco
-	err = s.rollRepo.Create(rollRecord)
+	// Somar os modificadores das condições e dos itens que valem para esta rolagem. Eles somam ao total: paradas de
+	// sucessos e dados Fate não os recebem
+	sum, err := s.rollEngine.IsSumExpression(sheetData, expression)
 	if err != nil {
-		return nil, fmt.Errorf("erro ao salvar rolagem: %w", err)
+		return nil, nil, fmt.Errorf("erro na rolagem: %w", err)
+	}
+	var bonus, itemBonus string
+	var applied []models.AppliedCondition
+	var appliedItems []models.AppliedItem
+	if sum {
+		conditions, err := s.conditionRepo.GetBySheetID(sheet.ID)
+		if err != nil {
+			return nil, nil, fmt.Errorf("erro ao buscar condições da ficha: %w", err)
+		}
+		bonus, applied = conditionModifiers(conditions, req.FieldName, tags, untargeted)
+		itemBonus, appliedItems = itemModifiers(items, req.FieldName, tags, untargeted)
 	}
 
-	// Retornar resposta
-	response := rollRecord.ToResponse()
-	response.ResultDetails = rollDetails
-	response.User = &models.UserResponse{ID: userID}
+	// Executar rolagem, resolvendo as referências a campos da ficha
+	rollDetails, resolved, err := s.rollEngine.RollExpressionWithSheet(sheetData, expression+bonus+itemBonus, options)
+	if err != nil {
+		return nil, nil, fmt.Errorf("erro na rolagem: %w", err)
+	}
+	rollRecord.Expression = resolved
+	rollDetails.Conditions = applied
+	rollDetails.Items = appliedItems
+	rollRecord.SetDetails(rollDetails)
 
-	return response, nil
+	return rollRecord, rollDetails, nil
 }
 
-// GetRollsByTableID lista rolagens da mesa
-func (s *PlayerSheetService) GetRollsByTableID(tableID string, userID int, page, limit int) ([]*models.RollResponse, error) {
+// GetRollsByTableID lista rolagens da mesa que atendem ao filtro, com o total de rolagens encontradas
+func (s *PlayerSheetService) GetRollsByTableID(tableID string, userID int, filter models.RollFilter, sort models.RollSort, page, limit int) ([]*models.RollResponse, int, error) {
 	// Verificar acesso à mesa
 	hasAccess, err := s.checkTableAccess(tableID, userID)
 	if err != nil {
-		return nil, fmt.Errorf("erro ao verificar acesso: %w", err)
+		return nil, 0, fmt.Errorf("erro ao verificar acesso: %w", err)
 	}
 	if !hasAccess {
-		return nil, errors.New("acesso negado à mesa")
+		return nil, 0, errors.New("acesso negado à mesa")
 	}
 
-	offset := (page - 1) * limit
-	rolls, err := s.rollRepo.GetByTableID(tableID, offset, limit)
-	if err != nil {
-		return nil, fmt.Errorf("erro ao buscar rolagens: %w", err)
-	}
-
-	return rolls, nil
+	filter.TableID = &tableID
+	return s.searchRolls(tableID, userID, filter, sort, page, limit)
 }
 
-// GetRollsBySheetID lista rolagens da ficha
-func (s *PlayerSheetService) GetRollsBySheetID(sheetID string, userID int, page, limit int) ([]*models.RollResponse, error) {
+// GetRollsBySheetID lista rolagens da ficha que atendem ao filtro, com o total de rolagens encontradas
+func (s *PlayerSheetService) GetRollsBySheetID(sheetID string, userID int, filter models.RollFilter, sort models.RollSort, page, limit int) ([]*models.RollResponse, int, error) {
 	// Buscar mesa da ficha
 	tableID, err := s.sheetRepo.GetTableIDBySheetID(sheetID)
 	if err != nil {
-		return nil, fmt.Errorf("erro ao buscar mesa da ficha: %w", err)
+		return nil, 0, fmt.Errorf("erro ao buscar mesa da ficha: %w", err)
 	}
 
 	// Verificar acesso à mesa
 	hasAccess, err := s.checkTableAccess(tableID, userID)
 	if err != nil {
-		return nil, fmt.Errorf("erro ao verificar acesso: %w", err)
+		return nil, 0, fmt.Errorf("erro ao verificar acesso: %w", err)
 	}
 	if !hasAccess {
-		return nil, errors.New("acesso negado à mesa")
+		return nil, 0, errors.New("acesso negado à mesa")
+	}
+
+	filter.SheetID = &sheetID
+	return s.searchRolls(tableID, userID, filter, sort, page, limit)
+}
+
+// VoidRoll anula uma rolagem com o motivo informado. O mestre da mesa pode anular qualquer rolagem da mesa;
+// quem rolou, apenas dentro do prazo. A rolagem continua no histórico e sai das estatísticas
+func (s *PlayerSheetService) VoidRoll(rollID string, userID int, reason string) (*models.Roll, error) {
+	reason = strings.TrimSpace(reason)
+	if reason == "" {
+		return nil, errors.New("o motivo da anulação é obrigatório")
+	}
+
+	rollRecord, err := s.rollRepo.GetByID(rollID)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao buscar rolagem: %w", err)
+	}
+	if rollRecord == nil {
+		return nil, errors.New("rolagem não encontrada")
+	}
+
+	gmID := 0
+	if rollRecord.TableID != nil {
+		if gmID, err = s.TableOwner(*rollRecord.TableID); err != nil {
+			return nil, err
+		}
+	}
+	if err := canVoidRoll(rollRecord, userID, gmID, time.Now()); err != nil {
+		return nil, err
+	}
+
+	voided, err := s.rollRepo.Void(rollRecord.ID, userID, reason)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao anular rolagem: %w", err)
+	}
+	if !voided {
+		return nil, errors.New("rolagem já anulada")
+	}
+
+	rollRecord, err = s.rollRepo.GetByID(rollRecord.ID)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao buscar rolagem: %w", err)
+	}
+	return rollRecord, nil
+}
+
+// canVoidRoll verifica se o usuário pode anular a rolagem: o mestre (gmID) sempre, quem rolou dentro do prazo
+func canVoidRoll(rollRecord *models.Roll, userID, gmID int, now time.Time) error {
+	if rollRecord.VoidedAt != nil {
+		return errors.New("rolagem já anulada")
+	}
+	if gmID != 0 && userID == gmID {
+		return nil
+	}
+	if rollRecord.UserID != userID {
+		return errors.New("apenas o mestre da mesa ou quem rolou pode anular a rolagem")
+	}
+	if now.Sub(rollRecord.CreatedAt) > rollVoidGracePeriod {
+		return errors.New("o prazo para anular a própria rolagem expirou; peça ao mestre da mesa")
+	}
+	return nil
+}
+
+// searchRolls busca uma página das rolagens da mesa visíveis ao usuário e o total, ocultando os
+// resultados que ele não pode ver
+func (s *PlayerSheetService) searchRolls(tableID string, userID int, filter models.RollFilter, sort models.RollSort, page, limit int) ([]*models.RollResponse, int, error) {
+	gmID, err := s.TableOwner(tableID)
+	if err != nil {
+		return nil, 0, err
 	}
 
 	offset := (page - 1) * limit
-	rolls, err := s.rollRepo.GetBySheetIDWithPagination(sheetID, offset, limit)
+	records, err := s.rollRepo.Search(filter, sort, userID, userID == gmID, offset, limit)
+	if err != nil {
+		return nil, 0, fmt.Errorf("erro ao buscar rolagens: %w", err)
+	}
+	total, err := s.rollRepo.Count(filter, userID, userID == gmID)
+	if err != nil {
+		return nil, 0, fmt.Errorf("erro ao contar rolagens: %w", err)
+	}
+
+	rolls := make([]*models.RollResponse, len(records))
+	for i := range records {
+		rolls[i] = records[i].ToResponse()
+	}
+	return redactRolls(rolls, userID, gmID), total, nil
+}
+
+// TableOwner retorna o mestre (dono) da mesa, que vê as rolagens "gm" e "blind" dos jogadores
+func (s *PlayerSheetService) TableOwner(tableID string) (int, error) {
+	ownerID, err := s.gameTableRepo.GetOwnerByTableID(tableID)
+	if err != nil {
+		return 0, fmt.Errorf("erro ao buscar mestre da mesa: %w", err)
+	}
+	return ownerID, nil
+}
+
+// NormalizeRollVisibility valida a visibilidade pedida para uma rolagem; vazio é pública.
+// Uma rolagem "blind" do próprio mestre equivale a "gm": só ele a vê
+func NormalizeRollVisibility(visibility string, rollerIsGM bool) (string, error) {
+	switch visibility {
+	case "":
+		return models.RollVisibilityPublic, nil
+	case models.RollVisibilityBlind:
+		if rollerIsGM {
+			return models.RollVisibilityGM, nil
+		}
+		return visibility, nil
+	case models.RollVisibilityPublic, models.RollVisibilityGM, models.RollVisibilitySelf:
+		return visibility, nil
+	}
+	return "", fmt.Errorf("visibilidade inválida: '%s' (use public, gm, blind ou self)", visibility)
+}
+
+// NormalizeRollLabel valida o rótulo e as tags pedidos para uma rolagem; comment é o rótulo obsoleto, usado quando
+// o rótulo vem vazio. As hashtags escritas no rótulo entram nas tags, normalizadas e sem repetição
+func NormalizeRollLabel(req models.RollLabel, comment string) (string, []string, error) {
+	label := strings.TrimSpace(req.Label)
+	if label == "" {
+		label = strings.TrimSpace(comment)
+	}
+	if utf8.RuneCountInString(label) > maxRollLabelLength {
+		return "", nil, fmt.Errorf("o rótulo deve ter no máximo %d caracteres", maxRollLabelLength)
+	}
+
+	var tags []string
+	seen := make(map[string]bool)
+	for _, tag := range append(slices.Clone(req.Tags), models.LabelHashtags(label)...) {
+		normalized, err := models.NormalizeRollTag(tag)
+		if err != nil {
+			return "", nil, err
+		}
+		if !seen[normalized] {
+			seen[normalized] = true
+			tags = append(tags, normalized)
+		}
+	}
+	if len(tags) > models.MaxRollTags {
+		return "", nil, fmt.Errorf("use no máximo %d tags por rolagem", models.MaxRollTags)
+	}
+
+	return label, tags, nil
+}
+
+// redactRolls oculta o resultado das rolagens que o usuário vê sem poder ver o resultado
+func redactRolls(rolls []*models.RollResponse, viewerID, gmID int) []*models.RollResponse {
+	for i, rollResponse := range rolls {
+		if _, full := models.RollAccess(rollResponse.Visibility, rollResponse.UserID, viewerID, gmID); !full {
+			rolls[i] = rollResponse.Redacted()
+		}
+	}
+	return rolls
+}
+
+// RollOptions monta as definições de rolagem do template e da mesa.
+// Dados personalizados, regras de crítico e tabelas de resultados da mesa prevalecem sobre os do template.
+func (s *PlayerSheetService) RollOptions(tableID string, templateID int) (*roll.RollOptions, error) {
+	options := &roll.RollOptions{}
+
+	template, err := s.templateRepo.GetByID(templateID)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao buscar template: %w", err)
+	}
+	if template != nil {
+		rules, err := models.ParseRollRules(template.Definition)
+		if err == nil {
+			applyRollRules(options, rules)
+		}
+	}
+
+	table, err := s.gameTableRepo.GetByID(tableID)
 	if err != nil {
-		return nil, fmt.Errorf("erro ao buscar rolagens: %w", err)
+		return nil, fmt.Errorf("erro ao buscar mesa: %w", err)
+	}
+	if table != nil {
+		applyRollRules(options, &table.GetSettings().RollRules)
+	}
+
+	return options, nil
+}
+
+// applyRollRules acrescenta as regras declaradas às opções, substituindo as regras de crítico já definidas
+func applyRollRules(options *roll.RollOptions, rules *models.RollRules) {
+	options.CustomDice = append(options.CustomDice, rules.CustomDice...)
+	if rules.Critical != nil {
+		options.Critical = rules.Critical
+	}
+	if rules.Fumble != nil {
+		options.Fumble = rules.Fumble
+	}
+	for name, table := range rules.Outcomes {
+		if options.OutcomeTables == nil {
+			options.OutcomeTables = make(map[string]models.OutcomeTable)
+		}
+		options.OutcomeTables[name] = table
+	}
+}
+
+// SheetRules lê os recursos, as defesas e a capacidade de carga declarados no template da ficha
+func (s *PlayerSheetService) SheetRules(templateID int) (*models.SheetRules, error) {
+	template, err := s.templateRepo.GetByID(templateID)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao buscar template: %w", err)
+	}
+	if template == nil {
+		return &models.SheetRules{}, nil
+	}
+
+	rules, err := models.ParseSheetRules(template.Definition)
+	if err != nil {
+		return nil, errors.New("recursos do template inválidos")
+	}
+	return rules, nil
+}
+
+// RollOptionsForSheet monta as definições de rolagem da ficha informada; rolagens sem ficha não têm definições
+func (s *PlayerSheetService) RollOptionsForSheet(sheetID *string) (*roll.RollOptions, error) {
+	if sheetID == nil {
+		return nil, nil
+	}
+
+	sheet, err := s.sheetRepo.GetByID(*sheetID)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao buscar ficha: %w", err)
+	}
+	if sheet == nil {
+		return nil, errors.New("ficha não encontrada")
 	}
 
-	return rolls, nil
+	return s.RollOptions(sheet.TableID, sheet.TemplateID)
 }
 
 // checkTableAccess verifica se usuário tem acesso à mesa
@@ -0,0 +1,224 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/luizdequeiroz/rpg-backend/internal/app/models"
+	"github.com/luizdequeiroz/rpg-backend/internal/app/repositories"
+	"github.com/luizdequeiroz/rpg-backend/pkg/db"
+	"github.com/luizdequeiroz/rpg-backend/pkg/roll"
+)
+
+// newTestSheetService cria o serviço de fichas sobre o banco de teste
+func newTestSheetService(database *db.DB) *PlayerSheetService {
+	engine := roll.NewRollEngine()
+	rollRepo := repositories.NewRollRepository(database.DB)
+	fairnessService := NewRollFairnessService(repositories.NewRollSeedRepository(database.DB), rollRepo, engine)
+	return NewPlayerSheetService(
+		repositories.NewPlayerSheetRepository(database.DB),
+		rollRepo,
+		repositories.NewGameTableRepository(database),
+		repositories.NewSheetTemplateRepository(database),
+		engine,
+		fairnessService,
+		repositories.NewSheetConditionRepository(database.DB),
+		repositories.NewSheetItemRepository(database.DB),
+	)
+}
+
+// newTestSheet cria uma mesa do mestre 1 e nela uma ficha do jogador 2 com os dados informados
+func newTestSheet(t *testing.T, database *db.DB, data string) *models.PlayerSheetResponse {
+	database.MustExec(`INSERT INTO game_tables (id, name, system, owner_id) VALUES ('table', 'Mesa', 'D&D 5e', 1)`)
+	database.MustExec(`INSERT INTO sheet_templates (id, name, definition, description) VALUES (1, 'Template', '{}', '')`)
+	database.MustExec(`INSERT INTO player_sheets (id, table_id, template_id, owner_id, name, data) VALUES ('sheet', 'table', 1, 2, 'Aria', '{}')`)
+
+	return &models.PlayerSheetResponse{
+		ID:         "sheet",
+		TableID:    "table",
+		TemplateID: 1,
+		OwnerID:    2,
+		Name:       "Aria",
+		Data:       newTestSheetData(t, data),
+	}
+}
+
+func TestNormalizeRollVisibility(t *testing.T) {
+	tests := []struct {
+		name       string
+		visibility string
+		rollerIsGM bool
+		expected   string
+	}{
+		{name: "Padrão público", visibility: "", expected: models.RollVisibilityPublic},
+		{name: "Pública", visibility: models.RollVisibilityPublic, expected: models.RollVisibilityPublic},
+		{name: "Mestre", visibility: models.RollVisibilityGM, rollerIsGM: true, expected: models.RollVisibilityGM},
+		{name: "Cega do jogador", visibility: models.RollVisibilityBlind, expected: models.RollVisibilityBlind},
+		{name: "Cega do mestre vira gm", visibility: models.RollVisibilityBlind, rollerIsGM: true, expected: models.RollVisibilityGM},
+		{name: "Só para si", visibility: models.RollVisibilitySelf, expected: models.RollVisibilitySelf},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			visibility, err := NormalizeRollVisibility(tt.visibility, tt.rollerIsGM)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, visibility)
+		})
+	}
+
+	_, err := NormalizeRollVisibility("secret", false)
+	assert.Error(t, err)
+}
+
+func TestNormalizeRollLabel(t *testing.T) {
+	label, tags, err := NormalizeRollLabel(models.RollLabel{
+		Label: "  Ataque furtivo no guarda #Stealth #ataque-surpresa ",
+		Tags:  []string{"#attack", "stealth"},
+	}, "")
+	assert.NoError(t, err)
+	assert.Equal(t, "Ataque furtivo no guarda #Stealth #ataque-surpresa", label)
+	assert.Equal(t, []string{"attack", "stealth", "ataque-surpresa"}, tags)
+
+	// comment é o rótulo obsoleto, usado apenas sem label
+	label, tags, err = NormalizeRollLabel(models.RollLabel{}, "Teste de Força")
+	assert.NoError(t, err)
+	assert.Equal(t, "Teste de Força", label)
+	assert.Empty(t, tags)
+
+	label, _, err = NormalizeRollLabel(models.RollLabel{Label: "Iniciativa"}, "Teste de Força")
+	assert.NoError(t, err)
+	assert.Equal(t, "Iniciativa", label)
+
+	// "#" no meio de uma palavra não é hashtag
+	_, tags, err = NormalizeRollLabel(models.RollLabel{Label: "Magia em C#"}, "")
+	assert.NoError(t, err)
+	assert.Empty(t, tags)
+
+	_, _, err = NormalizeRollLabel(models.RollLabel{Tags: []string{"dano físico"}}, "")
+	assert.Error(t, err)
+	_, _, err = NormalizeRollLabel(models.RollLabel{Tags: []string{"#"}}, "")
+	assert.Error(t, err)
+	_, _, err = NormalizeRollLabel(models.RollLabel{Label: "#a #b #c #d #e #f #g #h #i #j #k"}, "")
+	assert.Error(t, err)
+}
+
+func TestRedactRolls(t *testing.T) {
+	const gm, roller, other = 1, 2, 3
+	total := 17
+
+	newRoll := func(visibility string) *models.RollResponse {
+		return &models.RollResponse{
+			UserID:        roller,
+			Visibility:    visibility,
+			ResultValue:   total,
+			ResultDetails: &models.RollDetails{Total: total},
+		}
+	}
+
+	// Em listagens, o repositório já excluiu o que o usuário não vê; aqui só resta ocultar resultados
+	tests := []struct {
+		name       string
+		visibility string
+		viewer     int
+		hidden     bool
+	}{
+		{name: "Pública para outro jogador", visibility: models.RollVisibilityPublic, viewer: other},
+		{name: "Cega para o mestre", visibility: models.RollVisibilityBlind, viewer: gm},
+		{name: "Cega para quem rolou", visibility: models.RollVisibilityBlind, viewer: roller, hidden: true},
+		{name: "Secreta para quem rolou", visibility: models.RollVisibilityGM, viewer: roller},
+		{name: "Só para si", visibility: models.RollVisibilitySelf, viewer: roller},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rolls := redactRolls([]*models.RollResponse{newRoll(tt.visibility)}, tt.viewer, gm)
+			assert.Equal(t, tt.hidden, rolls[0].Hidden)
+			if tt.hidden {
+				assert.Zero(t, rolls[0].ResultValue)
+				assert.Nil(t, rolls[0].ResultDetails)
+			} else {
+				assert.Equal(t, total, rolls[0].ResultValue)
+			}
+		})
+	}
+}
+
+func TestRollAccess(t *testing.T) {
+	const gm, roller, other = 1, 2, 3
+
+	tests := []struct {
+		visibility string
+		viewer     int
+		visible    bool
+		full       bool
+	}{
+		{models.RollVisibilityPublic, other, true, true},
+		{models.RollVisibilityGM, gm, true, true},
+		{models.RollVisibilityGM, roller, true, true},
+		{models.RollVisibilityGM, other, false, false},
+		{models.RollVisibilityBlind, gm, true, true},
+		{models.RollVisibilityBlind, roller, true, false},
+		{models.RollVisibilityBlind, other, false, false},
+		{models.RollVisibilitySelf, roller, true, true},
+		{models.RollVisibilitySelf, gm, false, false},
+		{models.RollVisibilitySelf, other, false, false},
+	}
+
+	for _, tt := range tests {
+		visible, full := models.RollAccess(tt.visibility, roller, tt.viewer, gm)
+		assert.Equal(t, tt.visible, visible, "%s para %d", tt.visibility, tt.viewer)
+		assert.Equal(t, tt.full, full, "%s para %d", tt.visibility, tt.viewer)
+	}
+}
+
+func TestRedactRollBatch(t *testing.T) {
+	batch := &models.RollBatchResponse{
+		BatchID:    "lote",
+		Visibility: models.RollVisibilityBlind,
+		Rolls: []models.RollBatchResult{
+			{Index: 0, Expression: "4d6kh3", Roll: &models.RollResponse{ResultValue: 14, ResultDetails: &models.RollDetails{Total: 14}}},
+			{Index: 1, Expression: "1d0", Error: "expressão inválida"},
+		},
+		Failed: 1,
+	}
+
+	redacted := batch.Redacted()
+	assert.True(t, redacted.Hidden)
+	assert.Equal(t, 0, redacted.Rolls[0].Roll.ResultValue)
+	assert.Nil(t, redacted.Rolls[0].Roll.ResultDetails)
+	assert.Equal(t, "expressão inválida", redacted.Rolls[1].Error)
+
+	// O lote original continua completo para quem pode ver os resultados
+	assert.False(t, batch.Hidden)
+	assert.Equal(t, 14, batch.Rolls[0].Roll.ResultValue)
+}
+
+func TestBatchExpressions(t *testing.T) {
+	assert.Equal(t, []string{"6x 4d6kh3"}, batchExpressions("6x 4d6kh3", nil))
+	assert.Equal(t, []string{"1d20+5", "1d8+3"}, batchExpressions("", []string{"1d20+5", "1d8+3"}))
+}
+
+func TestCanVoidRoll(t *testing.T) {
+	const gmID, playerID, otherID = 1, 2, 3
+	now := time.Now()
+
+	recent := &models.Roll{UserID: playerID, CreatedAt: now.Add(-time.Minute)}
+	old := &models.Roll{UserID: playerID, CreatedAt: now.Add(-time.Hour)}
+	voidedAt := now
+	voided := &models.Roll{UserID: playerID, CreatedAt: now, VoidedAt: &voidedAt}
+
+	// Quem rolou anula dentro do prazo; o mestre, a qualquer momento
+	assert.NoError(t, canVoidRoll(recent, playerID, gmID, now))
+	assert.NoError(t, canVoidRoll(recent, gmID, gmID, now))
+	assert.NoError(t, canVoidRoll(old, gmID, gmID, now))
+
+	assert.EqualError(t, canVoidRoll(old, playerID, gmID, now), "o prazo para anular a própria rolagem expirou; peça ao mestre da mesa")
+	assert.EqualError(t, canVoidRoll(recent, otherID, gmID, now), "apenas o mestre da mesa ou quem rolou pode anular a rolagem")
+	assert.EqualError(t, canVoidRoll(voided, gmID, gmID, now), "rolagem já anulada")
+
+	// Sem mesa não há mestre
+	assert.EqualError(t, canVoidRoll(recent, otherID, 0, now), "apenas o mestre da mesa ou quem rolou pode anular a rolagem")
+	assert.NoError(t, canVoidRoll(recent, playerID, 0, now))
+}
@@ -0,0 +1,142 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/luizdequeiroz/rpg-backend/internal/app/models"
+	"github.com/luizdequeiroz/rpg-backend/internal/app/repositories"
+	"github.com/luizdequeiroz/rpg-backend/pkg/roll"
+)
+
+// RollContestService gerencia rolagens resistidas entre duas fichas (e.g., agarrar, furtividade contra percepção)
+type RollContestService struct {
+	contestRepo  *repositories.RollContestRepository
+	rollRepo     *repositories.RollRepository
+	sheetService *PlayerSheetService
+}
+
+// NewRollContestService cria nova instância do serviço
+func NewRollContestService(contestRepo *repositories.RollContestRepository, rollRepo *repositories.RollRepository, sheetService *PlayerSheetService) *RollContestService {
+	return &RollContestService{
+		contestRepo:  contestRepo,
+		rollRepo:     rollRepo,
+		sheetService: sheetService,
+	}
+}
+
+// Create rola as duas fichas com as definições de rolagem de cada uma, decide o vencedor e salva a disputa. Quem
+// abre a disputa rola os dois lados: as duas rolagens são dele, com a semente verificável dele e a visibilidade
+// pedida, e o histórico, a notificação e a consulta da disputa as tratam da mesma forma.
+// Retorna a disputa completa; quem a exibe oculta os resultados conforme a visibilidade (ver models.RollAccess)
+func (s *RollContestService) Create(req models.CreateRollContestRequest, userID int) (*models.RollContestResponse, error) {
+	if err := roll.ValidateTieRule(req.TieRule); err != nil {
+		return nil, err
+	}
+	tieRule := req.TieRule
+	if tieRule == "" {
+		tieRule = models.ContestTieRuleDefender
+	}
+
+	if req.Attacker.SheetID == req.Defender.SheetID {
+		return nil, errors.New("atacante e defensor devem ser fichas diferentes")
+	}
+
+	attackerSheet, err := s.sheetService.sheetForRoll(req.Attacker.SheetID, userID)
+	if err != nil {
+		return nil, err
+	}
+	defenderSheet, err := s.sheetService.sheetForRoll(req.Defender.SheetID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if attackerSheet.TableID != defenderSheet.TableID {
+		return nil, errors.New("as fichas devem estar na mesma mesa")
+	}
+
+	attacker, attackerDetails, err := s.sheetService.rollForSheet(attackerSheet, contestantRoll(req.Attacker, req), userID)
+	if err != nil {
+		return nil, fmt.Errorf("atacante: %w", err)
+	}
+	defender, defenderDetails, err := s.sheetService.rollForSheet(defenderSheet, contestantRoll(req.Defender, req), userID)
+	if err != nil {
+		return nil, fmt.Errorf("defensor: %w", err)
+	}
+
+	contest := models.NewRollContest(attackerSheet.TableID, userID, attacker, defender, tieRule)
+	contest.Winner, contest.Margin, err = roll.ResolveContest(attackerDetails, defenderDetails, tieRule)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.contestRepo.Create(contest, attacker, defender); err != nil {
+		return nil, fmt.Errorf("erro ao salvar disputa: %w", err)
+	}
+
+	return contest.ToResponse(contestRollResponse(attacker), contestRollResponse(defender)), nil
+}
+
+// GetByID busca uma disputa com as duas rolagens
+func (s *RollContestService) GetByID(contestID string, userID int) (*models.RollContestResponse, error) {
+	contest, err := s.contestRepo.GetByID(contestID)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao buscar disputa: %w", err)
+	}
+	if contest == nil {
+		return nil, errors.New("disputa não encontrada")
+	}
+
+	hasAccess, err := s.sheetService.checkTableAccess(contest.TableID, userID)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao verificar acesso: %w", err)
+	}
+	if !hasAccess {
+		return nil, errors.New("acesso negado à mesa")
+	}
+	gmID, err := s.sheetService.TableOwner(contest.TableID)
+	if err != nil {
+		return nil, err
+	}
+
+	attacker, err := s.rollRepo.GetByID(contest.AttackerRollID)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao buscar rolagem: %w", err)
+	}
+	defender, err := s.rollRepo.GetByID(contest.DefenderRollID)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao buscar rolagem: %w", err)
+	}
+	if attacker == nil || defender == nil {
+		return nil, errors.New("rolagem não encontrada")
+	}
+
+	// As duas rolagens são de quem abriu a disputa e têm a mesma visibilidade
+	visible, full := models.RollAccess(attacker.Visibility, contest.UserID, userID, gmID)
+	if !visible {
+		return nil, errors.New("disputa não encontrada")
+	}
+
+	response := contest.ToResponse(contestRollResponse(attacker), contestRollResponse(defender))
+	if !full {
+		return response.Redacted(), nil
+	}
+	return response, nil
+}
+
+// contestantRoll converte um lado da disputa para uma rolagem de ficha
+func contestantRoll(contestant models.ContestantRequest, req models.CreateRollContestRequest) models.CreateRollRequest {
+	return models.CreateRollRequest{
+		SheetID:    contestant.SheetID,
+		Expression: contestant.Expression,
+		FieldName:  contestant.FieldName,
+		ClientSeed: req.ClientSeed,
+		Visibility: req.Visibility,
+	}
+}
+
+// contestRollResponse converte uma rolagem da disputa para a resposta da API
+func contestRollResponse(rollRecord *models.Roll) *models.RollResponse {
+	response := rollRecord.ToResponse()
+	response.User = &models.UserResponse{ID: rollRecord.UserID}
+	return response
+}
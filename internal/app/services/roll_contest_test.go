@@ -0,0 +1,153 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/luizdequeiroz/rpg-backend/internal/app/models"
+	"github.com/luizdequeiroz/rpg-backend/internal/app/repositories"
+)
+
+// newTestContestService cria o serviço de disputas com a ficha do jogador 2, um goblin do mestre 1 e a ficha rival
+// do jogador 3 na mesma mesa
+func newTestContestService(t *testing.T) (*RollContestService, *repositories.RollRepository) {
+	database := newTestDatabase(t)
+	newTestSheet(t, database, `{}`)
+	database.MustExec(`INSERT INTO player_sheets (id, table_id, template_id, owner_id, name, data) VALUES ('goblin', 'table', 1, 1, 'Goblin', '{}')`)
+	database.MustExec(`INSERT INTO player_sheets (id, table_id, template_id, owner_id, name, data) VALUES ('rival', 'table', 1, 3, 'Rival', '{}')`)
+
+	rollRepo := repositories.NewRollRepository(database.DB)
+	service := NewRollContestService(repositories.NewRollContestRepository(database.DB), rollRepo, newTestSheetService(database))
+	return service, rollRepo
+}
+
+// newTestContestRequest cria uma disputa da ficha do jogador contra o goblin
+func newTestContestRequest(attacker, defender, tieRule, visibility string) models.CreateRollContestRequest {
+	return models.CreateRollContestRequest{
+		Attacker:   models.ContestantRequest{SheetID: "sheet", Expression: attacker},
+		Defender:   models.ContestantRequest{SheetID: "goblin", Expression: defender},
+		TieRule:    tieRule,
+		Visibility: visibility,
+	}
+}
+
+func TestContestRollsBelongToRequester(t *testing.T) {
+	service, rollRepo := newTestContestService(t)
+
+	contest, err := service.Create(newTestContestRequest("1d20", "1d20", "", ""), 2)
+	require.NoError(t, err)
+	assert.Equal(t, 2, contest.UserID)
+	assert.Equal(t, 2, contest.Attacker.User.ID)
+	assert.Equal(t, 2, contest.Defender.User.ID, "quem abre a disputa rola os dois lados")
+
+	attacker, err := rollRepo.GetByID(contest.Attacker.ID)
+	require.NoError(t, err)
+	defender, err := rollRepo.GetByID(contest.Defender.ID)
+	require.NoError(t, err)
+	assert.Equal(t, *attacker.SeedID, *defender.SeedID, "as duas rolagens usam a semente de quem abriu a disputa")
+}
+
+func TestContestVisibilityMatchesHistory(t *testing.T) {
+	for _, visibility := range []string{models.RollVisibilityPublic, models.RollVisibilityGM, models.RollVisibilityBlind, models.RollVisibilitySelf} {
+		t.Run(visibility, func(t *testing.T) {
+			service, rollRepo := newTestContestService(t)
+
+			// O jogador 2 disputa contra a ficha do jogador 3
+			req := newTestContestRequest("1d20", "1d20", "", visibility)
+			req.Defender.SheetID = "rival"
+			created, err := service.Create(req, 2)
+			require.NoError(t, err)
+
+			// Mestre, quem abriu a disputa, dono da ficha do defensor e outro jogador
+			for _, viewerID := range []int{1, 2, 3, 4} {
+				contest, err := service.GetByID(created.ID, viewerID)
+				contestVisible := err == nil
+				contestFull := contestVisible && !contest.Hidden
+
+				rolls, err := rollRepo.Search(models.RollFilter{}, models.RollSort{}, viewerID, viewerID == 1, 0, 10)
+				require.NoError(t, err)
+				responses := make([]*models.RollResponse, len(rolls))
+				for i := range rolls {
+					responses[i] = rolls[i].ToResponse()
+				}
+				history := redactRolls(responses, viewerID, 1)
+
+				if contestVisible {
+					assert.Len(t, history, 2, "usuário %d", viewerID)
+				} else {
+					assert.Empty(t, history, "usuário %d", viewerID)
+				}
+				for _, rollResponse := range history {
+					assert.Equal(t, contestFull, !rollResponse.Hidden, "usuário %d", viewerID)
+				}
+			}
+		})
+	}
+}
+
+func TestContestTieRules(t *testing.T) {
+	tests := []struct {
+		name     string
+		attacker string
+		defender string
+		tieRule  string
+		winner   string
+		margin   int
+	}{
+		{name: "Atacante maior", attacker: "5", defender: "2", winner: models.ContestAttacker, margin: 3},
+		{name: "Defensor maior", attacker: "2", defender: "5", winner: models.ContestDefender, margin: -3},
+		{name: "Empate vai ao defensor por padrão", attacker: "3", defender: "3", winner: models.ContestDefender},
+		{name: "Empate ao atacante", attacker: "3", defender: "3", tieRule: models.ContestTieRuleAttacker, winner: models.ContestAttacker},
+		{name: "Empate sem vencedor", attacker: "3", defender: "3", tieRule: models.ContestTieRuleNone, winner: models.ContestTie},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service, _ := newTestContestService(t)
+
+			contest, err := service.Create(newTestContestRequest(tt.attacker, tt.defender, tt.tieRule, ""), 2)
+			require.NoError(t, err)
+			assert.Equal(t, tt.winner, contest.Winner)
+			assert.Equal(t, tt.margin, contest.Margin)
+			if tt.winner == models.ContestTie {
+				assert.Nil(t, contest.WinnerSheetID)
+			}
+		})
+	}
+
+	service, _ := newTestContestService(t)
+	_, err := service.Create(newTestContestRequest("1d20", "1d20", "reroll", ""), 2)
+	assert.Error(t, err)
+}
+
+func TestContestRedactsHiddenResults(t *testing.T) {
+	service, _ := newTestContestService(t)
+
+	created, err := service.Create(newTestContestRequest("5", "2", "", models.RollVisibilityBlind), 2)
+	require.NoError(t, err)
+	assert.Equal(t, models.RollVisibilityBlind, created.Attacker.Visibility)
+	assert.Equal(t, models.RollVisibilityBlind, created.Defender.Visibility, "a visibilidade é a pedida por quem abriu a disputa")
+
+	// Quem abriu a disputa cega vê apenas que ela aconteceu
+	contest, err := service.GetByID(created.ID, 2)
+	require.NoError(t, err)
+	assert.True(t, contest.Hidden)
+	assert.Empty(t, contest.Winner)
+	assert.Nil(t, contest.WinnerSheetID)
+	assert.Zero(t, contest.Margin)
+	assert.Nil(t, contest.Attacker.Fairness)
+	assert.Nil(t, contest.Defender.Fairness)
+
+	// O mestre vê o resultado completo
+	contest, err = service.GetByID(created.ID, 1)
+	require.NoError(t, err)
+	assert.False(t, contest.Hidden)
+	assert.Equal(t, models.ContestAttacker, contest.Winner)
+	assert.Equal(t, 3, contest.Margin)
+
+	// Os demais jogadores não veem a disputa
+	_, err = service.GetByID(created.ID, 3)
+	assert.EqualError(t, err, "disputa não encontrada")
+}
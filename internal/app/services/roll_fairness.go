@@ -0,0 +1,252 @@
+package services
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/luizdequeiroz/rpg-backend/internal/app/models"
+	"github.com/luizdequeiroz/rpg-backend/internal/app/repositories"
+	"github.com/luizdequeiroz/rpg-backend/pkg/roll"
+)
+
+// maxClientSeedLength limita o tamanho da semente informada pelo jogador
+const maxClientSeedLength = 64
+
+// RollFairnessService gerencia rolagens verificáveis (commit-reveal).
+//
+// Cada usuário tem uma semente secreta ativa, da qual apenas o hash é publicado. Cada rolagem
+// usa essa semente, uma semente do cliente e um nonce crescente; a verificação de qualquer
+// rolagem revela a semente, que deixa de ser usada, e recalcula os dados a partir dela.
+// Rolagens ocultas usam outra semente ativa, que nunca é revelada: revelá-la permitiria a
+// quem conhece semente do cliente e nonce recalcular resultados que não pode ver
+type RollFairnessService struct {
+	seedRepo   *repositories.RollSeedRepository
+	rollRepo   *repositories.RollRepository
+	rollEngine *roll.RollEngine
+}
+
+// NewRollFairnessService cria nova instância do serviço
+func NewRollFairnessService(seedRepo *repositories.RollSeedRepository, rollRepo *repositories.RollRepository, rollEngine *roll.RollEngine) *RollFairnessService {
+	return &RollFairnessService{
+		seedRepo:   seedRepo,
+		rollRepo:   rollRepo,
+		rollEngine: rollEngine,
+	}
+}
+
+// GetActiveSeed retorna o compromisso da semente que será usada nas próximas rolagens públicas do usuário
+func (s *RollFairnessService) GetActiveSeed(userID int) (*models.RollSeedResponse, error) {
+	seed, err := s.activeSeed(userID, false)
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.RollSeedResponse{
+		ServerSeedHash: seed.ServerSeedHash,
+		NextNonce:      seed.NextNonce,
+		CreatedAt:      seed.CreatedAt,
+	}, nil
+}
+
+// Apply reserva semente e nonce para a rolagem do registro e retorna as opções com a fonte verificável.
+// A visibilidade do registro escolhe a semente pública ou a oculta. Sem semente do cliente, uma é gerada pelo servidor
+func (s *RollFairnessService) Apply(rollRecord *models.Roll, clientSeed string, options *roll.RollOptions) (*roll.RollOptions, error) {
+	if len(clientSeed) > maxClientSeedLength {
+		return nil, fmt.Errorf("client_seed deve ter no máximo %d caracteres", maxClientSeedLength)
+	}
+	if clientSeed == "" {
+		generated, err := roll.GenerateClientSeed()
+		if err != nil {
+			return nil, err
+		}
+		clientSeed = generated
+	}
+
+	// A semente ativa pode ser revelada entre a leitura e a reserva; nesse caso uma nova é criada
+	for attempt := 0; attempt < 2; attempt++ {
+		hidden := rollRecord.Visibility != models.RollVisibilityPublic
+		seed, err := s.activeSeed(rollRecord.UserID, hidden)
+		if err != nil {
+			return nil, err
+		}
+
+		nonce, err := s.seedRepo.ReserveNonce(seed.ID)
+		if errors.Is(err, sql.ErrNoRows) {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("erro ao reservar nonce: %w", err)
+		}
+
+		rollRecord.SetFairness(seed.ID, &models.RollFairness{
+			ServerSeedHash: seed.ServerSeedHash,
+			ClientSeed:     clientSeed,
+			Nonce:          nonce,
+		})
+		rollRecord.SetRules(rollRules(options))
+		return withSource(options, roll.NewFairSource(seed.ServerSeed, clientSeed, nonce)), nil
+	}
+
+	return nil, errors.New("erro ao reservar semente da rolagem")
+}
+
+// GetRoll busca uma rolagem pública para verificação
+func (s *RollFairnessService) GetRoll(rollID string) (*models.Roll, error) {
+	rollRecord, err := s.rollRepo.GetByID(rollID)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao buscar rolagem: %w", err)
+	}
+	if rollRecord == nil {
+		return nil, errors.New("rolagem não encontrada")
+	}
+	// A verificação revela o resultado a qualquer pessoa; rolagens ocultas não são verificáveis publicamente
+	if rollRecord.Visibility != models.RollVisibilityPublic {
+		return nil, errors.New("rolagem não pública")
+	}
+	return rollRecord, nil
+}
+
+// Verify revela a semente da rolagem e recalcula os dados com as regras de rolagem gravadas com ela.
+// Rolagens anteriores, sem regras gravadas, usam as opções informadas (as atuais da ficha)
+func (s *RollFairnessService) Verify(rollRecord *models.Roll, options *roll.RollOptions) (*models.RollVerificationResponse, error) {
+	fairness := rollRecord.Fairness()
+	if rollRecord.SeedID == nil || fairness == nil {
+		return nil, errors.New("rolagem sem dados de verificação")
+	}
+
+	seed, err := s.seedRepo.GetByID(*rollRecord.SeedID)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao buscar semente: %w", err)
+	}
+	if seed == nil {
+		return nil, errors.New("rolagem sem dados de verificação")
+	}
+
+	// Revelar encerra o uso da semente; as próximas rolagens do usuário usam uma nova.
+	// Sementes ocultas, e as anteriores que também cobrem rolagens ocultas, nunca são reveladas
+	if seed.RevealedAt == nil {
+		covers, err := s.seedRepo.CoversHiddenRolls(seed.ID)
+		if err != nil {
+			return nil, fmt.Errorf("erro ao verificar semente: %w", err)
+		}
+		if seed.Hidden || covers {
+			return nil, errors.New("a semente desta rolagem também cobre rolagens ocultas e não pode ser revelada")
+		}
+		if err := s.seedRepo.Reveal(seed.ID); err != nil {
+			return nil, fmt.Errorf("erro ao revelar semente: %w", err)
+		}
+	}
+
+	// Alterações posteriores do template ou da mesa não valem para a rolagem já feita
+	rules := rollRecord.RuleSnapshot()
+	if rules != nil {
+		options = ruleOptions(rules)
+	}
+
+	source := roll.NewFairSource(seed.ServerSeed, fairness.ClientSeed, fairness.Nonce)
+	recomputed, err := s.rollEngine.RollWithOptions(rollRecord.Expression, withSource(options, source))
+	if err != nil {
+		return nil, fmt.Errorf("erro ao recalcular rolagem: %w", err)
+	}
+
+	stored := rollRecord.Details()
+	hashMatches := roll.HashServerSeed(seed.ServerSeed) == fairness.ServerSeedHash
+	diceMatch := sameDice(stored, recomputed)
+
+	return &models.RollVerificationResponse{
+		RollID:         rollRecord.ID,
+		Expression:     rollRecord.Expression,
+		ServerSeed:     seed.ServerSeed,
+		ServerSeedHash: fairness.ServerSeedHash,
+		ClientSeed:     fairness.ClientSeed,
+		Nonce:          fairness.Nonce,
+		HashMatches:    hashMatches,
+		DiceMatch:      diceMatch,
+		Verified:       hashMatches && diceMatch,
+		Stored:         stored,
+		Recomputed:     recomputed,
+		Rules:          rules,
+	}, nil
+}
+
+// activeSeed retorna a semente ativa das rolagens públicas ou ocultas do usuário, criando uma nova se necessário
+func (s *RollFairnessService) activeSeed(userID int, hidden bool) (*models.RollSeed, error) {
+	seed, err := s.seedRepo.GetActiveByUserID(userID, hidden)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao buscar semente: %w", err)
+	}
+	if seed != nil {
+		return seed, nil
+	}
+
+	serverSeed, err := roll.GenerateServerSeed()
+	if err != nil {
+		return nil, err
+	}
+	seed = models.NewRollSeed(userID, hidden, serverSeed, roll.HashServerSeed(serverSeed))
+	if err := s.seedRepo.Create(seed); err != nil {
+		return nil, fmt.Errorf("erro ao salvar semente: %w", err)
+	}
+	return seed, nil
+}
+
+// rollRules copia das opções as regras de que a verificação precisa para recalcular a rolagem; das tabelas de
+// resultados, apenas a que o teste usa
+func rollRules(options *roll.RollOptions) *models.RollRuleSnapshot {
+	rules := &models.RollRuleSnapshot{}
+	if options == nil {
+		return rules
+	}
+	rules.CustomDice = options.CustomDice
+	rules.Critical = options.Critical
+	rules.Fumble = options.Fumble
+	rules.Check = options.Check
+
+	if check := options.Check; check != nil && check.Outcomes == nil && (check.OutcomeTable != "" || check.Difficulty != nil) {
+		name := check.OutcomeTable
+		if name == "" {
+			name = roll.DefaultOutcomeTable
+		}
+		if table, ok := options.OutcomeTables[name]; ok {
+			rules.Outcomes = map[string]models.OutcomeTable{name: table}
+		}
+	}
+	return rules
+}
+
+// ruleOptions monta as opções de rolagem com as regras gravadas na rolagem
+func ruleOptions(rules *models.RollRuleSnapshot) *roll.RollOptions {
+	options := &roll.RollOptions{Check: rules.Check}
+	applyRollRules(options, &rules.RollRules)
+	return options
+}
+
+// withSource copia as opções de rolagem trocando a fonte de aleatoriedade
+func withSource(options *roll.RollOptions, source roll.RandomSource) *roll.RollOptions {
+	result := &roll.RollOptions{}
+	if options != nil {
+		*result = *options
+	}
+	result.Source = source
+	return result
+}
+
+// sameDice compara os dados de duas rolagens (valores, rerrolagens, explosões e descartes por termo)
+func sameDice(stored, recomputed *models.RollDetails) bool {
+	if stored == nil || recomputed == nil {
+		return false
+	}
+
+	storedJSON, err := json.Marshal([]interface{}{stored.Dice, stored.Terms})
+	if err != nil {
+		return false
+	}
+	recomputedJSON, err := json.Marshal([]interface{}{recomputed.Dice, recomputed.Terms})
+	if err != nil {
+		return false
+	}
+	return bytes.Equal(storedJSON, recomputedJSON)
+}
@@ -0,0 +1,131 @@
+package services
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/luizdequeiroz/rpg-backend/internal/app/models"
+	"github.com/luizdequeiroz/rpg-backend/internal/app/repositories"
+	"github.com/luizdequeiroz/rpg-backend/pkg/db"
+	"github.com/luizdequeiroz/rpg-backend/pkg/roll"
+)
+
+// newTestDatabase cria um banco em memória, exclusivo do teste, com todas as migrações aplicadas
+func newTestDatabase(t *testing.T) *db.DB {
+	name := strings.NewReplacer("/", "_", " ", "_").Replace(t.Name())
+	database, err := db.NewDBWithDSN("file:" + name + "?mode=memory&cache=shared")
+	require.NoError(t, err)
+	t.Cleanup(func() { database.Close() })
+
+	require.NoError(t, db.NewMigrationManager(database, "../../../migrations").Up())
+	return database
+}
+
+// newTestFairRoll faz e salva uma rolagem verificável do usuário com a visibilidade informada
+func newTestFairRoll(t *testing.T, service *RollFairnessService, rollRepo *repositories.RollRepository, engine *roll.RollEngine, userID int, visibility string) *models.Roll {
+	rollRecord := models.NewRoll("sheet", "table", userID, "10d20", nil)
+	rollRecord.Visibility = visibility
+	options, err := service.Apply(rollRecord, "", nil)
+	require.NoError(t, err)
+
+	details, err := engine.RollWithOptions(rollRecord.Expression, options)
+	require.NoError(t, err)
+	rollRecord.SetDetails(details)
+	require.NoError(t, rollRepo.Create(rollRecord))
+	return rollRecord
+}
+
+func TestRedactedBlindRollCannotBeRecomputed(t *testing.T) {
+	database := newTestDatabase(t)
+	engine := roll.NewRollEngine()
+	seedRepo := repositories.NewRollSeedRepository(database.DB)
+	rollRepo := repositories.NewRollRepository(database.DB)
+	service := NewRollFairnessService(seedRepo, rollRepo, engine)
+
+	blind := newTestFairRoll(t, service, rollRepo, engine, 1, models.RollVisibilityBlind)
+	public := newTestFairRoll(t, service, rollRepo, engine, 1, models.RollVisibilityPublic)
+	assert.NotEqual(t, *blind.SeedID, *public.SeedID, "rolagens ocultas usam outra semente")
+
+	// Quem não vê o resultado não recebe semente do cliente nem nonce
+	assert.Nil(t, blind.ToResponse().Redacted().Fairness)
+	assert.Nil(t, newDiceRollResponse(blind).Redacted().Fairness)
+
+	// Verificar a rolagem pública revela apenas a semente dela, que não reproduz a rolagem oculta
+	verification, err := service.Verify(public, nil)
+	require.NoError(t, err)
+	assert.True(t, verification.Verified)
+
+	fairness := blind.Fairness()
+	recomputed, err := engine.RollWithOptions(blind.Expression,
+		withSource(nil, roll.NewFairSource(verification.ServerSeed, fairness.ClientSeed, fairness.Nonce)))
+	require.NoError(t, err)
+	assert.False(t, sameDice(blind.Details(), recomputed))
+
+	hiddenSeed, err := seedRepo.GetByID(*blind.SeedID)
+	require.NoError(t, err)
+	assert.Nil(t, hiddenSeed.RevealedAt)
+}
+
+func TestVerifyKeepsSeedCoveringHiddenRolls(t *testing.T) {
+	database := newTestDatabase(t)
+	engine := roll.NewRollEngine()
+	seedRepo := repositories.NewRollSeedRepository(database.DB)
+	rollRepo := repositories.NewRollRepository(database.DB)
+	service := NewRollFairnessService(seedRepo, rollRepo, engine)
+
+	// Sementes anteriores à separação cobrem rolagens públicas e ocultas
+	public := newTestFairRoll(t, service, rollRepo, engine, 1, models.RollVisibilityPublic)
+	blind := newTestFairRoll(t, service, rollRepo, engine, 1, models.RollVisibilityBlind)
+	_, err := database.Exec(`UPDATE rolls SET seed_id = ? WHERE id = ?`, *public.SeedID, blind.ID)
+	require.NoError(t, err)
+
+	_, err = service.Verify(public, nil)
+	assert.EqualError(t, err, "a semente desta rolagem também cobre rolagens ocultas e não pode ser revelada")
+
+	seed, err := seedRepo.GetByID(*public.SeedID)
+	require.NoError(t, err)
+	assert.Nil(t, seed.RevealedAt)
+}
+
+func TestVerifyUsesRulesStoredWithRoll(t *testing.T) {
+	database := newTestDatabase(t)
+	engine := roll.NewRollEngine()
+	rollRepo := repositories.NewRollRepository(database.DB)
+	service := NewRollFairnessService(repositories.NewRollSeedRepository(database.DB), rollRepo, engine)
+
+	faces := func(values ...int) []models.DieFace {
+		result := make([]models.DieFace, len(values))
+		for i, value := range values {
+			result[i] = models.DieFace{Value: value}
+		}
+		return result
+	}
+	rules := func(values ...int) *roll.RollOptions {
+		return &roll.RollOptions{
+			CustomDice: []models.CustomDie{{Name: "boost", Faces: faces(values...)}},
+			Critical:   &models.CritRule{Dice: "1d20", Min: intPtr(19)},
+		}
+	}
+
+	rollRecord := models.NewRoll("sheet", "table", 1, "10d[boost]+1d20", nil)
+	options, err := service.Apply(rollRecord, "", rules(0, 1, 2))
+	require.NoError(t, err)
+	details, err := engine.RollWithOptions(rollRecord.Expression, options)
+	require.NoError(t, err)
+	rollRecord.SetDetails(details)
+	require.NoError(t, rollRepo.Create(rollRecord))
+
+	stored, err := rollRepo.GetByID(rollRecord.ID)
+	require.NoError(t, err)
+
+	// O mestre muda as faces do dado depois da rolagem: a verificação usa as regras gravadas com ela
+	verification, err := service.Verify(stored, rules(5, 6, 7))
+	require.NoError(t, err)
+	assert.True(t, verification.Verified)
+	require.NotNil(t, verification.Rules)
+	assert.Equal(t, faces(0, 1, 2), verification.Rules.CustomDice[0].Faces)
+	assert.Equal(t, 19, *verification.Rules.Critical.Min)
+}
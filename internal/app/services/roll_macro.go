@@ -0,0 +1,313 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/luizdequeiroz/rpg-backend/internal/app/models"
+	"github.com/luizdequeiroz/rpg-backend/internal/app/repositories"
+	"github.com/luizdequeiroz/rpg-backend/pkg/roll"
+)
+
+// Limites das macros de rolagem
+const (
+	maxMacroNameLength       = 100
+	maxMacroSteps            = 20
+	maxMacroExpressionLength = 200
+)
+
+// RollMacroService gerencia macros de rolagem nomeadas, do usuário ou de uma ficha
+type RollMacroService struct {
+	macroRepo    *repositories.RollMacroRepository
+	sheetRepo    *repositories.PlayerSheetRepository
+	rollRepo     *repositories.RollRepository
+	sheetService *PlayerSheetService
+	rollEngine   *roll.RollEngine
+}
+
+// NewRollMacroService cria nova instância do serviço
+func NewRollMacroService(
+	macroRepo *repositories.RollMacroRepository,
+	sheetRepo *repositories.PlayerSheetRepository,
+	rollRepo *repositories.RollRepository,
+	sheetService *PlayerSheetService,
+	rollEngine *roll.RollEngine,
+) *RollMacroService {
+	return &RollMacroService{
+		macroRepo:    macroRepo,
+		sheetRepo:    sheetRepo,
+		rollRepo:     rollRepo,
+		sheetService: sheetService,
+		rollEngine:   rollEngine,
+	}
+}
+
+// Create cria uma macro; com sheet_id ela pertence à ficha e só o dono da ficha pode criá-la
+func (s *RollMacroService) Create(req models.CreateRollMacroRequest, userID int) (*models.RollMacroResponse, error) {
+	req.Name = strings.TrimSpace(req.Name)
+	if err := s.validate(req.Name, req.Steps); err != nil {
+		return nil, err
+	}
+
+	if req.SheetID != nil {
+		if err := s.checkSheetOwnership(*req.SheetID, userID); err != nil {
+			return nil, err
+		}
+	}
+
+	existing, err := s.findByName(req.SheetID, userID, req.Name)
+	if err != nil {
+		return nil, err
+	}
+	if existing != nil {
+		return nil, errors.New("já existe uma macro com esse nome")
+	}
+
+	macro := models.NewRollMacro(req, userID)
+	if err := s.macroRepo.Create(macro); err != nil {
+		return nil, fmt.Errorf("erro ao criar macro: %w", err)
+	}
+
+	return macro.ToResponse(), nil
+}
+
+// GetByID busca uma macro; macros de ficha são visíveis a quem pode rolar a ficha
+func (s *RollMacroService) GetByID(id string, userID int) (*models.RollMacroResponse, error) {
+	macro, err := s.macroRepo.GetByID(id)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao buscar macro: %w", err)
+	}
+	if macro == nil {
+		return nil, errors.New("macro não encontrada")
+	}
+
+	if macro.SheetID != nil {
+		if _, err := s.sheetService.sheetForRoll(*macro.SheetID, userID); err != nil {
+			return nil, err
+		}
+	} else if macro.UserID != userID {
+		return nil, errors.New("macro não encontrada")
+	}
+	return macro.ToResponse(), nil
+}
+
+// List lista as macros do usuário ou, com sheetID, as macros da ficha
+func (s *RollMacroService) List(sheetID string, userID int) ([]*models.RollMacroResponse, error) {
+	var macros []*models.RollMacro
+	var err error
+
+	if sheetID != "" {
+		if _, err := s.sheetService.sheetForRoll(sheetID, userID); err != nil {
+			return nil, err
+		}
+		macros, err = s.macroRepo.GetBySheetID(sheetID)
+	} else {
+		macros, err = s.macroRepo.GetByUserID(userID)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("erro ao listar macros: %w", err)
+	}
+
+	responses := make([]*models.RollMacroResponse, len(macros))
+	for i, macro := range macros {
+		responses[i] = macro.ToResponse()
+	}
+	return responses, nil
+}
+
+// Update atualiza uma macro; apenas o dono pode alterá-la
+func (s *RollMacroService) Update(id string, req models.UpdateRollMacroRequest, userID int) (*models.RollMacroResponse, error) {
+	macro, err := s.ownedMacro(id, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Name != nil {
+		name := strings.TrimSpace(*req.Name)
+		req.Name = &name
+	}
+	macro.Update(req)
+	if err := s.validate(macro.Name, macro.GetSteps()); err != nil {
+		return nil, err
+	}
+
+	if req.Name != nil {
+		existing, err := s.findByName(macro.SheetID, userID, macro.Name)
+		if err != nil {
+			return nil, err
+		}
+		if existing != nil && existing.ID != macro.ID {
+			return nil, errors.New("já existe uma macro com esse nome")
+		}
+	}
+
+	if err := s.macroRepo.Update(macro); err != nil {
+		return nil, fmt.Errorf("erro ao atualizar macro: %w", err)
+	}
+
+	return macro.ToResponse(), nil
+}
+
+// Delete remove uma macro; apenas o dono pode removê-la
+func (s *RollMacroService) Delete(id string, userID int) error {
+	if _, err := s.ownedMacro(id, userID); err != nil {
+		return err
+	}
+
+	if err := s.macroRepo.Delete(id); err != nil {
+		return fmt.Errorf("erro ao remover macro: %w", err)
+	}
+	return nil
+}
+
+// Execute rola os passos da macro informada em req.Macro na ficha, resolvendo as referências a campos.
+// A macro da ficha tem precedência sobre a macro do usuário com o mesmo nome; as rolagens
+// são salvas juntas, com o mesmo batch_id. Modificadores de condições e itens sem alvo valem apenas para o primeiro
+// passo (e.g., o ataque); os seguintes (e.g., o dano) recebem só os que têm como alvo as tags do pedido
+func (s *RollMacroService) Execute(req models.CreateRollRequest, userID int) (*models.MacroRollResponse, error) {
+	sheet, err := s.sheetService.sheetForRoll(req.SheetID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	macro, err := s.findByName(&sheet.ID, userID, req.Macro)
+	if err != nil {
+		return nil, err
+	}
+	if macro == nil {
+		macro, err = s.findByName(nil, userID, req.Macro)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if macro == nil {
+		return nil, errors.New("macro não encontrada")
+	}
+
+	// Rótulo e tags do pedido valem para todos os passos; o rótulo de cada passo tem precedência
+	label, tags, err := NormalizeRollLabel(req.RollLabel, "")
+	if err != nil {
+		return nil, err
+	}
+
+	steps := macro.GetSteps()
+	batchID := uuid.New().String()
+	rolls := make([]*models.Roll, 0, len(steps))
+	details := make([]*models.RollDetails, 0, len(steps))
+
+	for i, step := range steps {
+		rollRecord, rollDetails, err := s.sheetService.rollSheetStep(sheet, models.CreateRollRequest{
+			SheetID:    sheet.ID,
+			Expression: step.Expression,
+			ClientSeed: req.ClientSeed,
+			Visibility: req.Visibility,
+			RollLabel:  models.RollLabel{Label: stepLabel(step, label), Tags: tags},
+		}, userID, i == 0)
+		if err != nil {
+			return nil, fmt.Errorf("passo %d: %w", i+1, err)
+		}
+
+		rollRecord.BatchID = &batchID
+		rollRecord.MacroID = &macro.ID
+		rolls = append(rolls, rollRecord)
+		details = append(details, rollDetails)
+	}
+
+	if err := s.rollRepo.CreateBatch(rolls); err != nil {
+		return nil, fmt.Errorf("erro ao salvar rolagens: %w", err)
+	}
+
+	response := &models.MacroRollResponse{
+		BatchID:   batchID,
+		MacroID:   macro.ID,
+		MacroName: macro.Name,
+		SheetID:   sheet.ID,
+		Steps:     make([]models.MacroStepResult, len(rolls)),
+	}
+	for i, rollRecord := range rolls {
+		rollResponse := rollRecord.ToResponse()
+		rollResponse.ResultDetails = details[i]
+		rollResponse.User = &models.UserResponse{ID: userID}
+
+		// Em rolagens "blind" quem rolou não vê o resultado (ver CreateRoll)
+		if rollRecord.Visibility == models.RollVisibilityBlind {
+			rollResponse = rollResponse.Redacted()
+		}
+		response.Steps[i] = models.MacroStepResult{Label: steps[i].Label, Roll: rollResponse}
+	}
+
+	return response, nil
+}
+
+// stepLabel retorna o rótulo da rolagem de um passo: o do passo ou, sem ele, o do pedido
+func stepLabel(step models.MacroStep, label string) string {
+	if step.Label != "" {
+		return step.Label
+	}
+	return label
+}
+
+// validate verifica nome e passos da macro
+func (s *RollMacroService) validate(name string, steps []models.MacroStep) error {
+	if name == "" || len(name) > maxMacroNameLength {
+		return fmt.Errorf("nome da macro deve ter entre 1 e %d caracteres", maxMacroNameLength)
+	}
+	if len(steps) == 0 || len(steps) > maxMacroSteps {
+		return fmt.Errorf("a macro deve ter entre 1 e %d passos", maxMacroSteps)
+	}
+
+	for i, step := range steps {
+		if step.Expression == "" || len(step.Expression) > maxMacroExpressionLength {
+			return fmt.Errorf("passo %d: expressão deve ter entre 1 e %d caracteres", i+1, maxMacroExpressionLength)
+		}
+		if err := s.rollEngine.ValidatePlaceholderExpression(step.Expression); err != nil {
+			return fmt.Errorf("passo %d: %w", i+1, err)
+		}
+	}
+	return nil
+}
+
+// checkSheetOwnership verifica se o usuário é dono da ficha à qual a macro será ligada
+func (s *RollMacroService) checkSheetOwnership(sheetID string, userID int) error {
+	isOwner, err := s.sheetRepo.CheckOwnership(sheetID, userID)
+	if err != nil {
+		return fmt.Errorf("erro ao verificar propriedade: %w", err)
+	}
+	if !isOwner {
+		return errors.New("apenas o proprietário da ficha pode criar macros nela")
+	}
+	return nil
+}
+
+// ownedMacro busca uma macro e verifica se o usuário é o dono
+func (s *RollMacroService) ownedMacro(id string, userID int) (*models.RollMacro, error) {
+	macro, err := s.macroRepo.GetByID(id)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao buscar macro: %w", err)
+	}
+	if macro == nil {
+		return nil, errors.New("macro não encontrada")
+	}
+	if macro.UserID != userID {
+		return nil, errors.New("apenas o dono pode alterar a macro")
+	}
+	return macro, nil
+}
+
+// findByName busca uma macro da ficha ou, sem ficha, do usuário pelo nome
+func (s *RollMacroService) findByName(sheetID *string, userID int, name string) (*models.RollMacro, error) {
+	var macro *models.RollMacro
+	var err error
+
+	if sheetID != nil {
+		macro, err = s.macroRepo.GetBySheetAndName(*sheetID, name)
+	} else {
+		macro, err = s.macroRepo.GetByUserAndName(userID, name)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("erro ao buscar macro: %w", err)
+	}
+	return macro, nil
+}
@@ -0,0 +1,181 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/luizdequeiroz/rpg-backend/internal/app/models"
+	"github.com/luizdequeiroz/rpg-backend/internal/app/repositories"
+	"github.com/luizdequeiroz/rpg-backend/pkg/roll"
+)
+
+// newTestMacroService cria o serviço de macros com a ficha do jogador 2 e uma segunda ficha dele na mesma mesa
+func newTestMacroService(t *testing.T) (*RollMacroService, *repositories.RollMacroRepository, *repositories.RollRepository) {
+	database := newTestDatabase(t)
+	newTestSheet(t, database, `{}`)
+	database.MustExec(`UPDATE player_sheets SET data = '{"str_mod": 3}' WHERE id = 'sheet'`)
+	database.MustExec(`INSERT INTO player_sheets (id, table_id, template_id, owner_id, name, data) VALUES ('other', 'table', 1, 2, 'Bran', '{}')`)
+
+	macroRepo := repositories.NewRollMacroRepository(database.DB)
+	rollRepo := repositories.NewRollRepository(database.DB)
+	service := NewRollMacroService(macroRepo, repositories.NewPlayerSheetRepository(database.DB), rollRepo,
+		newTestSheetService(database), roll.NewRollEngine())
+	return service, macroRepo, rollRepo
+}
+
+// newTestMacro cria uma macro do jogador 2, da ficha informada ou, com sheetID vazio, do usuário
+func newTestMacro(t *testing.T, service *RollMacroService, sheetID, name string, expressions ...string) *models.RollMacroResponse {
+	req := models.CreateRollMacroRequest{Name: name}
+	if sheetID != "" {
+		req.SheetID = &sheetID
+	}
+	for _, expression := range expressions {
+		req.Steps = append(req.Steps, models.MacroStep{Expression: expression})
+	}
+
+	macro, err := service.Create(req, 2)
+	require.NoError(t, err)
+	return macro
+}
+
+func TestMacroExecutePrefersSheetMacro(t *testing.T) {
+	service, _, _ := newTestMacroService(t)
+
+	newTestMacro(t, service, "", "Ataque", "1")
+	sheetMacro := newTestMacro(t, service, "sheet", "Ataque", "2")
+	userMacro := newTestMacro(t, service, "", "Dano", "3")
+
+	// A macro da ficha tem precedência sobre a do usuário com o mesmo nome
+	result, err := service.Execute(models.CreateRollRequest{SheetID: "sheet", Macro: "Ataque"}, 2)
+	require.NoError(t, err)
+	assert.Equal(t, sheetMacro.ID, result.MacroID)
+	assert.Equal(t, 2, result.Steps[0].Roll.ResultValue)
+
+	// Sem macro da ficha com o nome, vale a do usuário
+	result, err = service.Execute(models.CreateRollRequest{SheetID: "sheet", Macro: "Dano"}, 2)
+	require.NoError(t, err)
+	assert.Equal(t, userMacro.ID, result.MacroID)
+
+	// Macros de outra ficha não valem
+	result, err = service.Execute(models.CreateRollRequest{SheetID: "other", Macro: "Ataque"}, 2)
+	require.NoError(t, err)
+	assert.NotEqual(t, sheetMacro.ID, result.MacroID)
+	assert.Equal(t, 1, result.Steps[0].Roll.ResultValue)
+
+	_, err = service.Execute(models.CreateRollRequest{SheetID: "sheet", Macro: "Cura"}, 2)
+	assert.EqualError(t, err, "macro não encontrada")
+}
+
+func TestMacroExecuteSavesStepsTogether(t *testing.T) {
+	service, _, rollRepo := newTestMacroService(t)
+
+	macro, err := service.Create(models.CreateRollMacroRequest{
+		Name: "Ataque com espada",
+		Steps: []models.MacroStep{
+			{Label: "Ataque", Expression: "1d20+{str_mod}"},
+			{Label: "Dano", Expression: "1d8+{str_mod}"},
+			{Expression: "1d6"},
+		},
+	}, 2)
+	require.NoError(t, err)
+
+	result, err := service.Execute(models.CreateRollRequest{SheetID: "sheet", Macro: macro.Name, RollLabel: models.RollLabel{Label: "Golpe"}}, 2)
+	require.NoError(t, err)
+	require.Len(t, result.Steps, 3)
+	assert.NotEmpty(t, result.BatchID)
+
+	rolls, err := rollRepo.GetBySheetID("sheet")
+	require.NoError(t, err)
+	require.Len(t, rolls, 3)
+	labels := make(map[string]bool)
+	for _, rollRecord := range rolls {
+		require.NotNil(t, rollRecord.BatchID)
+		require.NotNil(t, rollRecord.MacroID)
+		assert.Equal(t, result.BatchID, *rollRecord.BatchID)
+		assert.Equal(t, macro.ID, *rollRecord.MacroID)
+		require.NotNil(t, rollRecord.Label)
+		labels[*rollRecord.Label] = true
+	}
+	// O rótulo do passo tem precedência sobre o do pedido
+	assert.Equal(t, map[string]bool{"Ataque": true, "Dano": true, "Golpe": true}, labels)
+
+	// Um passo inválido não salva nenhuma rolagem
+	broken := newTestMacro(t, service, "", "Quebrada", "1d20", "{missing}")
+	_, err = service.Execute(models.CreateRollRequest{SheetID: "sheet", Macro: broken.Name}, 2)
+	assert.ErrorContains(t, err, "passo 2")
+	rolls, err = rollRepo.GetBySheetID("sheet")
+	require.NoError(t, err)
+	assert.Len(t, rolls, 3)
+}
+
+func TestMacroOwnership(t *testing.T) {
+	service, _, _ := newTestMacroService(t)
+
+	// Apenas o dono da ficha cria macros nela, nem o mestre
+	sheetID := "sheet"
+	for _, userID := range []int{1, 3} {
+		_, err := service.Create(models.CreateRollMacroRequest{Name: "Ataque", SheetID: &sheetID, Steps: []models.MacroStep{{Expression: "1d20"}}}, userID)
+		assert.EqualError(t, err, "apenas o proprietário da ficha pode criar macros nela")
+	}
+
+	userMacro := newTestMacro(t, service, "", "Ataque", "1d20")
+	sheetMacro := newTestMacro(t, service, "sheet", "Ataque", "1d20")
+
+	// Apenas o dono altera ou remove a macro
+	name := "Roubada"
+	for _, macro := range []*models.RollMacroResponse{userMacro, sheetMacro} {
+		_, err := service.Update(macro.ID, models.UpdateRollMacroRequest{Name: &name}, 3)
+		assert.EqualError(t, err, "apenas o dono pode alterar a macro")
+		assert.EqualError(t, service.Delete(macro.ID, 1), "apenas o dono pode alterar a macro")
+	}
+
+	// Macros do usuário são privadas; as da ficha são vistas por quem pode rolar a ficha
+	_, err := service.GetByID(userMacro.ID, 3)
+	assert.EqualError(t, err, "macro não encontrada")
+	found, err := service.GetByID(sheetMacro.ID, 1)
+	require.NoError(t, err)
+	assert.Equal(t, sheetMacro.ID, found.ID)
+
+	updated, err := service.Update(userMacro.ID, models.UpdateRollMacroRequest{Name: &name}, 2)
+	require.NoError(t, err)
+	assert.Equal(t, name, updated.Name)
+	require.NoError(t, service.Delete(userMacro.ID, 2))
+	_, err = service.GetByID(userMacro.ID, 2)
+	assert.EqualError(t, err, "macro não encontrada")
+}
+
+func TestMacroUniqueNames(t *testing.T) {
+	service, macroRepo, _ := newTestMacroService(t)
+
+	newTestMacro(t, service, "", "Ataque", "1d20")
+	newTestMacro(t, service, "sheet", "Ataque", "1d20")
+
+	// O mesmo nome vale uma vez entre as macros do usuário e uma vez por ficha
+	newTestMacro(t, service, "other", "Ataque", "1d20")
+	for _, sheetID := range []string{"", "sheet"} {
+		req := models.CreateRollMacroRequest{Name: " Ataque ", Steps: []models.MacroStep{{Expression: "1d20"}}}
+		if sheetID != "" {
+			req.SheetID = &sheetID
+		}
+		_, err := service.Create(req, 2)
+		assert.EqualError(t, err, "já existe uma macro com esse nome")
+	}
+
+	// Outro usuário pode usar o mesmo nome
+	_, err := service.Create(models.CreateRollMacroRequest{Name: "Ataque", Steps: []models.MacroStep{{Expression: "1d20"}}}, 3)
+	assert.NoError(t, err)
+
+	// Renomear para um nome em uso também é rejeitado
+	dano := newTestMacro(t, service, "", "Dano", "1d8")
+	name := "Ataque"
+	_, err = service.Update(dano.ID, models.UpdateRollMacroRequest{Name: &name}, 2)
+	assert.EqualError(t, err, "já existe uma macro com esse nome")
+
+	// Os índices únicos do banco garantem os nomes mesmo sem a verificação do serviço
+	assert.Error(t, macroRepo.Create(models.NewRollMacro(models.CreateRollMacroRequest{Name: "Ataque", Steps: []models.MacroStep{{Expression: "1d20"}}}, 2)))
+	sheetID := "sheet"
+	assert.Error(t, macroRepo.Create(models.NewRollMacro(models.CreateRollMacroRequest{Name: "Ataque", SheetID: &sheetID, Steps: []models.MacroStep{{Expression: "1d20"}}}, 3)))
+	assert.NoError(t, macroRepo.Create(models.NewRollMacro(models.CreateRollMacroRequest{Name: "Cura", SheetID: &sheetID, Steps: []models.MacroStep{{Expression: "1d20"}}}, 2)))
+}
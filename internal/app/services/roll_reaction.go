@@ -0,0 +1,161 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/luizdequeiroz/rpg-backend/internal/app/models"
+	"github.com/luizdequeiroz/rpg-backend/internal/app/repositories"
+)
+
+// Limites das reações às rolagens
+const (
+	maxReactionsPerUser = 10 // Emojis distintos de um usuário em uma rolagem
+	maxReactionRunes    = 10 // Sequências com modificadores (e.g., tom de pele, ZWJ) usam vários caracteres
+)
+
+// RollReactionService gerencia as reações (emoji) dos jogadores às rolagens
+type RollReactionService struct {
+	reactionRepo *repositories.RollReactionRepository
+	rollRepo     *repositories.RollRepository
+	sheetService *PlayerSheetService
+}
+
+// NewRollReactionService cria nova instância do serviço
+func NewRollReactionService(reactionRepo *repositories.RollReactionRepository, rollRepo *repositories.RollRepository, sheetService *PlayerSheetService) *RollReactionService {
+	return &RollReactionService{
+		reactionRepo: reactionRepo,
+		rollRepo:     rollRepo,
+		sheetService: sheetService,
+	}
+}
+
+// Add registra a reação do usuário a uma rolagem que ele pode ver; reagir de novo com o mesmo emoji não tem efeito.
+// Retorna as reações atualizadas e a rolagem, cuja visibilidade define quem é notificado
+func (s *RollReactionService) Add(rollID string, userID int, emoji string) (*models.RollReactionsResponse, *models.Roll, error) {
+	emoji, err := NormalizeReactionEmoji(emoji)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	rollRecord, err := s.visibleRoll(rollID, userID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	added, err := s.reactionRepo.Add(rollRecord.ID, userID, emoji)
+	if err != nil {
+		return nil, nil, fmt.Errorf("erro ao salvar reação: %w", err)
+	}
+	if added {
+		count, err := s.reactionRepo.CountByUser(rollRecord.ID, userID)
+		if err != nil {
+			return nil, nil, fmt.Errorf("erro ao contar reações: %w", err)
+		}
+		if count > maxReactionsPerUser {
+			if _, err := s.reactionRepo.Remove(rollRecord.ID, userID, emoji); err != nil {
+				return nil, nil, fmt.Errorf("erro ao remover reação: %w", err)
+			}
+			return nil, nil, fmt.Errorf("limite de %d reações por rolagem atingido", maxReactionsPerUser)
+		}
+	}
+
+	return s.response(rollRecord, userID, emoji, true)
+}
+
+// Remove remove a reação do usuário a uma rolagem que ele pode ver; remover uma reação inexistente não tem efeito
+func (s *RollReactionService) Remove(rollID string, userID int, emoji string) (*models.RollReactionsResponse, *models.Roll, error) {
+	emoji, err := NormalizeReactionEmoji(emoji)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	rollRecord, err := s.visibleRoll(rollID, userID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if _, err := s.reactionRepo.Remove(rollRecord.ID, userID, emoji); err != nil {
+		return nil, nil, fmt.Errorf("erro ao remover reação: %w", err)
+	}
+
+	return s.response(rollRecord, userID, emoji, false)
+}
+
+// visibleRoll busca a rolagem e verifica se o usuário a vê: rolagens sem mesa apenas quem rolou;
+// as da mesa, os membros conforme a visibilidade. Rolagens ocultas ao usuário são tratadas como inexistentes
+func (s *RollReactionService) visibleRoll(rollID string, userID int) (*models.Roll, error) {
+	rollRecord, err := s.rollRepo.GetByID(rollID)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao buscar rolagem: %w", err)
+	}
+	if rollRecord == nil {
+		return nil, errors.New("rolagem não encontrada")
+	}
+
+	if rollRecord.TableID == nil {
+		if rollRecord.UserID != userID {
+			return nil, errors.New("rolagem não encontrada")
+		}
+		return rollRecord, nil
+	}
+
+	hasAccess, err := s.sheetService.checkTableAccess(*rollRecord.TableID, userID)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao verificar acesso: %w", err)
+	}
+	if !hasAccess {
+		return nil, errors.New("acesso negado à mesa")
+	}
+
+	gmID, err := s.sheetService.TableOwner(*rollRecord.TableID)
+	if err != nil {
+		return nil, err
+	}
+	if visible, _ := models.RollAccess(rollRecord.Visibility, rollRecord.UserID, userID, gmID); !visible {
+		return nil, errors.New("rolagem não encontrada")
+	}
+	return rollRecord, nil
+}
+
+// response monta as reações atualizadas da rolagem
+func (s *RollReactionService) response(rollRecord *models.Roll, userID int, emoji string, added bool) (*models.RollReactionsResponse, *models.Roll, error) {
+	records, err := s.reactionRepo.GetByRollID(rollRecord.ID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("erro ao buscar reações: %w", err)
+	}
+
+	return &models.RollReactionsResponse{
+		RollID:    rollRecord.ID,
+		TableID:   rollRecord.TableID,
+		UserID:    userID,
+		Emoji:     emoji,
+		Added:     added,
+		Reactions: models.SummarizeReactions(records),
+	}, rollRecord, nil
+}
+
+// NormalizeReactionEmoji valida uma reação: um emoji, possivelmente com modificadores, sem letras, números ou espaços
+func NormalizeReactionEmoji(emoji string) (string, error) {
+	emoji = strings.TrimSpace(emoji)
+	if emoji == "" || utf8.RuneCountInString(emoji) > maxReactionRunes {
+		return "", errors.New("reação inválida: use um emoji")
+	}
+
+	hasSymbol := false
+	for _, r := range emoji {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) || unicode.IsSpace(r) || unicode.IsControl(r) {
+			return "", errors.New("reação inválida: use um emoji")
+		}
+		if unicode.IsSymbol(r) {
+			hasSymbol = true
+		}
+	}
+	if !hasSymbol {
+		return "", errors.New("reação inválida: use um emoji")
+	}
+	return emoji, nil
+}
@@ -0,0 +1,36 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/luizdequeiroz/rpg-backend/internal/app/models"
+)
+
+func TestNormalizeReactionEmoji(t *testing.T) {
+	for _, emoji := range []string{"🔥", " 🎲 ", "👍🏽", "❤️", "🇧🇷", "👨‍👩‍👧"} {
+		normalized, err := NormalizeReactionEmoji(emoji)
+		assert.NoError(t, err, emoji)
+		assert.NotEmpty(t, normalized)
+	}
+
+	for _, emoji := range []string{"", "ok", "1", "🔥 🔥", "!", "🔥🔥🔥🔥🔥🔥🔥🔥🔥🔥🔥"} {
+		_, err := NormalizeReactionEmoji(emoji)
+		assert.Error(t, err, emoji)
+	}
+}
+
+func TestSummarizeReactions(t *testing.T) {
+	reactions := models.SummarizeReactions([]models.RollReactionRecord{
+		{UserID: 2, Emoji: "🔥"},
+		{UserID: 3, Emoji: "😱"},
+		{UserID: 3, Emoji: "🔥"},
+	})
+	assert.Equal(t, []models.RollReaction{
+		{Emoji: "🔥", Count: 2, UserIDs: []int{2, 3}},
+		{Emoji: "😱", Count: 1, UserIDs: []int{3}},
+	}, reactions)
+
+	assert.Empty(t, models.SummarizeReactions(nil))
+}
@@ -0,0 +1,317 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"sort"
+	"time"
+
+	"github.com/luizdequeiroz/rpg-backend/internal/app/models"
+	"github.com/luizdequeiroz/rpg-backend/internal/app/repositories"
+	"github.com/luizdequeiroz/rpg-backend/pkg/roll"
+)
+
+// Parâmetros dos relatórios de estatísticas
+const (
+	maxStatsExpressions  = 20        // Expressões distintas analisadas por relatório; as demais ficam fora da sorte
+	statsExactWork       = 1_000_000 // Operações de convolução por expressão; as que passam disso ficam fora da sorte
+	maxStatsFields       = 10        // Campos listados entre os mais rolados
+	minFaceExpectation   = 5         // Contagem esperada mínima por face para aplicar o qui-quadrado
+	suspiciousDiePValue  = 0.001     // p-valor abaixo do qual um tamanho de dado é marcado como suspeito
+	expectedValueEpsilon = 1e-9
+)
+
+// RollStatsService calcula estatísticas e relatórios de sorte das rolagens
+type RollStatsService struct {
+	rollRepo     *repositories.RollRepository
+	sheetService *PlayerSheetService
+	rollEngine   *roll.RollEngine
+}
+
+// NewRollStatsService cria nova instância do serviço
+func NewRollStatsService(rollRepo *repositories.RollRepository, sheetService *PlayerSheetService, rollEngine *roll.RollEngine) *RollStatsService {
+	return &RollStatsService{
+		rollRepo:     rollRepo,
+		sheetService: sheetService,
+		rollEngine:   rollEngine,
+	}
+}
+
+// UserStats calcula as estatísticas das rolagens do próprio usuário em todas as mesas.
+// Rolagens "blind" ficam de fora: quem rolou não vê o resultado
+func (s *RollStatsService) UserStats(userID int, from, to *time.Time) (*models.RollStatsResponse, error) {
+	filter := models.RollFilter{UserID: &userID, From: from, To: to}
+	return s.stats(models.RollStatsScopeUser, fmt.Sprint(userID), filter, userID, false)
+}
+
+// SheetStats calcula as estatísticas das rolagens da ficha que o usuário pode ver com resultado
+func (s *RollStatsService) SheetStats(sheetID string, userID int, from, to *time.Time) (*models.RollStatsResponse, error) {
+	sheet, err := s.sheetService.sheetForRoll(sheetID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	gmID, err := s.sheetService.TableOwner(sheet.TableID)
+	if err != nil {
+		return nil, err
+	}
+
+	filter := models.RollFilter{SheetID: &sheet.ID, From: from, To: to}
+	return s.stats(models.RollStatsScopeSheet, sheet.ID, filter, userID, userID == gmID)
+}
+
+// TableStats calcula as estatísticas das rolagens da mesa que o usuário pode ver com resultado;
+// com playerID, apenas as rolagens desse jogador
+func (s *RollStatsService) TableStats(tableID string, userID int, playerID *int, from, to *time.Time) (*models.RollStatsResponse, error) {
+	hasAccess, err := s.sheetService.checkTableAccess(tableID, userID)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao verificar acesso: %w", err)
+	}
+	if !hasAccess {
+		return nil, errors.New("acesso negado à mesa")
+	}
+
+	gmID, err := s.sheetService.TableOwner(tableID)
+	if err != nil {
+		return nil, err
+	}
+
+	filter := models.RollFilter{TableID: &tableID, UserID: playerID, From: from, To: to}
+	return s.stats(models.RollStatsScopeTable, tableID, filter, userID, userID == gmID)
+}
+
+// stats busca as rolagens do filtro e monta o relatório
+func (s *RollStatsService) stats(scope, scopeID string, filter models.RollFilter, viewerID int, viewerIsGM bool) (*models.RollStatsResponse, error) {
+	if filter.From != nil && filter.To != nil && !filter.From.Before(*filter.To) {
+		return nil, errors.New("data inicial deve ser anterior à data final")
+	}
+
+	rolls, err := s.rollRepo.GetForStats(filter, viewerID, viewerIsGM)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao buscar rolagens: %w", err)
+	}
+
+	response := summarizeRolls(rolls, s.expectation())
+	response.Scope = scope
+	response.ScopeID = scopeID
+	response.UserID = filter.UserID
+	response.From = filter.From
+	response.To = filter.To
+	return response, nil
+}
+
+// expectation retorna uma função que calcula, com cache, a distribuição exata de cada expressão.
+// Expressões que não podem ser analisadas sem as definições da mesa (e.g., dados personalizados) ou cuja
+// distribuição exata passa de statsExactWork ficam de fora; o Monte Carlo custaria caro demais por relatório
+func (s *RollStatsService) expectation() func(expression string) *roll.Distribution {
+	cache := make(map[string]*roll.Distribution)
+	return func(expression string) *roll.Distribution {
+		if distribution, ok := cache[expression]; ok {
+			return distribution
+		}
+		if len(cache) >= maxStatsExpressions {
+			return nil
+		}
+
+		distribution, err := s.rollEngine.AnalyzeExact(expression, nil, statsExactWork)
+		if err != nil {
+			distribution = nil
+		}
+		cache[expression] = distribution
+		return distribution
+	}
+}
+
+// summarizeRolls agrega rolagens em ordem cronológica: médias, sorte, críticos, sequências,
+// campos mais rolados e aderência de cada tamanho de dado
+func summarizeRolls(rolls []models.Roll, expectation func(expression string) *roll.Distribution) *models.RollStatsResponse {
+	response := &models.RollStatsResponse{
+		Count:     len(rolls),
+		TopFields: []models.RollFieldStats{},
+		Dice:      []models.DieStats{},
+	}
+	if len(rolls) == 0 {
+		return response
+	}
+
+	var total, luckTotal, expectedTotal, scoreTotal float64
+	luck := &models.RollLuckStats{}
+	var streaks streakCounter
+	fields := make(map[string]*models.RollFieldStats)
+	faces := make(map[int][]int)
+
+	for _, rollRecord := range rolls {
+		value := float64(rollRecord.ResultValue)
+		total += value
+
+		details := rollRecord.Details()
+		critical := details != nil && details.Critical
+		fumble := details != nil && details.Fumble
+		if critical {
+			response.Criticals++
+		}
+		if fumble {
+			response.Fumbles++
+		}
+		trackStreak(&streaks.criticals, &response.Streaks.Criticals, critical)
+		trackStreak(&streaks.fumbles, &response.Streaks.Fumbles, fumble)
+
+		if distribution := expectation(rollRecord.Expression); distribution != nil {
+			luck.Analyzed++
+			luckTotal += value
+			expectedTotal += distribution.Mean
+			if distribution.StdDev > 0 {
+				scoreTotal += (value - distribution.Mean) / distribution.StdDev
+			}
+
+			above := value > distribution.Mean+expectedValueEpsilon
+			below := value < distribution.Mean-expectedValueEpsilon
+			if above {
+				luck.AboveExpected++
+			}
+			if below {
+				luck.BelowExpected++
+			}
+			trackStreak(&streaks.above, &response.Streaks.AboveExpected, above)
+			trackStreak(&streaks.below, &response.Streaks.BelowExpected, below)
+		}
+
+		if rollRecord.FieldName != nil && *rollRecord.FieldName != "" {
+			field, ok := fields[*rollRecord.FieldName]
+			if !ok {
+				field = &models.RollFieldStats{Field: *rollRecord.FieldName}
+				fields[field.Field] = field
+			}
+			field.Count++
+			field.Average += value // Soma; dividida ao final
+		}
+
+		if details != nil {
+			countFaces(details, faces)
+		}
+	}
+
+	count := float64(len(rolls))
+	response.Average = total / count
+	response.CriticalRate = float64(response.Criticals) / count
+	response.FumbleRate = float64(response.Fumbles) / count
+
+	if luck.Analyzed > 0 {
+		analyzed := float64(luck.Analyzed)
+		luck.Average = luckTotal / analyzed
+		luck.Expected = expectedTotal / analyzed
+		luck.Delta = luck.Average - luck.Expected
+		luck.Score = scoreTotal / analyzed
+		response.Luck = luck
+	}
+
+	response.TopFields = topFields(fields)
+	response.Dice = dieStats(faces)
+	return response
+}
+
+// streakCounter acompanha as sequências em andamento de cada tipo
+type streakCounter struct {
+	criticals, fumbles, above, below int
+}
+
+// trackStreak estende ou encerra uma sequência, atualizando a maior já vista
+func trackStreak(current, longest *int, hit bool) {
+	if !hit {
+		*current = 0
+		return
+	}
+	*current++
+	*longest = max(*longest, *current)
+}
+
+// countFaces soma as faces sorteadas nos termos de dados comuns e percentuais, por tamanho de dado.
+// Entram todas as rolagens feitas (rerrolagens e partes de dados compostos); dados percentuais com
+// dezenas de bônus ou penalidade ficam de fora, pois a dezena mantida não é uniforme
+func countFaces(details *models.RollDetails, faces map[int][]int) {
+	for _, term := range details.Terms {
+		if !term.IsDice() || term.Sides == 0 {
+			continue
+		}
+
+		counts := faces[term.Sides]
+		if counts == nil {
+			counts = make([]int, term.Sides)
+			faces[term.Sides] = counts
+		}
+
+		for _, die := range term.Dice {
+			if len(die.Tens) > 1 {
+				continue
+			}
+			values := slices.Clone(die.Rerolls)
+			if len(die.Rolls) > 0 {
+				values = append(values, die.Rolls...)
+			} else {
+				values = append(values, die.Value)
+			}
+			for _, value := range values {
+				if value >= 1 && value <= term.Sides {
+					counts[value-1]++
+				}
+			}
+		}
+	}
+}
+
+// topFields ordena os campos por número de rolagens e calcula a média de cada um
+func topFields(fields map[string]*models.RollFieldStats) []models.RollFieldStats {
+	result := make([]models.RollFieldStats, 0, len(fields))
+	for _, field := range fields {
+		field.Average /= float64(field.Count)
+		result = append(result, *field)
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Count != result[j].Count {
+			return result[i].Count > result[j].Count
+		}
+		return result[i].Field < result[j].Field
+	})
+	if len(result) > maxStatsFields {
+		result = result[:maxStatsFields]
+	}
+	return result
+}
+
+// dieStats calcula média e qui-quadrado de cada tamanho de dado, do menor para o maior.
+// O teste só é aplicado quando cada face tem contagem esperada de ao menos minFaceExpectation
+func dieStats(faces map[int][]int) []models.DieStats {
+	result := make([]models.DieStats, 0, len(faces))
+	for sides, counts := range faces {
+		stats := models.DieStats{
+			Sides:    sides,
+			Faces:    counts,
+			Expected: float64(sides+1) / 2,
+		}
+
+		sum := 0
+		for i, count := range counts {
+			stats.Count += count
+			sum += count * (i + 1)
+		}
+		if stats.Count == 0 {
+			continue
+		}
+		stats.Average = float64(sum) / float64(stats.Count)
+
+		if stats.Count >= minFaceExpectation*sides {
+			statistic, pValue := roll.ChiSquare(counts)
+			stats.ChiSquare = &statistic
+			stats.PValue = &pValue
+			stats.Suspicious = pValue < suspiciousDiePValue
+		}
+		result = append(result, stats)
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Sides < result[j].Sides
+	})
+	return result
+}
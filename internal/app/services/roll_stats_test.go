@@ -0,0 +1,140 @@
+package services
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/luizdequeiroz/rpg-backend/internal/app/models"
+	"github.com/luizdequeiroz/rpg-backend/pkg/roll"
+)
+
+// statsRoll monta uma rolagem de 1d20 com o valor, campo e marcação de crítico/fumble informados
+func statsRoll(value int, field string, critical, fumble bool) models.Roll {
+	details, _ := json.Marshal(models.RollDetails{
+		Dice:     []int{value},
+		Total:    value,
+		Critical: critical,
+		Fumble:   fumble,
+		Terms: []models.RollTerm{{
+			Type:       models.RollTermDice,
+			Expression: "1d20",
+			Sides:      20,
+			Dice:       []models.DieResult{{Value: value}},
+			Value:      value,
+		}},
+	})
+
+	rollRecord := models.Roll{Expression: "1d20", ResultValue: value, ResultDetails: string(details)}
+	if field != "" {
+		rollRecord.FieldName = &field
+	}
+	return rollRecord
+}
+
+func TestSummarizeRolls(t *testing.T) {
+	engine := roll.NewRollEngine()
+	expectation := (&RollStatsService{rollEngine: engine}).expectation()
+
+	rolls := []models.Roll{
+		statsRoll(20, "skills.athletics", true, false),
+		statsRoll(20, "skills.athletics", true, false),
+		statsRoll(15, "skills.stealth", false, false),
+		statsRoll(1, "skills.athletics", false, true),
+		statsRoll(4, "", false, false),
+		statsRoll(3, "", false, false),
+	}
+
+	stats := summarizeRolls(rolls, expectation)
+	assert.Equal(t, 6, stats.Count)
+	assert.InDelta(t, 63.0/6, stats.Average, 1e-9)
+	assert.Equal(t, 2, stats.Criticals)
+	assert.Equal(t, 1, stats.Fumbles)
+	assert.InDelta(t, 2.0/6, stats.CriticalRate, 1e-9)
+
+	// 1d20 tem média 10,5
+	assert.NotNil(t, stats.Luck)
+	assert.Equal(t, 6, stats.Luck.Analyzed)
+	assert.InDelta(t, 10.5, stats.Luck.Expected, 1e-9)
+	assert.InDelta(t, 63.0/6-10.5, stats.Luck.Delta, 1e-9)
+	assert.Equal(t, 3, stats.Luck.AboveExpected)
+	assert.Equal(t, 3, stats.Luck.BelowExpected)
+
+	assert.Equal(t, models.RollStreaks{AboveExpected: 3, BelowExpected: 3, Criticals: 2, Fumbles: 1}, stats.Streaks)
+
+	assert.Equal(t, []models.RollFieldStats{
+		{Field: "skills.athletics", Count: 3, Average: 41.0 / 3},
+		{Field: "skills.stealth", Count: 1, Average: 15},
+	}, stats.TopFields)
+
+	// Poucos lançamentos para o qui-quadrado de um d20
+	assert.Len(t, stats.Dice, 1)
+	assert.Equal(t, 20, stats.Dice[0].Sides)
+	assert.Equal(t, 6, stats.Dice[0].Count)
+	assert.Equal(t, 2, stats.Dice[0].Faces[19])
+	assert.Nil(t, stats.Dice[0].ChiSquare)
+
+	empty := summarizeRolls(nil, expectation)
+	assert.Equal(t, 0, empty.Count)
+	assert.Nil(t, empty.Luck)
+	assert.Empty(t, empty.Dice)
+}
+
+func TestExpectationBudget(t *testing.T) {
+	expectation := (&RollStatsService{rollEngine: roll.NewRollEngine()}).expectation()
+
+	// Expressões pesadas ficam fora da sorte sem recorrer ao Monte Carlo
+	start := time.Now()
+	for i := 1; i <= maxStatsExpressions*2; i++ {
+		assert.Nil(t, expectation(fmt.Sprintf("100d1000+%d", i)))
+	}
+	assert.Nil(t, expectation("1d6!"))
+	assert.Less(t, time.Since(start), time.Second)
+
+	// O limite de expressões distintas já foi atingido: novas expressões não são analisadas
+	assert.Nil(t, expectation("1d20"))
+
+	expectation = (&RollStatsService{rollEngine: roll.NewRollEngine()}).expectation()
+	distribution := expectation("3d6")
+	assert.NotNil(t, distribution)
+	assert.True(t, distribution.Exact)
+	assert.Same(t, distribution, expectation("3d6"))
+}
+
+func TestSummarizeRollsDieFairness(t *testing.T) {
+	expectation := func(string) *roll.Distribution { return nil }
+
+	// d6 viciado: o 6 sai em metade dos lançamentos
+	var rolls []models.Roll
+	for i := 0; i < 60; i++ {
+		value := 6
+		if i%2 == 0 {
+			value = i/2%5 + 1
+		}
+		details, _ := json.Marshal(models.RollDetails{Terms: []models.RollTerm{{
+			Type:  models.RollTermDice,
+			Sides: 6,
+			Dice:  []models.DieResult{{Value: value}},
+		}}})
+		rolls = append(rolls, models.Roll{Expression: "1d6", ResultValue: value, ResultDetails: string(details)})
+	}
+
+	stats := summarizeRolls(rolls, expectation)
+	assert.Nil(t, stats.Luck)
+	assert.Len(t, stats.Dice, 1)
+	assert.Equal(t, []int{6, 6, 6, 6, 6, 30}, stats.Dice[0].Faces)
+	assert.NotNil(t, stats.Dice[0].PValue)
+	assert.True(t, stats.Dice[0].Suspicious)
+
+	// Rerrolagens e partes de dados compostos também são lançamentos
+	faces := make(map[int][]int)
+	countFaces(&models.RollDetails{Terms: []models.RollTerm{
+		{Type: models.RollTermDice, Sides: 6, Dice: []models.DieResult{{Value: 4, Rerolls: []int{1}}}},
+		{Type: models.RollTermDice, Sides: 6, Dice: []models.DieResult{{Value: 9, Rolls: []int{6, 3}}}},
+		{Type: models.RollTermConstant, Value: 2},
+	}}, faces)
+	assert.Equal(t, []int{1, 0, 1, 1, 0, 1}, faces[6])
+}
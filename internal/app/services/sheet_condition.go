@@ -0,0 +1,334 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/luizdequeiroz/rpg-backend/internal/app/models"
+	"github.com/luizdequeiroz/rpg-backend/internal/app/repositories"
+	"github.com/luizdequeiroz/rpg-backend/pkg/roll"
+)
+
+// Limites das condições e dos modificadores de rolagem
+const (
+	maxSheetConditions    = 30
+	maxConditionDuration  = 10000
+	maxModifierExpression = 50
+)
+
+// saveTags são as tags das resistências contra condições
+var saveTags = []string{"save"}
+
+// SheetConditionService gerencia as condições das fichas e os modificadores que elas somam às rolagens
+type SheetConditionService struct {
+	conditionRepo *repositories.SheetConditionRepository
+	rollRepo      *repositories.RollRepository
+	sheetService  *PlayerSheetService
+	rollEngine    *roll.RollEngine
+}
+
+// NewSheetConditionService cria nova instância do serviço
+func NewSheetConditionService(
+	conditionRepo *repositories.SheetConditionRepository,
+	rollRepo *repositories.RollRepository,
+	sheetService *PlayerSheetService,
+	rollEngine *roll.RollEngine,
+) *SheetConditionService {
+	return &SheetConditionService{
+		conditionRepo: conditionRepo,
+		rollRepo:      rollRepo,
+		sheetService:  sheetService,
+		rollEngine:    rollEngine,
+	}
+}
+
+// Create aplica uma condição à ficha; apenas o dono da ficha ou o mestre da mesa pode aplicá-la
+func (s *SheetConditionService) Create(sheetID string, req models.CreateConditionRequest, userID int) (*models.ConditionEvent, error) {
+	sheet, err := s.sheetForCondition(sheetID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	name := strings.TrimSpace(req.Name)
+	if name == "" {
+		return nil, errors.New("o nome da condição é obrigatório")
+	}
+
+	conditions, err := s.conditionRepo.GetBySheetID(sheet.ID)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao buscar condições da ficha: %w", err)
+	}
+	if len(conditions) >= maxSheetConditions {
+		return nil, fmt.Errorf("a ficha pode ter no máximo %d condições", maxSheetConditions)
+	}
+	for _, other := range conditions {
+		if strings.EqualFold(other.Name, name) {
+			return nil, errors.New("a ficha já tem essa condição")
+		}
+	}
+
+	condition := models.NewSheetCondition(sheet.ID, sheet.TableID, userID, name)
+	if source := strings.TrimSpace(req.Source); source != "" {
+		condition.Source = &source
+	}
+	if err := setConditionDuration(condition, req); err != nil {
+		return nil, err
+	}
+
+	modifiers := make([]models.ConditionModifier, len(req.Modifiers))
+	for i, modifier := range req.Modifiers {
+		if modifiers[i], err = normalizeRollModifier(s.rollEngine, modifier); err != nil {
+			return nil, err
+		}
+	}
+	condition.SetModifiers(modifiers)
+
+	if err := s.conditionRepo.Create(condition); err != nil {
+		return nil, fmt.Errorf("erro ao aplicar condição: %w", err)
+	}
+
+	return conditionEvent(models.ConditionActionAdded, condition), nil
+}
+
+// List lista as condições da ficha
+func (s *SheetConditionService) List(sheetID string, userID int) ([]*models.SheetConditionResponse, error) {
+	sheet, err := s.sheetService.sheetForRoll(sheetID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	conditions, err := s.conditionRepo.GetBySheetID(sheet.ID)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao buscar condições da ficha: %w", err)
+	}
+	return models.ConditionResponses(conditions), nil
+}
+
+// Remove remove uma condição da ficha; apenas o dono da ficha ou o mestre da mesa pode removê-la
+func (s *SheetConditionService) Remove(conditionID string, userID int) (*models.ConditionEvent, error) {
+	condition, err := s.getCondition(conditionID)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := s.sheetForCondition(condition.SheetID, userID); err != nil {
+		return nil, err
+	}
+
+	if err := s.conditionRepo.Delete(condition.ID); err != nil {
+		return nil, fmt.Errorf("erro ao remover condição: %w", err)
+	}
+	return conditionEvent(models.ConditionActionRemoved, condition), nil
+}
+
+// Save rola a resistência da ficha contra uma condição until_save, com a dificuldade informada ou a save_dc da
+// condição. A rolagem fica no histórico da mesa com a tag "save"; com sucesso, a condição termina
+func (s *SheetConditionService) Save(conditionID string, req models.ConditionSaveRequest, userID int) (*models.ConditionSaveResponse, *models.ConditionEvent, error) {
+	condition, err := s.getCondition(conditionID)
+	if err != nil {
+		return nil, nil, err
+	}
+	sheet, err := s.sheetForCondition(condition.SheetID, userID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if condition.DurationType != models.ConditionDurationUntilSave {
+		return nil, nil, errors.New("a condição não termina com resistência")
+	}
+
+	difficulty := req.Difficulty
+	if difficulty == nil {
+		difficulty = condition.SaveDC
+	}
+	if difficulty == nil {
+		return nil, nil, errors.New("informe a dificuldade da resistência")
+	}
+
+	rollRecord, rollDetails, err := s.sheetService.rollForSheet(sheet, models.CreateRollRequest{
+		SheetID:    sheet.ID,
+		Expression: req.Expression,
+		FieldName:  req.FieldName,
+		ClientSeed: req.ClientSeed,
+		RollCheck:  models.RollCheck{Difficulty: difficulty},
+		RollLabel:  models.RollLabel{Label: "Resistência: " + condition.Name, Tags: saveTags},
+	}, userID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if err := s.rollRepo.Create(rollRecord); err != nil {
+		return nil, nil, fmt.Errorf("erro ao salvar rolagem: %w", err)
+	}
+
+	saved := rollDetails.Outcome != nil && rollDetails.Outcome.Success
+	action := models.ConditionActionSaveFailed
+	if saved {
+		if err := s.conditionRepo.Delete(condition.ID); err != nil {
+			return nil, nil, fmt.Errorf("erro ao remover condição: %w", err)
+		}
+		action = models.ConditionActionSaved
+	}
+
+	rollResponse := rollRecord.ToResponse()
+	rollResponse.ResultDetails = rollDetails
+	rollResponse.User = &models.UserResponse{ID: userID}
+
+	event := conditionEvent(action, condition)
+	event.Roll = rollResponse
+	return &models.ConditionSaveResponse{
+		Saved:     saved,
+		Condition: event.Conditions[0],
+		Roll:      rollResponse,
+	}, event, nil
+}
+
+// expiredConditionsEvent monta a notificação das condições que expiraram com a nova rodada; nil se nenhuma expirou
+func expiredConditionsEvent(expired []*models.SheetCondition) *models.ConditionEvent {
+	if len(expired) == 0 {
+		return nil
+	}
+	return &models.ConditionEvent{
+		Action:     models.ConditionActionExpired,
+		Conditions: models.ConditionResponses(expired),
+	}
+}
+
+// getCondition busca uma condição por ID
+func (s *SheetConditionService) getCondition(conditionID string) (*models.SheetCondition, error) {
+	condition, err := s.conditionRepo.GetByID(conditionID)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao buscar condição: %w", err)
+	}
+	if condition == nil {
+		return nil, errors.New("condição não encontrada")
+	}
+	return condition, nil
+}
+
+// sheetForCondition busca a ficha e verifica se o usuário pode alterar as condições dela: o dono ou o mestre da mesa
+func (s *SheetConditionService) sheetForCondition(sheetID string, userID int) (*models.PlayerSheetResponse, error) {
+	sheet, err := s.sheetService.sheetForRoll(sheetID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if sheet.OwnerID == userID {
+		return sheet, nil
+	}
+
+	gmID, err := s.sheetService.TableOwner(sheet.TableID)
+	if err != nil {
+		return nil, err
+	}
+	if userID != gmID {
+		return nil, errors.New("apenas o dono da ficha ou o mestre da mesa pode alterar as condições")
+	}
+	return sheet, nil
+}
+
+// normalizeRollModifier valida a expressão de um modificador de condição ou de item, com sinal explícito
+// (e.g., "+1d4", "-2", "+{prof}"), e normaliza os alvos em minúsculas, sem "#"
+func normalizeRollModifier(rollEngine *roll.RollEngine, modifier models.ConditionModifier) (models.ConditionModifier, error) {
+	expression := strings.TrimSpace(modifier.Expression)
+	if expression == "" {
+		return modifier, errors.New("a expressão do modificador é obrigatória")
+	}
+	if !strings.HasPrefix(expression, "+") && !strings.HasPrefix(expression, "-") {
+		expression = "+" + expression
+	}
+	if len(expression) > maxModifierExpression {
+		return modifier, fmt.Errorf("a expressão do modificador pode ter no máximo %d caracteres", maxModifierExpression)
+	}
+	if err := rollEngine.ValidatePlaceholderExpression("0" + expression); err != nil {
+		return modifier, fmt.Errorf("modificador inválido '%s': %w", modifier.Expression, err)
+	}
+
+	var appliesTo []string
+	for _, target := range modifier.AppliesTo {
+		target = strings.ToLower(strings.TrimPrefix(strings.TrimSpace(target), "#"))
+		if target == "" {
+			return modifier, errors.New("alvo do modificador vazio")
+		}
+		if !slices.Contains(appliesTo, target) {
+			appliesTo = append(appliesTo, target)
+		}
+	}
+
+	return models.ConditionModifier{Expression: expression, AppliesTo: appliesTo}, nil
+}
+
+// setConditionDuration valida a duração pedida; rodadas e minutos definem as rodadas restantes
+func setConditionDuration(condition *models.SheetCondition, req models.CreateConditionRequest) error {
+	switch req.DurationType {
+	case models.ConditionDurationRounds, models.ConditionDurationMinutes:
+		if req.Duration == nil || *req.Duration < 1 || *req.Duration > maxConditionDuration {
+			return fmt.Errorf("a duração deve estar entre 1 e %d", maxConditionDuration)
+		}
+		rounds := *req.Duration
+		if req.DurationType == models.ConditionDurationMinutes {
+			rounds *= models.ConditionRoundsPerMinute
+		}
+		condition.Duration = req.Duration
+		condition.RemainingRounds = &rounds
+	case models.ConditionDurationUntilSave, models.ConditionDurationIndefinite:
+		if req.Duration != nil {
+			return errors.New("a duração vale apenas para rounds e minutes")
+		}
+	default:
+		return fmt.Errorf("duração inválida: '%s' (use rounds, minutes, until_save ou indefinite)", req.DurationType)
+	}
+
+	if req.SaveDC != nil && req.DurationType != models.ConditionDurationUntilSave {
+		return errors.New("save_dc vale apenas para condições until_save")
+	}
+	condition.DurationType = req.DurationType
+	condition.SaveDC = req.SaveDC
+	return nil
+}
+
+// conditionModifiers soma os modificadores das condições que valem para a rolagem: os sem alvo, com untargeted, e os
+// que têm como alvo uma tag da rolagem ou o campo rolado (ou um grupo dele, e.g., "attacks" vale para "attacks.longsword")
+func conditionModifiers(conditions []*models.SheetCondition, fieldName string, tags []string, untargeted bool) (string, []models.AppliedCondition) {
+	fieldName = strings.ToLower(fieldName)
+
+	var bonus strings.Builder
+	var applied []models.AppliedCondition
+	for _, condition := range conditions {
+		for _, modifier := range condition.ModifierList() {
+			if !modifierApplies(modifier, fieldName, tags, untargeted) {
+				continue
+			}
+			bonus.WriteString(modifier.Expression)
+			applied = append(applied, models.AppliedCondition{
+				ConditionID: condition.ID,
+				Name:        condition.Name,
+				Expression:  modifier.Expression,
+			})
+		}
+	}
+	return bonus.String(), applied
+}
+
+// modifierApplies informa se o modificador vale para a rolagem do campo e das tags informados; os sem alvo valem
+// apenas com untargeted
+func modifierApplies(modifier models.ConditionModifier, fieldName string, tags []string, untargeted bool) bool {
+	if len(modifier.AppliesTo) == 0 {
+		return untargeted
+	}
+	for _, target := range modifier.AppliesTo {
+		if slices.Contains(tags, target) {
+			return true
+		}
+		if fieldName != "" && (fieldName == target || strings.HasPrefix(fieldName, target+".")) {
+			return true
+		}
+	}
+	return false
+}
+
+// conditionEvent monta a notificação de uma ação sobre uma condição
+func conditionEvent(action string, condition *models.SheetCondition) *models.ConditionEvent {
+	return &models.ConditionEvent{
+		Action:     action,
+		Conditions: []*models.SheetConditionResponse{condition.ToResponse()},
+	}
+}
@@ -0,0 +1,123 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/luizdequeiroz/rpg-backend/internal/app/models"
+	"github.com/luizdequeiroz/rpg-backend/internal/app/repositories"
+	"github.com/luizdequeiroz/rpg-backend/pkg/roll"
+)
+
+func newTestCondition(name string, modifiers ...models.ConditionModifier) *models.SheetCondition {
+	condition := models.NewSheetCondition("sheet", "table", 1, name)
+	condition.SetModifiers(modifiers)
+	return condition
+}
+
+func TestConditionModifiers(t *testing.T) {
+	conditions := []*models.SheetCondition{
+		newTestCondition("abençoado", models.ConditionModifier{Expression: "+1d4", AppliesTo: []string{"attack", "save"}}),
+		newTestCondition("envenenado", models.ConditionModifier{Expression: "-2"}),
+		newTestCondition("concentrado"),
+		newTestCondition("inspirado", models.ConditionModifier{Expression: "+1", AppliesTo: []string{"attacks"}}),
+	}
+
+	bonus, applied := conditionModifiers(conditions, "", []string{"attack"}, true)
+	assert.Equal(t, "+1d4-2", bonus)
+	assert.Equal(t, []models.AppliedCondition{
+		{ConditionID: conditions[0].ID, Name: "abençoado", Expression: "+1d4"},
+		{ConditionID: conditions[1].ID, Name: "envenenado", Expression: "-2"},
+	}, applied)
+
+	bonus, _ = conditionModifiers(conditions, "Attacks.Longsword", nil, true)
+	assert.Equal(t, "-2+1", bonus)
+
+	bonus, _ = conditionModifiers(conditions, "attacks_bonus", []string{"initiative"}, true)
+	assert.Equal(t, "-2", bonus)
+
+	// Sem untargeted (e.g., o dano depois do ataque na macro) valem apenas os modificadores com alvo
+	bonus, _ = conditionModifiers(conditions, "", []string{"attack"}, false)
+	assert.Equal(t, "+1d4", bonus)
+
+	bonus, applied = conditionModifiers(nil, "athletics", nil, true)
+	assert.Empty(t, bonus)
+	assert.Nil(t, applied)
+}
+
+func TestNormalizeRollModifier(t *testing.T) {
+	rollEngine := roll.NewRollEngine()
+
+	modifier, err := normalizeRollModifier(rollEngine, models.ConditionModifier{Expression: " 1d4 ", AppliesTo: []string{"#Attack", "attack", " save "}})
+	assert.NoError(t, err)
+	assert.Equal(t, models.ConditionModifier{Expression: "+1d4", AppliesTo: []string{"attack", "save"}}, modifier)
+
+	modifier, err = normalizeRollModifier(rollEngine, models.ConditionModifier{Expression: "-{prof}"})
+	assert.NoError(t, err)
+	assert.Equal(t, "-{prof}", modifier.Expression)
+
+	for _, expression := range []string{"", "+", "1d", "+1d4)", "*2"} {
+		_, err = normalizeRollModifier(rollEngine, models.ConditionModifier{Expression: expression})
+		assert.Error(t, err, expression)
+	}
+	_, err = normalizeRollModifier(rollEngine, models.ConditionModifier{Expression: "+1", AppliesTo: []string{"#"}})
+	assert.Error(t, err)
+}
+
+func TestSetConditionDuration(t *testing.T) {
+	ten, dc := 10, 13
+
+	condition := newTestCondition("abençoado")
+	assert.NoError(t, setConditionDuration(condition, models.CreateConditionRequest{DurationType: models.ConditionDurationRounds, Duration: &ten}))
+	assert.Equal(t, 10, *condition.RemainingRounds)
+
+	condition = newTestCondition("enfeitiçado")
+	assert.NoError(t, setConditionDuration(condition, models.CreateConditionRequest{DurationType: models.ConditionDurationMinutes, Duration: &ten}))
+	assert.Equal(t, 10*models.ConditionRoundsPerMinute, *condition.RemainingRounds)
+
+	condition = newTestCondition("envenenado")
+	assert.NoError(t, setConditionDuration(condition, models.CreateConditionRequest{DurationType: models.ConditionDurationUntilSave, SaveDC: &dc}))
+	assert.Nil(t, condition.RemainingRounds)
+	assert.Equal(t, 13, *condition.SaveDC)
+
+	for _, req := range []models.CreateConditionRequest{
+		{DurationType: models.ConditionDurationRounds},
+		{DurationType: models.ConditionDurationIndefinite, Duration: &ten},
+		{DurationType: models.ConditionDurationIndefinite, SaveDC: &dc},
+		{DurationType: "turns", Duration: &ten},
+	} {
+		assert.Error(t, setConditionDuration(newTestCondition("caído"), req), req.DurationType)
+	}
+}
+
+func TestConditionModifiersOnlyApplyToSumRolls(t *testing.T) {
+	database := newTestDatabase(t)
+	service := newTestSheetService(database)
+	sheet := newTestSheet(t, database, `{"dex_mod": 3}`)
+
+	conditionRepo := repositories.NewSheetConditionRepository(database.DB)
+	require.NoError(t, conditionRepo.Create(newTestCondition("abençoado", models.ConditionModifier{Expression: "+1d4"})))
+
+	rollWithSheet := func(expression string) *models.DiceRollResponse {
+		result, err := service.RollWithSheet(models.DiceRollWithSheetRequest{SheetID: sheet.ID, Expression: expression}, sheet)
+		require.NoError(t, err)
+		return result
+	}
+
+	// A rolagem de soma de /dice/roll-with-sheet recebe o modificador da condição
+	result := rollWithSheet("1d20+{dex_mod}")
+	assert.Equal(t, "1d20+3+1d4", result.Expression)
+	require.Len(t, result.ResultDetails.Conditions, 1)
+
+	// Na parada de sucessos o +1d4 somaria sucessos, e no Fate mudaria a escala: nenhuma das duas o recebe
+	for _, expression := range []string{"8d10>=8", "4dF+1"} {
+		result := rollWithSheet(expression)
+		assert.Equal(t, expression, result.Expression)
+		assert.Empty(t, result.ResultDetails.Conditions)
+		for _, term := range result.ResultDetails.Terms {
+			assert.NotEqual(t, "1d4", term.Expression)
+		}
+	}
+}
@@ -0,0 +1,617 @@
+package services
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/luizdequeiroz/rpg-backend/internal/app/models"
+	"github.com/luizdequeiroz/rpg-backend/internal/app/repositories"
+	"github.com/luizdequeiroz/rpg-backend/pkg/roll"
+)
+
+// Limites do inventário
+const (
+	maxSheetItems       = 200
+	maxItemQuantity     = 100000
+	maxItemWeight       = 100000
+	maxItemStatValue    = 10000
+	maxTableItemResults = 100
+)
+
+// SheetItemService gerencia o inventário das fichas, a entrega e a troca de itens entre fichas da mesa
+type SheetItemService struct {
+	itemRepo     *repositories.SheetItemRepository
+	sheetService *PlayerSheetService
+	rollEngine   *roll.RollEngine
+}
+
+// NewSheetItemService cria nova instância do serviço
+func NewSheetItemService(
+	itemRepo *repositories.SheetItemRepository,
+	sheetService *PlayerSheetService,
+	rollEngine *roll.RollEngine,
+) *SheetItemService {
+	return &SheetItemService{
+		itemRepo:     itemRepo,
+		sheetService: sheetService,
+		rollEngine:   rollEngine,
+	}
+}
+
+// Create adiciona um item à ficha; apenas o dono da ficha ou o mestre da mesa pode adicioná-lo
+func (s *SheetItemService) Create(sheetID string, req models.CreateItemRequest, userID int) (*models.InventoryEvent, error) {
+	sheet, err := s.sheetForInventory(sheetID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	count, err := s.itemRepo.CountBySheetID(sheet.ID)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao contar itens da ficha: %w", err)
+	}
+	if count >= maxSheetItems {
+		return nil, fmt.Errorf("a ficha pode ter no máximo %d itens", maxSheetItems)
+	}
+
+	item := models.NewSheetItem(sheet.ID, sheet.TableID, userID, "")
+	quantity := 1
+	if req.Quantity != nil {
+		quantity = *req.Quantity
+	}
+	update := models.UpdateItemRequest{
+		Name:        &req.Name,
+		Description: &req.Description,
+		Quantity:    &quantity,
+		Weight:      &req.Weight,
+		Equipped:    &req.Equipped,
+		Modifiers:   &req.Modifiers,
+		Stats:       &req.Stats,
+	}
+	if err := s.applyItemUpdate(item, update); err != nil {
+		return nil, err
+	}
+
+	if err := s.itemRepo.Create(item); err != nil {
+		return nil, fmt.Errorf("erro ao adicionar item: %w", err)
+	}
+	return inventoryEvent(models.InventoryActionAdded, sheet.TableID, item), nil
+}
+
+// Inventory retorna os itens da ficha com a carga total, a capacidade declarada no template e os campos da ficha
+// alterados pelos itens equipados
+func (s *SheetItemService) Inventory(sheetID string, userID int) (*models.InventoryResponse, error) {
+	sheet, err := s.sheetService.sheetForRoll(sheetID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	items, err := s.itemRepo.GetBySheetID(sheet.ID)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao buscar itens da ficha: %w", err)
+	}
+	rules, err := s.sheetService.SheetRules(sheet.TemplateID)
+	if err != nil {
+		return nil, err
+	}
+
+	var equipped []*models.SheetItem
+	for _, item := range items {
+		if item.Equipped {
+			equipped = append(equipped, item)
+		}
+	}
+	_, stats, err := equippedSheetData(sheet.Data, equipped)
+	if err != nil {
+		return nil, err
+	}
+
+	response := &models.InventoryResponse{
+		SheetID:     sheet.ID,
+		Items:       models.ItemResponses(items),
+		TotalWeight: inventoryWeight(items),
+		Capacity:    inventoryCapacity(sheet.Data, rules.Inventory),
+		Stats:       stats,
+	}
+	response.Encumbered = response.Capacity != nil && response.TotalWeight > *response.Capacity
+	return response, nil
+}
+
+// Update altera um item; apenas o dono da ficha ou o mestre da mesa pode alterá-lo
+func (s *SheetItemService) Update(itemID string, req models.UpdateItemRequest, userID int) (*models.InventoryEvent, error) {
+	item, err := s.getItem(itemID)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := s.sheetForInventory(item.SheetID, userID); err != nil {
+		return nil, err
+	}
+
+	if err := s.applyItemUpdate(item, req); err != nil {
+		return nil, err
+	}
+	item.UpdatedAt = time.Now()
+	if err := s.itemRepo.Update(item); err != nil {
+		return nil, fmt.Errorf("erro ao atualizar item: %w", err)
+	}
+	return inventoryEvent(models.InventoryActionUpdated, item.TableID, item), nil
+}
+
+// Remove remove um item da ficha; apenas o dono da ficha ou o mestre da mesa pode removê-lo
+func (s *SheetItemService) Remove(itemID string, userID int) (*models.InventoryEvent, error) {
+	item, err := s.getItem(itemID)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := s.sheetForInventory(item.SheetID, userID); err != nil {
+		return nil, err
+	}
+
+	if err := s.itemRepo.Delete(item.ID); err != nil {
+		return nil, fmt.Errorf("erro ao remover item: %w", err)
+	}
+	item.Quantity = 0
+	return inventoryEvent(models.InventoryActionRemoved, item.TableID, item), nil
+}
+
+// Give entrega unidades de um item (padrão: todas) a outra ficha da mesma mesa; apenas o dono da ficha ou o mestre
+// da mesa pode entregá-lo. As unidades chegam desequipadas e se juntam a um item igual da outra ficha
+func (s *SheetItemService) Give(itemID string, req models.GiveItemRequest, userID int) (*models.InventoryEvent, error) {
+	item, err := s.getItem(itemID)
+	if err != nil {
+		return nil, err
+	}
+	sheet, err := s.sheetForInventory(item.SheetID, userID)
+	if err != nil {
+		return nil, err
+	}
+	target, err := s.targetSheet(sheet, req.SheetID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	quantity := item.Quantity
+	if req.Quantity != nil {
+		quantity = *req.Quantity
+	}
+	if quantity < 1 || quantity > item.Quantity {
+		return nil, fmt.Errorf("a quantidade deve estar entre 1 e %d", item.Quantity)
+	}
+
+	items, ok, err := s.itemRepo.Give(models.ItemMove{
+		ItemID:      item.ID,
+		FromSheetID: sheet.ID,
+		ToSheetID:   target.ID,
+		Quantity:    quantity,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("erro ao entregar item: %w", err)
+	}
+	if !ok {
+		return nil, errors.New("o item foi alterado por outra ação; tente novamente")
+	}
+	return inventoryEvent(models.InventoryActionGiven, sheet.TableID, items...), nil
+}
+
+// Search busca os itens das fichas da mesa pelo nome (e.g., quem tem a "Espada da Aurora")
+func (s *SheetItemService) Search(tableID, name string, userID int) ([]*models.TableItemResponse, error) {
+	hasAccess, err := s.sheetService.checkTableAccess(tableID, userID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, errors.New("mesa não encontrada")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("erro ao verificar acesso: %w", err)
+	}
+	if !hasAccess {
+		return nil, errors.New("acesso negado à mesa")
+	}
+
+	items, err := s.itemRepo.SearchByTable(tableID, strings.TrimSpace(name), maxTableItemResults)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao buscar itens da mesa: %w", err)
+	}
+
+	responses := make([]*models.TableItemResponse, len(items))
+	for i, item := range items {
+		responses[i] = &models.TableItemResponse{
+			SheetItemResponse: item.ToResponse(),
+			SheetName:         item.SheetName,
+			OwnerID:           item.OwnerID,
+		}
+	}
+	return responses, nil
+}
+
+// CreateTrade propõe uma troca de itens a outra ficha da mesma mesa; apenas o dono da ficha ou o mestre da mesa
+// pode propô-la. Os itens só mudam de ficha quando a outra ficha aceita
+func (s *SheetItemService) CreateTrade(sheetID string, req models.CreateTradeRequest, userID int) (*models.InventoryEvent, error) {
+	sheet, err := s.sheetForInventory(sheetID, userID)
+	if err != nil {
+		return nil, err
+	}
+	target, err := s.targetSheet(sheet, req.ToSheetID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if len(req.Offered) == 0 && len(req.Requested) == 0 {
+		return nil, errors.New("a troca precisa de ao menos um item")
+	}
+
+	seen := make(map[string]bool)
+	offered, err := s.tradeItems(req.Offered, sheet.ID, seen)
+	if err != nil {
+		return nil, err
+	}
+	requested, err := s.tradeItems(req.Requested, target.ID, seen)
+	if err != nil {
+		return nil, err
+	}
+
+	trade := models.NewItemTrade(sheet.TableID, sheet.ID, target.ID, userID, offered, requested)
+	if err := s.itemRepo.CreateTrade(trade); err != nil {
+		return nil, fmt.Errorf("erro ao propor troca: %w", err)
+	}
+	return tradeEvent(trade), nil
+}
+
+// ListTrades lista as trocas pendentes propostas pela ficha ou a ela
+func (s *SheetItemService) ListTrades(sheetID string, userID int) ([]*models.ItemTradeResponse, error) {
+	sheet, err := s.sheetService.sheetForRoll(sheetID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	trades, err := s.itemRepo.GetPendingTradesBySheetID(sheet.ID)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao buscar trocas: %w", err)
+	}
+
+	responses := make([]*models.ItemTradeResponse, len(trades))
+	for i, trade := range trades {
+		responses[i] = trade.ToResponse()
+	}
+	return responses, nil
+}
+
+// AcceptTrade efetiva uma troca pendente, movendo os itens dos dois lados de uma vez; apenas o dono da ficha que
+// recebeu a proposta ou o mestre da mesa pode aceitá-la
+func (s *SheetItemService) AcceptTrade(tradeID string, userID int) (*models.InventoryEvent, error) {
+	trade, err := s.pendingTrade(tradeID)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := s.sheetForInventory(trade.ToSheetID, userID); err != nil {
+		return nil, err
+	}
+
+	items, ok, err := s.itemRepo.AcceptTrade(trade, userID)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao aceitar troca: %w", err)
+	}
+	if !ok {
+		if _, err := s.pendingTrade(tradeID); err != nil {
+			return nil, err
+		}
+		return nil, errors.New("os itens da troca não estão mais disponíveis")
+	}
+
+	event := tradeEvent(trade)
+	event.Items = models.ItemResponses(items)
+	return event, nil
+}
+
+// DeclineTrade encerra uma troca pendente sem mover itens: a ficha que propôs cancela e a que recebeu, ou o mestre
+// da mesa, recusa
+func (s *SheetItemService) DeclineTrade(tradeID string, userID int) (*models.InventoryEvent, error) {
+	trade, err := s.pendingTrade(tradeID)
+	if err != nil {
+		return nil, err
+	}
+
+	status := models.TradeStatusCanceled
+	if _, err := s.sheetForInventory(trade.FromSheetID, userID); err != nil {
+		if _, err := s.sheetForInventory(trade.ToSheetID, userID); err != nil {
+			return nil, err
+		}
+		status = models.TradeStatusDeclined
+	}
+
+	ok, err := s.itemRepo.ResolveTrade(trade, status, userID)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao encerrar troca: %w", err)
+	}
+	if !ok {
+		return nil, errors.New("a troca não está mais pendente")
+	}
+	return tradeEvent(trade), nil
+}
+
+// getItem busca um item por ID
+func (s *SheetItemService) getItem(itemID string) (*models.SheetItem, error) {
+	item, err := s.itemRepo.GetByID(itemID)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao buscar item: %w", err)
+	}
+	if item == nil {
+		return nil, errors.New("item não encontrado")
+	}
+	return item, nil
+}
+
+// pendingTrade busca uma troca e verifica se ela ainda está pendente
+func (s *SheetItemService) pendingTrade(tradeID string) (*models.ItemTrade, error) {
+	trade, err := s.itemRepo.GetTrade(tradeID)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao buscar troca: %w", err)
+	}
+	if trade == nil {
+		return nil, errors.New("troca não encontrada")
+	}
+	if trade.Status != models.TradeStatusPending {
+		return nil, errors.New("a troca não está mais pendente")
+	}
+	return trade, nil
+}
+
+// sheetForInventory busca a ficha e verifica se o usuário pode alterar o inventário dela: o dono ou o mestre da mesa
+func (s *SheetItemService) sheetForInventory(sheetID string, userID int) (*models.PlayerSheetResponse, error) {
+	sheet, err := s.sheetService.sheetForRoll(sheetID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if sheet.OwnerID == userID {
+		return sheet, nil
+	}
+
+	gmID, err := s.sheetService.TableOwner(sheet.TableID)
+	if err != nil {
+		return nil, err
+	}
+	if userID != gmID {
+		return nil, errors.New("apenas o dono da ficha ou o mestre da mesa pode alterar o inventário")
+	}
+	return sheet, nil
+}
+
+// targetSheet busca a ficha que recebe itens e verifica se ela é outra ficha da mesma mesa
+func (s *SheetItemService) targetSheet(sheet *models.PlayerSheetResponse, targetID string, userID int) (*models.PlayerSheetResponse, error) {
+	if targetID == sheet.ID {
+		return nil, errors.New("os itens devem ir para outra ficha")
+	}
+	target, err := s.sheetService.sheetForRoll(targetID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if target.TableID != sheet.TableID {
+		return nil, errors.New("os itens só podem ir para fichas da mesma mesa")
+	}
+	return target, nil
+}
+
+// tradeItems valida os itens de um lado da troca, que devem estar na ficha com unidades suficientes, e preenche
+// os nomes; seen evita o mesmo item nos dois lados
+func (s *SheetItemService) tradeItems(requested []models.TradeItem, sheetID string, seen map[string]bool) ([]models.TradeItem, error) {
+	items := make([]models.TradeItem, len(requested))
+	for i, tradeItem := range requested {
+		if seen[tradeItem.ItemID] {
+			return nil, errors.New("o mesmo item aparece mais de uma vez na troca")
+		}
+		seen[tradeItem.ItemID] = true
+
+		item, err := s.getItem(tradeItem.ItemID)
+		if err != nil {
+			return nil, err
+		}
+		if item.SheetID != sheetID {
+			return nil, fmt.Errorf("o item '%s' não pertence à ficha do seu lado da troca", item.Name)
+		}
+		if tradeItem.Quantity < 1 || tradeItem.Quantity > item.Quantity {
+			return nil, fmt.Errorf("a quantidade de '%s' deve estar entre 1 e %d", item.Name, item.Quantity)
+		}
+		items[i] = models.TradeItem{ItemID: item.ID, Name: item.Name, Quantity: tradeItem.Quantity}
+	}
+	return items, nil
+}
+
+// applyItemUpdate valida e aplica ao item os campos informados
+func (s *SheetItemService) applyItemUpdate(item *models.SheetItem, req models.UpdateItemRequest) error {
+	if req.Name != nil {
+		name := strings.TrimSpace(*req.Name)
+		if name == "" {
+			return errors.New("o nome do item é obrigatório")
+		}
+		item.Name = name
+	}
+	if req.Description != nil {
+		item.Description = nil
+		if description := strings.TrimSpace(*req.Description); description != "" {
+			item.Description = &description
+		}
+	}
+	if req.Quantity != nil {
+		if *req.Quantity < 1 || *req.Quantity > maxItemQuantity {
+			return fmt.Errorf("a quantidade deve estar entre 1 e %d", maxItemQuantity)
+		}
+		item.Quantity = *req.Quantity
+	}
+	if req.Weight != nil {
+		if *req.Weight < 0 || *req.Weight > maxItemWeight {
+			return fmt.Errorf("o peso deve estar entre 0 e %d", maxItemWeight)
+		}
+		item.Weight = *req.Weight
+	}
+	if req.Equipped != nil {
+		item.Equipped = *req.Equipped
+	}
+	if req.Modifiers != nil {
+		modifiers := make([]models.ConditionModifier, len(*req.Modifiers))
+		for i, modifier := range *req.Modifiers {
+			var err error
+			if modifiers[i], err = normalizeRollModifier(s.rollEngine, modifier); err != nil {
+				return err
+			}
+		}
+		item.SetModifiers(modifiers)
+	}
+	if req.Stats != nil {
+		stats, err := normalizeItemStats(*req.Stats)
+		if err != nil {
+			return err
+		}
+		item.SetStats(stats)
+	}
+	return nil
+}
+
+// normalizeItemStats valida os campos da ficha alterados pelo item: cada um com set ou add
+func normalizeItemStats(stats []models.ItemStat) ([]models.ItemStat, error) {
+	normalized := make([]models.ItemStat, len(stats))
+	for i, stat := range stats {
+		stat.Path = strings.TrimSpace(stat.Path)
+		if !isSheetPath(stat.Path) {
+			return nil, fmt.Errorf("campo inválido '%s': use notação de ponto (e.g., \"ac\", \"abilities.str\")", stat.Path)
+		}
+		if (stat.Set == nil) == (stat.Add == nil) {
+			return nil, fmt.Errorf("informe set ou add para o campo '%s'", stat.Path)
+		}
+		for _, value := range []*int{stat.Set, stat.Add} {
+			if value != nil && (*value < -maxItemStatValue || *value > maxItemStatValue) {
+				return nil, fmt.Errorf("o valor do campo '%s' deve estar entre -%d e %d", stat.Path, maxItemStatValue, maxItemStatValue)
+			}
+		}
+		normalized[i] = stat
+	}
+	return normalized, nil
+}
+
+// equippedSheetData aplica aos dados da ficha os campos alterados pelos itens equipados: em cada campo vale o maior
+// set e depois são somados os add. Retorna uma cópia dos dados e as alterações; campos que não são números inteiros
+// na ficha não são alterados
+func equippedSheetData(data models.PlayerSheetData, items []*models.SheetItem) (models.PlayerSheetData, []models.SheetFieldChange, error) {
+	changes := []models.SheetFieldChange{}
+
+	sets := make(map[string]int)
+	adds := make(map[string]int)
+	var paths []string
+	for _, item := range items {
+		for _, stat := range item.StatList() {
+			_, hasSet := sets[stat.Path]
+			_, hasAdd := adds[stat.Path]
+			if !hasSet && !hasAdd {
+				paths = append(paths, stat.Path)
+			}
+			if stat.Set != nil && (!hasSet || *stat.Set > sets[stat.Path]) {
+				sets[stat.Path] = *stat.Set
+			}
+			if stat.Add != nil {
+				adds[stat.Path] += *stat.Add
+			}
+		}
+	}
+	if len(paths) == 0 {
+		return data, changes, nil
+	}
+
+	dataJSON, err := json.Marshal(data)
+	if err != nil {
+		return nil, nil, fmt.Errorf("erro ao serializar dados da ficha: %w", err)
+	}
+	var equipped models.PlayerSheetData
+	if err := json.Unmarshal(dataJSON, &equipped); err != nil {
+		return nil, nil, fmt.Errorf("erro ao deserializar dados da ficha: %w", err)
+	}
+	if equipped == nil {
+		equipped = make(models.PlayerSheetData)
+	}
+
+	for _, path := range paths {
+		old, _, err := sheetNumber(equipped, path)
+		if err != nil {
+			continue
+		}
+		value := old
+		if set, ok := sets[path]; ok {
+			value = set
+		}
+		value += adds[path]
+
+		if changes, err = setResourceField(equipped, path, old, value, changes); err != nil {
+			return nil, nil, err
+		}
+	}
+	return equipped, changes, nil
+}
+
+// itemModifiers soma os modificadores dos itens equipados que valem para a rolagem, com as mesmas regras de alvo
+// dos modificadores de condições
+func itemModifiers(items []*models.SheetItem, fieldName string, tags []string, untargeted bool) (string, []models.AppliedItem) {
+	fieldName = strings.ToLower(fieldName)
+
+	var bonus strings.Builder
+	var applied []models.AppliedItem
+	for _, item := range items {
+		for _, modifier := range item.ModifierList() {
+			if !modifierApplies(modifier, fieldName, tags, untargeted) {
+				continue
+			}
+			bonus.WriteString(modifier.Expression)
+			applied = append(applied, models.AppliedItem{
+				ItemID:     item.ID,
+				Name:       item.Name,
+				Expression: modifier.Expression,
+			})
+		}
+	}
+	return bonus.String(), applied
+}
+
+// inventoryWeight soma o peso de todas as unidades dos itens
+func inventoryWeight(items []*models.SheetItem) float64 {
+	var total float64
+	for _, item := range items {
+		total += item.Weight * float64(item.Quantity)
+	}
+	return total
+}
+
+// inventoryCapacity retorna a capacidade de carga declarada no template: fixa ou lida do campo da ficha
+func inventoryCapacity(data models.PlayerSheetData, rule *models.InventoryRule) *float64 {
+	if rule == nil {
+		return nil
+	}
+	if rule.Capacity != nil || rule.CapacityPath == "" {
+		return rule.Capacity
+	}
+
+	value, exists, err := sheetValue(data, rule.CapacityPath)
+	if err != nil || !exists {
+		return nil
+	}
+	capacity, ok := value.(float64)
+	if !ok {
+		return nil
+	}
+	return &capacity
+}
+
+// inventoryEvent monta o evento de alteração de itens
+func inventoryEvent(action, tableID string, items ...*models.SheetItem) *models.InventoryEvent {
+	return &models.InventoryEvent{
+		Action:  action,
+		TableID: tableID,
+		Items:   models.ItemResponses(items),
+	}
+}
+
+// tradeEvent monta o evento de uma troca; os itens são preenchidos quando ela é aceita
+func tradeEvent(trade *models.ItemTrade) *models.InventoryEvent {
+	return &models.InventoryEvent{
+		Action:  models.InventoryActionTrade,
+		TableID: trade.TableID,
+		Items:   []*models.SheetItemResponse{},
+		Trade:   trade.ToResponse(),
+	}
+}
@@ -0,0 +1,135 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/luizdequeiroz/rpg-backend/internal/app/repositories"
+
+	"github.com/luizdequeiroz/rpg-backend/internal/app/models"
+)
+
+func intPtr(value int) *int {
+	return &value
+}
+
+func newTestItem(name string, quantity int, weight float64, stats []models.ItemStat, modifiers []models.ConditionModifier) *models.SheetItem {
+	item := models.NewSheetItem("sheet", "table", 1, name)
+	item.Quantity = quantity
+	item.Weight = weight
+	item.SetStats(stats)
+	item.SetModifiers(modifiers)
+	return item
+}
+
+func TestNormalizeItemStats(t *testing.T) {
+	stats, err := normalizeItemStats([]models.ItemStat{{Path: " ac ", Set: intPtr(16)}})
+	assert.NoError(t, err)
+	assert.Equal(t, "ac", stats[0].Path)
+
+	_, err = normalizeItemStats([]models.ItemStat{{Path: "ac"}})
+	assert.Error(t, err)
+
+	_, err = normalizeItemStats([]models.ItemStat{{Path: "ac", Set: intPtr(16), Add: intPtr(2)}})
+	assert.Error(t, err)
+
+	_, err = normalizeItemStats([]models.ItemStat{{Path: "ac..x", Add: intPtr(2)}})
+	assert.Error(t, err)
+
+	_, err = normalizeItemStats([]models.ItemStat{{Path: "ac", Add: intPtr(maxItemStatValue + 1)}})
+	assert.Error(t, err)
+}
+
+func TestEquippedSheetData(t *testing.T) {
+	data := newTestSheetData(t, `{"ac": 10, "abilities": {"str": 12}, "name": "Aria"}`)
+
+	items := []*models.SheetItem{
+		newTestItem("Cota de malha", 1, 20, []models.ItemStat{{Path: "ac", Set: intPtr(16)}}, nil),
+		newTestItem("Couro", 1, 10, []models.ItemStat{{Path: "ac", Set: intPtr(11)}}, nil),
+		newTestItem("Escudo", 1, 6, []models.ItemStat{{Path: "ac", Add: intPtr(2)}}, nil),
+		newTestItem("Manoplas", 1, 1, []models.ItemStat{{Path: "abilities.str", Add: intPtr(2)}, {Path: "name", Add: intPtr(1)}}, nil),
+	}
+
+	equipped, changes, err := equippedSheetData(data, items)
+	assert.NoError(t, err)
+	assert.Equal(t, []models.SheetFieldChange{
+		{Path: "ac", Old: 10, New: 18, Delta: 8},
+		{Path: "abilities.str", Old: 12, New: 14, Delta: 2},
+	}, changes)
+
+	value, _, _ := sheetNumber(equipped, "ac")
+	assert.Equal(t, 18, value)
+	value, _, _ = sheetNumber(data, "ac")
+	assert.Equal(t, 10, value, "os dados originais não devem mudar")
+
+	same, changes, err := equippedSheetData(data, nil)
+	assert.NoError(t, err)
+	assert.Empty(t, changes)
+	assert.Equal(t, data, same)
+}
+
+func TestItemModifiers(t *testing.T) {
+	items := []*models.SheetItem{
+		newTestItem("Espada", 1, 3, nil, []models.ConditionModifier{{Expression: "+1", AppliesTo: []string{"attack"}}}),
+		newTestItem("Amuleto", 1, 0, nil, []models.ConditionModifier{{Expression: "+1d4", AppliesTo: []string{"saves"}}}),
+	}
+
+	bonus, applied := itemModifiers(items, "", []string{"attack"}, true)
+	assert.Equal(t, "+1", bonus)
+	assert.Len(t, applied, 1)
+	assert.Equal(t, "Espada", applied[0].Name)
+
+	bonus, applied = itemModifiers(items, "Saves.Wis", nil, true)
+	assert.Equal(t, "+1d4", bonus)
+	assert.Len(t, applied, 1)
+
+	bonus, applied = itemModifiers(items, "skills.stealth", nil, true)
+	assert.Empty(t, bonus)
+	assert.Empty(t, applied)
+}
+
+func TestInventoryWeightAndCapacity(t *testing.T) {
+	items := []*models.SheetItem{
+		newTestItem("Flechas", 20, 0.05, nil, nil),
+		newTestItem("Armadura", 1, 20, nil, nil),
+	}
+	assert.InDelta(t, 21.0, inventoryWeight(items), 0.0001)
+
+	data := newTestSheetData(t, `{"carry": {"capacity": 150}}`)
+	assert.Nil(t, inventoryCapacity(data, nil))
+
+	fixed := 60.0
+	assert.Equal(t, &fixed, inventoryCapacity(data, &models.InventoryRule{Capacity: &fixed}))
+
+	capacity := inventoryCapacity(data, &models.InventoryRule{CapacityPath: "carry.capacity"})
+	if assert.NotNil(t, capacity) {
+		assert.Equal(t, 150.0, *capacity)
+	}
+	assert.Nil(t, inventoryCapacity(data, &models.InventoryRule{CapacityPath: "carry.missing"}))
+}
+
+func TestRollWithSheetAppliesEquippedItems(t *testing.T) {
+	database := newTestDatabase(t)
+	service := newTestSheetService(database)
+	sheet := newTestSheet(t, database, `{"ac": 10}`)
+
+	armor := newTestItem("Cota de malha", 1, 20, []models.ItemStat{{Path: "ac", Set: intPtr(16)}}, nil)
+	ring := newTestItem("Anel", 1, 0, nil, []models.ConditionModifier{{Expression: "+2", AppliesTo: []string{"defense"}}})
+	for _, item := range []*models.SheetItem{armor, ring} {
+		item.Equipped = true
+		require.NoError(t, repositories.NewSheetItemRepository(database.DB).Create(item))
+	}
+
+	// /dice/roll-with-sheet usa os itens equipados como as rolagens da ficha
+	result, err := service.RollWithSheet(models.DiceRollWithSheetRequest{
+		SheetID:    sheet.ID,
+		Expression: "1d20+{ac}",
+		RollLabel:  models.RollLabel{Tags: []string{"defense"}},
+	}, sheet)
+	require.NoError(t, err)
+	assert.Equal(t, "1d20+16+2", result.Expression)
+	require.Len(t, result.ResultDetails.Items, 1)
+	assert.Equal(t, "Anel", result.ResultDetails.Items[0].Name)
+}
@@ -0,0 +1,472 @@
+package services
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/luizdequeiroz/rpg-backend/internal/app/models"
+	"github.com/luizdequeiroz/rpg-backend/internal/app/repositories"
+)
+
+// maxSheetUpdateAttempts é o número de tentativas de uma operação quando outra alteração da ficha chega antes
+const maxSheetUpdateAttempts = 3
+
+// resourceOperation altera os dados da ficha lidos do banco e registra as alterações no evento
+type resourceOperation func(data models.PlayerSheetData, rule models.ResourceRule, defenses *models.DefenseRule, event *models.SheetDeltaEvent) error
+
+// SheetResourceService aplica dano, cura e gasto de recursos aos campos numéricos declarados no template da ficha
+type SheetResourceService struct {
+	sheetRepo    *repositories.PlayerSheetRepository
+	sheetService *PlayerSheetService
+}
+
+// NewSheetResourceService cria nova instância do serviço
+func NewSheetResourceService(
+	sheetRepo *repositories.PlayerSheetRepository,
+	sheetService *PlayerSheetService,
+) *SheetResourceService {
+	return &SheetResourceService{
+		sheetRepo:    sheetRepo,
+		sheetService: sheetService,
+	}
+}
+
+// Damage aplica dano a um recurso da ficha (padrão: hp) com as defesas da ficha contra o tipo de dano.
+// Os pontos temporários do recurso são consumidos antes e o valor não passa do mínimo
+func (s *SheetResourceService) Damage(sheetID string, req models.DamageRequest, userID int) (*models.SheetDeltaEvent, error) {
+	resource := resourceName(req.Resource, models.DefaultHealthResource)
+	return s.apply(sheetID, resource, models.SheetActionDamage, userID,
+		func(data models.PlayerSheetData, rule models.ResourceRule, defenses *models.DefenseRule, event *models.SheetDeltaEvent) error {
+			damageType := strings.ToLower(strings.TrimSpace(req.DamageType))
+			defense, err := damageDefense(data, defenses, damageType)
+			if err != nil {
+				return err
+			}
+
+			event.Amount = req.Amount
+			event.Applied = defendedDamage(req.Amount, defense)
+			event.DamageType = damageType
+			event.Defense = defense
+			event.Changes, err = damageResource(data, rule, event.Applied)
+			return err
+		})
+}
+
+// Heal cura um recurso da ficha (padrão: hp) sem passar do máximo; os pontos temporários não mudam
+func (s *SheetResourceService) Heal(sheetID string, req models.ResourceAmountRequest, userID int) (*models.SheetDeltaEvent, error) {
+	if req.Amount == nil {
+		return nil, errors.New("informe a quantidade")
+	}
+	resource := resourceName(req.Resource, models.DefaultHealthResource)
+	return s.apply(sheetID, resource, models.SheetActionHeal, userID, restoreOperation(req.Amount))
+}
+
+// TempHP concede pontos temporários a um recurso da ficha (padrão: hp). Pontos temporários não se acumulam:
+// fica o maior valor entre o atual e o concedido
+func (s *SheetResourceService) TempHP(sheetID string, req models.ResourceAmountRequest, userID int) (*models.SheetDeltaEvent, error) {
+	if req.Amount == nil {
+		return nil, errors.New("informe a quantidade")
+	}
+	resource := resourceName(req.Resource, models.DefaultHealthResource)
+	return s.apply(sheetID, resource, models.SheetActionTempHP, userID,
+		func(data models.PlayerSheetData, rule models.ResourceRule, _ *models.DefenseRule, event *models.SheetDeltaEvent) error {
+			if rule.TempPath == "" {
+				return fmt.Errorf("o recurso '%s' não tem pontos temporários", event.Resource)
+			}
+			temp, _, err := sheetNumber(data, rule.TempPath)
+			if err != nil {
+				return err
+			}
+
+			event.Amount = *req.Amount
+			event.Applied = *req.Amount
+			event.Changes, err = setResourceField(data, rule.TempPath, temp, max(temp, *req.Amount), event.Changes)
+			return err
+		})
+}
+
+// Spend gasta um recurso da ficha (padrão: 1); falha se o recurso ficaria abaixo do mínimo
+func (s *SheetResourceService) Spend(sheetID string, req models.ResourceAmountRequest, userID int) (*models.SheetDeltaEvent, error) {
+	resource := resourceName(req.Resource, "")
+	if resource == "" {
+		return nil, errors.New("informe o recurso")
+	}
+	amount := 1
+	if req.Amount != nil {
+		amount = *req.Amount
+	}
+	return s.apply(sheetID, resource, models.SheetActionSpend, userID,
+		func(data models.PlayerSheetData, rule models.ResourceRule, _ *models.DefenseRule, event *models.SheetDeltaEvent) error {
+			var err error
+			event.Amount = amount
+			event.Applied = amount
+			event.Changes, err = spendResource(data, rule, amount)
+			return err
+		})
+}
+
+// Restore recupera um recurso da ficha sem passar do máximo; sem quantidade, recupera até o máximo
+func (s *SheetResourceService) Restore(sheetID string, req models.ResourceAmountRequest, userID int) (*models.SheetDeltaEvent, error) {
+	resource := resourceName(req.Resource, "")
+	if resource == "" {
+		return nil, errors.New("informe o recurso")
+	}
+	return s.apply(sheetID, resource, models.SheetActionRestore, userID, restoreOperation(req.Amount))
+}
+
+// apply executa a operação sobre os dados atuais da ficha e grava o resultado apenas se a ficha não mudou desde a
+// leitura, repetindo a operação quando outra alteração chega antes
+func (s *SheetResourceService) apply(sheetID, resource, action string, userID int, operation resourceOperation) (*models.SheetDeltaEvent, error) {
+	sheet, err := s.sheetForResource(sheetID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	rules, err := s.sheetService.SheetRules(sheet.TemplateID)
+	if err != nil {
+		return nil, err
+	}
+	rule, ok := rules.Resources[resource]
+	if !ok {
+		return nil, fmt.Errorf("recurso '%s' não declarado no template da ficha", resource)
+	}
+
+	for attempt := 0; attempt < maxSheetUpdateAttempts; attempt++ {
+		record, err := s.sheetRepo.GetByID(sheet.ID)
+		if err != nil {
+			return nil, fmt.Errorf("erro ao buscar ficha: %w", err)
+		}
+		if record == nil {
+			return nil, errors.New("ficha não encontrada")
+		}
+
+		var data models.PlayerSheetData
+		if err := json.Unmarshal([]byte(record.Data), &data); err != nil {
+			return nil, fmt.Errorf("erro ao decodificar dados da ficha: %w", err)
+		}
+		if data == nil {
+			data = make(models.PlayerSheetData)
+		}
+
+		event := &models.SheetDeltaEvent{
+			SheetID:  record.ID,
+			TableID:  record.TableID,
+			Action:   action,
+			Resource: resource,
+			Changes:  []models.SheetFieldChange{},
+			UserID:   userID,
+		}
+		if err := operation(data, rule, rules.Defenses, event); err != nil {
+			return nil, err
+		}
+		if len(event.Changes) == 0 {
+			return event, nil
+		}
+
+		dataJSON, err := json.Marshal(data)
+		if err != nil {
+			return nil, fmt.Errorf("erro ao serializar dados da ficha: %w", err)
+		}
+		saved, err := s.sheetRepo.UpdateData(record.ID, record.Data, string(dataJSON), time.Now())
+		if err != nil {
+			return nil, fmt.Errorf("erro ao atualizar ficha: %w", err)
+		}
+		if saved {
+			return event, nil
+		}
+	}
+
+	return nil, errors.New("a ficha foi alterada por outra ação; tente novamente")
+}
+
+// sheetForResource busca a ficha e verifica se o usuário pode alterar os recursos dela: o dono ou o mestre da mesa
+func (s *SheetResourceService) sheetForResource(sheetID string, userID int) (*models.PlayerSheetResponse, error) {
+	sheet, err := s.sheetService.sheetForRoll(sheetID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if sheet.OwnerID == userID {
+		return sheet, nil
+	}
+
+	gmID, err := s.sheetService.TableOwner(sheet.TableID)
+	if err != nil {
+		return nil, err
+	}
+	if userID != gmID {
+		return nil, errors.New("apenas o dono da ficha ou o mestre da mesa pode alterar os recursos")
+	}
+	return sheet, nil
+}
+
+// resourceName normaliza o nome do recurso pedido, usando o padrão quando vazio
+func resourceName(resource, fallback string) string {
+	resource = strings.TrimSpace(resource)
+	if resource == "" {
+		return fallback
+	}
+	return resource
+}
+
+// restoreOperation recupera a quantidade informada do recurso, ou até o máximo quando ela não é informada
+func restoreOperation(amount *int) resourceOperation {
+	return func(data models.PlayerSheetData, rule models.ResourceRule, _ *models.DefenseRule, event *models.SheetDeltaEvent) error {
+		changes, err := restoreResource(data, rule, amount)
+		if err != nil {
+			return err
+		}
+		if amount != nil {
+			event.Amount = *amount
+			event.Applied = *amount
+		} else if len(changes) > 0 {
+			event.Amount = changes[0].Delta
+			event.Applied = changes[0].Delta
+		}
+		event.Changes = changes
+		return nil
+	}
+}
+
+// damageResource subtrai o dano primeiro dos pontos temporários e depois do recurso, sem passar do mínimo
+func damageResource(data models.PlayerSheetData, rule models.ResourceRule, amount int) ([]models.SheetFieldChange, error) {
+	changes := []models.SheetFieldChange{}
+
+	if rule.TempPath != "" && amount > 0 {
+		temp, _, err := sheetNumber(data, rule.TempPath)
+		if err != nil {
+			return nil, err
+		}
+		if absorbed := min(max(temp, 0), amount); absorbed > 0 {
+			if changes, err = setResourceField(data, rule.TempPath, temp, temp-absorbed, changes); err != nil {
+				return nil, err
+			}
+			amount -= absorbed
+		}
+	}
+	if amount == 0 {
+		return changes, nil
+	}
+
+	current, err := resourceValue(data, rule)
+	if err != nil {
+		return nil, err
+	}
+	return setResourceField(data, rule.Path, current, max(current-amount, rule.Min), changes)
+}
+
+// restoreResource soma a quantidade ao recurso sem passar do máximo (um valor já acima dele é mantido);
+// sem quantidade, recupera até o máximo
+func restoreResource(data models.PlayerSheetData, rule models.ResourceRule, amount *int) ([]models.SheetFieldChange, error) {
+	current, err := resourceValue(data, rule)
+	if err != nil {
+		return nil, err
+	}
+	maximum, err := resourceMax(data, rule)
+	if err != nil {
+		return nil, err
+	}
+
+	var next int
+	switch {
+	case amount != nil && maximum != nil:
+		next = max(current, min(current+*amount, *maximum))
+	case amount != nil:
+		next = current + *amount
+	case maximum != nil:
+		next = max(current, *maximum)
+	default:
+		return nil, errors.New("o recurso não tem máximo; informe a quantidade")
+	}
+	return setResourceField(data, rule.Path, current, next, []models.SheetFieldChange{})
+}
+
+// spendResource subtrai a quantidade do recurso; falha se ele ficaria abaixo do mínimo
+func spendResource(data models.PlayerSheetData, rule models.ResourceRule, amount int) ([]models.SheetFieldChange, error) {
+	current, err := resourceValue(data, rule)
+	if err != nil {
+		return nil, err
+	}
+	if current-amount < rule.Min {
+		return nil, errors.New("recurso insuficiente")
+	}
+	return setResourceField(data, rule.Path, current, current-amount, []models.SheetFieldChange{})
+}
+
+// damageDefense retorna a defesa da ficha contra o tipo de dano. Imunidade prevalece; resistência e
+// vulnerabilidade ao mesmo tipo se anulam
+func damageDefense(data models.PlayerSheetData, defenses *models.DefenseRule, damageType string) (string, error) {
+	if defenses == nil || damageType == "" {
+		return "", nil
+	}
+
+	has := func(path string) (bool, error) {
+		if path == "" {
+			return false, nil
+		}
+		types, err := sheetStrings(data, path)
+		if err != nil {
+			return false, err
+		}
+		for _, t := range types {
+			if strings.EqualFold(strings.TrimSpace(t), damageType) {
+				return true, nil
+			}
+		}
+		return false, nil
+	}
+
+	immune, err := has(defenses.Immunities)
+	if err != nil {
+		return "", err
+	}
+	if immune {
+		return models.DamageDefenseImmunity, nil
+	}
+	resistant, err := has(defenses.Resistances)
+	if err != nil {
+		return "", err
+	}
+	vulnerable, err := has(defenses.Vulnerabilities)
+	if err != nil {
+		return "", err
+	}
+
+	switch {
+	case resistant && !vulnerable:
+		return models.DamageDefenseResistance, nil
+	case vulnerable && !resistant:
+		return models.DamageDefenseVulnerability, nil
+	}
+	return "", nil
+}
+
+// defendedDamage aplica a defesa ao dano: imunidade anula, resistência divide por dois e vulnerabilidade dobra
+func defendedDamage(amount int, defense string) int {
+	switch defense {
+	case models.DamageDefenseImmunity:
+		return 0
+	case models.DamageDefenseResistance:
+		return amount / 2
+	case models.DamageDefenseVulnerability:
+		return amount * 2
+	}
+	return amount
+}
+
+// resourceValue retorna o valor atual do recurso, que deve existir na ficha
+func resourceValue(data models.PlayerSheetData, rule models.ResourceRule) (int, error) {
+	value, exists, err := sheetNumber(data, rule.Path)
+	if err != nil {
+		return 0, err
+	}
+	if !exists {
+		return 0, fmt.Errorf("campo '%s' não encontrado na ficha", rule.Path)
+	}
+	return value, nil
+}
+
+// resourceMax retorna o máximo do recurso: o fixo, o do campo max_path ou nil quando não há máximo
+func resourceMax(data models.PlayerSheetData, rule models.ResourceRule) (*int, error) {
+	if rule.Max != nil || rule.MaxPath == "" {
+		return rule.Max, nil
+	}
+	value, exists, err := sheetNumber(data, rule.MaxPath)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, fmt.Errorf("campo '%s' não encontrado na ficha", rule.MaxPath)
+	}
+	return &value, nil
+}
+
+// setResourceField grava o novo valor do campo e registra a alteração; valores iguais não são alterações
+func setResourceField(data models.PlayerSheetData, path string, old, value int, changes []models.SheetFieldChange) ([]models.SheetFieldChange, error) {
+	if old == value {
+		return changes, nil
+	}
+	if err := setSheetNumber(data, path, value); err != nil {
+		return nil, err
+	}
+	return append(changes, models.SheetFieldChange{Path: path, Old: old, New: value, Delta: value - old}), nil
+}
+
+// sheetValue busca o valor de um campo com notação de ponto (e.g., "hp.current"); campos ausentes retornam false
+func sheetValue(data models.PlayerSheetData, path string) (interface{}, bool, error) {
+	parts := strings.Split(path, ".")
+	current := map[string]interface{}(data)
+	for i, part := range parts {
+		value, exists := current[part]
+		if !exists || value == nil {
+			return nil, false, nil
+		}
+		if i == len(parts)-1 {
+			return value, true, nil
+		}
+		next, ok := value.(map[string]interface{})
+		if !ok {
+			return nil, false, fmt.Errorf("campo '%s' não é um objeto", strings.Join(parts[:i+1], "."))
+		}
+		current = next
+	}
+	return nil, false, nil
+}
+
+// sheetNumber busca um campo numérico inteiro da ficha; campos ausentes valem 0
+func sheetNumber(data models.PlayerSheetData, path string) (int, bool, error) {
+	value, exists, err := sheetValue(data, path)
+	if err != nil || !exists {
+		return 0, false, err
+	}
+	number, ok := value.(float64)
+	if !ok || number != float64(int(number)) {
+		return 0, false, fmt.Errorf("campo '%s' não é um número inteiro", path)
+	}
+	return int(number), true, nil
+}
+
+// sheetStrings busca um campo da ficha com uma lista de textos; campos ausentes retornam lista vazia
+func sheetStrings(data models.PlayerSheetData, path string) ([]string, error) {
+	value, exists, err := sheetValue(data, path)
+	if err != nil || !exists {
+		return nil, err
+	}
+	items, ok := value.([]interface{})
+	if !ok {
+		return nil, fmt.Errorf("campo '%s' não é uma lista", path)
+	}
+
+	var values []string
+	for _, item := range items {
+		if text, ok := item.(string); ok {
+			values = append(values, text)
+		}
+	}
+	return values, nil
+}
+
+// setSheetNumber grava um campo numérico da ficha, criando os objetos intermediários ausentes.
+// O valor é gravado como float64, o mesmo tipo dos números lidos do JSON
+func setSheetNumber(data models.PlayerSheetData, path string, value int) error {
+	parts := strings.Split(path, ".")
+	current := map[string]interface{}(data)
+	for i, part := range parts[:len(parts)-1] {
+		next, exists := current[part]
+		if !exists || next == nil {
+			created := make(map[string]interface{})
+			current[part] = created
+			current = created
+			continue
+		}
+		nextMap, ok := next.(map[string]interface{})
+		if !ok {
+			return fmt.Errorf("campo '%s' não é um objeto", strings.Join(parts[:i+1], "."))
+		}
+		current = nextMap
+	}
+	current[parts[len(parts)-1]] = float64(value)
+	return nil
+}
@@ -0,0 +1,141 @@
+package services
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/luizdequeiroz/rpg-backend/internal/app/models"
+)
+
+func newTestSheetData(t *testing.T, data string) models.PlayerSheetData {
+	var sheetData models.PlayerSheetData
+	assert.NoError(t, json.Unmarshal([]byte(data), &sheetData))
+	return sheetData
+}
+
+var testHealthRule = models.ResourceRule{Path: "hp.current", MaxPath: "hp.max", TempPath: "hp.temp"}
+
+func TestDamageResource(t *testing.T) {
+	data := newTestSheetData(t, `{"hp": {"current": 30, "max": 30, "temp": 5}}`)
+
+	changes, err := damageResource(data, testHealthRule, 12)
+	assert.NoError(t, err)
+	assert.Equal(t, []models.SheetFieldChange{
+		{Path: "hp.temp", Old: 5, New: 0, Delta: -5},
+		{Path: "hp.current", Old: 30, New: 23, Delta: -7},
+	}, changes)
+
+	changes, err = damageResource(data, testHealthRule, 100)
+	assert.NoError(t, err)
+	assert.Equal(t, []models.SheetFieldChange{{Path: "hp.current", Old: 23, New: 0, Delta: -23}}, changes)
+
+	changes, err = damageResource(data, testHealthRule, 5)
+	assert.NoError(t, err)
+	assert.Empty(t, changes)
+
+	negative := models.ResourceRule{Path: "hp.current", Min: -10}
+	changes, err = damageResource(data, negative, 20)
+	assert.NoError(t, err)
+	assert.Equal(t, -10, changes[0].New)
+
+	_, err = damageResource(newTestSheetData(t, `{"hp": {"temp": 3}}`), testHealthRule, 5)
+	assert.Error(t, err)
+	_, err = damageResource(newTestSheetData(t, `{"hp": {"current": "full"}}`), testHealthRule, 5)
+	assert.Error(t, err)
+}
+
+func TestRestoreResource(t *testing.T) {
+	data := newTestSheetData(t, `{"hp": {"current": 20, "max": 30}, "ki": 1}`)
+	five, fifty := 5, 50
+
+	changes, err := restoreResource(data, testHealthRule, &five)
+	assert.NoError(t, err)
+	assert.Equal(t, []models.SheetFieldChange{{Path: "hp.current", Old: 20, New: 25, Delta: 5}}, changes)
+
+	changes, err = restoreResource(data, testHealthRule, &fifty)
+	assert.NoError(t, err)
+	assert.Equal(t, 30, changes[0].New)
+
+	changes, err = restoreResource(data, testHealthRule, &five)
+	assert.NoError(t, err)
+	assert.Empty(t, changes)
+
+	maxKi := 4
+	ki := models.ResourceRule{Path: "ki", Max: &maxKi}
+	changes, err = restoreResource(data, ki, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, []models.SheetFieldChange{{Path: "ki", Old: 1, New: 4, Delta: 3}}, changes)
+
+	_, err = restoreResource(data, models.ResourceRule{Path: "ki"}, nil)
+	assert.Error(t, err)
+	_, err = restoreResource(data, models.ResourceRule{Path: "ki", MaxPath: "ki_max"}, &five)
+	assert.Error(t, err)
+}
+
+func TestSpendResource(t *testing.T) {
+	data := newTestSheetData(t, `{"spells": {"slots_1": 2}}`)
+	slots := models.ResourceRule{Path: "spells.slots_1"}
+
+	changes, err := spendResource(data, slots, 2)
+	assert.NoError(t, err)
+	assert.Equal(t, []models.SheetFieldChange{{Path: "spells.slots_1", Old: 2, New: 0, Delta: -2}}, changes)
+
+	_, err = spendResource(data, slots, 1)
+	assert.EqualError(t, err, "recurso insuficiente")
+	assert.Equal(t, float64(0), data["spells"].(map[string]interface{})["slots_1"])
+}
+
+func TestDamageDefense(t *testing.T) {
+	data := newTestSheetData(t, `{"defenses": {"resistances": ["Fire", "cold"], "immunities": ["poison"], "vulnerabilities": ["cold"]}}`)
+	defenses := &models.DefenseRule{
+		Resistances:     "defenses.resistances",
+		Immunities:      "defenses.immunities",
+		Vulnerabilities: "defenses.vulnerabilities",
+	}
+
+	for damageType, expected := range map[string]string{
+		"fire":     models.DamageDefenseResistance,
+		"poison":   models.DamageDefenseImmunity,
+		"cold":     "",
+		"slashing": "",
+		"":         "",
+	} {
+		defense, err := damageDefense(data, defenses, damageType)
+		assert.NoError(t, err)
+		assert.Equal(t, expected, defense, damageType)
+	}
+
+	defense, err := damageDefense(data, nil, "fire")
+	assert.NoError(t, err)
+	assert.Empty(t, defense)
+
+	_, err = damageDefense(newTestSheetData(t, `{"defenses": {"resistances": "fire"}}`), defenses, "fire")
+	assert.Error(t, err)
+
+	assert.Equal(t, 0, defendedDamage(13, models.DamageDefenseImmunity))
+	assert.Equal(t, 6, defendedDamage(13, models.DamageDefenseResistance))
+	assert.Equal(t, 26, defendedDamage(13, models.DamageDefenseVulnerability))
+	assert.Equal(t, 13, defendedDamage(13, ""))
+}
+
+func TestSetSheetNumber(t *testing.T) {
+	data := newTestSheetData(t, `{"hp": {"current": 10}, "name": "Aria"}`)
+
+	assert.NoError(t, setSheetNumber(data, "hp.temp", 4))
+	assert.NoError(t, setSheetNumber(data, "resources.ki.current", 3))
+	assert.Error(t, setSheetNumber(data, "name.first", 1))
+
+	value, exists, err := sheetNumber(data, "resources.ki.current")
+	assert.NoError(t, err)
+	assert.True(t, exists)
+	assert.Equal(t, 3, value)
+
+	_, exists, err = sheetNumber(data, "hp.max")
+	assert.NoError(t, err)
+	assert.False(t, exists)
+
+	_, _, err = sheetNumber(newTestSheetData(t, `{"hp": 2.5}`), "hp")
+	assert.Error(t, err)
+}
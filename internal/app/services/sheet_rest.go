@@ -0,0 +1,237 @@
+package services
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+
+	"github.com/luizdequeiroz/rpg-backend/internal/app/models"
+	"github.com/luizdequeiroz/rpg-backend/internal/app/repositories"
+)
+
+// maxRestHistory é o número de descansos listados no histórico de uma ficha ou mesa
+const maxRestHistory = 50
+
+// SheetRestService renova os recursos das fichas nos descansos, conforme a regra refresh declarada no template
+type SheetRestService struct {
+	sheetRepo       *repositories.PlayerSheetRepository
+	restRepo        *repositories.SheetRestRepository
+	sheetService    *PlayerSheetService
+	resourceService *SheetResourceService
+}
+
+// NewSheetRestService cria nova instância do serviço
+func NewSheetRestService(
+	sheetRepo *repositories.PlayerSheetRepository,
+	restRepo *repositories.SheetRestRepository,
+	sheetService *PlayerSheetService,
+	resourceService *SheetResourceService,
+) *SheetRestService {
+	return &SheetRestService{
+		sheetRepo:       sheetRepo,
+		restRepo:        restRepo,
+		sheetService:    sheetService,
+		resourceService: resourceService,
+	}
+}
+
+// RestSheet renova os recursos da ficha no descanso; apenas o dono da ficha ou o mestre da mesa pode descansar
+func (s *SheetRestService) RestSheet(sheetID string, req models.RestRequest, userID int) (*models.RestEvent, error) {
+	sheet, err := s.resourceService.sheetForResource(sheetID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.rest(sheet.TableID, req.Type, userID, func() ([]*models.PlayerSheet, error) {
+		record, err := s.sheetRepo.GetByID(sheet.ID)
+		if err != nil {
+			return nil, fmt.Errorf("erro ao buscar ficha: %w", err)
+		}
+		if record == nil {
+			return nil, errors.New("ficha não encontrada")
+		}
+		return []*models.PlayerSheet{record}, nil
+	})
+}
+
+// RestTable renova de uma vez os recursos de todas as fichas da mesa no descanso; apenas o mestre pode conceder
+func (s *SheetRestService) RestTable(tableID string, req models.RestRequest, userID int) (*models.RestEvent, error) {
+	gmID, err := s.sheetService.TableOwner(tableID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, errors.New("mesa não encontrada")
+	}
+	if err != nil {
+		return nil, err
+	}
+	if userID != gmID {
+		return nil, errors.New("apenas o mestre da mesa pode conceder descanso à mesa")
+	}
+
+	return s.rest(tableID, req.Type, userID, func() ([]*models.PlayerSheet, error) {
+		sheets, err := s.sheetRepo.GetAllByTableID(tableID)
+		if err != nil {
+			return nil, fmt.Errorf("erro ao buscar fichas da mesa: %w", err)
+		}
+		if len(sheets) == 0 {
+			return nil, errors.New("a mesa não tem fichas")
+		}
+		return sheets, nil
+	})
+}
+
+// SheetHistory lista os descansos mais recentes da ficha
+func (s *SheetRestService) SheetHistory(sheetID string, userID int) ([]*models.SheetRestResponse, error) {
+	sheet, err := s.sheetService.sheetForRoll(sheetID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	rests, err := s.restRepo.GetBySheetID(sheet.ID, maxRestHistory)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao buscar descansos da ficha: %w", err)
+	}
+	return models.RestResponses(rests), nil
+}
+
+// TableHistory lista os descansos mais recentes das fichas da mesa
+func (s *SheetRestService) TableHistory(tableID string, userID int) ([]*models.SheetRestResponse, error) {
+	hasAccess, err := s.sheetService.checkTableAccess(tableID, userID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, errors.New("mesa não encontrada")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("erro ao verificar acesso: %w", err)
+	}
+	if !hasAccess {
+		return nil, errors.New("acesso negado à mesa")
+	}
+
+	rests, err := s.restRepo.GetByTableID(tableID, maxRestHistory)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao buscar descansos da mesa: %w", err)
+	}
+	return models.RestResponses(rests), nil
+}
+
+// rest renova os recursos das fichas carregadas e grava os dados e o histórico de todas de uma vez, apenas se
+// nenhuma ficha mudou desde a leitura; repete o descanso quando outra alteração chega antes
+func (s *SheetRestService) rest(tableID, restType string, userID int, load func() ([]*models.PlayerSheet, error)) (*models.RestEvent, error) {
+	rulesByTemplate := make(map[int]*models.SheetRules)
+
+	for attempt := 0; attempt < maxSheetUpdateAttempts; attempt++ {
+		sheets, err := load()
+		if err != nil {
+			return nil, err
+		}
+
+		rests := make([]*models.SheetRest, 0, len(sheets))
+		var updates []repositories.SheetDataUpdate
+		for _, sheet := range sheets {
+			rules, ok := rulesByTemplate[sheet.TemplateID]
+			if !ok {
+				if rules, err = s.sheetService.SheetRules(sheet.TemplateID); err != nil {
+					return nil, err
+				}
+				rulesByTemplate[sheet.TemplateID] = rules
+			}
+
+			var data models.PlayerSheetData
+			if err := json.Unmarshal([]byte(sheet.Data), &data); err != nil {
+				return nil, fmt.Errorf("erro ao decodificar dados da ficha: %w", err)
+			}
+			if data == nil {
+				data = make(models.PlayerSheetData)
+			}
+
+			changes, err := restResources(data, rules, restType)
+			if err != nil {
+				return nil, fmt.Errorf("ficha '%s': %w", sheet.Name, err)
+			}
+
+			rest := models.NewSheetRest(sheet.ID, sheet.TableID, restType, userID)
+			rest.SetChanges(changes)
+			rests = append(rests, rest)
+			if len(changes) == 0 {
+				continue
+			}
+
+			dataJSON, err := json.Marshal(data)
+			if err != nil {
+				return nil, fmt.Errorf("erro ao serializar dados da ficha: %w", err)
+			}
+			updates = append(updates, repositories.SheetDataUpdate{
+				SheetID:      sheet.ID,
+				PreviousData: sheet.Data,
+				Data:         string(dataJSON),
+			})
+		}
+
+		saved, err := s.restRepo.Record(rests, updates)
+		if err != nil {
+			return nil, fmt.Errorf("erro ao registrar descanso: %w", err)
+		}
+		if saved {
+			return &models.RestEvent{
+				TableID:  tableID,
+				RestType: restType,
+				Rests:    models.RestResponses(rests),
+				UserID:   userID,
+			}, nil
+		}
+	}
+
+	return nil, errors.New("a ficha foi alterada por outra ação; tente novamente")
+}
+
+// restResources recupera, em ordem de nome, os recursos que o descanso renova. Recursos cujo campo não existe na
+// ficha são ignorados
+func restResources(data models.PlayerSheetData, rules *models.SheetRules, restType string) ([]models.RestChange, error) {
+	changes := []models.RestChange{}
+	for _, name := range slices.Sorted(maps.Keys(rules.Resources)) {
+		rule := rules.Resources[name]
+		if !models.RestRefreshes(restType, rule.Refresh) {
+			continue
+		}
+		_, exists, err := sheetNumber(data, rule.Path)
+		if err != nil {
+			return nil, fmt.Errorf("recurso '%s': %w", name, err)
+		}
+		if !exists {
+			continue
+		}
+
+		amount, err := refreshAmount(data, rule)
+		if err != nil {
+			return nil, fmt.Errorf("recurso '%s': %w", name, err)
+		}
+		restored, err := restoreResource(data, rule, amount)
+		if err != nil {
+			return nil, fmt.Errorf("recurso '%s': %w", name, err)
+		}
+		for _, change := range restored {
+			changes = append(changes, models.RestChange{Resource: name, SheetFieldChange: change})
+		}
+	}
+	return changes, nil
+}
+
+// refreshAmount retorna a quantidade recuperada pelo descanso: a fixa, a porcentagem do máximo (no mínimo 1) ou
+// nil para recuperar até o máximo
+func refreshAmount(data models.PlayerSheetData, rule models.ResourceRule) (*int, error) {
+	if rule.RefreshAmount != nil || rule.RefreshPercent == nil {
+		return rule.RefreshAmount, nil
+	}
+
+	maximum, err := resourceMax(data, rule)
+	if err != nil {
+		return nil, err
+	}
+	if maximum == nil {
+		return nil, errors.New("refresh_percent exige um máximo")
+	}
+	amount := max(*maximum**rule.RefreshPercent/100, 1)
+	return &amount, nil
+}
@@ -0,0 +1,89 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/luizdequeiroz/rpg-backend/internal/app/models"
+)
+
+func newTestRestRules() *models.SheetRules {
+	return &models.SheetRules{Resources: map[string]models.ResourceRule{
+		"hp":        {Path: "hp.current", MaxPath: "hp.max", Refresh: models.RestLong},
+		"ki":        {Path: "ki.current", MaxPath: "ki.max", Refresh: models.RestShort},
+		"hit_dice":  {Path: "hit_dice.current", MaxPath: "hit_dice.max", Refresh: models.RestLong, RefreshPercent: intPtr(50)},
+		"luck":      {Path: "luck", Max: intPtr(3), Refresh: models.RestDaily},
+		"inspire":   {Path: "inspire", Max: intPtr(5), Refresh: models.RestShort, RefreshAmount: intPtr(1)},
+		"potions":   {Path: "potions", Max: intPtr(2)},
+		"rage":      {Path: "rage", Max: intPtr(3), Refresh: models.RestLong},
+		"undefined": {Path: "missing.field", Max: intPtr(3), Refresh: models.RestShort},
+	}}
+}
+
+func TestRestResources(t *testing.T) {
+	data := newTestSheetData(t, `{
+		"hp": {"current": 4, "max": 30},
+		"ki": {"current": 1, "max": 5},
+		"hit_dice": {"current": 1, "max": 5},
+		"luck": 0, "inspire": 2, "potions": 0, "rage": 3
+	}`)
+
+	changes, err := restResources(data, newTestRestRules(), models.RestShort)
+	assert.NoError(t, err)
+	assert.Equal(t, []models.RestChange{
+		{Resource: "inspire", SheetFieldChange: models.SheetFieldChange{Path: "inspire", Old: 2, New: 3, Delta: 1}},
+		{Resource: "ki", SheetFieldChange: models.SheetFieldChange{Path: "ki.current", Old: 1, New: 5, Delta: 4}},
+	}, changes)
+
+	changes, err = restResources(data, newTestRestRules(), models.RestLong)
+	assert.NoError(t, err)
+	assert.Equal(t, []models.RestChange{
+		{Resource: "hit_dice", SheetFieldChange: models.SheetFieldChange{Path: "hit_dice.current", Old: 1, New: 3, Delta: 2}},
+		{Resource: "hp", SheetFieldChange: models.SheetFieldChange{Path: "hp.current", Old: 4, New: 30, Delta: 26}},
+		{Resource: "inspire", SheetFieldChange: models.SheetFieldChange{Path: "inspire", Old: 3, New: 4, Delta: 1}},
+	}, changes, "o descanso longo também renova os recursos do curto")
+
+	changes, err = restResources(data, newTestRestRules(), models.RestDaily)
+	assert.NoError(t, err)
+	assert.Equal(t, []models.RestChange{
+		{Resource: "luck", SheetFieldChange: models.SheetFieldChange{Path: "luck", Old: 0, New: 3, Delta: 3}},
+	}, changes)
+
+	potions, _, _ := sheetNumber(data, "potions")
+	assert.Equal(t, 0, potions, "recursos manuais não mudam nos descansos")
+
+	invalid := newTestSheetData(t, `{"ki": {"current": "muito", "max": 5}}`)
+	_, err = restResources(invalid, newTestRestRules(), models.RestShort)
+	assert.Error(t, err)
+}
+
+func TestRefreshAmount(t *testing.T) {
+	data := newTestSheetData(t, `{"hit_dice": {"current": 0, "max": 1}}`)
+
+	amount, err := refreshAmount(data, models.ResourceRule{Path: "hit_dice.current", MaxPath: "hit_dice.max", RefreshPercent: intPtr(50)})
+	assert.NoError(t, err)
+	assert.Equal(t, 1, *amount, "a porcentagem recupera no mínimo 1")
+
+	amount, err = refreshAmount(data, models.ResourceRule{Path: "hit_dice.current", RefreshAmount: intPtr(2)})
+	assert.NoError(t, err)
+	assert.Equal(t, 2, *amount)
+
+	amount, err = refreshAmount(data, models.ResourceRule{Path: "hit_dice.current", MaxPath: "hit_dice.max"})
+	assert.NoError(t, err)
+	assert.Nil(t, amount)
+}
+
+func TestValidateResourceRefresh(t *testing.T) {
+	assert.Empty(t, validateResourceRefresh(models.ResourceRule{Path: "ki", Max: intPtr(5), Refresh: models.RestShort}))
+	assert.Empty(t, validateResourceRefresh(models.ResourceRule{Path: "gold", Refresh: models.RefreshManual}))
+	assert.Empty(t, validateResourceRefresh(models.ResourceRule{Path: "luck", Refresh: models.RestDaily, RefreshAmount: intPtr(1)}))
+
+	assert.NotEmpty(t, validateResourceRefresh(models.ResourceRule{Path: "ki", Max: intPtr(5), Refresh: "weekly"}))
+	assert.NotEmpty(t, validateResourceRefresh(models.ResourceRule{Path: "ki", Max: intPtr(5), RefreshAmount: intPtr(1)}))
+	assert.NotEmpty(t, validateResourceRefresh(models.ResourceRule{Path: "ki", Max: intPtr(5), Refresh: models.RestShort, RefreshAmount: intPtr(1), RefreshPercent: intPtr(50)}))
+	assert.NotEmpty(t, validateResourceRefresh(models.ResourceRule{Path: "ki", Max: intPtr(5), Refresh: models.RestShort, RefreshAmount: intPtr(0)}))
+	assert.NotEmpty(t, validateResourceRefresh(models.ResourceRule{Path: "ki", Max: intPtr(5), Refresh: models.RestShort, RefreshPercent: intPtr(150)}))
+	assert.NotEmpty(t, validateResourceRefresh(models.ResourceRule{Path: "ki", Refresh: models.RestShort, RefreshPercent: intPtr(50)}))
+	assert.NotEmpty(t, validateResourceRefresh(models.ResourceRule{Path: "ki", Refresh: models.RestLong}))
+}
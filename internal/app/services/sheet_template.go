@@ -3,11 +3,15 @@ package services
 import (
 	"encoding/json"
 	"errors"
+	"maps"
+	"slices"
 	"strconv"
+	"strings"
 
 	"github.com/luizdequeiroz/rpg-backend/internal/app/models"
 	"github.com/luizdequeiroz/rpg-backend/internal/app/repositories"
 	"github.com/luizdequeiroz/rpg-backend/pkg/db"
+	"github.com/luizdequeiroz/rpg-backend/pkg/roll"
 )
 
 var (
@@ -165,6 +169,9 @@ func (s *SheetTemplateService) ValidateCreateRequest(req models.CreateSheetTempl
 			Field:   "definition",
 			Message: "Definition deve ter estrutura válida com sections",
 		})
+	} else {
+		errors = append(errors, s.validateRollRules(req.Definition)...)
+		errors = append(errors, s.validateSheetRules(req.Definition)...)
 	}
 
 	if len(req.Description) > 500 {
@@ -203,11 +210,16 @@ func (s *SheetTemplateService) ValidateUpdateRequest(req models.UpdateSheetTempl
 		}
 	}
 
-	if req.Definition != nil && !models.IsValidDefinition(req.Definition) {
-		errors = append(errors, models.SheetTemplateValidationError{
-			Field:   "definition",
-			Message: "Definition deve ter estrutura válida com sections",
-		})
+	if req.Definition != nil {
+		if !models.IsValidDefinition(req.Definition) {
+			errors = append(errors, models.SheetTemplateValidationError{
+				Field:   "definition",
+				Message: "Definition deve ter estrutura válida com sections",
+			})
+		} else {
+			errors = append(errors, s.validateRollRules(req.Definition)...)
+			errors = append(errors, s.validateSheetRules(req.Definition)...)
+		}
 	}
 
 	if req.Description != nil && len(*req.Description) > 500 {
@@ -220,3 +232,164 @@ func (s *SheetTemplateService) ValidateUpdateRequest(req models.UpdateSheetTempl
 
 	return errors
 }
+
+// validateRollRules valida as regras de rolagem declaradas na definition (e.g., "custom_dice", "critical", "fumble", "outcomes")
+func (s *SheetTemplateService) validateRollRules(definition interface{}) []models.SheetTemplateValidationError {
+	var errors []models.SheetTemplateValidationError
+
+	definitionJSON, err := models.ConvertDefinitionToString(definition)
+	if err != nil {
+		return errors
+	}
+
+	rules, err := models.ParseRollRules(definitionJSON)
+	if err != nil {
+		errors = append(errors, models.SheetTemplateValidationError{
+			Field:   "definition",
+			Message: "Regras de rolagem inválidas: custom_dice deve ser uma lista de {name, faces} critical/fumble objetos {dice, min, max} e outcomes um mapa de tabelas {basis, bands}",
+		})
+		return errors
+	}
+
+	if err := roll.ValidateCustomDice(rules.CustomDice); err != nil {
+		errors = append(errors, models.SheetTemplateValidationError{
+			Field:   "definition.custom_dice",
+			Message: err.Error(),
+		})
+	}
+	if err := roll.ValidateCritRule(rules.Critical); err != nil {
+		errors = append(errors, models.SheetTemplateValidationError{
+			Field:   "definition.critical",
+			Message: err.Error(),
+		})
+	}
+	if err := roll.ValidateCritRule(rules.Fumble); err != nil {
+		errors = append(errors, models.SheetTemplateValidationError{
+			Field:   "definition.fumble",
+			Message: err.Error(),
+		})
+	}
+	if err := roll.ValidateOutcomeTables(rules.Outcomes); err != nil {
+		errors = append(errors, models.SheetTemplateValidationError{
+			Field:   "definition.outcomes",
+			Message: err.Error(),
+		})
+	}
+
+	return errors
+}
+
+// validateSheetRules valida os recursos, as defesas e a capacidade de carga declarados na definition
+// (e.g., "resources", "defenses", "inventory")
+func (s *SheetTemplateService) validateSheetRules(definition interface{}) []models.SheetTemplateValidationError {
+	var errors []models.SheetTemplateValidationError
+
+	definitionJSON, err := models.ConvertDefinitionToString(definition)
+	if err != nil {
+		return errors
+	}
+
+	rules, err := models.ParseSheetRules(definitionJSON)
+	if err != nil {
+		errors = append(errors, models.SheetTemplateValidationError{
+			Field:   "definition",
+			Message: "Recursos inválidos: resources deve ser um mapa de {path, min, max, max_path, temp_path, refresh, refresh_amount, refresh_percent}, defenses um objeto {resistances, immunities, vulnerabilities} e inventory um objeto {capacity, capacity_path}",
+		})
+		return errors
+	}
+
+	for _, name := range slices.Sorted(maps.Keys(rules.Resources)) {
+		rule := rules.Resources[name]
+		field := "definition.resources." + name
+		var message string
+		switch {
+		case name == "" || len(name) > 50:
+			message = "O nome do recurso deve ter entre 1 e 50 caracteres"
+		case !isSheetPath(rule.Path):
+			message = "path é obrigatório e deve usar notação de ponto (e.g., \"hp.current\")"
+		case rule.MaxPath != "" && !isSheetPath(rule.MaxPath):
+			message = "max_path deve usar notação de ponto (e.g., \"hp.max\")"
+		case rule.TempPath != "" && !isSheetPath(rule.TempPath):
+			message = "temp_path deve usar notação de ponto (e.g., \"hp.temp\")"
+		case rule.Max != nil && rule.MaxPath != "":
+			message = "Use max ou max_path, não ambos"
+		case rule.Max != nil && *rule.Max < rule.Min:
+			message = "max deve ser maior ou igual a min"
+		default:
+			message = validateResourceRefresh(rule)
+		}
+		if message != "" {
+			errors = append(errors, models.SheetTemplateValidationError{Field: field, Message: message})
+		}
+	}
+
+	if inventory := rules.Inventory; inventory != nil {
+		var message string
+		switch {
+		case inventory.Capacity != nil && inventory.CapacityPath != "":
+			message = "Use capacity ou capacity_path, não ambos"
+		case inventory.Capacity != nil && *inventory.Capacity < 0:
+			message = "capacity não pode ser negativa"
+		case inventory.CapacityPath != "" && !isSheetPath(inventory.CapacityPath):
+			message = "capacity_path deve usar notação de ponto (e.g., \"carry.capacity\")"
+		}
+		if message != "" {
+			errors = append(errors, models.SheetTemplateValidationError{Field: "definition.inventory", Message: message})
+		}
+	}
+
+	if defenses := rules.Defenses; defenses != nil {
+		for _, defense := range []struct{ field, path string }{
+			{"resistances", defenses.Resistances},
+			{"immunities", defenses.Immunities},
+			{"vulnerabilities", defenses.Vulnerabilities},
+		} {
+			field, path := defense.field, defense.path
+			if path != "" && !isSheetPath(path) {
+				errors = append(errors, models.SheetTemplateValidationError{
+					Field:   "definition.defenses." + field,
+					Message: field + " deve usar notação de ponto (e.g., \"defenses." + field + "\")",
+				})
+			}
+		}
+	}
+
+	return errors
+}
+
+// validateResourceRefresh valida quando e quanto o recurso é renovado nos descansos; retorna vazio se válido
+func validateResourceRefresh(rule models.ResourceRule) string {
+	refreshed := rule.Refresh != "" && rule.Refresh != models.RefreshManual
+	hasMax := rule.Max != nil || rule.MaxPath != ""
+	switch {
+	case rule.Refresh != "" && rule.Refresh != models.RefreshManual && rule.Refresh != models.RestShort &&
+		rule.Refresh != models.RestLong && rule.Refresh != models.RestDaily:
+		return "refresh deve ser short_rest, long_rest, daily ou manual"
+	case !refreshed && (rule.RefreshAmount != nil || rule.RefreshPercent != nil):
+		return "refresh_amount e refresh_percent exigem refresh short_rest, long_rest ou daily"
+	case rule.RefreshAmount != nil && rule.RefreshPercent != nil:
+		return "Use refresh_amount ou refresh_percent, não ambos"
+	case rule.RefreshAmount != nil && *rule.RefreshAmount < 1:
+		return "refresh_amount deve ser maior que zero"
+	case rule.RefreshPercent != nil && (*rule.RefreshPercent < 1 || *rule.RefreshPercent > 100):
+		return "refresh_percent deve estar entre 1 e 100"
+	case rule.RefreshPercent != nil && !hasMax:
+		return "refresh_percent exige max ou max_path"
+	case refreshed && !hasMax && rule.RefreshAmount == nil:
+		return "Recursos renovados em descansos precisam de max, max_path ou refresh_amount"
+	}
+	return ""
+}
+
+// isSheetPath verifica se o caminho de um campo da ficha usa notação de ponto sem partes vazias
+func isSheetPath(path string) bool {
+	if path == "" || len(path) > 100 {
+		return false
+	}
+	for _, part := range strings.Split(path, ".") {
+		if part == "" {
+			return false
+		}
+	}
+	return true
+}
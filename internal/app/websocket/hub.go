@@ -7,20 +7,30 @@ import (
 	"time"
 
 	"github.com/gorilla/websocket"
+	"github.com/luizdequeiroz/rpg-backend/internal/app/interfaces"
 )
 
 // EventType representa tipos de eventos WebSocket
 type EventType string
 
 const (
-	EventInviteCreated  EventType = "invite_created"
-	EventInviteAccepted EventType = "invite_accepted"
-	EventInviteDeclined EventType = "invite_declined"
-	EventSheetCreated   EventType = "sheet_created"
-	EventSheetUpdated   EventType = "sheet_updated"
-	EventSheetDeleted   EventType = "sheet_deleted"
-	EventRollPerformed  EventType = "roll_performed"
-	EventTableUpdated   EventType = "table_updated"
+	EventInviteCreated    EventType = "invite_created"
+	EventInviteAccepted   EventType = "invite_accepted"
+	EventInviteDeclined   EventType = "invite_declined"
+	EventSheetCreated     EventType = "sheet_created"
+	EventSheetUpdated     EventType = "sheet_updated"
+	EventSheetDeleted     EventType = "sheet_deleted"
+	EventSheetDelta       EventType = "sheet_delta"
+	EventRollPerformed    EventType = "roll_performed"
+	EventRollContested    EventType = "roll_contested"
+	EventRollBatch        EventType = "roll_batch"
+	EventRollVoided       EventType = "roll_voided"
+	EventRollReaction     EventType = "roll_reaction"
+	EventTableUpdated     EventType = "table_updated"
+	EventEncounterUpdated EventType = "encounter_updated"
+	EventConditionUpdated EventType = "condition_updated"
+	EventInventoryUpdated EventType = "inventory_updated"
+	EventSheetRested      EventType = "sheet_rested"
 )
 
 // Event representa um evento WebSocket
@@ -105,48 +115,70 @@ func (h *Hub) Run() {
 
 // BroadcastToTable envia evento para todos os clientes de uma mesa
 func (h *Hub) BroadcastToTable(tableID string, eventType EventType, userID int, userEmail string, data interface{}) {
-	event := Event{
-		Type:      eventType,
-		UserID:    userID,
-		UserEmail: userEmail,
-		TableID:   tableID,
-		Data:      data,
-		Timestamp: getTimestamp(),
-	}
-
-	eventJSON, err := json.Marshal(event)
-	if err != nil {
-		log.Printf("Erro ao serializar evento: %v", err)
-		return
-	}
+	h.BroadcastToTableFiltered(tableID, eventType, userID, userEmail, func(int) (interface{}, bool) {
+		return data, true
+	})
+}
 
+// BroadcastToTableFiltered envia evento aos clientes de uma mesa, com os dados escolhidos para cada destinatário
+// (e.g., rolagens secretas ou com resultado oculto)
+func (h *Hub) BroadcastToTableFiltered(tableID string, eventType EventType, userID int, userEmail string, dataFor interfaces.RecipientData) {
 	h.mutex.RLock()
-	tableClients := h.clients[tableID]
+	recipients := make([]*Client, 0, len(h.clients[tableID]))
+	for client := range h.clients[tableID] {
+		recipients = append(recipients, client)
+	}
 	h.mutex.RUnlock()
 
-	if tableClients == nil {
+	if len(recipients) == 0 {
 		log.Printf("Nenhum cliente conectado na mesa %s", tableID)
 		return
 	}
 
-	for client := range tableClients {
+	timestamp := getTimestamp()
+	sent := 0
+
+	for _, client := range recipients {
+		data, ok := dataFor(client.userID)
+		if !ok {
+			continue
+		}
+
+		eventJSON, err := json.Marshal(Event{
+			Type:      eventType,
+			UserID:    userID,
+			UserEmail: userEmail,
+			TableID:   tableID,
+			Data:      data,
+			Timestamp: timestamp,
+		})
+		if err != nil {
+			log.Printf("Erro ao serializar evento: %v", err)
+			return
+		}
+
 		select {
 		case client.send <- eventJSON:
 			// Evento enviado com sucesso
+			sent++
 		default:
 			// Cliente não está respondendo, desconectar
 			h.mutex.Lock()
-			delete(tableClients, client)
-			close(client.send)
-			if len(tableClients) == 0 {
-				delete(h.clients, tableID)
+			if tableClients, exists := h.clients[tableID]; exists {
+				if _, exists := tableClients[client]; exists {
+					delete(tableClients, client)
+					close(client.send)
+					if len(tableClients) == 0 {
+						delete(h.clients, tableID)
+					}
+				}
 			}
 			h.mutex.Unlock()
 		}
 	}
 
-	log.Printf("Evento %s enviado para %d clientes na mesa %s",
-		eventType, len(tableClients), tableID)
+	log.Printf("Evento %s enviado para %d de %d clientes na mesa %s",
+		eventType, sent, len(recipients), tableID)
 }
 
 // GetConnectedClients retorna número de clientes por mesa
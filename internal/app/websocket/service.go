@@ -58,10 +58,40 @@ func (ws *WebSocketService) NotifySheetDeleted(tableID string, userID int, userE
 	ws.hub.BroadcastToTable(tableID, EventSheetDeleted, userID, userEmail, sheetData)
 }
 
+// NotifySheetDelta notifica os campos alterados por dano, cura ou gasto de recursos da ficha
+func (ws *WebSocketService) NotifySheetDelta(tableID string, userID int, userEmail string, deltaData interface{}) {
+	log.Printf("WebSocket: Notificando alteração de recursos de ficha na mesa %s por usuário %d", tableID, userID)
+	ws.hub.BroadcastToTable(tableID, EventSheetDelta, userID, userEmail, deltaData)
+}
+
 // NotifyRollPerformed notifica rolagem de dados
-func (ws *WebSocketService) NotifyRollPerformed(tableID string, userID int, userEmail string, rollData interface{}) {
+func (ws *WebSocketService) NotifyRollPerformed(tableID string, userID int, userEmail string, rollData interfaces.RecipientData) {
 	log.Printf("WebSocket: Notificando rolagem na mesa %s por usuário %d", tableID, userID)
-	ws.hub.BroadcastToTable(tableID, EventRollPerformed, userID, userEmail, rollData)
+	ws.hub.BroadcastToTableFiltered(tableID, EventRollPerformed, userID, userEmail, rollData)
+}
+
+// NotifyRollContested notifica rolagem resistida entre duas fichas
+func (ws *WebSocketService) NotifyRollContested(tableID string, userID int, userEmail string, contestData interfaces.RecipientData) {
+	log.Printf("WebSocket: Notificando disputa na mesa %s por usuário %d", tableID, userID)
+	ws.hub.BroadcastToTableFiltered(tableID, EventRollContested, userID, userEmail, contestData)
+}
+
+// NotifyRollBatch notifica um lote de rolagens feitas em uma única requisição
+func (ws *WebSocketService) NotifyRollBatch(tableID string, userID int, userEmail string, batchData interfaces.RecipientData) {
+	log.Printf("WebSocket: Notificando lote de rolagens na mesa %s por usuário %d", tableID, userID)
+	ws.hub.BroadcastToTableFiltered(tableID, EventRollBatch, userID, userEmail, batchData)
+}
+
+// NotifyRollVoided notifica a anulação de uma rolagem
+func (ws *WebSocketService) NotifyRollVoided(tableID string, userID int, userEmail string, rollData interfaces.RecipientData) {
+	log.Printf("WebSocket: Notificando anulação de rolagem na mesa %s por usuário %d", tableID, userID)
+	ws.hub.BroadcastToTableFiltered(tableID, EventRollVoided, userID, userEmail, rollData)
+}
+
+// NotifyRollReaction notifica uma reação adicionada ou removida de uma rolagem
+func (ws *WebSocketService) NotifyRollReaction(tableID string, userID int, userEmail string, reactionData interfaces.RecipientData) {
+	log.Printf("WebSocket: Notificando reação a rolagem na mesa %s por usuário %d", tableID, userID)
+	ws.hub.BroadcastToTableFiltered(tableID, EventRollReaction, userID, userEmail, reactionData)
 }
 
 // NotifyTableUpdated notifica atualização da mesa
@@ -70,6 +100,30 @@ func (ws *WebSocketService) NotifyTableUpdated(tableID string, userID int, userE
 	ws.hub.BroadcastToTable(tableID, EventTableUpdated, userID, userEmail, tableData)
 }
 
+// NotifyEncounterUpdated notifica uma alteração de encontro: participantes, iniciativa ou turno
+func (ws *WebSocketService) NotifyEncounterUpdated(tableID string, userID int, userEmail string, encounterData interface{}) {
+	log.Printf("WebSocket: Notificando atualização de encontro na mesa %s por usuário %d", tableID, userID)
+	ws.hub.BroadcastToTable(tableID, EventEncounterUpdated, userID, userEmail, encounterData)
+}
+
+// NotifyConditionUpdated notifica condições de fichas aplicadas, removidas, expiradas ou encerradas por resistência
+func (ws *WebSocketService) NotifyConditionUpdated(tableID string, userID int, userEmail string, conditionData interface{}) {
+	log.Printf("WebSocket: Notificando condições de fichas na mesa %s por usuário %d", tableID, userID)
+	ws.hub.BroadcastToTable(tableID, EventConditionUpdated, userID, userEmail, conditionData)
+}
+
+// NotifyInventoryUpdated notifica itens adicionados, alterados, removidos, entregues ou trocados entre fichas
+func (ws *WebSocketService) NotifyInventoryUpdated(tableID string, userID int, userEmail string, inventoryData interface{}) {
+	log.Printf("WebSocket: Notificando inventários de fichas na mesa %s por usuário %d", tableID, userID)
+	ws.hub.BroadcastToTable(tableID, EventInventoryUpdated, userID, userEmail, inventoryData)
+}
+
+// NotifySheetRested notifica um descanso de fichas da mesa e os recursos renovados
+func (ws *WebSocketService) NotifySheetRested(tableID string, userID int, userEmail string, restData interface{}) {
+	log.Printf("WebSocket: Notificando descanso de fichas na mesa %s por usuário %d", tableID, userID)
+	ws.hub.BroadcastToTable(tableID, EventSheetRested, userID, userEmail, restData)
+}
+
 // GetConnectedClients retorna clientes conectados por mesa
 func (ws *WebSocketService) GetConnectedClients() map[string]int {
 	return ws.hub.GetConnectedClients()
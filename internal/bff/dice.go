@@ -14,20 +14,27 @@ type DiceHandler struct {
 }
 
 // NewDiceHandler cria novo handler para dados
-func NewDiceHandler(diceService *services.DiceService, playerSheetService *services.PlayerSheetService, notificationService interfaces.NotificationService) *DiceHandler {
+func NewDiceHandler(diceService *services.DiceService, playerSheetService *services.PlayerSheetService, fairnessService *services.RollFairnessService, contestService *services.RollContestService, notificationService interfaces.NotificationService) *DiceHandler {
 	return &DiceHandler{
-		diceHandler: handlers.NewDiceHandler(diceService, playerSheetService, notificationService),
+		diceHandler: handlers.NewDiceHandler(diceService, playerSheetService, fairnessService, contestService, notificationService),
 	}
 }
 
 // SetupDiceRoutes configura rotas de dados
 func (h *DiceHandler) SetupDiceRoutes(router *gin.RouterGroup, authService *services.AuthService) {
+	authMiddleware := middleware.AuthMiddleware(authService)
+
 	dice := router.Group("/dice")
-	dice.Use(middleware.AuthMiddleware(authService))
 	{
-		dice.POST("/roll", h.diceHandler.RollDice)
-		dice.POST("/roll-with-sheet", h.diceHandler.RollWithSheet)
-		dice.GET("/history", h.diceHandler.GetHistory)
+		dice.POST("/roll", authMiddleware, h.diceHandler.RollDice)
+		dice.POST("/roll-with-sheet", authMiddleware, h.diceHandler.RollWithSheet)
+		dice.POST("/analyze", authMiddleware, h.diceHandler.AnalyzeDice)
+		dice.GET("/history", authMiddleware, h.diceHandler.GetHistory)
+		dice.GET("/seed", authMiddleware, h.diceHandler.GetSeed)
+		dice.POST("/contest", authMiddleware, h.diceHandler.ContestRoll)
+		dice.GET("/contest/:contestID", authMiddleware, h.diceHandler.GetContest)
+		// Verificação pública: qualquer pessoa pode conferir uma rolagem
+		dice.GET("/verify/:rollID", h.diceHandler.VerifyRoll)
 	}
 }
 
@@ -40,6 +47,26 @@ func (h *DiceHandler) RollWithSheet(c *gin.Context) {
 	h.diceHandler.RollWithSheet(c)
 }
 
+func (h *DiceHandler) AnalyzeDice(c *gin.Context) {
+	h.diceHandler.AnalyzeDice(c)
+}
+
 func (h *DiceHandler) GetHistory(c *gin.Context) {
 	h.diceHandler.GetHistory(c)
 }
+
+func (h *DiceHandler) GetSeed(c *gin.Context) {
+	h.diceHandler.GetSeed(c)
+}
+
+func (h *DiceHandler) VerifyRoll(c *gin.Context) {
+	h.diceHandler.VerifyRoll(c)
+}
+
+func (h *DiceHandler) ContestRoll(c *gin.Context) {
+	h.diceHandler.ContestRoll(c)
+}
+
+func (h *DiceHandler) GetContest(c *gin.Context) {
+	h.diceHandler.GetContest(c)
+}
@@ -0,0 +1,555 @@
+package bff
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/luizdequeiroz/rpg-backend/internal/app/interfaces"
+	"github.com/luizdequeiroz/rpg-backend/internal/app/middleware"
+	"github.com/luizdequeiroz/rpg-backend/internal/app/models"
+	"github.com/luizdequeiroz/rpg-backend/internal/app/services"
+)
+
+// EncounterHandler gerencia os encontros de combate: participantes, iniciativa e ordem de turnos
+type EncounterHandler struct {
+	service             *services.EncounterService
+	notificationService interfaces.NotificationService
+}
+
+// NewEncounterHandler cria uma nova instância do handler
+func NewEncounterHandler(service *services.EncounterService, notificationService interfaces.NotificationService) *EncounterHandler {
+	return &EncounterHandler{
+		service:             service,
+		notificationService: notificationService,
+	}
+}
+
+// SetupEncounterRoutes configura as rotas de encontros
+func (h *EncounterHandler) SetupEncounterRoutes(router *gin.RouterGroup, authService *services.AuthService) {
+	auth := middleware.AuthMiddleware(authService)
+
+	// Encontros da mesa
+	tables := router.Group("/tables/:id/encounters", auth)
+	{
+		tables.POST("", h.CreateEncounter)
+		tables.GET("", h.ListEncounters)
+	}
+
+	// Rotas de encontros (todas requerem autenticação)
+	encounters := router.Group("/encounters", auth)
+	{
+		encounters.GET("/:encounterID", h.GetEncounter)
+		encounters.PUT("/:encounterID", h.UpdateEncounter)
+		encounters.DELETE("/:encounterID", h.DeleteEncounter)
+
+		encounters.POST("/:encounterID/participants", h.AddParticipant)
+		encounters.DELETE("/:encounterID/participants/:participantID", h.RemoveParticipant)
+		encounters.POST("/:encounterID/participants/:participantID/initiative", h.SetInitiative)
+		encounters.POST("/:encounterID/participants/:participantID/delay", h.Delay)
+		encounters.POST("/:encounterID/participants/:participantID/ready", h.Ready)
+		encounters.POST("/:encounterID/participants/:participantID/act", h.Act)
+
+		encounters.POST("/:encounterID/start", h.Start)
+		encounters.POST("/:encounterID/next", h.Next)
+		encounters.POST("/:encounterID/previous", h.Previous)
+		encounters.POST("/:encounterID/end", h.End)
+	}
+}
+
+// CreateEncounter godoc
+// @Summary Criar encontro
+// @Description Cria um encontro de combate na mesa; apenas o mestre pode criar. tie_break define o desempate da iniciativa: modifier (padrão), players_first ou npcs_first
+// @Tags Encounters
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "ID da mesa"
+// @Param body body models.CreateEncounterRequest true "Dados do encontro"
+// @Success 201 {object} models.EncounterResponse
+// @Failure 400 {object} map[string]interface{} "Dados inválidos"
+// @Failure 401 {object} map[string]interface{} "Não autorizado"
+// @Failure 403 {object} map[string]interface{} "Apenas o mestre da mesa"
+// @Failure 404 {object} map[string]interface{} "Mesa não encontrada"
+// @Router /api/v1/tables/{id}/encounters [post]
+func (h *EncounterHandler) CreateEncounter(c *gin.Context) {
+	userID, userEmail, exists := middleware.GetUserFromContext(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Usuário não autenticado"})
+		return
+	}
+
+	var req models.CreateEncounterRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Dados inválidos",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	event, err := h.service.Create(c.Param("id"), req, userID)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	h.notify(event, userID, userEmail)
+	c.JSON(http.StatusCreated, event.Encounter)
+}
+
+// ListEncounters godoc
+// @Summary Listar encontros da mesa
+// @Description Lista os encontros da mesa, do mais recente para o mais antigo, com os participantes na ordem dos turnos
+// @Tags Encounters
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "ID da mesa"
+// @Success 200 {array} models.EncounterResponse
+// @Failure 401 {object} map[string]interface{} "Não autorizado"
+// @Failure 403 {object} map[string]interface{} "Acesso negado"
+// @Router /api/v1/tables/{id}/encounters [get]
+func (h *EncounterHandler) ListEncounters(c *gin.Context) {
+	userID, _, exists := middleware.GetUserFromContext(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Usuário não autenticado"})
+		return
+	}
+
+	encounters, err := h.service.List(c.Param("id"), userID)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, encounters)
+}
+
+// GetEncounter godoc
+// @Summary Buscar encontro
+// @Description Retorna o encontro com a rodada, o participante da vez e os participantes na ordem dos turnos
+// @Tags Encounters
+// @Produce json
+// @Security BearerAuth
+// @Param encounterID path string true "ID do encontro"
+// @Success 200 {object} models.EncounterResponse
+// @Failure 401 {object} map[string]interface{} "Não autorizado"
+// @Failure 403 {object} map[string]interface{} "Acesso negado"
+// @Failure 404 {object} map[string]interface{} "Encontro não encontrado"
+// @Router /api/v1/encounters/{encounterID} [get]
+func (h *EncounterHandler) GetEncounter(c *gin.Context) {
+	userID, _, exists := middleware.GetUserFromContext(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Usuário não autenticado"})
+		return
+	}
+
+	encounter, err := h.service.Get(c.Param("encounterID"), userID)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, encounter)
+}
+
+// UpdateEncounter godoc
+// @Summary Atualizar encontro
+// @Description Altera o nome e a regra de desempate; antes do início a ordem é refeita com a nova regra. Apenas o mestre pode alterar
+// @Tags Encounters
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param encounterID path string true "ID do encontro"
+// @Param body body models.UpdateEncounterRequest true "Dados do encontro"
+// @Success 200 {object} models.EncounterResponse
+// @Failure 400 {object} map[string]interface{} "Dados inválidos"
+// @Failure 401 {object} map[string]interface{} "Não autorizado"
+// @Failure 403 {object} map[string]interface{} "Apenas o mestre da mesa"
+// @Failure 404 {object} map[string]interface{} "Encontro não encontrado"
+// @Failure 409 {object} map[string]interface{} "Encontro alterado por outra ação"
+// @Router /api/v1/encounters/{encounterID} [put]
+func (h *EncounterHandler) UpdateEncounter(c *gin.Context) {
+	userID, userEmail, exists := middleware.GetUserFromContext(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Usuário não autenticado"})
+		return
+	}
+
+	var req models.UpdateEncounterRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Dados inválidos",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	event, err := h.service.Update(c.Param("encounterID"), req, userID)
+	h.respond(c, event, err, userID, userEmail)
+}
+
+// DeleteEncounter godoc
+// @Summary Remover encontro
+// @Description Remove o encontro e seus participantes; as rolagens de iniciativa continuam no histórico. Apenas o mestre pode remover
+// @Tags Encounters
+// @Produce json
+// @Security BearerAuth
+// @Param encounterID path string true "ID do encontro"
+// @Success 200 {object} map[string]interface{}
+// @Failure 401 {object} map[string]interface{} "Não autorizado"
+// @Failure 403 {object} map[string]interface{} "Apenas o mestre da mesa"
+// @Failure 404 {object} map[string]interface{} "Encontro não encontrado"
+// @Router /api/v1/encounters/{encounterID} [delete]
+func (h *EncounterHandler) DeleteEncounter(c *gin.Context) {
+	userID, userEmail, exists := middleware.GetUserFromContext(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Usuário não autenticado"})
+		return
+	}
+
+	event, err := h.service.Delete(c.Param("encounterID"), userID)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	h.notify(event, userID, userEmail)
+	c.JSON(http.StatusOK, gin.H{"message": "Encontro removido com sucesso"})
+}
+
+// AddParticipant godoc
+// @Summary Adicionar participante
+// @Description Adiciona uma ficha da mesa (sheet_id) ou um NPC sem ficha (name). Jogadores adicionam apenas as próprias fichas; fichas do mestre entram como NPCs. Com initiative, o participante já entra na ordem
+// @Tags Encounters
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param encounterID path string true "ID do encontro"
+// @Param body body models.AddParticipantRequest true "Ficha ou NPC"
+// @Success 201 {object} models.EncounterResponse
+// @Failure 400 {object} map[string]interface{} "Dados inválidos"
+// @Failure 401 {object} map[string]interface{} "Não autorizado"
+// @Failure 403 {object} map[string]interface{} "Ficha de outro usuário"
+// @Failure 404 {object} map[string]interface{} "Encontro ou ficha não encontrados"
+// @Failure 409 {object} map[string]interface{} "Ficha já participa do encontro"
+// @Router /api/v1/encounters/{encounterID}/participants [post]
+func (h *EncounterHandler) AddParticipant(c *gin.Context) {
+	userID, userEmail, exists := middleware.GetUserFromContext(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Usuário não autenticado"})
+		return
+	}
+
+	var req models.AddParticipantRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Dados inválidos",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	event, err := h.service.AddParticipant(c.Param("encounterID"), req, userID)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	h.notify(event, userID, userEmail)
+	c.JSON(http.StatusCreated, event.Encounter)
+}
+
+// RemoveParticipant godoc
+// @Summary Remover participante
+// @Description Remove um participante do encontro; se for a vez dele, o turno passa ao próximo. O mestre ou quem controla o participante pode remover
+// @Tags Encounters
+// @Produce json
+// @Security BearerAuth
+// @Param encounterID path string true "ID do encontro"
+// @Param participantID path string true "ID do participante"
+// @Success 200 {object} models.EncounterResponse
+// @Failure 401 {object} map[string]interface{} "Não autorizado"
+// @Failure 403 {object} map[string]interface{} "Participante de outro usuário"
+// @Failure 404 {object} map[string]interface{} "Encontro ou participante não encontrados"
+// @Failure 409 {object} map[string]interface{} "Encontro alterado por outra ação"
+// @Router /api/v1/encounters/{encounterID}/participants/{participantID} [delete]
+func (h *EncounterHandler) RemoveParticipant(c *gin.Context) {
+	userID, userEmail, exists := middleware.GetUserFromContext(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Usuário não autenticado"})
+		return
+	}
+
+	event, err := h.service.RemoveParticipant(c.Param("encounterID"), c.Param("participantID"), userID)
+	h.respond(c, event, err, userID, userEmail)
+}
+
+// SetInitiative godoc
+// @Summary Definir iniciativa
+// @Description Define a iniciativa do participante: informada (value) ou rolada com uma expressão ou um campo da ficha (NPCs sem ficha rolam uma expressão). A rolagem fica no histórico da mesa com a tag "initiative" e o modificador dela desempata a ordem. Em combate, apenas o participante é reposicionado
+// @Tags Encounters
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param encounterID path string true "ID do encontro"
+// @Param participantID path string true "ID do participante"
+// @Param body body models.InitiativeRequest true "Iniciativa informada ou rolada"
+// @Success 200 {object} models.EncounterResponse
+// @Failure 400 {object} map[string]interface{} "Dados inválidos ou erro na rolagem"
+// @Failure 401 {object} map[string]interface{} "Não autorizado"
+// @Failure 403 {object} map[string]interface{} "Participante de outro usuário"
+// @Failure 404 {object} map[string]interface{} "Encontro ou participante não encontrados"
+// @Failure 409 {object} map[string]interface{} "Encontro alterado por outra ação"
+// @Router /api/v1/encounters/{encounterID}/participants/{participantID}/initiative [post]
+func (h *EncounterHandler) SetInitiative(c *gin.Context) {
+	userID, userEmail, exists := middleware.GetUserFromContext(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Usuário não autenticado"})
+		return
+	}
+
+	var req models.InitiativeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Dados inválidos",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	event, err := h.service.SetInitiative(c.Param("encounterID"), c.Param("participantID"), req, userID)
+	h.respond(c, event, err, userID, userEmail)
+}
+
+// Delay godoc
+// @Summary Adiar turno
+// @Description O participante da vez adia o turno: é pulado até agir (act) e a vez passa ao próximo
+// @Tags Encounters
+// @Produce json
+// @Security BearerAuth
+// @Param encounterID path string true "ID do encontro"
+// @Param participantID path string true "ID do participante"
+// @Success 200 {object} models.EncounterResponse
+// @Failure 400 {object} map[string]interface{} "Não é a vez do participante"
+// @Failure 401 {object} map[string]interface{} "Não autorizado"
+// @Failure 403 {object} map[string]interface{} "Participante de outro usuário"
+// @Failure 404 {object} map[string]interface{} "Encontro ou participante não encontrados"
+// @Failure 409 {object} map[string]interface{} "Encontro alterado por outra ação"
+// @Router /api/v1/encounters/{encounterID}/participants/{participantID}/delay [post]
+func (h *EncounterHandler) Delay(c *gin.Context) {
+	userID, userEmail, exists := middleware.GetUserFromContext(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Usuário não autenticado"})
+		return
+	}
+
+	event, err := h.service.Delay(c.Param("encounterID"), c.Param("participantID"), userID)
+	h.respond(c, event, err, userID, userEmail)
+}
+
+// Ready godoc
+// @Summary Preparar ação
+// @Description O participante da vez prepara uma ação para um gatilho e a vez passa ao próximo. A ação dispara com act ou expira quando chega de novo a vez dele
+// @Tags Encounters
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param encounterID path string true "ID do encontro"
+// @Param participantID path string true "ID do participante"
+// @Param body body models.ReadyRequest true "Gatilho da ação"
+// @Success 200 {object} models.EncounterResponse
+// @Failure 400 {object} map[string]interface{} "Não é a vez do participante"
+// @Failure 401 {object} map[string]interface{} "Não autorizado"
+// @Failure 403 {object} map[string]interface{} "Participante de outro usuário"
+// @Failure 404 {object} map[string]interface{} "Encontro ou participante não encontrados"
+// @Failure 409 {object} map[string]interface{} "Encontro alterado por outra ação"
+// @Router /api/v1/encounters/{encounterID}/participants/{participantID}/ready [post]
+func (h *EncounterHandler) Ready(c *gin.Context) {
+	userID, userEmail, exists := middleware.GetUserFromContext(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Usuário não autenticado"})
+		return
+	}
+
+	var req models.ReadyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Dados inválidos",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	event, err := h.service.Ready(c.Param("encounterID"), c.Param("participantID"), req.Trigger, userID)
+	h.respond(c, event, err, userID, userEmail)
+}
+
+// Act godoc
+// @Summary Agir após adiar ou preparar
+// @Description Um participante que adiou o turno ou preparou uma ação age agora e passa para a posição imediatamente antes do participante da vez. Quem adiou assume a vez; quem preparou age fora do turno
+// @Tags Encounters
+// @Produce json
+// @Security BearerAuth
+// @Param encounterID path string true "ID do encontro"
+// @Param participantID path string true "ID do participante"
+// @Success 200 {object} models.EncounterResponse
+// @Failure 400 {object} map[string]interface{} "O participante não adiou nem preparou"
+// @Failure 401 {object} map[string]interface{} "Não autorizado"
+// @Failure 403 {object} map[string]interface{} "Participante de outro usuário"
+// @Failure 404 {object} map[string]interface{} "Encontro ou participante não encontrados"
+// @Failure 409 {object} map[string]interface{} "Encontro alterado por outra ação"
+// @Router /api/v1/encounters/{encounterID}/participants/{participantID}/act [post]
+func (h *EncounterHandler) Act(c *gin.Context) {
+	userID, userEmail, exists := middleware.GetUserFromContext(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Usuário não autenticado"})
+		return
+	}
+
+	event, err := h.service.Act(c.Param("encounterID"), c.Param("participantID"), userID)
+	h.respond(c, event, err, userID, userEmail)
+}
+
+// Start godoc
+// @Summary Iniciar combate
+// @Description Ordena os participantes pela iniciativa, abre a rodada 1 e passa a vez ao primeiro. Todos precisam ter iniciativa. Apenas o mestre pode iniciar
+// @Tags Encounters
+// @Produce json
+// @Security BearerAuth
+// @Param encounterID path string true "ID do encontro"
+// @Success 200 {object} models.EncounterResponse
+// @Failure 400 {object} map[string]interface{} "Participantes sem iniciativa"
+// @Failure 401 {object} map[string]interface{} "Não autorizado"
+// @Failure 403 {object} map[string]interface{} "Apenas o mestre da mesa"
+// @Failure 404 {object} map[string]interface{} "Encontro não encontrado"
+// @Failure 409 {object} map[string]interface{} "Encontro alterado por outra ação"
+// @Router /api/v1/encounters/{encounterID}/start [post]
+func (h *EncounterHandler) Start(c *gin.Context) {
+	userID, userEmail, exists := middleware.GetUserFromContext(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Usuário não autenticado"})
+		return
+	}
+
+	event, err := h.service.Start(c.Param("encounterID"), userID)
+	h.respond(c, event, err, userID, userEmail)
+}
+
+// Next godoc
+// @Summary Próximo turno
+// @Description Passa a vez ao próximo participante, pulando quem adiou o turno; ao passar do último abre uma nova rodada, que desconta uma rodada das condições das fichas do encontro e notifica as que expiraram. O mestre ou quem controla o participante da vez pode encerrar o turno
+// @Tags Encounters
+// @Produce json
+// @Security BearerAuth
+// @Param encounterID path string true "ID do encontro"
+// @Success 200 {object} models.EncounterResponse
+// @Failure 400 {object} map[string]interface{} "Encontro não está em andamento"
+// @Failure 401 {object} map[string]interface{} "Não autorizado"
+// @Failure 403 {object} map[string]interface{} "Não é a vez do usuário"
+// @Failure 404 {object} map[string]interface{} "Encontro não encontrado"
+// @Failure 409 {object} map[string]interface{} "Encontro alterado por outra ação"
+// @Router /api/v1/encounters/{encounterID}/next [post]
+func (h *EncounterHandler) Next(c *gin.Context) {
+	userID, userEmail, exists := middleware.GetUserFromContext(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Usuário não autenticado"})
+		return
+	}
+
+	event, err := h.service.Next(c.Param("encounterID"), userID)
+	h.respond(c, event, err, userID, userEmail)
+}
+
+// Previous godoc
+// @Summary Turno anterior
+// @Description Devolve a vez ao participante anterior, voltando a rodada ao passar do primeiro. Apenas o mestre pode voltar o turno
+// @Tags Encounters
+// @Produce json
+// @Security BearerAuth
+// @Param encounterID path string true "ID do encontro"
+// @Success 200 {object} models.EncounterResponse
+// @Failure 400 {object} map[string]interface{} "Encontro já está no primeiro turno"
+// @Failure 401 {object} map[string]interface{} "Não autorizado"
+// @Failure 403 {object} map[string]interface{} "Apenas o mestre da mesa"
+// @Failure 404 {object} map[string]interface{} "Encontro não encontrado"
+// @Failure 409 {object} map[string]interface{} "Encontro alterado por outra ação"
+// @Router /api/v1/encounters/{encounterID}/previous [post]
+func (h *EncounterHandler) Previous(c *gin.Context) {
+	userID, userEmail, exists := middleware.GetUserFromContext(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Usuário não autenticado"})
+		return
+	}
+
+	event, err := h.service.Previous(c.Param("encounterID"), userID)
+	h.respond(c, event, err, userID, userEmail)
+}
+
+// End godoc
+// @Summary Encerrar combate
+// @Description Encerra o encontro; apenas o mestre pode encerrar
+// @Tags Encounters
+// @Produce json
+// @Security BearerAuth
+// @Param encounterID path string true "ID do encontro"
+// @Success 200 {object} models.EncounterResponse
+// @Failure 400 {object} map[string]interface{} "Encontro já terminou"
+// @Failure 401 {object} map[string]interface{} "Não autorizado"
+// @Failure 403 {object} map[string]interface{} "Apenas o mestre da mesa"
+// @Failure 404 {object} map[string]interface{} "Encontro não encontrado"
+// @Failure 409 {object} map[string]interface{} "Encontro alterado por outra ação"
+// @Router /api/v1/encounters/{encounterID}/end [post]
+func (h *EncounterHandler) End(c *gin.Context) {
+	userID, userEmail, exists := middleware.GetUserFromContext(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Usuário não autenticado"})
+		return
+	}
+
+	event, err := h.service.End(c.Param("encounterID"), userID)
+	h.respond(c, event, err, userID, userEmail)
+}
+
+// respond notifica a mesa e responde com o encontro atualizado
+func (h *EncounterHandler) respond(c *gin.Context, event *models.EncounterEvent, err error, userID int, userEmail string) {
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	h.notify(event, userID, userEmail)
+	c.JSON(http.StatusOK, event.Encounter)
+}
+
+// notify avisa a mesa da alteração do encontro e das condições que expiraram com a nova rodada;
+// a ordem de turnos e as condições são públicas, então todos recebem os mesmos dados
+func (h *EncounterHandler) notify(event *models.EncounterEvent, userID int, userEmail string) {
+	if h.notificationService == nil {
+		return
+	}
+	h.notificationService.NotifyEncounterUpdated(event.Encounter.TableID, userID, userEmail, event)
+	if event.ExpiredConditions != nil {
+		h.notificationService.NotifyConditionUpdated(event.Encounter.TableID, userID, userEmail, event.ExpiredConditions)
+	}
+}
+
+// handleError converte os erros do serviço de encontros em respostas HTTP; as demais regras do combate são 400
+func (h *EncounterHandler) handleError(c *gin.Context, err error) {
+	switch err.Error() {
+	case "encontro não encontrado", "participante não encontrado", "ficha não encontrada", "mesa não encontrada":
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case "acesso negado à mesa",
+		"apenas o mestre da mesa pode gerenciar o encontro",
+		"apenas o mestre da mesa pode adicionar NPCs",
+		"apenas o dono da ficha ou o mestre da mesa pode adicioná-la",
+		"apenas o mestre da mesa ou quem controla o participante pode removê-lo",
+		"apenas o mestre da mesa ou quem controla o participante pode definir a iniciativa",
+		"apenas o mestre da mesa ou quem controla o participante pode agir por ele",
+		"apenas o mestre da mesa ou o participante da vez pode encerrar o turno":
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+	case "a ficha já participa do encontro", "o encontro foi alterado por outra ação; tente novamente":
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	}
+}
@@ -10,21 +10,30 @@ import (
 	"github.com/luizdequeiroz/rpg-backend/internal/app/services"
 	"github.com/luizdequeiroz/rpg-backend/internal/app/websocket"
 	"github.com/luizdequeiroz/rpg-backend/pkg/db"
+	"github.com/luizdequeiroz/rpg-backend/pkg/roll"
 )
 
 // Handler contém as dependências da camada BFF
 type Handler struct {
-	db                   *db.DB
-	authService          *services.AuthService
-	authHandler          *AuthHandler
-	sheetTemplateService *services.SheetTemplateService
-	sheetTemplateHandler *SheetTemplateHandler
-	userHandler          *UserHandler
-	gameTableService     *services.GameTableService
-	gameTableHandler     *GameTableHandler
-	playerSheetService   *services.PlayerSheetService
-	playerSheetHandler   *PlayerSheetHandler
-	diceHandler          *handlers.DiceHandler
+	db                    *db.DB
+	authService           *services.AuthService
+	authHandler           *AuthHandler
+	sheetTemplateService  *services.SheetTemplateService
+	sheetTemplateHandler  *SheetTemplateHandler
+	userHandler           *UserHandler
+	gameTableService      *services.GameTableService
+	gameTableHandler      *GameTableHandler
+	playerSheetService    *services.PlayerSheetService
+	playerSheetHandler    *PlayerSheetHandler
+	rollMacroHandler      *RollMacroHandler
+	rollStatsHandler      *RollStatsHandler
+	rollReactionHandler   *RollReactionHandler
+	encounterHandler      *EncounterHandler
+	sheetConditionHandler *SheetConditionHandler
+	sheetResourceHandler  *SheetResourceHandler
+	sheetItemHandler      *SheetItemHandler
+	sheetRestHandler      *SheetRestHandler
+	diceHandler           *handlers.DiceHandler
 
 	wsService *websocket.WebSocketService
 	wsHandler *websocket.WebSocketHandler
@@ -46,11 +55,25 @@ func NewHandler(database *db.DB) *Handler {
 	gameTableService := services.NewGameTableService(gameTableRepo, inviteRepo)
 	gameTableHandler := NewGameTableHandler(gameTableService)
 
+	// Motor de rolagem compartilhado por /rolls e /dice
+	rollEngine := roll.NewRollEngine()
+
 	// Inicializar repositórios e serviços para PlayerSheet
 	playerSheetRepo := repositories.NewPlayerSheetRepository(database.DB)
 	rollRepo := repositories.NewRollRepository(database.DB)
-	playerSheetService := services.NewPlayerSheetService(playerSheetRepo, rollRepo, gameTableRepo)
-	playerSheetHandler := NewPlayerSheetHandler(playerSheetService)
+	sheetTemplateRepo := repositories.NewSheetTemplateRepository(database)
+
+	// Rolagens verificáveis (commit-reveal) usadas por /rolls e /dice
+	rollSeedRepo := repositories.NewRollSeedRepository(database.DB)
+	rollFairnessService := services.NewRollFairnessService(rollSeedRepo, rollRepo, rollEngine)
+
+	// Condições das fichas, cujos modificadores entram nas rolagens da ficha
+	sheetConditionRepo := repositories.NewSheetConditionRepository(database.DB)
+
+	// Itens das fichas, cujos modificadores entram nas rolagens da ficha enquanto equipados
+	sheetItemRepo := repositories.NewSheetItemRepository(database.DB)
+
+	playerSheetService := services.NewPlayerSheetService(playerSheetRepo, rollRepo, gameTableRepo, sheetTemplateRepo, rollEngine, rollFairnessService, sheetConditionRepo, sheetItemRepo)
 
 	// Inicializar serviço e handler para WebSocket
 	wsHub := websocket.NewHub()
@@ -58,24 +81,74 @@ func NewHandler(database *db.DB) *Handler {
 	wsService := websocket.NewWebSocketService(wsHub)
 	wsHandler := websocket.NewWebSocketHandler(wsHub)
 
+	// Macros de rolagem nomeadas, executadas por /rolls
+	rollMacroRepo := repositories.NewRollMacroRepository(database.DB)
+	rollMacroService := services.NewRollMacroService(rollMacroRepo, playerSheetRepo, rollRepo, playerSheetService, rollEngine)
+	rollMacroHandler := NewRollMacroHandler(rollMacroService)
+
+	playerSheetHandler := NewPlayerSheetHandler(playerSheetService, rollMacroService, wsService)
+
+	// Rolagens resistidas entre duas fichas
+	rollContestRepo := repositories.NewRollContestRepository(database.DB)
+	rollContestService := services.NewRollContestService(rollContestRepo, rollRepo, playerSheetService)
+
+	// Estatísticas e relatórios de sorte das rolagens
+	rollStatsService := services.NewRollStatsService(rollRepo, playerSheetService, rollEngine)
+	rollStatsHandler := NewRollStatsHandler(rollStatsService)
+
+	// Reações (emoji) dos jogadores às rolagens
+	rollReactionRepo := repositories.NewRollReactionRepository(database.DB)
+	rollReactionService := services.NewRollReactionService(rollReactionRepo, rollRepo, playerSheetService)
+	rollReactionHandler := NewRollReactionHandler(rollReactionService, playerSheetService, wsService)
+
+	// Condições das fichas (e.g., abençoado, envenenado), com expiração pelas rodadas dos encontros
+	sheetConditionService := services.NewSheetConditionService(sheetConditionRepo, rollRepo, playerSheetService, rollEngine)
+	sheetConditionHandler := NewSheetConditionHandler(sheetConditionService, wsService)
+
+	// Dano, cura e gasto de recursos declarados no template, aplicados sem sobrescrever a ficha inteira
+	sheetResourceService := services.NewSheetResourceService(playerSheetRepo, playerSheetService)
+	sheetResourceHandler := NewSheetResourceHandler(sheetResourceService, wsService)
+
+	// Inventário das fichas: itens, carga, entrega e troca entre fichas da mesa
+	sheetItemService := services.NewSheetItemService(sheetItemRepo, playerSheetService, rollEngine)
+	sheetItemHandler := NewSheetItemHandler(sheetItemService, wsService)
+
+	// Descansos que renovam os recursos das fichas conforme a regra refresh do template
+	sheetRestRepo := repositories.NewSheetRestRepository(database.DB)
+	sheetRestService := services.NewSheetRestService(playerSheetRepo, sheetRestRepo, playerSheetService, sheetResourceService)
+	sheetRestHandler := NewSheetRestHandler(sheetRestService, wsService)
+
 	// Inicializar serviço e handler para Dice (com notificação WebSocket)
-	diceService := services.NewDiceService(rollRepo)
-	diceHandler := handlers.NewDiceHandler(diceService, playerSheetService, wsService)
+	diceService := services.NewDiceService(rollRepo, rollEngine, rollFairnessService)
+	diceHandler := handlers.NewDiceHandler(diceService, playerSheetService, rollFairnessService, rollContestService, wsService)
+
+	// Encontros de combate: participantes, iniciativa e ordem de turnos
+	encounterRepo := repositories.NewEncounterRepository(database.DB)
+	encounterService := services.NewEncounterService(encounterRepo, rollRepo, playerSheetService, diceService)
+	encounterHandler := NewEncounterHandler(encounterService, wsService)
 
 	return &Handler{
-		db:                   database,
-		authService:          authService,
-		authHandler:          authHandler,
-		sheetTemplateService: sheetTemplateService,
-		sheetTemplateHandler: sheetTemplateHandler,
-		userHandler:          userHandler,
-		gameTableService:     gameTableService,
-		gameTableHandler:     gameTableHandler,
-		playerSheetService:   playerSheetService,
-		playerSheetHandler:   playerSheetHandler,
-		diceHandler:          diceHandler,
-		wsService:            wsService,
-		wsHandler:            wsHandler,
+		db:                    database,
+		authService:           authService,
+		authHandler:           authHandler,
+		sheetTemplateService:  sheetTemplateService,
+		sheetTemplateHandler:  sheetTemplateHandler,
+		userHandler:           userHandler,
+		gameTableService:      gameTableService,
+		gameTableHandler:      gameTableHandler,
+		playerSheetService:    playerSheetService,
+		playerSheetHandler:    playerSheetHandler,
+		rollMacroHandler:      rollMacroHandler,
+		rollStatsHandler:      rollStatsHandler,
+		rollReactionHandler:   rollReactionHandler,
+		encounterHandler:      encounterHandler,
+		sheetConditionHandler: sheetConditionHandler,
+		sheetResourceHandler:  sheetResourceHandler,
+		sheetItemHandler:      sheetItemHandler,
+		sheetRestHandler:      sheetRestHandler,
+		diceHandler:           diceHandler,
+		wsService:             wsService,
+		wsHandler:             wsHandler,
 	}
 }
 
@@ -96,6 +169,30 @@ func (h *Handler) SetupRoutes(router *gin.RouterGroup) {
 	// Rotas de fichas de personagens e rolagens
 	h.setupPlayerSheetRoutes(router)
 
+	// Rotas de macros de rolagem
+	h.rollMacroHandler.SetupRollMacroRoutes(router, h.authService)
+
+	// Rotas de estatísticas de rolagens
+	h.rollStatsHandler.SetupRollStatsRoutes(router, h.authService)
+
+	// Rotas de reações às rolagens
+	h.rollReactionHandler.SetupRollReactionRoutes(router, h.authService)
+
+	// Rotas de encontros de combate
+	h.encounterHandler.SetupEncounterRoutes(router, h.authService)
+
+	// Rotas de condições das fichas
+	h.sheetConditionHandler.SetupSheetConditionRoutes(router, h.authService)
+
+	// Rotas de dano, cura e recursos das fichas
+	h.sheetResourceHandler.SetupSheetResourceRoutes(router, h.authService)
+
+	// Rotas de inventário e trocas de itens
+	h.sheetItemHandler.SetupSheetItemRoutes(router, h.authService)
+
+	// Rotas de descanso das fichas e da mesa
+	h.sheetRestHandler.SetupSheetRestRoutes(router, h.authService)
+
 	// Rotas de campanhas
 	campaigns := router.Group("/campaigns")
 	{
@@ -305,6 +402,7 @@ func (h *Handler) setupPlayerSheetRoutes(router *gin.RouterGroup) {
 		rolls.POST("/", authMiddleware, h.playerSheetHandler.RollDice)
 		rolls.GET("/sheet/:sheetID", authMiddleware, h.playerSheetHandler.GetRollsBySheet)
 		rolls.GET("/table/:tableID", authMiddleware, h.playerSheetHandler.GetRollsByTable)
+		rolls.POST("/:rollID/void", authMiddleware, h.playerSheetHandler.VoidRoll)
 	}
 
 	// WebSocket routes
@@ -320,6 +418,12 @@ func (h *Handler) setupPlayerSheetRoutes(router *gin.RouterGroup) {
 	{
 		dice.POST("/roll", authMiddleware, h.diceHandler.RollDice)
 		dice.POST("/roll-with-sheet", authMiddleware, h.diceHandler.RollWithSheet)
+		dice.POST("/analyze", authMiddleware, h.diceHandler.AnalyzeDice)
 		dice.GET("/history", authMiddleware, h.diceHandler.GetHistory)
+		dice.GET("/seed", authMiddleware, h.diceHandler.GetSeed)
+		dice.POST("/contest", authMiddleware, h.diceHandler.ContestRoll)
+		dice.GET("/contest/:contestID", authMiddleware, h.diceHandler.GetContest)
+		// Verificação pública: qualquer pessoa pode conferir uma rolagem
+		dice.GET("/verify/:rollID", h.diceHandler.VerifyRoll)
 	}
 }
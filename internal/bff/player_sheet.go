@@ -5,20 +5,27 @@ import (
 	"strconv"
 
 	"github.com/gin-gonic/gin"
+	"github.com/luizdequeiroz/rpg-backend/internal/app/handlers"
+	"github.com/luizdequeiroz/rpg-backend/internal/app/interfaces"
 	"github.com/luizdequeiroz/rpg-backend/internal/app/middleware"
 	"github.com/luizdequeiroz/rpg-backend/internal/app/models"
 	"github.com/luizdequeiroz/rpg-backend/internal/app/services"
+	"github.com/luizdequeiroz/rpg-backend/pkg/roll"
 )
 
 // PlayerSheetHandler gerencia requisições HTTP para fichas
 type PlayerSheetHandler struct {
-	sheetService *services.PlayerSheetService
+	sheetService        *services.PlayerSheetService
+	macroService        *services.RollMacroService
+	notificationService interfaces.NotificationService
 }
 
 // NewPlayerSheetHandler cria novo handler
-func NewPlayerSheetHandler(sheetService *services.PlayerSheetService) *PlayerSheetHandler {
+func NewPlayerSheetHandler(sheetService *services.PlayerSheetService, macroService *services.RollMacroService, notificationService interfaces.NotificationService) *PlayerSheetHandler {
 	return &PlayerSheetHandler{
-		sheetService: sheetService,
+		sheetService:        sheetService,
+		macroService:        macroService,
+		notificationService: notificationService,
 	}
 }
 
@@ -264,7 +271,13 @@ func (h *PlayerSheetHandler) DeleteSheet(c *gin.Context) {
 
 // RollDice executa rolagem de dados
 // @Summary Rolar dados
-// @Description Executa rolagem de dados baseada em expressão ou campo da ficha
+// @Description Executa rolagem de dados baseada em expressão ou campo da ficha. Com macro, executa a macro
+// @Description nomeada da ficha (ou do usuário) e retorna as rolagens do grupo (models.MacroRollResponse). Com expressions
+// @Description ou a sintaxe de repetição (e.g., "6x 4d6kh3"), executa um lote de rolagens independentes, notificado à mesa
+// @Description em um único evento roll_batch, e retorna models.RollBatchResponse. O rótulo (label) e as tags,
+// @Description incluindo as hashtags escritas no rótulo, ficam salvos em cada rolagem. Modificadores de condições e
+// @Description itens somam apenas a rolagens de soma (não a paradas de sucessos nem a dados Fate); na macro, os sem
+// @Description applies_to valem só para o primeiro passo
 // @Tags Player Sheets
 // @Accept json
 // @Produce json
@@ -300,7 +313,38 @@ func (h *PlayerSheetHandler) RollDice(c *gin.Context) {
 		return
 	}
 
-	roll, err := h.sheetService.CreateRoll(req.SheetID, req, userID)
+	var result interface{}
+	var err error
+	if req.Macro != "" {
+		result, err = h.macroService.Execute(req, userID)
+	} else if roll.IsBatch(req.Expression, req.Expressions) {
+		h.rollBatch(c, req, userID)
+		return
+	} else {
+		result, err = h.sheetService.CreateRoll(req.SheetID, req, userID)
+	}
+	if err != nil {
+		if err.Error() == "ficha não encontrada" || err.Error() == "macro não encontrada" {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		if err.Error() == "acesso negado à mesa" {
+			c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Erro na rolagem",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// rollBatch executa um lote de rolagens, notifica a mesa e responde conforme a visibilidade do lote
+func (h *PlayerSheetHandler) rollBatch(c *gin.Context, req models.CreateRollRequest, userID int) {
+	batch, err := h.sheetService.CreateRollBatch(req, userID)
 	if err != nil {
 		if err.Error() == "ficha não encontrada" {
 			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
@@ -317,38 +361,145 @@ func (h *PlayerSheetHandler) RollDice(c *gin.Context) {
 		return
 	}
 
-	c.JSON(http.StatusOK, roll)
+	gmID, err := h.sheetService.TableOwner(batch.TableID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	redacted := batch.Redacted()
+
+	if h.notificationService != nil {
+		_, userEmail, _ := middleware.GetUserFromContext(c)
+		h.notificationService.NotifyRollBatch(batch.TableID, userID, userEmail,
+			handlers.RollRecipients(batch.Visibility, userID, gmID, batch, redacted))
+	}
+
+	if _, full := models.RollAccess(batch.Visibility, userID, userID, gmID); !full {
+		c.JSON(http.StatusOK, redacted)
+		return
+	}
+	c.JSON(http.StatusOK, batch)
+}
+
+// VoidRoll anula uma rolagem
+// @Summary Anular rolagem
+// @Description Anula uma rolagem com um motivo. O mestre da mesa pode anular qualquer rolagem da mesa; quem rolou, apenas nos primeiros minutos. A rolagem continua no histórico, marcada como anulada, e deixa de contar nas estatísticas
+// @Tags Player Sheets
+// @Accept json
+// @Produce json
+// @Param rollID path string true "ID da rolagem"
+// @Param body body models.VoidRollRequest true "Motivo da anulação"
+// @Security BearerAuth
+// @Success 200 {object} models.RollResponse
+// @Failure 400 {object} map[string]interface{}
+// @Failure 401 {object} map[string]interface{}
+// @Failure 403 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 409 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Router /api/v1/rolls/{rollID}/void [post]
+func (h *PlayerSheetHandler) VoidRoll(c *gin.Context) {
+	userID, userEmail, exists := middleware.GetUserFromContext(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Usuário não autenticado"})
+		return
+	}
+
+	var req models.VoidRollRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Dados inválidos",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	rollRecord, err := h.sheetService.VoidRoll(c.Param("rollID"), userID, req.Reason)
+	if err != nil {
+		switch err.Error() {
+		case "rolagem não encontrada":
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		case "apenas o mestre da mesa ou quem rolou pode anular a rolagem",
+			"o prazo para anular a própria rolagem expirou; peça ao mestre da mesa":
+			c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+		case "rolagem já anulada":
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		case "o motivo da anulação é obrigatório":
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		}
+		return
+	}
+
+	response := rollRecord.ToResponse()
+	redacted := response.Redacted()
+
+	// Rolagens sem mesa não têm mestre nem destinatários
+	gmID := 0
+	if rollRecord.TableID != nil {
+		if gmID, err = h.sheetService.TableOwner(*rollRecord.TableID); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		if h.notificationService != nil {
+			h.notificationService.NotifyRollVoided(*rollRecord.TableID, userID, userEmail,
+				handlers.RollRecipients(rollRecord.Visibility, rollRecord.UserID, gmID, response, redacted))
+		}
+	}
+
+	if _, full := models.RollAccess(rollRecord.Visibility, rollRecord.UserID, userID, gmID); !full {
+		c.JSON(http.StatusOK, redacted)
+		return
+	}
+	c.JSON(http.StatusOK, response)
 }
 
 // GetRollsByTable lista rolagens da mesa
 // @Summary Listar rolagens da mesa
-// @Description Lista histórico de rolagens de uma mesa
+// @Description Lista histórico de rolagens de uma mesa, com filtros e ordenação; total traz o número de rolagens encontradas. Filtros pelo resultado ignoram as rolagens cujo resultado o usuário não vê
 // @Tags Player Sheets
 // @Produce json
 // @Param tableID path string true "ID da mesa"
+// @Param user_id query int false "ID de quem rolou"
+// @Param sheet_id query string false "ID da ficha"
+// @Param from query string false "Início do período, inclusivo (RFC3339 ou AAAA-MM-DD)"
+// @Param to query string false "Fim do período, exclusivo; uma data sem hora inclui o dia inteiro"
+// @Param field_name query string false "Campo da ficha rolado"
+// @Param expression query string false "Trecho da expressão"
+// @Param critical query bool false "Apenas críticos (true) ou não críticos (false)"
+// @Param fumble query bool false "Apenas falhas críticas (true) ou não (false)"
+// @Param success query bool false "Resultado do teste contra a dificuldade"
+// @Param outcome query string false "Resultado nomeado do teste"
+// @Param label query string false "Trecho do rótulo"
+// @Param tag query []string false "Tag que a rolagem deve ter, com ou sem #; repita para exigir várias" collectionFormat(multi)
+// @Param min_result query int false "Resultado mínimo"
+// @Param max_result query int false "Resultado máximo"
+// @Param sort query string false "Ordenação: created_at ou result_value" default(created_at)
+// @Param order query string false "Ordem: asc ou desc" default(desc)
 // @Param page query int false "Página" default(1)
 // @Param limit query int false "Itens por página" default(20)
 // @Security BearerAuth
 // @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
 // @Failure 401 {object} map[string]interface{}
 // @Failure 403 {object} map[string]interface{}
 // @Failure 500 {object} map[string]interface{}
 // @Router /api/v1/rolls/table/{tableID} [get]
 func (h *PlayerSheetHandler) GetRollsByTable(c *gin.Context) {
 	tableID := c.Param("tableID")
-	userID := c.GetInt("userID")
-
-	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
-	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
-
-	if page < 1 {
-		page = 1
+	userID, _, exists := middleware.GetUserFromContext(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Usuário não autenticado"})
+		return
 	}
-	if limit < 1 || limit > 100 {
-		limit = 20
+
+	filter, sort, page, limit, ok := bindRollHistoryQuery(c)
+	if !ok {
+		return
 	}
 
-	rolls, err := h.sheetService.GetRollsByTableID(tableID, userID, page, limit)
+	rolls, total, err := h.sheetService.GetRollsByTableID(tableID, userID, filter, sort, page, limit)
 	if err != nil {
 		if err.Error() == "acesso negado à mesa" {
 			c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
@@ -362,42 +513,58 @@ func (h *PlayerSheetHandler) GetRollsByTable(c *gin.Context) {
 	}
 
 	c.JSON(http.StatusOK, gin.H{
-		"rolls": rolls,
-		"total": len(rolls),
-		"page":  page,
-		"limit": limit,
+		"rolls":       rolls,
+		"total":       total,
+		"page":        page,
+		"limit":       limit,
+		"total_pages": (total + limit - 1) / limit,
 	})
 }
 
 // GetRollsBySheet lista rolagens da ficha
 // @Summary Listar rolagens da ficha
-// @Description Lista histórico de rolagens de uma ficha específica
+// @Description Lista histórico de rolagens de uma ficha específica, com filtros e ordenação; total traz o número de rolagens encontradas. Filtros pelo resultado ignoram as rolagens cujo resultado o usuário não vê
 // @Tags Player Sheets
 // @Produce json
 // @Param sheetID path string true "ID da ficha"
+// @Param user_id query int false "ID de quem rolou"
+// @Param from query string false "Início do período, inclusivo (RFC3339 ou AAAA-MM-DD)"
+// @Param to query string false "Fim do período, exclusivo; uma data sem hora inclui o dia inteiro"
+// @Param field_name query string false "Campo da ficha rolado"
+// @Param expression query string false "Trecho da expressão"
+// @Param critical query bool false "Apenas críticos (true) ou não críticos (false)"
+// @Param fumble query bool false "Apenas falhas críticas (true) ou não (false)"
+// @Param success query bool false "Resultado do teste contra a dificuldade"
+// @Param outcome query string false "Resultado nomeado do teste"
+// @Param label query string false "Trecho do rótulo"
+// @Param tag query []string false "Tag que a rolagem deve ter, com ou sem #; repita para exigir várias" collectionFormat(multi)
+// @Param min_result query int false "Resultado mínimo"
+// @Param max_result query int false "Resultado máximo"
+// @Param sort query string false "Ordenação: created_at ou result_value" default(created_at)
+// @Param order query string false "Ordem: asc ou desc" default(desc)
 // @Param page query int false "Página" default(1)
 // @Param limit query int false "Itens por página" default(20)
 // @Security BearerAuth
 // @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
 // @Failure 401 {object} map[string]interface{}
 // @Failure 403 {object} map[string]interface{}
 // @Failure 500 {object} map[string]interface{}
 // @Router /api/v1/rolls/sheet/{sheetID} [get]
 func (h *PlayerSheetHandler) GetRollsBySheet(c *gin.Context) {
 	sheetID := c.Param("sheetID")
-	userID := c.GetInt("userID")
-
-	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
-	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
-
-	if page < 1 {
-		page = 1
+	userID, _, exists := middleware.GetUserFromContext(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Usuário não autenticado"})
+		return
 	}
-	if limit < 1 || limit > 100 {
-		limit = 20
+
+	filter, sort, page, limit, ok := bindRollHistoryQuery(c)
+	if !ok {
+		return
 	}
 
-	rolls, err := h.sheetService.GetRollsBySheetID(sheetID, userID, page, limit)
+	rolls, total, err := h.sheetService.GetRollsBySheetID(sheetID, userID, filter, sort, page, limit)
 	if err != nil {
 		if err.Error() == "acesso negado à mesa" {
 			c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
@@ -411,9 +578,29 @@ func (h *PlayerSheetHandler) GetRollsBySheet(c *gin.Context) {
 	}
 
 	c.JSON(http.StatusOK, gin.H{
-		"rolls": rolls,
-		"total": len(rolls),
-		"page":  page,
-		"limit": limit,
+		"rolls":       rolls,
+		"total":       total,
+		"page":        page,
+		"limit":       limit,
+		"total_pages": (total + limit - 1) / limit,
 	})
 }
+
+// bindRollHistoryQuery lê filtros, ordenação e paginação do histórico; em caso de erro, responde 400 e retorna ok falso
+func bindRollHistoryQuery(c *gin.Context) (filter models.RollFilter, sort models.RollSort, page, limit int, ok bool) {
+	var query models.RollHistoryQuery
+	err := c.ShouldBindQuery(&query)
+	if err == nil {
+		filter, sort, err = query.Filter()
+	}
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Filtros inválidos",
+			"details": err.Error(),
+		})
+		return filter, sort, 0, 0, false
+	}
+
+	page, limit = query.Pagination(20)
+	return filter, sort, page, limit, true
+}
@@ -0,0 +1,231 @@
+package bff
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/luizdequeiroz/rpg-backend/internal/app/middleware"
+	"github.com/luizdequeiroz/rpg-backend/internal/app/models"
+	"github.com/luizdequeiroz/rpg-backend/internal/app/services"
+)
+
+// RollMacroHandler gerencia endpoints de macros de rolagem
+type RollMacroHandler struct {
+	service *services.RollMacroService
+}
+
+// NewRollMacroHandler cria uma nova instância do handler
+func NewRollMacroHandler(service *services.RollMacroService) *RollMacroHandler {
+	return &RollMacroHandler{
+		service: service,
+	}
+}
+
+// SetupRollMacroRoutes configura as rotas de macros de rolagem
+func (h *RollMacroHandler) SetupRollMacroRoutes(router *gin.RouterGroup, authService *services.AuthService) {
+	macros := router.Group("/macros")
+
+	// Rotas de macros (todas requerem autenticação)
+	macros.Use(middleware.AuthMiddleware(authService))
+	{
+		macros.POST("/", h.CreateMacro)
+		macros.GET("/", h.ListMacros)
+		macros.GET("/:id", h.GetMacro)
+		macros.PUT("/:id", h.UpdateMacro)
+		macros.DELETE("/:id", h.DeleteMacro)
+	}
+}
+
+// CreateMacro godoc
+// @Summary Criar macro de rolagem
+// @Description Cria uma macro nomeada do usuário ou, com sheet_id, da ficha. Os passos podem referenciar campos da ficha entre chaves (e.g., 1d20+{attack_bonus})
+// @Tags Roll Macros
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param body body models.CreateRollMacroRequest true "Dados da macro"
+// @Success 201 {object} models.RollMacroResponse
+// @Failure 400 {object} map[string]interface{} "Dados inválidos"
+// @Failure 401 {object} map[string]interface{} "Não autorizado"
+// @Failure 403 {object} map[string]interface{} "Ficha de outro usuário"
+// @Failure 409 {object} map[string]interface{} "Nome já utilizado"
+// @Router /api/v1/macros [post]
+func (h *RollMacroHandler) CreateMacro(c *gin.Context) {
+	userID, _, exists := middleware.GetUserFromContext(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Usuário não autenticado"})
+		return
+	}
+
+	var req models.CreateRollMacroRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Dados inválidos",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	macro, err := h.service.Create(req, userID)
+	if err != nil {
+		switch err.Error() {
+		case "apenas o proprietário da ficha pode criar macros nela":
+			c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+		case "já existe uma macro com esse nome":
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		}
+		return
+	}
+
+	c.JSON(http.StatusCreated, macro)
+}
+
+// ListMacros godoc
+// @Summary Listar macros de rolagem
+// @Description Lista as macros do usuário ou, com sheet_id, as macros da ficha
+// @Tags Roll Macros
+// @Produce json
+// @Security BearerAuth
+// @Param sheet_id query string false "ID da ficha"
+// @Success 200 {object} map[string]interface{} "Lista de macros"
+// @Failure 401 {object} map[string]interface{} "Não autorizado"
+// @Failure 403 {object} map[string]interface{} "Acesso negado"
+// @Failure 404 {object} map[string]interface{} "Ficha não encontrada"
+// @Failure 500 {object} map[string]interface{} "Erro interno"
+// @Router /api/v1/macros [get]
+func (h *RollMacroHandler) ListMacros(c *gin.Context) {
+	userID, _, exists := middleware.GetUserFromContext(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Usuário não autenticado"})
+		return
+	}
+
+	macros, err := h.service.List(c.Query("sheet_id"), userID)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"macros": macros,
+		"total":  len(macros),
+	})
+}
+
+// GetMacro godoc
+// @Summary Buscar macro de rolagem
+// @Description Retorna uma macro do usuário ou de uma ficha acessível
+// @Tags Roll Macros
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "ID da macro"
+// @Success 200 {object} models.RollMacroResponse
+// @Failure 401 {object} map[string]interface{} "Não autorizado"
+// @Failure 403 {object} map[string]interface{} "Acesso negado"
+// @Failure 404 {object} map[string]interface{} "Macro não encontrada"
+// @Failure 500 {object} map[string]interface{} "Erro interno"
+// @Router /api/v1/macros/{id} [get]
+func (h *RollMacroHandler) GetMacro(c *gin.Context) {
+	userID, _, exists := middleware.GetUserFromContext(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Usuário não autenticado"})
+		return
+	}
+
+	macro, err := h.service.GetByID(c.Param("id"), userID)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, macro)
+}
+
+// UpdateMacro godoc
+// @Summary Atualizar macro de rolagem
+// @Description Atualiza nome, descrição ou passos de uma macro; apenas o dono pode alterá-la
+// @Tags Roll Macros
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "ID da macro"
+// @Param body body models.UpdateRollMacroRequest true "Dados a atualizar"
+// @Success 200 {object} models.RollMacroResponse
+// @Failure 400 {object} map[string]interface{} "Dados inválidos"
+// @Failure 401 {object} map[string]interface{} "Não autorizado"
+// @Failure 403 {object} map[string]interface{} "Macro de outro usuário"
+// @Failure 404 {object} map[string]interface{} "Macro não encontrada"
+// @Failure 409 {object} map[string]interface{} "Nome já utilizado"
+// @Router /api/v1/macros/{id} [put]
+func (h *RollMacroHandler) UpdateMacro(c *gin.Context) {
+	userID, _, exists := middleware.GetUserFromContext(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Usuário não autenticado"})
+		return
+	}
+
+	var req models.UpdateRollMacroRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Dados inválidos",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	macro, err := h.service.Update(c.Param("id"), req, userID)
+	if err != nil {
+		switch err.Error() {
+		case "macro não encontrada", "apenas o dono pode alterar a macro", "já existe uma macro com esse nome":
+			h.handleError(c, err)
+		default:
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, macro)
+}
+
+// DeleteMacro godoc
+// @Summary Remover macro de rolagem
+// @Description Remove uma macro; apenas o dono pode removê-la
+// @Tags Roll Macros
+// @Security BearerAuth
+// @Param id path string true "ID da macro"
+// @Success 204
+// @Failure 401 {object} map[string]interface{} "Não autorizado"
+// @Failure 403 {object} map[string]interface{} "Macro de outro usuário"
+// @Failure 404 {object} map[string]interface{} "Macro não encontrada"
+// @Failure 500 {object} map[string]interface{} "Erro interno"
+// @Router /api/v1/macros/{id} [delete]
+func (h *RollMacroHandler) DeleteMacro(c *gin.Context) {
+	userID, _, exists := middleware.GetUserFromContext(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Usuário não autenticado"})
+		return
+	}
+
+	if err := h.service.Delete(c.Param("id"), userID); err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// handleError converte os erros do serviço de macros em respostas HTTP
+func (h *RollMacroHandler) handleError(c *gin.Context, err error) {
+	switch err.Error() {
+	case "macro não encontrada", "ficha não encontrada":
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case "apenas o dono pode alterar a macro", "acesso negado à mesa":
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+	case "já existe uma macro com esse nome":
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
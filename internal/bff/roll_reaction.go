@@ -0,0 +1,142 @@
+package bff
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/luizdequeiroz/rpg-backend/internal/app/handlers"
+	"github.com/luizdequeiroz/rpg-backend/internal/app/interfaces"
+	"github.com/luizdequeiroz/rpg-backend/internal/app/middleware"
+	"github.com/luizdequeiroz/rpg-backend/internal/app/models"
+	"github.com/luizdequeiroz/rpg-backend/internal/app/services"
+)
+
+// RollReactionHandler gerencia as reações (emoji) às rolagens
+type RollReactionHandler struct {
+	service             *services.RollReactionService
+	sheetService        *services.PlayerSheetService
+	notificationService interfaces.NotificationService
+}
+
+// NewRollReactionHandler cria uma nova instância do handler
+func NewRollReactionHandler(service *services.RollReactionService, sheetService *services.PlayerSheetService, notificationService interfaces.NotificationService) *RollReactionHandler {
+	return &RollReactionHandler{
+		service:             service,
+		sheetService:        sheetService,
+		notificationService: notificationService,
+	}
+}
+
+// SetupRollReactionRoutes configura as rotas de reações às rolagens
+func (h *RollReactionHandler) SetupRollReactionRoutes(router *gin.RouterGroup, authService *services.AuthService) {
+	rolls := router.Group("/rolls")
+
+	// Rotas de reações (todas requerem autenticação)
+	rolls.Use(middleware.AuthMiddleware(authService))
+	{
+		rolls.POST("/:rollID/reactions", h.AddReaction)
+		rolls.DELETE("/:rollID/reactions/:emoji", h.RemoveReaction)
+	}
+}
+
+// AddReaction godoc
+// @Summary Reagir a uma rolagem
+// @Description Adiciona a reação (emoji) do usuário a uma rolagem que ele vê; reagir de novo com o mesmo emoji não tem efeito. Quem vê a rolagem na mesa é notificado
+// @Tags Roll Reactions
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param rollID path string true "ID da rolagem"
+// @Param body body models.RollReactionRequest true "Emoji da reação"
+// @Success 200 {object} models.RollReactionsResponse
+// @Failure 400 {object} map[string]interface{} "Reação inválida"
+// @Failure 401 {object} map[string]interface{} "Não autorizado"
+// @Failure 403 {object} map[string]interface{} "Acesso negado"
+// @Failure 404 {object} map[string]interface{} "Rolagem não encontrada"
+// @Failure 500 {object} map[string]interface{} "Erro interno"
+// @Router /api/v1/rolls/{rollID}/reactions [post]
+func (h *RollReactionHandler) AddReaction(c *gin.Context) {
+	userID, userEmail, exists := middleware.GetUserFromContext(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Usuário não autenticado"})
+		return
+	}
+
+	var req models.RollReactionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Dados inválidos",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	reactions, rollRecord, err := h.service.Add(c.Param("rollID"), userID, req.Emoji)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	h.notify(rollRecord, userID, userEmail, reactions)
+	c.JSON(http.StatusOK, reactions)
+}
+
+// RemoveReaction godoc
+// @Summary Remover reação de uma rolagem
+// @Description Remove a reação (emoji) do usuário a uma rolagem; remover uma reação inexistente não tem efeito. Quem vê a rolagem na mesa é notificado
+// @Tags Roll Reactions
+// @Produce json
+// @Security BearerAuth
+// @Param rollID path string true "ID da rolagem"
+// @Param emoji path string true "Emoji da reação"
+// @Success 200 {object} models.RollReactionsResponse
+// @Failure 400 {object} map[string]interface{} "Reação inválida"
+// @Failure 401 {object} map[string]interface{} "Não autorizado"
+// @Failure 403 {object} map[string]interface{} "Acesso negado"
+// @Failure 404 {object} map[string]interface{} "Rolagem não encontrada"
+// @Failure 500 {object} map[string]interface{} "Erro interno"
+// @Router /api/v1/rolls/{rollID}/reactions/{emoji} [delete]
+func (h *RollReactionHandler) RemoveReaction(c *gin.Context) {
+	userID, userEmail, exists := middleware.GetUserFromContext(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Usuário não autenticado"})
+		return
+	}
+
+	reactions, rollRecord, err := h.service.Remove(c.Param("rollID"), userID, c.Param("emoji"))
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	h.notify(rollRecord, userID, userEmail, reactions)
+	c.JSON(http.StatusOK, reactions)
+}
+
+// notify avisa quem vê a rolagem na mesa; as reações não revelam o resultado, então todos recebem os mesmos dados
+func (h *RollReactionHandler) notify(rollRecord *models.Roll, userID int, userEmail string, reactions *models.RollReactionsResponse) {
+	if h.notificationService == nil || rollRecord.TableID == nil {
+		return
+	}
+
+	gmID, err := h.sheetService.TableOwner(*rollRecord.TableID)
+	if err != nil {
+		return
+	}
+	h.notificationService.NotifyRollReaction(*rollRecord.TableID, userID, userEmail,
+		handlers.RollRecipients(rollRecord.Visibility, rollRecord.UserID, gmID, reactions, reactions))
+}
+
+// handleError converte os erros do serviço de reações em respostas HTTP
+func (h *RollReactionHandler) handleError(c *gin.Context, err error) {
+	switch err.Error() {
+	case "rolagem não encontrada":
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case "acesso negado à mesa":
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+	case "reação inválida: use um emoji", "limite de 10 reações por rolagem atingido":
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
@@ -0,0 +1,179 @@
+package bff
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/luizdequeiroz/rpg-backend/internal/app/middleware"
+	"github.com/luizdequeiroz/rpg-backend/internal/app/models"
+	"github.com/luizdequeiroz/rpg-backend/internal/app/services"
+)
+
+// RollStatsHandler gerencia endpoints de estatísticas de rolagens
+type RollStatsHandler struct {
+	service *services.RollStatsService
+}
+
+// NewRollStatsHandler cria uma nova instância do handler
+func NewRollStatsHandler(service *services.RollStatsService) *RollStatsHandler {
+	return &RollStatsHandler{
+		service: service,
+	}
+}
+
+// SetupRollStatsRoutes configura as rotas de estatísticas de rolagens
+func (h *RollStatsHandler) SetupRollStatsRoutes(router *gin.RouterGroup, authService *services.AuthService) {
+	stats := router.Group("/rolls/stats")
+
+	// Rotas de estatísticas (todas requerem autenticação)
+	stats.Use(middleware.AuthMiddleware(authService))
+	{
+		stats.GET("/me", h.GetUserStats)
+		stats.GET("/sheet/:sheetID", h.GetSheetStats)
+		stats.GET("/table/:tableID", h.GetTableStats)
+	}
+}
+
+// GetUserStats godoc
+// @Summary Estatísticas das minhas rolagens
+// @Description Agrega as rolagens do usuário em todas as mesas: média contra o esperado, críticos, sequências, campos mais rolados e aderência de cada tamanho de dado (qui-quadrado). Rolagens "blind" ficam de fora
+// @Tags Roll Stats
+// @Produce json
+// @Security BearerAuth
+// @Param from query string false "Início do período, inclusivo (RFC3339 ou AAAA-MM-DD)"
+// @Param to query string false "Fim do período, exclusivo; uma data sem hora inclui o dia inteiro (RFC3339 ou AAAA-MM-DD)"
+// @Success 200 {object} models.RollStatsResponse
+// @Failure 400 {object} map[string]interface{} "Período inválido"
+// @Failure 401 {object} map[string]interface{} "Não autorizado"
+// @Failure 500 {object} map[string]interface{} "Erro interno"
+// @Router /api/v1/rolls/stats/me [get]
+func (h *RollStatsHandler) GetUserStats(c *gin.Context) {
+	userID, _, exists := middleware.GetUserFromContext(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Usuário não autenticado"})
+		return
+	}
+
+	from, to, ok := parseStatsPeriod(c)
+	if !ok {
+		return
+	}
+
+	stats, err := h.service.UserStats(userID, from, to)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, stats)
+}
+
+// GetSheetStats godoc
+// @Summary Estatísticas das rolagens da ficha
+// @Description Agrega as rolagens da ficha cujo resultado o usuário pode ver; o mestre inclui as rolagens ocultas dos jogadores
+// @Tags Roll Stats
+// @Produce json
+// @Security BearerAuth
+// @Param sheetID path string true "ID da ficha"
+// @Param from query string false "Início do período, inclusivo (RFC3339 ou AAAA-MM-DD)"
+// @Param to query string false "Fim do período, exclusivo; uma data sem hora inclui o dia inteiro (RFC3339 ou AAAA-MM-DD)"
+// @Success 200 {object} models.RollStatsResponse
+// @Failure 400 {object} map[string]interface{} "Período inválido"
+// @Failure 401 {object} map[string]interface{} "Não autorizado"
+// @Failure 403 {object} map[string]interface{} "Acesso negado"
+// @Failure 404 {object} map[string]interface{} "Ficha não encontrada"
+// @Failure 500 {object} map[string]interface{} "Erro interno"
+// @Router /api/v1/rolls/stats/sheet/{sheetID} [get]
+func (h *RollStatsHandler) GetSheetStats(c *gin.Context) {
+	userID, _, exists := middleware.GetUserFromContext(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Usuário não autenticado"})
+		return
+	}
+
+	from, to, ok := parseStatsPeriod(c)
+	if !ok {
+		return
+	}
+
+	stats, err := h.service.SheetStats(c.Param("sheetID"), userID, from, to)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, stats)
+}
+
+// GetTableStats godoc
+// @Summary Estatísticas das rolagens da mesa
+// @Description Agrega as rolagens da mesa cujo resultado o usuário pode ver, opcionalmente de um único jogador; o mestre inclui as rolagens ocultas dos jogadores
+// @Tags Roll Stats
+// @Produce json
+// @Security BearerAuth
+// @Param tableID path string true "ID da mesa"
+// @Param user_id query int false "ID do jogador"
+// @Param from query string false "Início do período, inclusivo (RFC3339 ou AAAA-MM-DD)"
+// @Param to query string false "Fim do período, exclusivo; uma data sem hora inclui o dia inteiro (RFC3339 ou AAAA-MM-DD)"
+// @Success 200 {object} models.RollStatsResponse
+// @Failure 400 {object} map[string]interface{} "Período ou jogador inválido"
+// @Failure 401 {object} map[string]interface{} "Não autorizado"
+// @Failure 403 {object} map[string]interface{} "Acesso negado"
+// @Failure 500 {object} map[string]interface{} "Erro interno"
+// @Router /api/v1/rolls/stats/table/{tableID} [get]
+func (h *RollStatsHandler) GetTableStats(c *gin.Context) {
+	userID, _, exists := middleware.GetUserFromContext(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Usuário não autenticado"})
+		return
+	}
+
+	var playerID *int
+	if value := c.Query("user_id"); value != "" {
+		id, err := strconv.Atoi(value)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "user_id inválido"})
+			return
+		}
+		playerID = &id
+	}
+
+	from, to, ok := parseStatsPeriod(c)
+	if !ok {
+		return
+	}
+
+	stats, err := h.service.TableStats(c.Param("tableID"), userID, playerID, from, to)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, stats)
+}
+
+// handleError converte os erros do serviço de estatísticas em respostas HTTP
+func (h *RollStatsHandler) handleError(c *gin.Context, err error) {
+	switch err.Error() {
+	case "ficha não encontrada":
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case "acesso negado à mesa":
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+	case "data inicial deve ser anterior à data final":
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
+
+// parseStatsPeriod lê os parâmetros from e to; em caso de erro, responde 400 e retorna ok falso
+func parseStatsPeriod(c *gin.Context) (from, to *time.Time, ok bool) {
+	from, to, err := models.ParseRollPeriod(c.Query("from"), c.Query("to"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return nil, nil, false
+	}
+	return from, to, true
+}
@@ -0,0 +1,206 @@
+package bff
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/luizdequeiroz/rpg-backend/internal/app/interfaces"
+	"github.com/luizdequeiroz/rpg-backend/internal/app/middleware"
+	"github.com/luizdequeiroz/rpg-backend/internal/app/models"
+	"github.com/luizdequeiroz/rpg-backend/internal/app/services"
+)
+
+// SheetConditionHandler gerencia as condições das fichas (e.g., abençoado, envenenado, caído)
+type SheetConditionHandler struct {
+	service             *services.SheetConditionService
+	notificationService interfaces.NotificationService
+}
+
+// NewSheetConditionHandler cria uma nova instância do handler
+func NewSheetConditionHandler(service *services.SheetConditionService, notificationService interfaces.NotificationService) *SheetConditionHandler {
+	return &SheetConditionHandler{
+		service:             service,
+		notificationService: notificationService,
+	}
+}
+
+// SetupSheetConditionRoutes configura as rotas de condições
+func (h *SheetConditionHandler) SetupSheetConditionRoutes(router *gin.RouterGroup, authService *services.AuthService) {
+	auth := middleware.AuthMiddleware(authService)
+
+	// Condições da ficha
+	sheets := router.Group("/sheets/:id/conditions", auth)
+	{
+		sheets.POST("", h.CreateCondition)
+		sheets.GET("", h.ListConditions)
+	}
+
+	// Rotas de condições (todas requerem autenticação)
+	conditions := router.Group("/conditions", auth)
+	{
+		conditions.DELETE("/:conditionID", h.RemoveCondition)
+		conditions.POST("/:conditionID/save", h.SaveAgainstCondition)
+	}
+}
+
+// CreateCondition godoc
+// @Summary Aplicar condição
+// @Description Aplica uma condição à ficha com origem, duração (rounds, minutes, until_save ou indefinite) e modificadores somados às rolagens da ficha (e.g., +1d4 nas rolagens com a tag attack). Rodadas e minutos (10 rodadas cada) expiram com as rodadas dos encontros. Apenas o dono da ficha ou o mestre pode aplicar
+// @Tags Conditions
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "ID da ficha"
+// @Param body body models.CreateConditionRequest true "Dados da condição"
+// @Success 201 {object} models.SheetConditionResponse
+// @Failure 400 {object} map[string]interface{} "Dados inválidos"
+// @Failure 401 {object} map[string]interface{} "Não autorizado"
+// @Failure 403 {object} map[string]interface{} "Ficha de outro usuário"
+// @Failure 404 {object} map[string]interface{} "Ficha não encontrada"
+// @Failure 409 {object} map[string]interface{} "A ficha já tem essa condição"
+// @Router /api/v1/sheets/{id}/conditions [post]
+func (h *SheetConditionHandler) CreateCondition(c *gin.Context) {
+	userID, userEmail, exists := middleware.GetUserFromContext(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Usuário não autenticado"})
+		return
+	}
+
+	var req models.CreateConditionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Dados inválidos",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	event, err := h.service.Create(c.Param("id"), req, userID)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	h.notify(event, userID, userEmail)
+	c.JSON(http.StatusCreated, event.Conditions[0])
+}
+
+// ListConditions godoc
+// @Summary Listar condições da ficha
+// @Description Lista as condições da ficha com as rodadas restantes e os modificadores
+// @Tags Conditions
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "ID da ficha"
+// @Success 200 {array} models.SheetConditionResponse
+// @Failure 401 {object} map[string]interface{} "Não autorizado"
+// @Failure 403 {object} map[string]interface{} "Acesso negado"
+// @Failure 404 {object} map[string]interface{} "Ficha não encontrada"
+// @Router /api/v1/sheets/{id}/conditions [get]
+func (h *SheetConditionHandler) ListConditions(c *gin.Context) {
+	userID, _, exists := middleware.GetUserFromContext(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Usuário não autenticado"})
+		return
+	}
+
+	conditions, err := h.service.List(c.Param("id"), userID)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, conditions)
+}
+
+// RemoveCondition godoc
+// @Summary Remover condição
+// @Description Remove a condição da ficha; apenas o dono da ficha ou o mestre pode remover
+// @Tags Conditions
+// @Produce json
+// @Security BearerAuth
+// @Param conditionID path string true "ID da condição"
+// @Success 200 {object} map[string]interface{}
+// @Failure 401 {object} map[string]interface{} "Não autorizado"
+// @Failure 403 {object} map[string]interface{} "Ficha de outro usuário"
+// @Failure 404 {object} map[string]interface{} "Condição não encontrada"
+// @Router /api/v1/conditions/{conditionID} [delete]
+func (h *SheetConditionHandler) RemoveCondition(c *gin.Context) {
+	userID, userEmail, exists := middleware.GetUserFromContext(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Usuário não autenticado"})
+		return
+	}
+
+	event, err := h.service.Remove(c.Param("conditionID"), userID)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	h.notify(event, userID, userEmail)
+	c.JSON(http.StatusOK, gin.H{"message": "Condição removida com sucesso"})
+}
+
+// SaveAgainstCondition godoc
+// @Summary Resistir a uma condição
+// @Description Rola a resistência da ficha contra uma condição until_save, com a dificuldade informada ou a save_dc da condição. A rolagem é pública e fica no histórico com a tag save; com sucesso, a condição termina
+// @Tags Conditions
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param conditionID path string true "ID da condição"
+// @Param body body models.ConditionSaveRequest true "Rolagem da resistência"
+// @Success 200 {object} models.ConditionSaveResponse
+// @Failure 400 {object} map[string]interface{} "Dados inválidos ou erro na rolagem"
+// @Failure 401 {object} map[string]interface{} "Não autorizado"
+// @Failure 403 {object} map[string]interface{} "Ficha de outro usuário"
+// @Failure 404 {object} map[string]interface{} "Condição não encontrada"
+// @Router /api/v1/conditions/{conditionID}/save [post]
+func (h *SheetConditionHandler) SaveAgainstCondition(c *gin.Context) {
+	userID, userEmail, exists := middleware.GetUserFromContext(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Usuário não autenticado"})
+		return
+	}
+
+	var req models.ConditionSaveRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Dados inválidos",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	result, event, err := h.service.Save(c.Param("conditionID"), req, userID)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	h.notify(event, userID, userEmail)
+	c.JSON(http.StatusOK, result)
+}
+
+// notify avisa a mesa da alteração das condições; as condições são públicas, então todos recebem os mesmos dados
+func (h *SheetConditionHandler) notify(event *models.ConditionEvent, userID int, userEmail string) {
+	if h.notificationService == nil {
+		return
+	}
+	h.notificationService.NotifyConditionUpdated(event.Conditions[0].TableID, userID, userEmail, event)
+}
+
+// handleError converte os erros do serviço de condições em respostas HTTP; os demais são dados inválidos
+func (h *SheetConditionHandler) handleError(c *gin.Context, err error) {
+	switch err.Error() {
+	case "condição não encontrada", "ficha não encontrada":
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case "acesso negado à mesa", "apenas o dono da ficha ou o mestre da mesa pode alterar as condições":
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+	case "a ficha já tem essa condição":
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	}
+}
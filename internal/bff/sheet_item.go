@@ -0,0 +1,411 @@
+package bff
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/luizdequeiroz/rpg-backend/internal/app/interfaces"
+	"github.com/luizdequeiroz/rpg-backend/internal/app/middleware"
+	"github.com/luizdequeiroz/rpg-backend/internal/app/models"
+	"github.com/luizdequeiroz/rpg-backend/internal/app/services"
+)
+
+// SheetItemHandler gerencia o inventário das fichas e a entrega e troca de itens entre fichas da mesa
+type SheetItemHandler struct {
+	service             *services.SheetItemService
+	notificationService interfaces.NotificationService
+}
+
+// NewSheetItemHandler cria uma nova instância do handler
+func NewSheetItemHandler(service *services.SheetItemService, notificationService interfaces.NotificationService) *SheetItemHandler {
+	return &SheetItemHandler{
+		service:             service,
+		notificationService: notificationService,
+	}
+}
+
+// SetupSheetItemRoutes configura as rotas de itens e trocas
+func (h *SheetItemHandler) SetupSheetItemRoutes(router *gin.RouterGroup, authService *services.AuthService) {
+	auth := middleware.AuthMiddleware(authService)
+
+	// Inventário e trocas da ficha
+	sheets := router.Group("/sheets/:id", auth)
+	{
+		sheets.POST("/items", h.CreateItem)
+		sheets.GET("/items", h.GetInventory)
+		sheets.POST("/trades", h.CreateTrade)
+		sheets.GET("/trades", h.ListTrades)
+	}
+
+	// Busca de itens nas fichas da mesa
+	router.GET("/tables/:id/items", auth, h.SearchItems)
+
+	// Rotas de itens (todas requerem autenticação)
+	items := router.Group("/items", auth)
+	{
+		items.PUT("/:itemID", h.UpdateItem)
+		items.DELETE("/:itemID", h.RemoveItem)
+		items.POST("/:itemID/give", h.GiveItem)
+	}
+
+	// Rotas de trocas (todas requerem autenticação)
+	trades := router.Group("/trades", auth)
+	{
+		trades.POST("/:tradeID/accept", h.AcceptTrade)
+		trades.POST("/:tradeID/decline", h.DeclineTrade)
+	}
+}
+
+// CreateItem godoc
+// @Summary Adicionar item
+// @Description Adiciona um item ao inventário da ficha com quantidade, peso unitário e, enquanto equipado, modificadores somados às rolagens da ficha (e.g., +1 nas rolagens com a tag attack) e campos da ficha alterados (e.g., a armadura define ac). Apenas o dono da ficha ou o mestre pode adicionar
+// @Tags Items
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "ID da ficha"
+// @Param body body models.CreateItemRequest true "Dados do item"
+// @Success 201 {object} models.SheetItemResponse
+// @Failure 400 {object} map[string]interface{} "Dados inválidos"
+// @Failure 401 {object} map[string]interface{} "Não autorizado"
+// @Failure 403 {object} map[string]interface{} "Ficha de outro usuário"
+// @Failure 404 {object} map[string]interface{} "Ficha não encontrada"
+// @Router /api/v1/sheets/{id}/items [post]
+func (h *SheetItemHandler) CreateItem(c *gin.Context) {
+	userID, userEmail, exists := middleware.GetUserFromContext(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Usuário não autenticado"})
+		return
+	}
+
+	var req models.CreateItemRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Dados inválidos",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	event, err := h.service.Create(c.Param("id"), req, userID)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	h.notify(event, userID, userEmail)
+	c.JSON(http.StatusCreated, event.Items[0])
+}
+
+// GetInventory godoc
+// @Summary Inventário da ficha
+// @Description Lista os itens da ficha com a carga total, a capacidade declarada em inventory no template (encumbered quando excedida) e os campos da ficha alterados pelos itens equipados
+// @Tags Items
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "ID da ficha"
+// @Success 200 {object} models.InventoryResponse
+// @Failure 401 {object} map[string]interface{} "Não autorizado"
+// @Failure 403 {object} map[string]interface{} "Acesso negado"
+// @Failure 404 {object} map[string]interface{} "Ficha não encontrada"
+// @Router /api/v1/sheets/{id}/items [get]
+func (h *SheetItemHandler) GetInventory(c *gin.Context) {
+	userID, _, exists := middleware.GetUserFromContext(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Usuário não autenticado"})
+		return
+	}
+
+	inventory, err := h.service.Inventory(c.Param("id"), userID)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, inventory)
+}
+
+// SearchItems godoc
+// @Summary Buscar itens da mesa
+// @Description Busca os itens das fichas da mesa pelo nome, em qualquer posição e sem diferenciar maiúsculas, com a ficha que possui cada um (até 100 itens)
+// @Tags Items
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "ID da mesa"
+// @Param name query string false "Trecho do nome do item"
+// @Success 200 {array} models.TableItemResponse
+// @Failure 401 {object} map[string]interface{} "Não autorizado"
+// @Failure 403 {object} map[string]interface{} "Acesso negado"
+// @Failure 404 {object} map[string]interface{} "Mesa não encontrada"
+// @Router /api/v1/tables/{id}/items [get]
+func (h *SheetItemHandler) SearchItems(c *gin.Context) {
+	userID, _, exists := middleware.GetUserFromContext(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Usuário não autenticado"})
+		return
+	}
+
+	items, err := h.service.Search(c.Param("id"), c.Query("name"), userID)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, items)
+}
+
+// UpdateItem godoc
+// @Summary Atualizar item
+// @Description Atualiza os campos informados do item (e.g., equipped para equipar ou desequipar); apenas o dono da ficha ou o mestre pode atualizar
+// @Tags Items
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param itemID path string true "ID do item"
+// @Param body body models.UpdateItemRequest true "Campos alterados"
+// @Success 200 {object} models.SheetItemResponse
+// @Failure 400 {object} map[string]interface{} "Dados inválidos"
+// @Failure 401 {object} map[string]interface{} "Não autorizado"
+// @Failure 403 {object} map[string]interface{} "Ficha de outro usuário"
+// @Failure 404 {object} map[string]interface{} "Item não encontrado"
+// @Router /api/v1/items/{itemID} [put]
+func (h *SheetItemHandler) UpdateItem(c *gin.Context) {
+	userID, userEmail, exists := middleware.GetUserFromContext(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Usuário não autenticado"})
+		return
+	}
+
+	var req models.UpdateItemRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Dados inválidos",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	event, err := h.service.Update(c.Param("itemID"), req, userID)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	h.notify(event, userID, userEmail)
+	c.JSON(http.StatusOK, event.Items[0])
+}
+
+// RemoveItem godoc
+// @Summary Remover item
+// @Description Remove o item do inventário; apenas o dono da ficha ou o mestre pode remover
+// @Tags Items
+// @Produce json
+// @Security BearerAuth
+// @Param itemID path string true "ID do item"
+// @Success 200 {object} map[string]interface{}
+// @Failure 401 {object} map[string]interface{} "Não autorizado"
+// @Failure 403 {object} map[string]interface{} "Ficha de outro usuário"
+// @Failure 404 {object} map[string]interface{} "Item não encontrado"
+// @Router /api/v1/items/{itemID} [delete]
+func (h *SheetItemHandler) RemoveItem(c *gin.Context) {
+	userID, userEmail, exists := middleware.GetUserFromContext(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Usuário não autenticado"})
+		return
+	}
+
+	event, err := h.service.Remove(c.Param("itemID"), userID)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	h.notify(event, userID, userEmail)
+	c.JSON(http.StatusOK, gin.H{"message": "Item removido com sucesso"})
+}
+
+// GiveItem godoc
+// @Summary Entregar item
+// @Description Entrega unidades do item (padrão: todas) a outra ficha da mesma mesa. As unidades chegam desequipadas e se juntam a um item igual da outra ficha; apenas o dono da ficha ou o mestre pode entregar
+// @Tags Items
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param itemID path string true "ID do item"
+// @Param body body models.GiveItemRequest true "Ficha que recebe e quantidade"
+// @Success 200 {object} models.InventoryEvent
+// @Failure 400 {object} map[string]interface{} "Dados inválidos ou ficha de outra mesa"
+// @Failure 401 {object} map[string]interface{} "Não autorizado"
+// @Failure 403 {object} map[string]interface{} "Ficha de outro usuário"
+// @Failure 404 {object} map[string]interface{} "Item ou ficha não encontrado"
+// @Failure 409 {object} map[string]interface{} "O item foi alterado por outra ação"
+// @Router /api/v1/items/{itemID}/give [post]
+func (h *SheetItemHandler) GiveItem(c *gin.Context) {
+	userID, userEmail, exists := middleware.GetUserFromContext(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Usuário não autenticado"})
+		return
+	}
+
+	var req models.GiveItemRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Dados inválidos",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	event, err := h.service.Give(c.Param("itemID"), req, userID)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	h.notify(event, userID, userEmail)
+	c.JSON(http.StatusOK, event)
+}
+
+// CreateTrade godoc
+// @Summary Propor troca
+// @Description Propõe a outra ficha da mesma mesa uma troca entre itens oferecidos pela ficha e pedidos à outra. Os itens só mudam de ficha quando o dono da outra ficha, ou o mestre, aceita
+// @Tags Items
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "ID da ficha que propõe"
+// @Param body body models.CreateTradeRequest true "Itens da troca"
+// @Success 201 {object} models.ItemTradeResponse
+// @Failure 400 {object} map[string]interface{} "Dados inválidos ou itens indisponíveis"
+// @Failure 401 {object} map[string]interface{} "Não autorizado"
+// @Failure 403 {object} map[string]interface{} "Ficha de outro usuário"
+// @Failure 404 {object} map[string]interface{} "Ficha ou item não encontrado"
+// @Router /api/v1/sheets/{id}/trades [post]
+func (h *SheetItemHandler) CreateTrade(c *gin.Context) {
+	userID, userEmail, exists := middleware.GetUserFromContext(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Usuário não autenticado"})
+		return
+	}
+
+	var req models.CreateTradeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Dados inválidos",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	event, err := h.service.CreateTrade(c.Param("id"), req, userID)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	h.notify(event, userID, userEmail)
+	c.JSON(http.StatusCreated, event.Trade)
+}
+
+// ListTrades godoc
+// @Summary Listar trocas pendentes
+// @Description Lista as trocas pendentes propostas pela ficha ou a ela, da mais recente para a mais antiga
+// @Tags Items
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "ID da ficha"
+// @Success 200 {array} models.ItemTradeResponse
+// @Failure 401 {object} map[string]interface{} "Não autorizado"
+// @Failure 403 {object} map[string]interface{} "Acesso negado"
+// @Failure 404 {object} map[string]interface{} "Ficha não encontrada"
+// @Router /api/v1/sheets/{id}/trades [get]
+func (h *SheetItemHandler) ListTrades(c *gin.Context) {
+	userID, _, exists := middleware.GetUserFromContext(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Usuário não autenticado"})
+		return
+	}
+
+	trades, err := h.service.ListTrades(c.Param("id"), userID)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, trades)
+}
+
+// AcceptTrade godoc
+// @Summary Aceitar troca
+// @Description Efetiva a troca, movendo os itens dos dois lados de uma vez; apenas o dono da ficha que recebeu a proposta ou o mestre pode aceitar
+// @Tags Items
+// @Produce json
+// @Security BearerAuth
+// @Param tradeID path string true "ID da troca"
+// @Success 200 {object} models.InventoryEvent
+// @Failure 401 {object} map[string]interface{} "Não autorizado"
+// @Failure 403 {object} map[string]interface{} "Ficha de outro usuário"
+// @Failure 404 {object} map[string]interface{} "Troca não encontrada"
+// @Failure 409 {object} map[string]interface{} "Troca encerrada ou itens indisponíveis"
+// @Router /api/v1/trades/{tradeID}/accept [post]
+func (h *SheetItemHandler) AcceptTrade(c *gin.Context) {
+	h.resolveTrade(c, h.service.AcceptTrade)
+}
+
+// DeclineTrade godoc
+// @Summary Recusar ou cancelar troca
+// @Description Encerra a troca sem mover itens: a ficha que propôs cancela (canceled) e a que recebeu, ou o mestre, recusa (declined)
+// @Tags Items
+// @Produce json
+// @Security BearerAuth
+// @Param tradeID path string true "ID da troca"
+// @Success 200 {object} models.InventoryEvent
+// @Failure 401 {object} map[string]interface{} "Não autorizado"
+// @Failure 403 {object} map[string]interface{} "Ficha de outro usuário"
+// @Failure 404 {object} map[string]interface{} "Troca não encontrada"
+// @Failure 409 {object} map[string]interface{} "Troca encerrada"
+// @Router /api/v1/trades/{tradeID}/decline [post]
+func (h *SheetItemHandler) DeclineTrade(c *gin.Context) {
+	h.resolveTrade(c, h.service.DeclineTrade)
+}
+
+// resolveTrade executa o aceite ou a recusa da troca e notifica a mesa
+func (h *SheetItemHandler) resolveTrade(c *gin.Context, resolve func(tradeID string, userID int) (*models.InventoryEvent, error)) {
+	userID, userEmail, exists := middleware.GetUserFromContext(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Usuário não autenticado"})
+		return
+	}
+
+	event, err := resolve(c.Param("tradeID"), userID)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	h.notify(event, userID, userEmail)
+	c.JSON(http.StatusOK, event)
+}
+
+// notify avisa a mesa da alteração dos inventários; os inventários são públicos, então todos recebem os mesmos dados
+func (h *SheetItemHandler) notify(event *models.InventoryEvent, userID int, userEmail string) {
+	if h.notificationService == nil {
+		return
+	}
+	h.notificationService.NotifyInventoryUpdated(event.TableID, userID, userEmail, event)
+}
+
+// handleError converte os erros do serviço de itens em respostas HTTP; os demais são dados inválidos
+func (h *SheetItemHandler) handleError(c *gin.Context, err error) {
+	switch err.Error() {
+	case "item não encontrado", "ficha não encontrada", "troca não encontrada", "mesa não encontrada":
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case "acesso negado à mesa", "apenas o dono da ficha ou o mestre da mesa pode alterar o inventário":
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+	case "a troca não está mais pendente", "os itens da troca não estão mais disponíveis",
+		"o item foi alterado por outra ação; tente novamente":
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	}
+}
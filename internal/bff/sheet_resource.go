@@ -0,0 +1,195 @@
+package bff
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/luizdequeiroz/rpg-backend/internal/app/interfaces"
+	"github.com/luizdequeiroz/rpg-backend/internal/app/middleware"
+	"github.com/luizdequeiroz/rpg-backend/internal/app/models"
+	"github.com/luizdequeiroz/rpg-backend/internal/app/services"
+)
+
+// SheetResourceHandler gerencia dano, cura e gasto de recursos das fichas (e.g., PV, ki, espaços de magia)
+type SheetResourceHandler struct {
+	service             *services.SheetResourceService
+	notificationService interfaces.NotificationService
+}
+
+// NewSheetResourceHandler cria uma nova instância do handler
+func NewSheetResourceHandler(service *services.SheetResourceService, notificationService interfaces.NotificationService) *SheetResourceHandler {
+	return &SheetResourceHandler{
+		service:             service,
+		notificationService: notificationService,
+	}
+}
+
+// SetupSheetResourceRoutes configura as rotas de recursos das fichas
+func (h *SheetResourceHandler) SetupSheetResourceRoutes(router *gin.RouterGroup, authService *services.AuthService) {
+	// Operações sobre os recursos da ficha (todas requerem autenticação)
+	sheets := router.Group("/sheets/:id", middleware.AuthMiddleware(authService))
+	{
+		sheets.POST("/damage", h.Damage)
+		sheets.POST("/heal", h.Heal)
+		sheets.POST("/temp-hp", h.TempHP)
+		sheets.POST("/spend", h.Spend)
+		sheets.POST("/restore", h.Restore)
+	}
+}
+
+// Damage godoc
+// @Summary Aplicar dano
+// @Description Aplica dano a um recurso declarado no template (padrão: hp). Imunidade, resistência (metade) e vulnerabilidade (dobro) ao tipo de dano vêm dos campos da ficha declarados em defenses; os pontos temporários são consumidos antes e o valor não passa do mínimo. Apenas o dono da ficha ou o mestre pode aplicar
+// @Tags Resources
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "ID da ficha"
+// @Param body body models.DamageRequest true "Dano"
+// @Success 200 {object} models.SheetDeltaEvent
+// @Failure 400 {object} map[string]interface{} "Dados inválidos ou recurso não declarado"
+// @Failure 401 {object} map[string]interface{} "Não autorizado"
+// @Failure 403 {object} map[string]interface{} "Ficha de outro usuário"
+// @Failure 404 {object} map[string]interface{} "Ficha não encontrada"
+// @Failure 409 {object} map[string]interface{} "A ficha foi alterada por outra ação"
+// @Router /api/v1/sheets/{id}/damage [post]
+func (h *SheetResourceHandler) Damage(c *gin.Context) {
+	var req models.DamageRequest
+	h.handle(c, &req, func(userID int) (*models.SheetDeltaEvent, error) {
+		return h.service.Damage(c.Param("id"), req, userID)
+	})
+}
+
+// Heal godoc
+// @Summary Curar
+// @Description Soma a cura a um recurso declarado no template (padrão: hp) sem passar do máximo (max ou max_path); os pontos temporários não mudam
+// @Tags Resources
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "ID da ficha"
+// @Param body body models.ResourceAmountRequest true "Cura"
+// @Success 200 {object} models.SheetDeltaEvent
+// @Failure 400 {object} map[string]interface{} "Dados inválidos ou recurso não declarado"
+// @Failure 401 {object} map[string]interface{} "Não autorizado"
+// @Failure 403 {object} map[string]interface{} "Ficha de outro usuário"
+// @Failure 404 {object} map[string]interface{} "Ficha não encontrada"
+// @Failure 409 {object} map[string]interface{} "A ficha foi alterada por outra ação"
+// @Router /api/v1/sheets/{id}/heal [post]
+func (h *SheetResourceHandler) Heal(c *gin.Context) {
+	var req models.ResourceAmountRequest
+	h.handle(c, &req, func(userID int) (*models.SheetDeltaEvent, error) {
+		return h.service.Heal(c.Param("id"), req, userID)
+	})
+}
+
+// TempHP godoc
+// @Summary Conceder pontos temporários
+// @Description Concede pontos temporários ao recurso (padrão: hp), no campo temp_path declarado no template. Não se acumulam: fica o maior valor entre o atual e o concedido
+// @Tags Resources
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "ID da ficha"
+// @Param body body models.ResourceAmountRequest true "Pontos temporários"
+// @Success 200 {object} models.SheetDeltaEvent
+// @Failure 400 {object} map[string]interface{} "Dados inválidos ou recurso sem pontos temporários"
+// @Failure 401 {object} map[string]interface{} "Não autorizado"
+// @Failure 403 {object} map[string]interface{} "Ficha de outro usuário"
+// @Failure 404 {object} map[string]interface{} "Ficha não encontrada"
+// @Failure 409 {object} map[string]interface{} "A ficha foi alterada por outra ação"
+// @Router /api/v1/sheets/{id}/temp-hp [post]
+func (h *SheetResourceHandler) TempHP(c *gin.Context) {
+	var req models.ResourceAmountRequest
+	h.handle(c, &req, func(userID int) (*models.SheetDeltaEvent, error) {
+		return h.service.TempHP(c.Param("id"), req, userID)
+	})
+}
+
+// Spend godoc
+// @Summary Gastar recurso
+// @Description Gasta a quantidade (padrão: 1) de um recurso declarado no template (e.g., ki, espaços de magia); falha se o recurso ficaria abaixo do mínimo
+// @Tags Resources
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "ID da ficha"
+// @Param body body models.ResourceAmountRequest true "Recurso e quantidade"
+// @Success 200 {object} models.SheetDeltaEvent
+// @Failure 400 {object} map[string]interface{} "Dados inválidos ou recurso não declarado"
+// @Failure 401 {object} map[string]interface{} "Não autorizado"
+// @Failure 403 {object} map[string]interface{} "Ficha de outro usuário"
+// @Failure 404 {object} map[string]interface{} "Ficha não encontrada"
+// @Failure 409 {object} map[string]interface{} "Recurso insuficiente ou ficha alterada por outra ação"
+// @Router /api/v1/sheets/{id}/spend [post]
+func (h *SheetResourceHandler) Spend(c *gin.Context) {
+	var req models.ResourceAmountRequest
+	h.handle(c, &req, func(userID int) (*models.SheetDeltaEvent, error) {
+		return h.service.Spend(c.Param("id"), req, userID)
+	})
+}
+
+// Restore godoc
+// @Summary Recuperar recurso
+// @Description Recupera a quantidade de um recurso declarado no template sem passar do máximo; sem quantidade, recupera até o máximo
+// @Tags Resources
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "ID da ficha"
+// @Param body body models.ResourceAmountRequest true "Recurso e quantidade"
+// @Success 200 {object} models.SheetDeltaEvent
+// @Failure 400 {object} map[string]interface{} "Dados inválidos ou recurso não declarado"
+// @Failure 401 {object} map[string]interface{} "Não autorizado"
+// @Failure 403 {object} map[string]interface{} "Ficha de outro usuário"
+// @Failure 404 {object} map[string]interface{} "Ficha não encontrada"
+// @Failure 409 {object} map[string]interface{} "A ficha foi alterada por outra ação"
+// @Router /api/v1/sheets/{id}/restore [post]
+func (h *SheetResourceHandler) Restore(c *gin.Context) {
+	var req models.ResourceAmountRequest
+	h.handle(c, &req, func(userID int) (*models.SheetDeltaEvent, error) {
+		return h.service.Restore(c.Param("id"), req, userID)
+	})
+}
+
+// handle lê a requisição, executa a operação e notifica a mesa dos campos alterados
+func (h *SheetResourceHandler) handle(c *gin.Context, req interface{}, operation func(userID int) (*models.SheetDeltaEvent, error)) {
+	userID, userEmail, exists := middleware.GetUserFromContext(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Usuário não autenticado"})
+		return
+	}
+
+	if err := c.ShouldBindJSON(req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Dados inválidos",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	event, err := operation(userID)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	if h.notificationService != nil && len(event.Changes) > 0 {
+		h.notificationService.NotifySheetDelta(event.TableID, userID, userEmail, event)
+	}
+	c.JSON(http.StatusOK, event)
+}
+
+// handleError converte os erros do serviço de recursos em respostas HTTP; os demais são dados inválidos
+func (h *SheetResourceHandler) handleError(c *gin.Context, err error) {
+	switch err.Error() {
+	case "ficha não encontrada":
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case "acesso negado à mesa", "apenas o dono da ficha ou o mestre da mesa pode alterar os recursos":
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+	case "recurso insuficiente", "a ficha foi alterada por outra ação; tente novamente":
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	}
+}
@@ -0,0 +1,171 @@
+package bff
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/luizdequeiroz/rpg-backend/internal/app/interfaces"
+	"github.com/luizdequeiroz/rpg-backend/internal/app/middleware"
+	"github.com/luizdequeiroz/rpg-backend/internal/app/models"
+	"github.com/luizdequeiroz/rpg-backend/internal/app/services"
+)
+
+// SheetRestHandler gerencia os descansos das fichas e da mesa, que renovam recursos como espaços de magia e ki
+type SheetRestHandler struct {
+	service             *services.SheetRestService
+	notificationService interfaces.NotificationService
+}
+
+// NewSheetRestHandler cria uma nova instância do handler
+func NewSheetRestHandler(service *services.SheetRestService, notificationService interfaces.NotificationService) *SheetRestHandler {
+	return &SheetRestHandler{
+		service:             service,
+		notificationService: notificationService,
+	}
+}
+
+// SetupSheetRestRoutes configura as rotas de descanso
+func (h *SheetRestHandler) SetupSheetRestRoutes(router *gin.RouterGroup, authService *services.AuthService) {
+	auth := middleware.AuthMiddleware(authService)
+
+	// Descanso de uma ficha e seu histórico
+	router.POST("/sheets/:id/rest", auth, h.RestSheet)
+	router.GET("/sheets/:id/rests", auth, h.GetSheetRests)
+
+	// Descanso da mesa inteira e o histórico das fichas da mesa
+	router.POST("/tables/:id/rest", auth, h.RestTable)
+	router.GET("/tables/:id/rests", auth, h.GetTableRests)
+}
+
+// RestSheet godoc
+// @Summary Descansar ficha
+// @Description Renova os recursos da ficha declarados no template com refresh: o descanso curto (short_rest) renova os recursos short_rest, o longo (long_rest) os short_rest e long_rest, e o novo dia (daily) os daily. Recursos manual só mudam pela recuperação manual. Cada recurso volta ao máximo ou recupera refresh_amount ou refresh_percent do máximo. Apenas o dono da ficha ou o mestre pode descansar
+// @Tags Resources
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "ID da ficha"
+// @Param body body models.RestRequest true "Tipo de descanso"
+// @Success 200 {object} models.RestEvent
+// @Failure 400 {object} map[string]interface{} "Dados inválidos"
+// @Failure 401 {object} map[string]interface{} "Não autorizado"
+// @Failure 403 {object} map[string]interface{} "Ficha de outro usuário"
+// @Failure 404 {object} map[string]interface{} "Ficha não encontrada"
+// @Failure 409 {object} map[string]interface{} "A ficha foi alterada por outra ação"
+// @Router /api/v1/sheets/{id}/rest [post]
+func (h *SheetRestHandler) RestSheet(c *gin.Context) {
+	h.rest(c, h.service.RestSheet)
+}
+
+// RestTable godoc
+// @Summary Descansar mesa
+// @Description Renova de uma vez, com as mesmas regras do descanso da ficha, os recursos de todas as fichas da mesa; nada é gravado se alguma ficha falhar. Apenas o mestre pode conceder
+// @Tags Resources
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "ID da mesa"
+// @Param body body models.RestRequest true "Tipo de descanso"
+// @Success 200 {object} models.RestEvent
+// @Failure 400 {object} map[string]interface{} "Dados inválidos ou mesa sem fichas"
+// @Failure 401 {object} map[string]interface{} "Não autorizado"
+// @Failure 403 {object} map[string]interface{} "Apenas o mestre"
+// @Failure 404 {object} map[string]interface{} "Mesa não encontrada"
+// @Failure 409 {object} map[string]interface{} "Uma ficha foi alterada por outra ação"
+// @Router /api/v1/tables/{id}/rest [post]
+func (h *SheetRestHandler) RestTable(c *gin.Context) {
+	h.rest(c, h.service.RestTable)
+}
+
+// GetSheetRests godoc
+// @Summary Histórico de descansos da ficha
+// @Description Lista os 50 descansos mais recentes da ficha com os campos renovados em cada um
+// @Tags Resources
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "ID da ficha"
+// @Success 200 {array} models.SheetRestResponse
+// @Failure 401 {object} map[string]interface{} "Não autorizado"
+// @Failure 403 {object} map[string]interface{} "Acesso negado"
+// @Failure 404 {object} map[string]interface{} "Ficha não encontrada"
+// @Router /api/v1/sheets/{id}/rests [get]
+func (h *SheetRestHandler) GetSheetRests(c *gin.Context) {
+	h.history(c, h.service.SheetHistory)
+}
+
+// GetTableRests godoc
+// @Summary Histórico de descansos da mesa
+// @Description Lista os 50 descansos mais recentes das fichas da mesa com os campos renovados em cada um
+// @Tags Resources
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "ID da mesa"
+// @Success 200 {array} models.SheetRestResponse
+// @Failure 401 {object} map[string]interface{} "Não autorizado"
+// @Failure 403 {object} map[string]interface{} "Acesso negado"
+// @Failure 404 {object} map[string]interface{} "Mesa não encontrada"
+// @Router /api/v1/tables/{id}/rests [get]
+func (h *SheetRestHandler) GetTableRests(c *gin.Context) {
+	h.history(c, h.service.TableHistory)
+}
+
+// rest valida o tipo de descanso, executa-o e notifica a mesa
+func (h *SheetRestHandler) rest(c *gin.Context, rest func(id string, req models.RestRequest, userID int) (*models.RestEvent, error)) {
+	userID, userEmail, exists := middleware.GetUserFromContext(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Usuário não autenticado"})
+		return
+	}
+
+	var req models.RestRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Dados inválidos",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	event, err := rest(c.Param("id"), req, userID)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	if h.notificationService != nil {
+		h.notificationService.NotifySheetRested(event.TableID, userID, userEmail, event)
+	}
+	c.JSON(http.StatusOK, event)
+}
+
+// history lista o histórico de descansos da ficha ou da mesa
+func (h *SheetRestHandler) history(c *gin.Context, list func(id string, userID int) ([]*models.SheetRestResponse, error)) {
+	userID, _, exists := middleware.GetUserFromContext(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Usuário não autenticado"})
+		return
+	}
+
+	rests, err := list(c.Param("id"), userID)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, rests)
+}
+
+// handleError converte os erros do serviço de descanso em respostas HTTP; os demais são dados inválidos
+func (h *SheetRestHandler) handleError(c *gin.Context, err error) {
+	switch err.Error() {
+	case "ficha não encontrada", "mesa não encontrada":
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case "acesso negado à mesa", "apenas o dono da ficha ou o mestre da mesa pode alterar os recursos",
+		"apenas o mestre da mesa pode conceder descanso à mesa":
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+	case "a ficha foi alterada por outra ação; tente novamente":
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	}
+}
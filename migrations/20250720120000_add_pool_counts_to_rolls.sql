@@ -0,0 +1,8 @@
+-- +goose Up
+-- Contagem de sucessos e falhas de paradas de dados (e.g., "8d10>=8")
+ALTER TABLE rolls ADD COLUMN successes INTEGER;
+ALTER TABLE rolls ADD COLUMN failures INTEGER;
+
+-- +goose Down
+ALTER TABLE rolls DROP COLUMN failures;
+ALTER TABLE rolls DROP COLUMN successes;
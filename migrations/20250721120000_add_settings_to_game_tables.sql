@@ -0,0 +1,6 @@
+-- +goose Up
+-- Configurações da mesa em JSON (e.g., dados personalizados)
+ALTER TABLE game_tables ADD COLUMN settings TEXT NOT NULL DEFAULT '{}';
+
+-- +goose Down
+ALTER TABLE game_tables DROP COLUMN settings;
@@ -0,0 +1,99 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Rolagens livres (/dice/roll) não têm ficha nem mesa, então sheet_id e table_id passam a ser opcionais
+-- SQLite não suporta ALTER COLUMN, então recriaremos a tabela
+CREATE TABLE rolls_new (
+    id VARCHAR(36) PRIMARY KEY DEFAULT (lower(hex(randomblob(4))) || '-' || lower(hex(randomblob(2))) || '-' || '4' || substr(lower(hex(randomblob(2))),2) || '-' || substr('ab89',abs(random()) % 4 + 1, 1) || substr(lower(hex(randomblob(2))),2) || '-' || lower(hex(randomblob(6)))),
+    sheet_id VARCHAR(36),
+    table_id VARCHAR(36),
+    user_id INTEGER NOT NULL,
+    expression VARCHAR(200) NOT NULL,
+    field_name VARCHAR(100),
+    result_value INTEGER NOT NULL,
+    result_details TEXT, -- JSON com detalhes da rolagem
+    success BOOLEAN,
+    successes INTEGER,
+    failures INTEGER,
+    created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+
+    FOREIGN KEY (sheet_id) REFERENCES player_sheets(id) ON DELETE CASCADE,
+    FOREIGN KEY (table_id) REFERENCES game_tables(id) ON DELETE CASCADE,
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+);
+
+-- Copiar dados existentes
+INSERT INTO rolls_new (id, sheet_id, table_id, user_id, expression, field_name, result_value, result_details, success, successes, failures, created_at)
+SELECT id, sheet_id, table_id, user_id, expression, field_name, result_value, result_details, success, successes, failures, created_at
+FROM rolls;
+
+-- Remover tabela antiga
+DROP TABLE rolls;
+
+-- Renomear nova tabela
+ALTER TABLE rolls_new RENAME TO rolls;
+
+-- Recriar índices
+CREATE INDEX idx_rolls_sheet_id ON rolls(sheet_id);
+CREATE INDEX idx_rolls_table_id ON rolls(table_id);
+CREATE INDEX idx_rolls_user_id ON rolls(user_id);
+CREATE INDEX idx_rolls_created_at ON rolls(created_at);
+
+-- Converter detalhes em texto do antigo DiceService (e.g., "[15] +3 = 18", "[3, 4] = 7") para JSON
+-- Crítico e fumble seguem a regra antiga: apenas um único d20
+UPDATE rolls
+SET result_details = json_object(
+    'dice', json(converted.dice),
+    'modifier', rolls.result_value - (SELECT COALESCE(SUM(value), 0) FROM json_each(converted.dice)),
+    'total', rolls.result_value,
+    'critical', json(CASE WHEN converted.single_d20 AND json_array_length(converted.dice) = 1 AND json_extract(converted.dice, '$[0]') = 20 THEN 'true' ELSE 'false' END),
+    'fumble', json(CASE WHEN converted.single_d20 AND json_array_length(converted.dice) = 1 AND json_extract(converted.dice, '$[0]') = 1 THEN 'true' ELSE 'false' END)
+)
+FROM (
+    SELECT
+        id,
+        '[' || substr(result_details, 2, instr(result_details, ']') - 2) || ']' AS dice,
+        (lower(replace(expression, ' ', '')) GLOB '*d20' OR lower(replace(expression, ' ', '')) GLOB '*d20[+-]*') AS single_d20
+    FROM rolls
+    WHERE result_details LIKE '[%]%'
+) AS converted
+WHERE rolls.id = converted.id
+  AND json_valid(converted.dice);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+-- Restaurar sheet_id e table_id obrigatórios; rolagens livres são descartadas
+-- A conversão dos detalhes para JSON não é revertida
+CREATE TABLE rolls_old (
+    id VARCHAR(36) PRIMARY KEY DEFAULT (lower(hex(randomblob(4))) || '-' || lower(hex(randomblob(2))) || '-' || '4' || substr(lower(hex(randomblob(2))),2) || '-' || substr('ab89',abs(random()) % 4 + 1, 1) || substr(lower(hex(randomblob(2))),2) || '-' || lower(hex(randomblob(6)))),
+    sheet_id VARCHAR(36) NOT NULL,
+    table_id VARCHAR(36) NOT NULL,
+    user_id INTEGER NOT NULL,
+    expression VARCHAR(200) NOT NULL,
+    field_name VARCHAR(100),
+    result_value INTEGER NOT NULL,
+    result_details TEXT, -- JSON com detalhes da rolagem
+    success BOOLEAN,
+    successes INTEGER,
+    failures INTEGER,
+    created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+
+    FOREIGN KEY (sheet_id) REFERENCES player_sheets(id) ON DELETE CASCADE,
+    FOREIGN KEY (table_id) REFERENCES game_tables(id) ON DELETE CASCADE,
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+);
+
+INSERT INTO rolls_old (id, sheet_id, table_id, user_id, expression, field_name, result_value, result_details, success, successes, failures, created_at)
+SELECT id, sheet_id, table_id, user_id, expression, field_name, result_value, result_details, success, successes, failures, created_at
+FROM rolls
+WHERE sheet_id IS NOT NULL AND table_id IS NOT NULL;
+
+DROP TABLE rolls;
+
+ALTER TABLE rolls_old RENAME TO rolls;
+
+CREATE INDEX idx_rolls_sheet_id ON rolls(sheet_id);
+CREATE INDEX idx_rolls_table_id ON rolls(table_id);
+CREATE INDEX idx_rolls_user_id ON rolls(user_id);
+CREATE INDEX idx_rolls_created_at ON rolls(created_at);
+-- +goose StatementEnd
@@ -0,0 +1,36 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Sementes do servidor para rolagens verificáveis (commit-reveal).
+-- Cada usuário tem uma semente ativa, publicada apenas pelo hash; a semente é revelada
+-- na verificação de uma rolagem e, a partir daí, o usuário passa a usar uma nova
+CREATE TABLE roll_seeds (
+    id VARCHAR(36) PRIMARY KEY,
+    user_id INTEGER NOT NULL,
+    server_seed VARCHAR(64) NOT NULL,
+    server_seed_hash VARCHAR(64) NOT NULL,
+    next_nonce INTEGER NOT NULL DEFAULT 0,
+    revealed_at DATETIME,
+    created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+);
+
+CREATE INDEX idx_roll_seeds_user_active ON roll_seeds(user_id, revealed_at);
+
+-- Dados de verificação de cada rolagem; nulos em rolagens anteriores
+ALTER TABLE rolls ADD COLUMN seed_id VARCHAR(36) REFERENCES roll_seeds(id);
+ALTER TABLE rolls ADD COLUMN server_seed_hash VARCHAR(64);
+ALTER TABLE rolls ADD COLUMN client_seed VARCHAR(64);
+ALTER TABLE rolls ADD COLUMN nonce INTEGER;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE rolls DROP COLUMN nonce;
+ALTER TABLE rolls DROP COLUMN client_seed;
+ALTER TABLE rolls DROP COLUMN server_seed_hash;
+ALTER TABLE rolls DROP COLUMN seed_id;
+
+DROP INDEX IF EXISTS idx_roll_seeds_user_active;
+DROP TABLE IF EXISTS roll_seeds;
+-- +goose StatementEnd
@@ -0,0 +1,8 @@
+-- +goose Up
+-- Resultado nomeado do teste (e.g., "sucesso parcial") e dificuldade usada; success já existia
+ALTER TABLE rolls ADD COLUMN outcome VARCHAR(50);
+ALTER TABLE rolls ADD COLUMN difficulty INTEGER;
+
+-- +goose Down
+ALTER TABLE rolls DROP COLUMN difficulty;
+ALTER TABLE rolls DROP COLUMN outcome;
@@ -0,0 +1,37 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Rolagens resistidas entre duas fichas da mesma mesa (e.g., agarrar, furtividade contra percepção).
+-- As duas rolagens ficam em rolls, ligadas à disputa por contest_id
+CREATE TABLE roll_contests (
+    id VARCHAR(36) PRIMARY KEY,
+    table_id VARCHAR(36) NOT NULL,
+    user_id INTEGER NOT NULL,
+    attacker_sheet_id VARCHAR(36) NOT NULL,
+    defender_sheet_id VARCHAR(36) NOT NULL,
+    attacker_roll_id VARCHAR(36) NOT NULL,
+    defender_roll_id VARCHAR(36) NOT NULL,
+    tie_rule VARCHAR(20) NOT NULL,
+    winner VARCHAR(20) NOT NULL,
+    margin INTEGER NOT NULL,
+    created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+
+    FOREIGN KEY (table_id) REFERENCES game_tables(id) ON DELETE CASCADE,
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE,
+    FOREIGN KEY (attacker_sheet_id) REFERENCES player_sheets(id) ON DELETE CASCADE,
+    FOREIGN KEY (defender_sheet_id) REFERENCES player_sheets(id) ON DELETE CASCADE
+);
+
+CREATE INDEX idx_roll_contests_table ON roll_contests(table_id, created_at);
+
+ALTER TABLE rolls ADD COLUMN contest_id VARCHAR(36) REFERENCES roll_contests(id);
+CREATE INDEX idx_rolls_contest ON rolls(contest_id);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_rolls_contest;
+ALTER TABLE rolls DROP COLUMN contest_id;
+
+DROP INDEX IF EXISTS idx_roll_contests_table;
+DROP TABLE IF EXISTS roll_contests;
+-- +goose StatementEnd
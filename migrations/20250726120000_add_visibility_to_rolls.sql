@@ -0,0 +1,6 @@
+-- +goose Up
+-- Visibilidade da rolagem na mesa: public, gm, blind ou self; rolagens anteriores são públicas
+ALTER TABLE rolls ADD COLUMN visibility VARCHAR(10) NOT NULL DEFAULT 'public';
+
+-- +goose Down
+ALTER TABLE rolls DROP COLUMN visibility;
@@ -0,0 +1,37 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Macros de rolagem: sequências nomeadas de expressões, do usuário ou ligadas a uma ficha
+CREATE TABLE roll_macros (
+    id VARCHAR(36) PRIMARY KEY,
+    user_id INTEGER NOT NULL,
+    sheet_id VARCHAR(36),
+    name VARCHAR(100) NOT NULL,
+    description TEXT NOT NULL DEFAULT '',
+    steps TEXT NOT NULL, -- JSON: [{"label": "...", "expression": "..."}]
+    created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE,
+    FOREIGN KEY (sheet_id) REFERENCES player_sheets(id) ON DELETE CASCADE
+);
+
+-- Nomes únicos entre as macros do usuário e entre as macros de cada ficha
+CREATE UNIQUE INDEX idx_roll_macros_user_name ON roll_macros(user_id, name) WHERE sheet_id IS NULL;
+CREATE UNIQUE INDEX idx_roll_macros_sheet_name ON roll_macros(sheet_id, name) WHERE sheet_id IS NOT NULL;
+
+-- Rolagens executadas juntas (e.g., passos de uma macro) compartilham batch_id
+ALTER TABLE rolls ADD COLUMN batch_id VARCHAR(36);
+ALTER TABLE rolls ADD COLUMN macro_id VARCHAR(36) REFERENCES roll_macros(id) ON DELETE SET NULL;
+CREATE INDEX idx_rolls_batch ON rolls(batch_id);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_rolls_batch;
+ALTER TABLE rolls DROP COLUMN macro_id;
+ALTER TABLE rolls DROP COLUMN batch_id;
+
+DROP INDEX IF EXISTS idx_roll_macros_sheet_name;
+DROP INDEX IF EXISTS idx_roll_macros_user_name;
+DROP TABLE IF EXISTS roll_macros;
+-- +goose StatementEnd
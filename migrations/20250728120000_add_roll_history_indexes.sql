@@ -0,0 +1,24 @@
+-- +goose Up
+-- Índices compostos para o histórico filtrado: escopo (mesa, ficha ou usuário) seguido da data,
+-- que substituem os índices simples das mesmas colunas
+CREATE INDEX IF NOT EXISTS idx_rolls_table_created ON rolls(table_id, created_at);
+CREATE INDEX IF NOT EXISTS idx_rolls_sheet_created ON rolls(sheet_id, created_at);
+CREATE INDEX IF NOT EXISTS idx_rolls_user_created ON rolls(user_id, created_at);
+DROP INDEX IF EXISTS idx_rolls_table_id;
+DROP INDEX IF EXISTS idx_rolls_sheet_id;
+DROP INDEX IF EXISTS idx_rolls_user_id;
+
+-- Filtros por campo da ficha e por resultado dentro da mesa
+CREATE INDEX IF NOT EXISTS idx_rolls_table_field ON rolls(table_id, field_name);
+CREATE INDEX IF NOT EXISTS idx_rolls_table_result ON rolls(table_id, result_value);
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_rolls_table_result;
+DROP INDEX IF EXISTS idx_rolls_table_field;
+
+CREATE INDEX IF NOT EXISTS idx_rolls_user_id ON rolls(user_id);
+CREATE INDEX IF NOT EXISTS idx_rolls_sheet_id ON rolls(sheet_id);
+CREATE INDEX IF NOT EXISTS idx_rolls_table_id ON rolls(table_id);
+DROP INDEX IF EXISTS idx_rolls_user_created;
+DROP INDEX IF EXISTS idx_rolls_sheet_created;
+DROP INDEX IF EXISTS idx_rolls_table_created;
@@ -0,0 +1,10 @@
+-- +goose Up
+-- Anulação de rolagens: a linha continua no histórico, marcada com quem anulou, quando e por quê
+ALTER TABLE rolls ADD COLUMN voided_at DATETIME;
+ALTER TABLE rolls ADD COLUMN voided_by INTEGER REFERENCES users(id) ON DELETE SET NULL;
+ALTER TABLE rolls ADD COLUMN void_reason TEXT;
+
+-- +goose Down
+ALTER TABLE rolls DROP COLUMN void_reason;
+ALTER TABLE rolls DROP COLUMN voided_by;
+ALTER TABLE rolls DROP COLUMN voided_at;
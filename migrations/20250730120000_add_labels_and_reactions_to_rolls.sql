@@ -0,0 +1,26 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Rótulo livre e tags (JSON: ["attack", "stealth"]) de cada rolagem. A coluna comment declarada em
+-- create_rolls_table nunca chegou a existir: a tabela rolls já vinha de create_rolls
+ALTER TABLE rolls ADD COLUMN label TEXT;
+ALTER TABLE rolls ADD COLUMN tags TEXT;
+
+-- Reações (emoji) dos jogadores às rolagens; cada usuário reage uma vez com cada emoji
+CREATE TABLE roll_reactions (
+    roll_id VARCHAR(36) NOT NULL,
+    user_id INTEGER NOT NULL,
+    emoji VARCHAR(32) NOT NULL,
+    created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+
+    PRIMARY KEY (roll_id, user_id, emoji),
+    FOREIGN KEY (roll_id) REFERENCES rolls(id) ON DELETE CASCADE,
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS roll_reactions;
+ALTER TABLE rolls DROP COLUMN tags;
+ALTER TABLE rolls DROP COLUMN label;
+-- +goose StatementEnd
@@ -0,0 +1,58 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Encontros de combate de uma mesa: rodada, participante da vez e ordem de iniciativa.
+-- version é incrementada a cada alteração, para que duas ações simultâneas não avancem o turno duas vezes
+CREATE TABLE encounters (
+    id VARCHAR(36) PRIMARY KEY,
+    table_id VARCHAR(36) NOT NULL,
+    name VARCHAR(100) NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'preparing', -- preparing, active ou ended
+    tie_break VARCHAR(20) NOT NULL DEFAULT 'modifier', -- modifier, players_first ou npcs_first
+    round INTEGER NOT NULL DEFAULT 0,
+    current_participant_id VARCHAR(36),
+    version INTEGER NOT NULL DEFAULT 0,
+    created_by INTEGER NOT NULL,
+    created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+
+    FOREIGN KEY (table_id) REFERENCES game_tables(id) ON DELETE CASCADE,
+    FOREIGN KEY (created_by) REFERENCES users(id) ON DELETE CASCADE
+);
+
+CREATE INDEX idx_encounters_table ON encounters(table_id, created_at);
+
+-- Participantes: fichas de jogadores ou NPCs; position é a ordem dos turnos
+CREATE TABLE encounter_participants (
+    id VARCHAR(36) PRIMARY KEY,
+    encounter_id VARCHAR(36) NOT NULL,
+    sheet_id VARCHAR(36),
+    user_id INTEGER NOT NULL, -- Quem controla o participante: dono da ficha ou mestre
+    name VARCHAR(100) NOT NULL,
+    kind VARCHAR(20) NOT NULL, -- player ou npc
+    initiative INTEGER,
+    initiative_modifier INTEGER NOT NULL DEFAULT 0,
+    tiebreaker INTEGER NOT NULL DEFAULT 0,
+    roll_id VARCHAR(36),
+    position INTEGER NOT NULL DEFAULT 0,
+    status VARCHAR(20) NOT NULL DEFAULT 'active', -- active, delayed ou ready
+    ready_trigger TEXT,
+    created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+
+    FOREIGN KEY (encounter_id) REFERENCES encounters(id) ON DELETE CASCADE,
+    FOREIGN KEY (sheet_id) REFERENCES player_sheets(id) ON DELETE CASCADE,
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE,
+    FOREIGN KEY (roll_id) REFERENCES rolls(id) ON DELETE SET NULL
+);
+
+CREATE INDEX idx_encounter_participants_encounter ON encounter_participants(encounter_id, position);
+CREATE UNIQUE INDEX idx_encounter_participants_sheet ON encounter_participants(encounter_id, sheet_id) WHERE sheet_id IS NOT NULL;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_encounter_participants_sheet;
+DROP INDEX IF EXISTS idx_encounter_participants_encounter;
+DROP TABLE IF EXISTS encounter_participants;
+DROP INDEX IF EXISTS idx_encounters_table;
+DROP TABLE IF EXISTS encounters;
+-- +goose StatementEnd
@@ -0,0 +1,31 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Condições das fichas (e.g., abençoado, envenenado, caído) com duração e modificadores de rolagem.
+-- remaining_rounds é decrementada a cada nova rodada dos encontros em que a ficha participa; NULL não expira por rodadas
+CREATE TABLE sheet_conditions (
+    id VARCHAR(36) PRIMARY KEY,
+    sheet_id VARCHAR(36) NOT NULL,
+    table_id VARCHAR(36) NOT NULL,
+    name VARCHAR(50) NOT NULL,
+    source VARCHAR(100),
+    duration_type VARCHAR(20) NOT NULL, -- rounds, minutes, until_save ou indefinite
+    duration INTEGER, -- Rodadas ou minutos pedidos
+    remaining_rounds INTEGER,
+    save_dc INTEGER, -- Dificuldade da resistência que encerra condições until_save
+    modifiers TEXT, -- JSON: [{"expression": "+1d4", "applies_to": ["attack"]}]
+    created_by INTEGER NOT NULL,
+    created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+
+    FOREIGN KEY (sheet_id) REFERENCES player_sheets(id) ON DELETE CASCADE,
+    FOREIGN KEY (table_id) REFERENCES game_tables(id) ON DELETE CASCADE,
+    FOREIGN KEY (created_by) REFERENCES users(id) ON DELETE CASCADE
+);
+
+CREATE INDEX idx_sheet_conditions_sheet ON sheet_conditions(sheet_id, created_at);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_sheet_conditions_sheet;
+DROP TABLE IF EXISTS sheet_conditions;
+-- +goose StatementEnd
@@ -0,0 +1,59 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Itens das fichas com quantidade, peso unitário e modificadores que valem enquanto o item está equipado
+CREATE TABLE sheet_items (
+    id VARCHAR(36) PRIMARY KEY,
+    sheet_id VARCHAR(36) NOT NULL,
+    table_id VARCHAR(36) NOT NULL,
+    name VARCHAR(100) NOT NULL,
+    description TEXT,
+    quantity INTEGER NOT NULL DEFAULT 1,
+    weight REAL NOT NULL DEFAULT 0, -- Peso de uma unidade
+    equipped BOOLEAN NOT NULL DEFAULT FALSE,
+    modifiers TEXT, -- JSON: [{"expression": "+1", "applies_to": ["attack"]}]
+    stats TEXT, -- JSON: [{"path": "ac", "set": 16}, {"path": "ac", "add": 2}]
+    created_by INTEGER NOT NULL,
+    created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+
+    FOREIGN KEY (sheet_id) REFERENCES player_sheets(id) ON DELETE CASCADE,
+    FOREIGN KEY (table_id) REFERENCES game_tables(id) ON DELETE CASCADE,
+    FOREIGN KEY (created_by) REFERENCES users(id) ON DELETE CASCADE
+);
+
+CREATE INDEX idx_sheet_items_sheet ON sheet_items(sheet_id, created_at);
+CREATE INDEX idx_sheet_items_table_name ON sheet_items(table_id, name);
+
+-- Trocas de itens entre fichas da mesma mesa, efetivadas quando o outro lado aceita
+CREATE TABLE item_trades (
+    id VARCHAR(36) PRIMARY KEY,
+    table_id VARCHAR(36) NOT NULL,
+    from_sheet_id VARCHAR(36) NOT NULL,
+    to_sheet_id VARCHAR(36) NOT NULL,
+    offered TEXT NOT NULL, -- JSON: [{"item_id": "...", "name": "Espada", "quantity": 1}]
+    requested TEXT NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'pending', -- pending, accepted, declined ou canceled
+    created_by INTEGER NOT NULL,
+    resolved_by INTEGER,
+    created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    resolved_at DATETIME,
+
+    FOREIGN KEY (table_id) REFERENCES game_tables(id) ON DELETE CASCADE,
+    FOREIGN KEY (from_sheet_id) REFERENCES player_sheets(id) ON DELETE CASCADE,
+    FOREIGN KEY (to_sheet_id) REFERENCES player_sheets(id) ON DELETE CASCADE,
+    FOREIGN KEY (created_by) REFERENCES users(id) ON DELETE CASCADE
+);
+
+CREATE INDEX idx_item_trades_from ON item_trades(from_sheet_id, status);
+CREATE INDEX idx_item_trades_to ON item_trades(to_sheet_id, status);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_item_trades_to;
+DROP INDEX IF EXISTS idx_item_trades_from;
+DROP TABLE IF EXISTS item_trades;
+DROP INDEX IF EXISTS idx_sheet_items_table_name;
+DROP INDEX IF EXISTS idx_sheet_items_sheet;
+DROP TABLE IF EXISTS sheet_items;
+-- +goose StatementEnd
@@ -0,0 +1,28 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Histórico dos descansos das fichas (curto, longo ou novo dia) com os campos renovados em cada um.
+-- Um descanso da mesa inteira grava uma linha por ficha, todas na mesma transação
+CREATE TABLE sheet_rests (
+    id VARCHAR(36) PRIMARY KEY,
+    sheet_id VARCHAR(36) NOT NULL,
+    table_id VARCHAR(36) NOT NULL,
+    rest_type VARCHAR(20) NOT NULL, -- short_rest, long_rest ou daily
+    changes TEXT NOT NULL, -- JSON: [{"resource": "ki", "path": "ki.current", "old": 1, "new": 5, "delta": 4}]
+    created_by INTEGER NOT NULL,
+    created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+
+    FOREIGN KEY (sheet_id) REFERENCES player_sheets(id) ON DELETE CASCADE,
+    FOREIGN KEY (table_id) REFERENCES game_tables(id) ON DELETE CASCADE,
+    FOREIGN KEY (created_by) REFERENCES users(id) ON DELETE CASCADE
+);
+
+CREATE INDEX idx_sheet_rests_sheet ON sheet_rests(sheet_id, created_at);
+CREATE INDEX idx_sheet_rests_table ON sheet_rests(table_id, created_at);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_sheet_rests_table;
+DROP INDEX IF EXISTS idx_sheet_rests_sheet;
+DROP TABLE IF EXISTS sheet_rests;
+-- +goose StatementEnd
@@ -0,0 +1,17 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Rolagens ocultas (gm, blind e self) usam uma semente própria, que a verificação pública de uma rolagem
+-- nunca revela; as sementes anteriores cobrem rolagens de qualquer visibilidade
+ALTER TABLE roll_seeds ADD COLUMN hidden BOOLEAN NOT NULL DEFAULT FALSE;
+
+DROP INDEX IF EXISTS idx_roll_seeds_user_active;
+CREATE INDEX idx_roll_seeds_user_active ON roll_seeds(user_id, hidden, revealed_at);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_roll_seeds_user_active;
+CREATE INDEX idx_roll_seeds_user_active ON roll_seeds(user_id, revealed_at);
+
+ALTER TABLE roll_seeds DROP COLUMN hidden;
+-- +goose StatementEnd
@@ -0,0 +1,13 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Última rodada cujas condições das fichas já foram descontadas. Voltar o turno não a reduz, para que avançar
+-- de novo até uma rodada já descontada não desconte as condições outra vez
+ALTER TABLE encounters ADD COLUMN ticked_round INTEGER NOT NULL DEFAULT 0;
+
+UPDATE encounters SET ticked_round = round;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE encounters DROP COLUMN ticked_round;
+-- +goose StatementEnd
@@ -0,0 +1,27 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Os filtros de data comparam o prefixo "AAAA-MM-DD HH:MM:SS" de created_at em UTC. Rolagens antigas foram gravadas
+-- na hora local do servidor, como "2025-07-20 21:30:00.5 -0300 -03": converte-as para UTC no mesmo formato das
+-- novas ("2025-07-21 00:30:00.5 +0000 UTC"). Datas sem fuso (CURRENT_TIMESTAMP) já estão em UTC
+UPDATE rolls
+SET created_at = normalized.created_at
+FROM (
+    SELECT id,
+           datetime(substr(created_at, 1, 19), printf('%+d minutes',
+               (CASE WHEN substr(zone, 1, 1) = '-' THEN 1 ELSE -1 END) *
+               (CAST(substr(zone, 2, 2) AS INTEGER) * 60 + CAST(substr(zone, 4, 2) AS INTEGER))))
+           || fraction || ' +0000 UTC' AS created_at
+    FROM (
+        SELECT id, created_at,
+               substr(rest, 1, instr(rest, ' ') - 1) AS fraction, -- Frações de segundo, quando houver
+               substr(rest, instr(rest, ' ') + 1, 5) AS zone      -- Deslocamento do fuso, e.g., "-0300"
+        FROM (SELECT id, created_at, substr(created_at, 20) AS rest FROM rolls)
+        WHERE created_at GLOB '[0-9][0-9][0-9][0-9]-[0-9][0-9]-[0-9][0-9] [0-9][0-9]:[0-9][0-9]:[0-9][0-9]* [+-][0-9][0-9][0-9][0-9] *'
+    )
+    WHERE zone != '+0000'
+) AS normalized
+WHERE rolls.id = normalized.id;
+-- +goose StatementEnd
+
+-- +goose Down
+-- A conversão para UTC não é desfeita: as datas continuam corretas
@@ -0,0 +1,11 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Regras usadas em cada rolagem verificável (dados personalizados, regras de crítico e o teste), para que a
+-- verificação recalcule a rolagem com elas mesmo depois que o template ou a mesa mudarem; nulas nas anteriores
+ALTER TABLE rolls ADD COLUMN rules TEXT;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE rolls DROP COLUMN rules;
+-- +goose StatementEnd
@@ -0,0 +1,320 @@
+package roll
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Node representa um nó da árvore sintática de uma expressão de dados
+type Node interface {
+	String() string
+}
+
+// NumberNode representa uma constante numérica
+type NumberNode struct {
+	Value int
+}
+
+// Tipos de dado
+const (
+	DiceStandard   = ""           // Dado comum com faces de 1 a N (e.g., "1d20")
+	DiceFate       = "fate"       // Dado Fate/Fudge com faces -1, 0 e +1 (e.g., "4dF")
+	DicePercentile = "percentile" // Dado percentual rolado como dezena + unidade (e.g., "d%")
+	DiceCustom     = "custom"     // Dado personalizado definido pela mesa ou template (e.g., "2d[boost]")
+)
+
+// DiceNode representa um termo de dados (e.g., "2d6", "2d20kh1", "1d6!", "8d10>=8", "4dF")
+type DiceNode struct {
+	Count   int              // Número de dados
+	Sides   int              // Número de lados (apenas dados comuns e percentuais)
+	Kind    string           // Tipo de dado (padrão: DiceStandard)
+	Custom  string           // Nome do dado personalizado (apenas DiceCustom)
+	Tens    *TensModifier    // Dados de dezena de bônus/penalidade (apenas DicePercentile)
+	Reroll  *RerollModifier  // Regra de rerrolagem (opcional)
+	Explode *ExplodeModifier // Regra de explosão (opcional)
+	Keep    *KeepModifier    // Regra de manter/descartar dados (opcional)
+	Success *ComparePoint    // Limite de sucesso; transforma o termo em parada de sucessos (opcional)
+	Failure *ComparePoint    // Limite de falha; cada falha cancela um sucesso (opcional)
+}
+
+// IsPool indica se o termo conta sucessos em vez de somar os dados
+func (n *DiceNode) IsPool() bool {
+	return n.Success != nil
+}
+
+// ComparePoint representa uma condição sobre o valor de um dado (e.g., "=1", ">=5")
+type ComparePoint struct {
+	Op    string // "=", ">", ">=", "<" ou "<="
+	Value int
+}
+
+// Matches verifica se o valor atende à condição
+func (c ComparePoint) Matches(value int) bool {
+	switch c.Op {
+	case ">":
+		return value > c.Value
+	case ">=":
+		return value >= c.Value
+	case "<":
+		return value < c.Value
+	case "<=":
+		return value <= c.Value
+	}
+	return value == c.Value
+}
+
+// String retorna a condição em notação compacta, omitindo o "=" implícito
+func (c ComparePoint) String() string {
+	if c.Op == "=" {
+		return strconv.Itoa(c.Value)
+	}
+	return c.Op + strconv.Itoa(c.Value)
+}
+
+// Limites de rerrolagem e explosão por dado
+const (
+	maxRerolls    = 100 // Máximo de rerrolagens de um dado com "r"
+	maxExplosions = 100 // Profundidade máxima de explosão de um dado
+)
+
+// RerollModifier representa uma regra de rerrolagem (e.g., "r1", "ro<2")
+type RerollModifier struct {
+	Compare *ComparePoint // Valores que disparam a rerrolagem (padrão: face mínima)
+	Once    bool          // Se rerrola apenas uma vez ("ro")
+}
+
+// trigger retorna a condição efetiva de rerrolagem para um dado com o número de lados informado
+func (m *RerollModifier) trigger(sides int) ComparePoint {
+	if m.Compare != nil {
+		return *m.Compare
+	}
+	return ComparePoint{Op: "=", Value: 1}
+}
+
+// String retorna o modificador em notação compacta
+func (m *RerollModifier) String() string {
+	text := "r"
+	if m.Once {
+		text = "ro"
+	}
+	if m.Compare != nil {
+		text += m.Compare.String()
+	}
+	return text
+}
+
+// ExplodeModifier representa uma regra de explosão (e.g., "!", "!!", "!>5l3")
+type ExplodeModifier struct {
+	Compare  *ComparePoint // Valores que disparam a explosão (padrão: face máxima)
+	Compound bool          // Se as explosões se somam ao mesmo dado ("!!")
+	Limit    int           // Profundidade máxima de explosão
+}
+
+// trigger retorna a condição efetiva de explosão para um dado com o número de lados informado
+func (m *ExplodeModifier) trigger(sides int) ComparePoint {
+	if m.Compare != nil {
+		return *m.Compare
+	}
+	return ComparePoint{Op: "=", Value: sides}
+}
+
+// String retorna o modificador em notação compacta
+func (m *ExplodeModifier) String() string {
+	text := "!"
+	if m.Compound {
+		text = "!!"
+	}
+	if m.Compare != nil {
+		text += m.Compare.String()
+	}
+	if m.Limit != maxExplosions {
+		text += "l" + strconv.Itoa(m.Limit)
+	}
+	return text
+}
+
+// Limite de dados de dezena extras em um dado percentual
+const maxExtraTens = 2
+
+// TensModifier representa dados de dezena extras de bônus ou penalidade (e.g., "b1", "p2"),
+// como no Call of Cthulhu: rola-se uma dezena a mais por dado e fica-se com a melhor (bônus) ou a pior (penalidade)
+type TensModifier struct {
+	Bonus bool // true para bônus ("b"), false para penalidade ("p")
+	Count int  // Quantidade de dezenas extras
+}
+
+// String retorna o modificador em notação compacta
+func (m *TensModifier) String() string {
+	if m.Bonus {
+		return "b" + strconv.Itoa(m.Count)
+	}
+	return "p" + strconv.Itoa(m.Count)
+}
+
+// Modos de manter/descartar dados
+const (
+	KeepHighest = "kh" // Manter os maiores
+	KeepLowest  = "kl" // Manter os menores
+	DropHighest = "dh" // Descartar os maiores
+	DropLowest  = "dl" // Descartar os menores
+)
+
+// KeepModifier representa um modificador de manter/descartar (e.g., "kh1", "dl1")
+type KeepModifier struct {
+	Mode  string // KeepHighest, KeepLowest, DropHighest ou DropLowest
+	Count int    // Quantidade de dados afetados
+}
+
+// String retorna o modificador em notação compacta
+func (m *KeepModifier) String() string {
+	return m.Mode + strconv.Itoa(m.Count)
+}
+
+// BinaryNode representa uma operação aritmética entre dois nós
+type BinaryNode struct {
+	Op    byte // '+', '-', '*' ou '/'
+	Left  Node
+	Right Node
+}
+
+// NegateNode representa a negação de um nó (e.g., "-1d4")
+type NegateNode struct {
+	Operand Node
+}
+
+// GroupNode representa uma subexpressão entre parênteses
+type GroupNode struct {
+	Inner Node
+}
+
+// FuncNode representa uma função aplicada a subexpressões (e.g., "floor((15-10)/2)", "max(1d6, 3)")
+type FuncNode struct {
+	Name string // Nome da função, em minúsculas
+	Args []Node // Argumentos, na ordem
+}
+
+// Funções aceitas nas expressões e seus limites de argumentos
+var functionArity = map[string]struct{ min, max int }{
+	"floor": {1, 1}, // Divisão arredondada para baixo (padrão da divisão)
+	"ceil":  {1, 1}, // Divisão arredondada para cima
+	"round": {1, 1}, // Divisão arredondada para o inteiro mais próximo
+	"abs":   {1, 1},
+	"min":   {2, 10},
+	"max":   {2, 10},
+}
+
+// String retorna a constante como texto
+func (n *NumberNode) String() string {
+	return strconv.Itoa(n.Value)
+}
+
+// String retorna o termo de dados em notação XdY seguida dos modificadores
+func (n *DiceNode) String() string {
+	var text string
+	switch n.Kind {
+	case DiceFate:
+		text = fmt.Sprintf("%ddF", n.Count)
+	case DicePercentile:
+		text = fmt.Sprintf("%dd%%", n.Count)
+	case DiceCustom:
+		text = fmt.Sprintf("%dd[%s]", n.Count, n.Custom)
+	default:
+		text = fmt.Sprintf("%dd%d", n.Count, n.Sides)
+	}
+	if n.Tens != nil {
+		text += n.Tens.String()
+	}
+	if n.Reroll != nil {
+		text += n.Reroll.String()
+	}
+	if n.Explode != nil {
+		text += n.Explode.String()
+	}
+	if n.Keep != nil {
+		text += n.Keep.String()
+	}
+	if n.Success != nil {
+		// O "=" é explícito aqui para não se confundir com o número de lados
+		text += n.Success.Op + strconv.Itoa(n.Success.Value)
+	}
+	if n.Failure != nil {
+		text += "f" + n.Failure.String()
+	}
+	return text
+}
+
+// String retorna a operação em notação infixa
+func (n *BinaryNode) String() string {
+	return n.Left.String() + string(n.Op) + n.Right.String()
+}
+
+// String retorna a negação do operando
+func (n *NegateNode) String() string {
+	return "-" + n.Operand.String()
+}
+
+// String retorna a subexpressão entre parênteses
+func (n *GroupNode) String() string {
+	return "(" + n.Inner.String() + ")"
+}
+
+// String retorna a chamada da função com os argumentos separados por vírgula
+func (n *FuncNode) String() string {
+	args := make([]string, len(n.Args))
+	for i, arg := range n.Args {
+		args[i] = arg.String()
+	}
+	return n.Name + "(" + strings.Join(args, ",") + ")"
+}
+
+// DiceExpression representa uma expressão de dados já analisada
+type DiceExpression struct {
+	Source string // Expressão original
+	Root   Node   // Raiz da árvore sintática
+}
+
+// String retorna a expressão normalizada
+func (e *DiceExpression) String() string {
+	return e.Root.String()
+}
+
+// DiceTerms retorna os termos de dados da expressão, na ordem em que aparecem
+func (e *DiceExpression) DiceTerms() []*DiceNode {
+	var terms []*DiceNode
+	walk(e.Root, func(n Node) {
+		if dice, ok := n.(*DiceNode); ok {
+			terms = append(terms, dice)
+		}
+	})
+	return terms
+}
+
+// IsSum indica se o resultado da expressão é a soma dos dados: paradas de sucessos contam sucessos e dados Fate
+// contam degraus da escala, e somar modificadores a eles mudaria a contagem
+func (e *DiceExpression) IsSum() bool {
+	for _, term := range e.DiceTerms() {
+		if term.IsPool() || term.Kind == DiceFate {
+			return false
+		}
+	}
+	return true
+}
+
+// walk percorre a árvore em ordem, da esquerda para a direita
+func walk(n Node, visit func(Node)) {
+	switch node := n.(type) {
+	case *BinaryNode:
+		walk(node.Left, visit)
+		walk(node.Right, visit)
+	case *NegateNode:
+		walk(node.Operand, visit)
+	case *GroupNode:
+		walk(node.Inner, visit)
+	case *FuncNode:
+		for _, arg := range node.Args {
+			walk(arg, visit)
+		}
+	}
+	visit(n)
+}
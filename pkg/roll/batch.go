@@ -0,0 +1,49 @@
+package roll
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// maxBatchRolls limita as rolagens de um lote, somando as repetições
+const maxBatchRolls = 20
+
+// repeatPattern reconhece a sintaxe de repetição (e.g., "6x 4d6kh3")
+var repeatPattern = regexp.MustCompile(`(?i)^\s*(\d+)\s*x\s*(\S.*)$`)
+
+// IsBatch indica se o pedido é um lote: uma lista de expressões ou uma expressão com repetição
+func IsBatch(expression string, expressions []string) bool {
+	return len(expressions) > 0 || repeatPattern.MatchString(expression)
+}
+
+// ExpandBatch expande as expressões de um lote, repetindo as que usam a sintaxe "Nx expressão"
+// (e.g., ["6x 4d6kh3"] vira seis vezes "4d6kh3"). As expressões não são validadas aqui: cada
+// rolagem do lote falha ou acontece de forma independente
+func ExpandBatch(expressions []string) ([]string, error) {
+	var expanded []string
+	for _, expression := range expressions {
+		count, inner := 1, strings.TrimSpace(expression)
+		if match := repeatPattern.FindStringSubmatch(expression); match != nil {
+			var err error
+			count, err = strconv.Atoi(match[1])
+			if err != nil || count < 1 || count > maxBatchRolls {
+				return nil, fmt.Errorf("número de repetições inválido: %s (1-%d)", match[1], maxBatchRolls)
+			}
+			inner = strings.TrimSpace(match[2])
+		}
+
+		if len(expanded)+count > maxBatchRolls {
+			return nil, fmt.Errorf("lote com rolagens demais (máximo %d)", maxBatchRolls)
+		}
+		for i := 0; i < count; i++ {
+			expanded = append(expanded, inner)
+		}
+	}
+
+	if len(expanded) == 0 {
+		return nil, fmt.Errorf("lote sem expressões")
+	}
+	return expanded, nil
+}
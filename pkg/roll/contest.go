@@ -0,0 +1,40 @@
+package roll
+
+import (
+	"fmt"
+
+	"github.com/luizdequeiroz/rpg-backend/internal/app/models"
+)
+
+// ValidateTieRule verifica a regra de desempate de uma disputa; vazio usa a regra padrão (vence o defensor)
+func ValidateTieRule(tieRule string) error {
+	switch tieRule {
+	case "", models.ContestTieRuleDefender, models.ContestTieRuleAttacker, models.ContestTieRuleNone:
+		return nil
+	}
+	return fmt.Errorf("regra de desempate inválida: '%s' (use defender, attacker ou none)", tieRule)
+}
+
+// ResolveContest decide o vencedor de uma rolagem resistida comparando os totais (ou sucessos líquidos,
+// em paradas de dados). Retorna o lado vencedor ou models.ContestTie e a margem do atacante sobre o defensor
+func ResolveContest(attacker, defender *models.RollDetails, tieRule string) (string, int, error) {
+	if err := ValidateTieRule(tieRule); err != nil {
+		return "", 0, err
+	}
+
+	margin := attacker.Total - defender.Total
+	switch {
+	case margin > 0:
+		return models.ContestAttacker, margin, nil
+	case margin < 0:
+		return models.ContestDefender, margin, nil
+	}
+
+	switch tieRule {
+	case models.ContestTieRuleAttacker:
+		return models.ContestAttacker, 0, nil
+	case models.ContestTieRuleNone:
+		return models.ContestTie, 0, nil
+	}
+	return models.ContestDefender, 0, nil
+}
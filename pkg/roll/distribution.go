@@ -0,0 +1,850 @@
+package roll
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"slices"
+
+	"github.com/luizdequeiroz/rpg-backend/internal/app/models"
+)
+
+// Limites da análise de probabilidade
+const (
+	maxExactWork     = 50_000_000 // Operações de convolução antes de recorrer ao Monte Carlo
+	combineWork      = 10         // Custo de cada par combinado valor a valor, que soma em mapa, em operações de convolução
+	maxExactSpan     = 1 << 20    // Amplitude máxima de uma distribuição exata
+	maxKeepOutcomes  = 200_000    // Combinações de dados avaliadas em keep/drop exato
+	maxSampleWork    = 50_000_000 // Dados rolados no pior caso somando todas as amostras do Monte Carlo
+	analysisSamples  = 50_000     // Amostras do Monte Carlo
+	minSamples       = 2_000      // Menos amostras que isso não dão estimativa útil; a expressão é rejeitada
+	analysisSeed     = 1          // Semente fixa do Monte Carlo, para respostas reproduzíveis
+	confidenceZScore = 1.96       // Confiança de 95% no limite de erro declarado
+)
+
+// errNotExact indica que a expressão não tem distribuição exata viável
+var errNotExact = errors.New("distribuição exata inviável")
+
+// Distribution representa a distribuição de probabilidade do total de uma expressão
+type Distribution struct {
+	Exact      bool    // Se foi calculada por convolução exata; senão, por Monte Carlo
+	Samples    int     // Amostras usadas no Monte Carlo (0 quando exata)
+	ErrorBound float64 // Erro máximo de cada probabilidade com 95% de confiança (0 quando exata)
+	Min        int     // Menor total possível (ou observado, no Monte Carlo)
+	Max        int     // Maior total possível (ou observado, no Monte Carlo)
+	Mean       float64 // Média
+	StdDev     float64 // Desvio padrão
+
+	probs   []float64 // Probabilidade de cada total, a partir de Min
+	atLeast []float64 // Probabilidade de o total ser maior ou igual a cada valor, a partir de Min
+}
+
+// Probability retorna a probabilidade de o total ser exatamente o valor informado
+func (d *Distribution) Probability(total int) float64 {
+	if total < d.Min || total > d.Max {
+		return 0
+	}
+	return d.probs[total-d.Min]
+}
+
+// AtLeast retorna a probabilidade de o total ser maior ou igual ao valor informado
+func (d *Distribution) AtLeast(total int) float64 {
+	if total <= d.Min {
+		return 1
+	}
+	if total > d.Max {
+		return 0
+	}
+	return d.atLeast[total-d.Min]
+}
+
+// Analyze calcula a distribuição do total de uma expressão.
+// Usa convolução exata quando viável; explosões (incluindo "again" de paradas) e expressões
+// grandes demais são estimadas por Monte Carlo, com o limite de erro declarado em ErrorBound. Expressões cujos totais
+// possíveis passam de maxExactSpan são rejeitadas antes de qualquer cálculo
+func (re *RollEngine) Analyze(expression string, options *RollOptions) (*Distribution, error) {
+	dice_expr, distribution, err := re.analyzeExact(expression, options, maxExactWork)
+	if !errors.Is(err, errNotExact) {
+		return distribution, err
+	}
+
+	return re.sample(dice_expr, options)
+}
+
+// AnalyzeExact calcula a distribuição exata do total de uma expressão sem passar de maxWork operações de
+// convolução. Não recorre ao Monte Carlo: expressões sem distribuição exata dentro do limite retornam erro
+func (re *RollEngine) AnalyzeExact(expression string, options *RollOptions, maxWork int) (*Distribution, error) {
+	_, distribution, err := re.analyzeExact(expression, options, min(maxWork, maxExactWork))
+	if errors.Is(err, errNotExact) {
+		return nil, fmt.Errorf("erro ao analisar expressão %s: %w", expression, err)
+	}
+	return distribution, err
+}
+
+// analyzeExact interpreta a expressão e calcula sua distribuição exata. Retorna errNotExact, sem envolvê-lo, quando
+// a distribuição exata é inviável dentro de maxWork
+func (re *RollEngine) analyzeExact(expression string, options *RollOptions, maxWork int) (*DiceExpression, *Distribution, error) {
+	dice_expr, err := re.ParseExpression(expression)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	an := &analyzer{customDice: customDiceByName(options), maxWork: maxWork}
+	// Totais espalhados demais não cabem no histograma: rejeita antes de calcular ou amostrar
+	if lo, hi := an.bounds(dice_expr.Root); hi-lo > maxExactSpan {
+		return nil, nil, fmt.Errorf("erro ao analisar expressão %s: resultado fora do intervalo permitido", expression)
+	}
+
+	result, err := an.eval(dice_expr.Root)
+	if errors.Is(err, errNotExact) {
+		return dice_expr, nil, err
+	}
+	if err != nil {
+		return nil, nil, fmt.Errorf("erro ao analisar expressão %s: %w", expression, err)
+	}
+	return dice_expr, newDistribution(result.min, result.probs, 0, 0), nil
+}
+
+// sample estima a distribuição rolando a expressão repetidamente com uma fonte determinística. O número de amostras
+// cai para que o pior caso de dados rolados caiba em maxSampleWork (e.g., "100d100!" pode rolar 10.100 dados por
+// amostra); expressões que não cabem nem em minSamples amostras são rejeitadas
+func (re *RollEngine) sample(dice_expr *DiceExpression, options *RollOptions) (*Distribution, error) {
+	samples := min(analysisSamples, maxSampleWork/max(sampleWork(dice_expr.Root), 1))
+	if samples < minSamples {
+		return nil, fmt.Errorf("expressão pesada demais para analisar: %s", dice_expr.Source)
+	}
+
+	ev := newEvaluator(nil, options)
+	ev.source = NewSeededSource(analysisSeed) // Ignora a fonte das opções
+
+	counts := make(map[int]int)
+	for i := 0; i < samples; i++ {
+		ev.dice, ev.terms = ev.dice[:0], ev.terms[:0]
+		total, err := ev.eval(dice_expr.Root)
+		if err != nil {
+			return nil, fmt.Errorf("erro ao analisar expressão %s: %w", dice_expr.Source, err)
+		}
+		counts[total]++
+	}
+
+	minTotal, maxTotal := math.MaxInt, math.MinInt
+	for total := range counts {
+		minTotal = min(minTotal, total)
+		maxTotal = max(maxTotal, total)
+	}
+	if maxTotal-minTotal > maxExactSpan {
+		return nil, errors.New("resultado fora do intervalo permitido")
+	}
+
+	probs := make([]float64, maxTotal-minTotal+1)
+	for total, count := range counts {
+		probs[total-minTotal] = float64(count) / float64(samples)
+	}
+	// Pior caso do intervalo de confiança de uma proporção (p = 0,5)
+	errorBound := confidenceZScore * math.Sqrt(0.25/float64(samples))
+	return newDistribution(minTotal, probs, samples, errorBound), nil
+}
+
+// sampleWork retorna quantos dados uma amostra rola no pior caso, com todas as rerrolagens e explosões
+func sampleWork(n Node) int {
+	switch node := n.(type) {
+	case *DiceNode:
+		perDie := 1
+		switch node.Kind {
+		case DicePercentile:
+			perDie = 2
+			if node.Tens != nil {
+				perDie += node.Tens.Count
+			}
+		case DiceStandard:
+			if node.Reroll != nil {
+				if node.Reroll.Once {
+					perDie++
+				} else {
+					perDie += maxRerolls
+				}
+			}
+			if node.Explode != nil {
+				if node.Explode.Compound {
+					perDie += node.Explode.Limit
+				} else {
+					perDie *= 1 + node.Explode.Limit
+				}
+			}
+		}
+		return node.Count * perDie
+	case *GroupNode:
+		return sampleWork(node.Inner)
+	case *NegateNode:
+		return sampleWork(node.Operand)
+	case *BinaryNode:
+		return sampleWork(node.Left) + sampleWork(node.Right)
+	case *FuncNode:
+		work := 0
+		for _, arg := range node.Args {
+			work += sampleWork(arg)
+		}
+		return work
+	}
+	return 0
+}
+
+// bounds retorna limites do total de um nó a partir da árvore sintática, sem rolar nem calcular a distribuição.
+// Os limites são conservadores: cobrem todas as explosões e, em keep/drop e divisões, podem ser mais largos que o
+// intervalo real. Usa float64 para que produtos grandes não transbordem
+func (an *analyzer) bounds(n Node) (lo, hi float64) {
+	switch node := n.(type) {
+	case *NumberNode:
+		return float64(node.Value), float64(node.Value)
+
+	case *DiceNode:
+		return an.diceBounds(node)
+
+	case *GroupNode:
+		return an.bounds(node.Inner)
+
+	case *NegateNode:
+		lo, hi = an.bounds(node.Operand)
+		return -hi, -lo
+
+	case *BinaryNode:
+		leftLo, leftHi := an.bounds(node.Left)
+		rightLo, rightHi := an.bounds(node.Right)
+		return operatorBounds(node.Op, leftLo, leftHi, rightLo, rightHi)
+
+	case *FuncNode:
+		if isRounding(node.Name) {
+			div := divisionOf(node.Args[0])
+			if div == nil {
+				return an.bounds(node.Args[0])
+			}
+			leftLo, leftHi := an.bounds(div.Left)
+			rightLo, rightHi := an.bounds(div.Right)
+			return operatorBounds('/', leftLo, leftHi, rightLo, rightHi)
+		}
+
+		lo, hi = an.bounds(node.Args[0])
+		if node.Name == "abs" {
+			return 0, max(math.Abs(lo), math.Abs(hi))
+		}
+		for _, arg := range node.Args[1:] {
+			argLo, argHi := an.bounds(arg)
+			if node.Name == "min" {
+				lo, hi = min(lo, argLo), min(hi, argHi)
+			} else {
+				lo, hi = max(lo, argLo), max(hi, argHi)
+			}
+		}
+		return lo, hi
+	}
+	return 0, 0
+}
+
+// diceBounds retorna limites do total de um termo de dados, contando todos os dados extras das explosões
+func (an *analyzer) diceBounds(node *DiceNode) (lo, hi float64) {
+	dice := float64(node.Count)
+	if node.Explode != nil && !node.Explode.Compound {
+		dice *= float64(1 + node.Explode.Limit)
+	}
+	if node.IsPool() {
+		return 0, dice
+	}
+
+	var faceLo, faceHi float64
+	switch node.Kind {
+	case DiceCustom:
+		faces := an.customDice[node.Custom].Faces // Dado não definido é rejeitado pela análise
+		for i, face := range faces {
+			if i == 0 {
+				faceLo, faceHi = float64(face.Value), float64(face.Value)
+			}
+			faceLo, faceHi = min(faceLo, float64(face.Value)), max(faceHi, float64(face.Value))
+		}
+	case DiceFate:
+		faceLo, faceHi = -1, 1
+	case DicePercentile:
+		faceLo, faceHi = 1, 100
+	default:
+		faceLo, faceHi = 1, float64(node.Sides)
+		if node.Explode != nil && node.Explode.Compound {
+			faceHi *= float64(1 + node.Explode.Limit)
+		}
+	}
+
+	// Descartar dados pode deixar de fora qualquer um deles; sem keep/drop, explosões podem não acontecer
+	if node.Keep != nil {
+		return dice * min(faceLo, 0), dice * max(faceHi, 0)
+	}
+	return min(float64(node.Count)*faceLo, dice*faceLo), dice * faceHi
+}
+
+// operatorBounds retorna limites de um operador aplicado a dois intervalos. Produto e quociente por divisor sem zero
+// têm os extremos nos cantos; com zero possível no divisor, o quociente não passa do dividendo em valor absoluto.
+// O quociente inteiro arredondado pode passar do real em uma unidade
+func operatorBounds(op byte, leftLo, leftHi, rightLo, rightHi float64) (lo, hi float64) {
+	switch op {
+	case '+':
+		return leftLo + rightLo, leftHi + rightHi
+	case '-':
+		return leftLo - rightHi, leftHi - rightLo
+	case '/':
+		if rightLo <= 0 && rightHi >= 0 {
+			limit := max(math.Abs(leftLo), math.Abs(leftHi))
+			return -limit, limit
+		}
+	}
+
+	if op == '/' {
+		corners := []float64{leftLo / rightLo, leftLo / rightHi, leftHi / rightLo, leftHi / rightHi}
+		return slices.Min(corners) - 1, slices.Max(corners) + 1
+	}
+	corners := []float64{leftLo * rightLo, leftLo * rightHi, leftHi * rightLo, leftHi * rightHi}
+	return slices.Min(corners), slices.Max(corners)
+}
+
+// newDistribution calcula as estatísticas de uma função de probabilidade, descartando caudas nulas. samples é o
+// número de amostras do Monte Carlo, ou 0 quando a distribuição é exata
+func newDistribution(minTotal int, probs []float64, samples int, errorBound float64) *Distribution {
+	start, end := 0, len(probs)-1
+	for start < end && probs[start] == 0 {
+		start++
+	}
+	for end > start && probs[end] == 0 {
+		end--
+	}
+	probs = probs[start : end+1]
+	minTotal += start
+
+	mean := 0.0
+	for i, p := range probs {
+		mean += float64(minTotal+i) * p
+	}
+	variance := 0.0
+	for i, p := range probs {
+		delta := float64(minTotal+i) - mean
+		variance += delta * delta * p
+	}
+
+	// Somas acumuladas a partir do maior total, para AtLeast responder sem percorrer a distribuição
+	atLeast := make([]float64, len(probs))
+	chance := 0.0
+	for i := len(probs) - 1; i >= 0; i-- {
+		chance += probs[i]
+		atLeast[i] = math.Min(chance, 1)
+	}
+
+	return &Distribution{
+		Exact:      samples == 0,
+		Samples:    samples,
+		ErrorBound: errorBound,
+		Min:        minTotal,
+		Max:        minTotal + len(probs) - 1,
+		Mean:       mean,
+		StdDev:     math.Sqrt(variance),
+		probs:      probs,
+		atLeast:    atLeast,
+	}
+}
+
+// pmf é uma função de probabilidade densa sobre inteiros consecutivos a partir de min
+type pmf struct {
+	min   int
+	probs []float64
+}
+
+// pointMass retorna a distribuição de um valor constante
+func pointMass(value int) pmf {
+	return pmf{min: value, probs: []float64{1}}
+}
+
+// uniform retorna a distribuição uniforme sobre os valores informados (repetições somam peso)
+func uniform(values []int) pmf {
+	weights := make(map[int]float64)
+	for _, value := range values {
+		weights[value] += 1 / float64(len(values))
+	}
+	return fromWeights(weights)
+}
+
+// fromWeights converte probabilidades esparsas em uma distribuição densa
+func fromWeights(weights map[int]float64) pmf {
+	minValue, maxValue := math.MaxInt, math.MinInt
+	for value := range weights {
+		minValue = min(minValue, value)
+		maxValue = max(maxValue, value)
+	}
+
+	result := pmf{min: minValue, probs: make([]float64, maxValue-minValue+1)}
+	for value, p := range weights {
+		result.probs[value-minValue] += p
+	}
+	return result
+}
+
+// analyzer calcula distribuições exatas percorrendo a árvore sintática
+type analyzer struct {
+	customDice map[string]models.CustomDie
+	work       int // Operações acumuladas, limitadas por maxWork
+	maxWork    int // Operações permitidas antes de desistir da análise exata
+}
+
+// eval calcula a distribuição de um nó
+func (an *analyzer) eval(n Node) (pmf, error) {
+	switch node := n.(type) {
+	case *NumberNode:
+		return pointMass(node.Value), nil
+
+	case *DiceNode:
+		return an.dice(node)
+
+	case *GroupNode:
+		return an.eval(node.Inner)
+
+	case *NegateNode:
+		operand, err := an.eval(node.Operand)
+		if err != nil {
+			return pmf{}, err
+		}
+		return negate(operand), nil
+
+	case *BinaryNode:
+		if err := an.checkSpan(node); err != nil {
+			return pmf{}, err
+		}
+		left, err := an.eval(node.Left)
+		if err != nil {
+			return pmf{}, err
+		}
+		right, err := an.eval(node.Right)
+		if err != nil {
+			return pmf{}, err
+		}
+		return an.combine(node.Op, left, right)
+
+	case *FuncNode:
+		if err := an.checkSpan(node); err != nil {
+			return pmf{}, err
+		}
+		return an.function(node)
+	}
+
+	return pmf{}, fmt.Errorf("nó de expressão desconhecido: %T", n)
+}
+
+// checkSpan desiste da análise exata de um nó cujos limites passam da amplitude máxima, antes de combinar as
+// distribuições dos operandos (e.g., "(5d1000*5d1000)/1000" combinaria 25 milhões de pares)
+func (an *analyzer) checkSpan(n Node) error {
+	if lo, hi := an.bounds(n); hi-lo > maxExactSpan {
+		return errNotExact
+	}
+	return nil
+}
+
+// function calcula a distribuição de uma função, com as mesmas regras de evalFunc
+func (an *analyzer) function(node *FuncNode) (pmf, error) {
+	if isRounding(node.Name) {
+		div := divisionOf(node.Args[0])
+		if div == nil {
+			return an.eval(node.Args[0])
+		}
+		left, err := an.eval(div.Left)
+		if err != nil {
+			return pmf{}, err
+		}
+		right, err := an.eval(div.Right)
+		if err != nil {
+			return pmf{}, err
+		}
+		return an.combineWith(left, right, func(a, b int) (int, error) {
+			return divide(a, b, node.Name)
+		})
+	}
+
+	result, err := an.eval(node.Args[0])
+	if err != nil {
+		return pmf{}, err
+	}
+	if node.Name == "abs" {
+		return an.combineWith(result, pointMass(0), func(a, _ int) (int, error) {
+			return abs(a), nil
+		})
+	}
+
+	// min e max combinam os argumentos dois a dois
+	for _, arg := range node.Args[1:] {
+		next, err := an.eval(arg)
+		if err != nil {
+			return pmf{}, err
+		}
+		result, err = an.combineWith(result, next, func(a, b int) (int, error) {
+			return applyFunction(node.Name, []int{a, b})
+		})
+		if err != nil {
+			return pmf{}, err
+		}
+	}
+	return result, nil
+}
+
+// negate espelha uma distribuição
+func negate(p pmf) pmf {
+	result := pmf{min: -(p.min + len(p.probs) - 1), probs: make([]float64, len(p.probs))}
+	for i, prob := range p.probs {
+		result.probs[len(p.probs)-1-i] = prob
+	}
+	return result
+}
+
+// combine aplica um operador a duas distribuições independentes
+func (an *analyzer) combine(op byte, left, right pmf) (pmf, error) {
+	switch op {
+	case '+':
+		return an.convolve(left, right)
+	case '-':
+		return an.convolve(left, negate(right))
+	}
+
+	return an.combineWith(left, right, func(a, b int) (int, error) {
+		return applyOperator(op, a, b)
+	})
+}
+
+// combineWith aplica uma operação qualquer a duas distribuições independentes, valor a valor
+func (an *analyzer) combineWith(left, right pmf, apply func(a, b int) (int, error)) (pmf, error) {
+	if err := an.spend(len(left.probs) * len(right.probs) * combineWork); err != nil {
+		return pmf{}, err
+	}
+
+	weights := make(map[int]float64)
+	minValue, maxValue := math.MaxInt, math.MinInt
+	for i, pl := range left.probs {
+		if pl == 0 {
+			continue
+		}
+		for j, pr := range right.probs {
+			if pr == 0 {
+				continue
+			}
+			value, err := apply(left.min+i, right.min+j)
+			if err != nil {
+				return pmf{}, err
+			}
+			weights[value] += pl * pr
+			minValue = min(minValue, value)
+			maxValue = max(maxValue, value)
+		}
+	}
+	if maxValue-minValue > maxExactSpan {
+		return pmf{}, errNotExact
+	}
+	return fromWeights(weights), nil
+}
+
+// convolve soma duas distribuições independentes
+func (an *analyzer) convolve(a, b pmf) (pmf, error) {
+	if err := an.spend(len(a.probs) * len(b.probs)); err != nil {
+		return pmf{}, err
+	}
+
+	minValue := a.min + b.min
+	maxValue := minValue + len(a.probs) + len(b.probs) - 2
+	if minValue < -maxMagnitude || maxValue > maxMagnitude {
+		return pmf{}, errors.New("resultado fora do intervalo permitido")
+	}
+	if maxValue-minValue > maxExactSpan {
+		return pmf{}, errNotExact
+	}
+
+	result := pmf{min: minValue, probs: make([]float64, maxValue-minValue+1)}
+	for i, pa := range a.probs {
+		if pa == 0 {
+			continue
+		}
+		for j, pb := range b.probs {
+			result.probs[i+j] += pa * pb
+		}
+	}
+	return result, nil
+}
+
+// spend contabiliza operações e desiste da análise exata quando o limite é atingido
+func (an *analyzer) spend(work int) error {
+	an.work += work
+	if an.work > an.maxWork {
+		return errNotExact
+	}
+	return nil
+}
+
+// dice calcula a distribuição de um termo de dados sem explosão
+func (an *analyzer) dice(node *DiceNode) (pmf, error) {
+	if node.Explode != nil {
+		return pmf{}, errNotExact
+	}
+
+	face, err := an.face(node)
+	if err != nil {
+		return pmf{}, err
+	}
+	if node.Keep != nil {
+		return an.keep(node, face)
+	}
+
+	// Em paradas, cada dado contribui com +1 (sucesso), -1 (falha) ou 0
+	if node.IsPool() {
+		face = poolContribution(node, face)
+	}
+
+	total := pointMass(0)
+	for i := 0; i < node.Count; i++ {
+		total, err = an.convolve(total, face)
+		if err != nil {
+			return pmf{}, err
+		}
+	}
+	if node.IsPool() {
+		total = clampAtZero(total)
+	}
+	return total, nil
+}
+
+// face calcula a distribuição de um único dado do termo
+func (an *analyzer) face(node *DiceNode) (pmf, error) {
+	switch node.Kind {
+	case DiceCustom:
+		custom, ok := an.customDice[node.Custom]
+		if !ok {
+			return pmf{}, fmt.Errorf("dado personalizado não definido: %s", node.Custom)
+		}
+		values := make([]int, len(custom.Faces))
+		for i, face := range custom.Faces {
+			values[i] = face.Value
+		}
+		return uniform(values), nil
+
+	case DiceFate:
+		return uniform([]int{-1, 0, 1}), nil
+
+	case DicePercentile:
+		return percentileFace(node.Tens), nil
+	}
+
+	return standardFace(node), nil
+}
+
+// standardFace calcula a distribuição de um dado comum com a regra de rerrolagem, se houver.
+// Com limite de L rerrolagens, uma face aceita sai na tentativa k+1 com probabilidade q^k/s
+// (q = chance de rerrolar) e uma face rerrolável só fica se todas as L+1 tentativas a rerrolarem
+func standardFace(node *DiceNode) pmf {
+	sides := node.Sides
+	result := pmf{min: 1, probs: make([]float64, sides)}
+	if node.Reroll == nil {
+		for i := range result.probs {
+			result.probs[i] = 1 / float64(sides)
+		}
+		return result
+	}
+
+	trigger := node.Reroll.trigger(sides)
+	limit := maxRerolls
+	if node.Reroll.Once {
+		limit = 1
+	}
+
+	matching := 0
+	for value := 1; value <= sides; value++ {
+		if trigger.Matches(value) {
+			matching++
+		}
+	}
+	q := float64(matching) / float64(sides)
+
+	accepted := 0.0 // Σ q^k, k = 0..L
+	for k, qk := 0, 1.0; k <= limit; k++ {
+		accepted += qk
+		qk *= q
+	}
+	rerolled := math.Pow(q, float64(limit))
+
+	for value := 1; value <= sides; value++ {
+		if trigger.Matches(value) {
+			result.probs[value-1] = rerolled / float64(sides)
+		} else {
+			result.probs[value-1] = accepted / float64(sides)
+		}
+	}
+	return result
+}
+
+// percentileFace calcula a distribuição de um dado percentual, com dezenas de bônus ou penalidade
+func percentileFace(tens *TensModifier) pmf {
+	rolls := 1
+	if tens != nil {
+		rolls += tens.Count
+	}
+
+	combinations := int(math.Pow(10, float64(rolls)))
+	weight := 1 / float64(10*combinations)
+
+	result := pmf{min: 1, probs: make([]float64, 100)}
+	for units := 0; units < 10; units++ {
+		for combination := 0; combination < combinations; combination++ {
+			value := 0
+			rest := combination
+			for i := 0; i < rolls; i++ {
+				candidate := (rest%10)*10 + units
+				rest /= 10
+				if candidate == 0 {
+					candidate = 100
+				}
+				switch {
+				case i == 0:
+					value = candidate
+				case tens.Bonus && candidate < value:
+					value = candidate
+				case !tens.Bonus && candidate > value:
+					value = candidate
+				}
+			}
+			result.probs[value-1] += weight
+		}
+	}
+	return result
+}
+
+// poolContribution converte a distribuição de um dado em sua contribuição para a parada
+func poolContribution(node *DiceNode, face pmf) pmf {
+	weights := make(map[int]float64)
+	for i, p := range face.probs {
+		if p > 0 {
+			weights[dieContribution(node, face.min+i)] += p
+		}
+	}
+	return fromWeights(weights)
+}
+
+// dieContribution retorna +1 para sucesso, -1 para falha e 0 caso contrário (ambos se anulam)
+func dieContribution(node *DiceNode, value int) int {
+	contribution := 0
+	if node.Success.Matches(value) {
+		contribution++
+	}
+	if node.Failure != nil && node.Failure.Matches(value) {
+		contribution--
+	}
+	return contribution
+}
+
+// clampAtZero acumula os totais negativos em zero, como as paradas fazem com falhas excedentes
+func clampAtZero(p pmf) pmf {
+	if p.min >= 0 {
+		return p
+	}
+	weights := make(map[int]float64)
+	for i, prob := range p.probs {
+		weights[max(p.min+i, 0)] += prob
+	}
+	return fromWeights(weights)
+}
+
+// keep calcula a distribuição de um termo com keep/drop enumerando as combinações de valores
+// (multiconjuntos), cada uma com seu peso multinomial
+func (an *analyzer) keep(node *DiceNode, face pmf) (pmf, error) {
+	// Valores possíveis do dado, do maior para o menor
+	var values []int
+	var probs []float64
+	for i := len(face.probs) - 1; i >= 0; i-- {
+		if face.probs[i] > 0 {
+			values = append(values, face.min+i)
+			probs = append(probs, face.probs[i])
+		}
+	}
+
+	n := node.Count
+	outcomes := binomial(n+len(values)-1, len(values)-1)
+	if outcomes > maxKeepOutcomes {
+		return pmf{}, errNotExact
+	}
+	if err := an.spend(int(outcomes) * len(values)); err != nil {
+		return pmf{}, err
+	}
+
+	// Posições mantidas [lo, hi) na ordem do maior para o menor, como em applyKeep
+	lo, hi := 0, n
+	switch node.Keep.Mode {
+	case KeepHighest:
+		hi = node.Keep.Count
+	case KeepLowest:
+		lo = n - node.Keep.Count
+	case DropHighest:
+		lo = node.Keep.Count
+	case DropLowest:
+		hi = n - node.Keep.Count
+	}
+
+	weights := make(map[int]float64)
+	counts := make([]int, len(values))
+
+	var enumerate func(index, remaining int, prob float64)
+	enumerate = func(index, remaining int, prob float64) {
+		if index == len(values)-1 {
+			counts[index] = remaining
+			prob *= math.Pow(probs[index], float64(remaining))
+			weights[keptTotal(node, values, counts, lo, hi)] += prob
+			return
+		}
+		for count := 0; count <= remaining; count++ {
+			counts[index] = count
+			weight := binomial(remaining, count) * math.Pow(probs[index], float64(count))
+			enumerate(index+1, remaining-count, prob*weight)
+		}
+	}
+	enumerate(0, n, 1)
+
+	return fromWeights(weights), nil
+}
+
+// keptTotal soma os dados mantidos de uma combinação (ou conta sucessos líquidos, em paradas)
+func keptTotal(node *DiceNode, values, counts []int, lo, hi int) int {
+	total := 0
+	position := 0
+	for i, value := range values {
+		kept := min(position+counts[i], hi) - max(position, lo)
+		if kept > 0 {
+			if node.IsPool() {
+				total += kept * dieContribution(node, value)
+			} else {
+				total += kept * value
+			}
+		}
+		position += counts[i]
+	}
+	if node.IsPool() && total < 0 {
+		total = 0
+	}
+	return total
+}
+
+// binomial calcula C(n, k) em ponto flutuante
+func binomial(n, k int) float64 {
+	if k < 0 || k > n {
+		return 0
+	}
+	k = min(k, n-k)
+	result := 1.0
+	for i := 1; i <= k; i++ {
+		result = result * float64(n-k+i) / float64(i)
+	}
+	return result
+}
+
+// Totals retorna os totais possíveis (ou observados, no Monte Carlo), em ordem crescente
+func (d *Distribution) Totals() []int {
+	totals := make([]int, 0, len(d.probs))
+	for i, p := range d.probs {
+		if p > 0 {
+			totals = append(totals, d.Min+i)
+		}
+	}
+	return totals
+}
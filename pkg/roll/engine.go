@@ -3,24 +3,135 @@ package roll
 import (
 	"encoding/json"
 	"fmt"
-	"math/rand"
-	"regexp"
 	"strconv"
 	"strings"
-	"time"
 
 	"github.com/luizdequeiroz/rpg-backend/internal/app/models"
 )
 
-// RollEngine gerencia rolagens de dados
+// RollEngine gerencia rolagens de dados.
+// É segura para uso concorrente desde que a fonte de aleatoriedade também seja.
 type RollEngine struct {
-	rand *rand.Rand
+	source RandomSource
 }
 
-// NewRollEngine cria nova instância do motor de rolagem
+// RollOptions reúne as definições da mesa ou do template usadas em uma rolagem
+type RollOptions struct {
+	CustomDice []models.CustomDie // Dados personalizados disponíveis (e.g., "2d[boost]"); nomes repetidos prevalecem os últimos
+	Source     RandomSource       // Fonte específica desta rolagem (e.g., rolagens verificáveis); nil usa a do motor
+	Critical   *models.CritRule   // Regra de crítico; nil usa DefaultCritical
+	Fumble     *models.CritRule   // Regra de falha crítica; nil usa DefaultFumble
+
+	OutcomeTables map[string]models.OutcomeTable // Tabelas de resultados declaradas, por nome
+	Check         *models.RollCheck              // Dificuldade e tabela de resultados desta rolagem
+}
+
+// Regras clássicas de crítico e falha crítica: 20 e 1 naturais em um único d20 mantido
+var (
+	DefaultCritical = models.CritRule{Dice: "1d20", Min: intPtr(20)}
+	DefaultFumble   = models.CritRule{Dice: "1d20", Max: intPtr(1)}
+)
+
+// Limites de dados personalizados
+const (
+	maxCustomFaces  = 100 // Máximo de faces por dado
+	maxFaceSymbols  = 10  // Máximo de símbolos por face
+	maxSymbolLength = 30  // Tamanho máximo do nome de um símbolo
+)
+
+// ValidateCustomDie verifica se a definição de um dado personalizado é válida
+func ValidateCustomDie(die models.CustomDie) error {
+	if !IsValidDieName(die.Name) {
+		return fmt.Errorf("nome de dado inválido: '%s' (use letras minúsculas, dígitos, '_' ou '-', até %d caracteres)", die.Name, maxNameLength)
+	}
+	if len(die.Faces) < minDiceSides || len(die.Faces) > maxCustomFaces {
+		return fmt.Errorf("dado '%s' com número de faces inválido: %d (%d-%d)", die.Name, len(die.Faces), minDiceSides, maxCustomFaces)
+	}
+
+	for i, face := range die.Faces {
+		if face.Value < -maxDiceSides || face.Value > maxDiceSides {
+			return fmt.Errorf("dado '%s' com valor inválido na face %d: %d", die.Name, i+1, face.Value)
+		}
+		if len(face.Symbols) > maxFaceSymbols {
+			return fmt.Errorf("dado '%s' com símbolos demais na face %d (máximo %d)", die.Name, i+1, maxFaceSymbols)
+		}
+		for _, symbol := range face.Symbols {
+			if symbol == "" || len(symbol) > maxSymbolLength {
+				return fmt.Errorf("dado '%s' com símbolo inválido na face %d: '%s'", die.Name, i+1, symbol)
+			}
+		}
+	}
+	return nil
+}
+
+// ValidateCritRule verifica se uma regra de crítico ou falha crítica é válida
+func ValidateCritRule(rule *models.CritRule) error {
+	if rule == nil {
+		return nil
+	}
+
+	count, sides, err := critDice(rule.Dice)
+	if err != nil {
+		return err
+	}
+	if rule.Min == nil && rule.Max == nil {
+		return fmt.Errorf("regra para '%s' deve informar min e/ou max", rule.Dice)
+	}
+	if rule.Min != nil && rule.Max != nil && *rule.Min > *rule.Max {
+		return fmt.Errorf("regra para '%s' com faixa vazia: %d-%d", rule.Dice, *rule.Min, *rule.Max)
+	}
+	for _, limit := range []*int{rule.Min, rule.Max} {
+		if limit != nil && (*limit < count || *limit > count*sides) {
+			return fmt.Errorf("regra para '%s' com valor fora do possível: %d (%d-%d)", rule.Dice, *limit, count, count*sides)
+		}
+	}
+	return nil
+}
+
+// critDice extrai quantidade e lados do termo de uma regra de crítico (e.g., "2d6", "d%")
+func critDice(dice string) (int, int, error) {
+	root, err := parse(dice)
+	if err != nil {
+		return 0, 0, fmt.Errorf("dados inválidos na regra de crítico: '%s' (%w)", dice, err)
+	}
+
+	node, ok := root.(*DiceNode)
+	if !ok || (node.Kind != DiceStandard && node.Kind != DicePercentile) ||
+		node.Tens != nil || node.Reroll != nil || node.Explode != nil || node.Keep != nil || node.IsPool() {
+		return 0, 0, fmt.Errorf("dados inválidos na regra de crítico: '%s' (use apenas quantidade e lados, e.g., \"1d20\")", dice)
+	}
+	return node.Count, node.Sides, nil
+}
+
+// intPtr retorna um ponteiro para o valor informado
+func intPtr(value int) *int {
+	return &value
+}
+
+// ValidateCustomDice verifica uma lista de dados personalizados, sem nomes repetidos
+func ValidateCustomDice(dice []models.CustomDie) error {
+	names := make(map[string]bool)
+	for _, die := range dice {
+		if err := ValidateCustomDie(die); err != nil {
+			return err
+		}
+		if names[die.Name] {
+			return fmt.Errorf("dado personalizado repetido: '%s'", die.Name)
+		}
+		names[die.Name] = true
+	}
+	return nil
+}
+
+// NewRollEngine cria nova instância do motor de rolagem com a fonte criptográfica padrão
 func NewRollEngine() *RollEngine {
+	return NewRollEngineWithSource(NewCryptoSource())
+}
+
+// NewRollEngineWithSource cria nova instância do motor de rolagem com a fonte informada
+func NewRollEngineWithSource(source RandomSource) *RollEngine {
 	return &RollEngine{
-		rand: rand.New(rand.NewSource(time.Now().UnixNano())),
+		source: source,
 	}
 }
 
@@ -68,102 +179,165 @@ func (re *RollEngine) getNestedValue(data models.PlayerSheetData, path string) (
 	return nil, fmt.Errorf("campo '%s' não encontrado", path)
 }
 
-// DiceExpression representa uma expressão de dados
-type DiceExpression struct {
-	Count    int // Número de dados
-	Sides    int // Número de lados
-	Modifier int // Modificador (+/-)
-}
-
-// ParseExpression analisa expressão de dados (e.g., "1d20+3", "2d6-1")
+// ParseExpression analisa expressão de dados (e.g., "1d20+3", "1d8+1d6+4", "2*(1d6+2)", "4dF", "d%b1")
 func (re *RollEngine) ParseExpression(expression string) (*DiceExpression, error) {
-	// Regex para capturar XdY+Z ou XdY-Z
-	re_dice := regexp.MustCompile(`^(\d+)d(\d+)([+-]\d+)?$`)
+	root, err := parse(expression)
+	if err != nil {
+		return nil, fmt.Errorf("expressão inválida: %s (%w)", expression, err)
+	}
+
+	return &DiceExpression{
+		Source: expression,
+		Root:   root,
+	}, nil
+}
 
-	// Limpar espaços
-	expression = strings.ReplaceAll(expression, " ", "")
-	expression = strings.ToLower(expression)
+// Roll executa rolagem baseada na expressão
+func (re *RollEngine) Roll(expression string) (*models.RollDetails, error) {
+	return re.RollWithOptions(expression, nil)
+}
 
-	matches := re_dice.FindStringSubmatch(expression)
-	if len(matches) < 3 {
-		return nil, fmt.Errorf("expressão inválida: %s (use formato XdY+Z)", expression)
+// RollWithOptions executa rolagem resolvendo as definições da mesa ou do template
+func (re *RollEngine) RollWithOptions(expression string, options *RollOptions) (*models.RollDetails, error) {
+	dice_expr, err := re.ParseExpression(expression)
+	if err != nil {
+		return nil, err
 	}
 
-	count, err := strconv.Atoi(matches[1])
-	if err != nil || count < 1 || count > 100 {
-		return nil, fmt.Errorf("número de dados inválido: %s (1-100)", matches[1])
+	ev := newEvaluator(re.source, options)
+	final_total, err := ev.eval(dice_expr.Root)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao avaliar expressão %s: %w", expression, err)
 	}
 
-	sides, err := strconv.Atoi(matches[2])
-	if err != nil || sides < 2 || sides > 1000 {
-		return nil, fmt.Errorf("número de lados inválido: %s (2-1000)", matches[2])
+	// Soma dos termos de dados para calcular o modificador líquido
+	dice_sum := 0
+	for _, term := range ev.terms {
+		if term.IsDice() {
+			dice_sum += term.Value
+		}
 	}
 
-	modifier := 0
-	if len(matches) > 3 && matches[3] != "" {
-		modifier, err = strconv.Atoi(matches[3])
-		if err != nil {
-			return nil, fmt.Errorf("modificador inválido: %s", matches[3])
-		}
+	// Verificar críticos e fumbles pelas regras da mesa/template (padrão: 20 e 1 naturais em 1d20)
+	critical, fumble := DefaultCritical, DefaultFumble
+	if options != nil && options.Critical != nil {
+		critical = *options.Critical
+	}
+	if options != nil && options.Fumble != nil {
+		fumble = *options.Fumble
 	}
 
-	return &DiceExpression{
-		Count:    count,
-		Sides:    sides,
-		Modifier: modifier,
-	}, nil
-}
+	details := &models.RollDetails{
+		Dice:     ev.dice,
+		Modifier: final_total - dice_sum,
+		Total:    final_total,
+		Critical: matchesCritRule(ev.terms, critical),
+		Fumble:   matchesCritRule(ev.terms, fumble),
+		Terms:    ev.terms,
+	}
+	applyPoolTotals(details)
+	applySymbolTotals(details)
 
-// Roll executa rolagem baseada na expressão
-func (re *RollEngine) Roll(expression string) (*models.RollDetails, error) {
-	dice_expr, err := re.ParseExpression(expression)
-	if err != nil {
+	if err := re.applyOutcome(details, options); err != nil {
 		return nil, err
 	}
 
-	// Rolar dados
-	dice_results := make([]int, dice_expr.Count)
-	total := 0
+	return details, nil
+}
 
-	for i := 0; i < dice_expr.Count; i++ {
-		roll := re.rand.Intn(dice_expr.Sides) + 1
-		dice_results[i] = roll
-		total += roll
+// applyPoolTotals soma sucessos e falhas das paradas da expressão e detecta falhas críticas
+func applyPoolTotals(details *models.RollDetails) {
+	pool := false
+	successes, failures := 0, 0
+	for _, term := range details.Terms {
+		if term.Type == models.RollTermPool {
+			pool = true
+			successes += term.Successes
+			failures += term.Failures
+		}
 	}
 
-	// Aplicar modificador
-	final_total := total + dice_expr.Modifier
+	if !pool {
+		return
+	}
 
-	// Verificar críticos e fumbles (apenas para d20)
-	critical := false
-	fumble := false
+	details.Successes = &successes
+	details.Failures = &failures
+	details.Botch = successes == 0 && failures > 0
+}
 
-	if dice_expr.Sides == 20 && dice_expr.Count == 1 {
-		if dice_results[0] == 20 {
-			critical = true
-		} else if dice_results[0] == 1 {
-			fumble = true
+// matchesCritRule indica se a soma natural do termo avaliado pela regra está na faixa.
+// A regra só se aplica quando exatamente um termo de dados tem a quantidade de dados mantidos
+// e os lados da regra (e.g., "1d20" vale para "1d20+5" e "2d20kh1", mas não para "1d20+1d20")
+func matchesCritRule(terms []models.RollTerm, rule models.CritRule) bool {
+	count, sides, err := critDice(rule.Dice)
+	if err != nil {
+		return false
+	}
+
+	natural, matches := 0, 0
+	for _, term := range terms {
+		if term.Type != models.RollTermDice || term.Sides != sides {
+			continue
+		}
+		kept := term.KeptValues()
+		if len(kept) != count {
+			continue
+		}
+		matches++
+		natural = 0
+		for _, value := range kept {
+			natural += value
 		}
 	}
 
-	return &models.RollDetails{
-		Dice:     dice_results,
-		Modifier: dice_expr.Modifier,
-		Total:    final_total,
-		Critical: critical,
-		Fumble:   fumble,
-	}, nil
+	if matches != 1 {
+		return false
+	}
+	if rule.Min != nil && natural < *rule.Min {
+		return false
+	}
+	if rule.Max != nil && natural > *rule.Max {
+		return false
+	}
+	return true
+}
+
+// applySymbolTotals soma os símbolos de todos os termos de dados personalizados
+func applySymbolTotals(details *models.RollDetails) {
+	for _, term := range details.Terms {
+		for symbol, count := range term.Symbols {
+			if details.Symbols == nil {
+				details.Symbols = make(map[string]int)
+			}
+			details.Symbols[symbol] += count
+		}
+	}
 }
 
 // RollFromField extrai valor de campo da ficha e executa rolagem
 func (re *RollEngine) RollFromField(sheetData models.PlayerSheetData, fieldName string) (*models.RollDetails, string, error) {
+	return re.RollFromFieldWithOptions(sheetData, fieldName, nil)
+}
+
+// RollFromFieldWithOptions extrai valor de campo da ficha e executa rolagem com as definições informadas
+func (re *RollEngine) RollFromFieldWithOptions(sheetData models.PlayerSheetData, fieldName string, options *RollOptions) (*models.RollDetails, string, error) {
+	expression, err := re.FieldExpression(sheetData, fieldName)
+	if err != nil {
+		return nil, "", err
+	}
+	return re.RollExpressionWithSheet(sheetData, expression, options)
+}
+
+// FieldExpression retorna a expressão de rolagem de um campo da ficha; números viram modificadores de 1d20
+func (re *RollEngine) FieldExpression(sheetData models.PlayerSheetData, fieldName string) (string, error) {
 	// Buscar campo na ficha (com suporte a campos aninhados)
 	value, err := re.getNestedValue(sheetData, fieldName)
 	if err != nil {
-		return nil, "", err
+		return "", err
 	}
 
-	// Converter para string
+	// Converter para string; textos podem referenciar outros campos (e.g., "1d20+{abilities.dex_mod}")
 	var expression string
 	switch v := value.(type) {
 	case string:
@@ -175,7 +349,7 @@ func (re *RollEngine) RollFromField(sheetData models.PlayerSheetData, fieldName
 		// JSON numbers são float64
 		expression = fmt.Sprintf("1d20%+d", int(v))
 	default:
-		return nil, "", fmt.Errorf("campo '%s' não é um valor de rolagem válido", fieldName)
+		return "", fmt.Errorf("campo '%s' não é um valor de rolagem válido", fieldName)
 	}
 
 	// Verificar se é expressão ou valor numérico
@@ -187,13 +361,19 @@ func (re *RollEngine) RollFromField(sheetData models.PlayerSheetData, fieldName
 		}
 	}
 
-	result, err := re.Roll(expression)
-	return result, expression, err
+	return expression, nil
 }
 
 // EvaluateSuccess avalia se rolagem foi bem-sucedida baseada em dificuldade
+// Em paradas de sucesso, a dificuldade é o número de sucessos necessários.
 func (re *RollEngine) EvaluateSuccess(result *models.RollDetails, difficulty int) bool {
+	if result.Botch {
+		return false
+	}
 	if difficulty <= 0 {
+		if result.Successes != nil {
+			return result.Total > 0 // Parada sem dificuldade: basta um sucesso líquido
+		}
 		return true // Sem dificuldade definida
 	}
 	return result.Total >= difficulty
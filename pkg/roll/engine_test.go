@@ -1,79 +1,307 @@
 package roll
 
 import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/binary"
+	"math"
+	"slices"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/luizdequeiroz/rpg-backend/internal/app/models"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestParseExpression(t *testing.T) {
 	engine := NewRollEngine()
 
 	tests := []struct {
-		name     string
-		expr     string
-		expected DiceExpression
-		hasError bool
+		name       string
+		expr       string
+		normalized string
+		dice       []DiceNode
+		hasError   bool
 	}{
 		{
-			name: "1d20",
-			expr: "1d20",
-			expected: DiceExpression{
-				Count:    1,
-				Sides:    20,
-				Modifier: 0,
-			},
-			hasError: false,
+			name:       "1d20",
+			expr:       "1d20",
+			normalized: "1d20",
+			dice:       []DiceNode{{Count: 1, Sides: 20}},
+			hasError:   false,
 		},
 		{
-			name: "2d6+3",
-			expr: "2d6+3",
-			expected: DiceExpression{
-				Count:    2,
-				Sides:    6,
-				Modifier: 3,
-			},
-			hasError: false,
+			name:       "2d6+3",
+			expr:       "2d6+3",
+			normalized: "2d6+3",
+			dice:       []DiceNode{{Count: 2, Sides: 6}},
+			hasError:   false,
 		},
 		{
-			name: "3d8-1",
-			expr: "3d8-1",
-			expected: DiceExpression{
-				Count:    3,
-				Sides:    8,
-				Modifier: -1,
-			},
-			hasError: false,
+			name:       "3d8-1",
+			expr:       "3d8-1",
+			normalized: "3d8-1",
+			dice:       []DiceNode{{Count: 3, Sides: 8}},
+			hasError:   false,
+		},
+		{
+			name:       "Múltiplos dados e constante",
+			expr:       "1d8+1d6+4",
+			normalized: "1d8+1d6+4",
+			dice:       []DiceNode{{Count: 1, Sides: 8}, {Count: 1, Sides: 6}},
+			hasError:   false,
+		},
+		{
+			name:       "Multiplicação com parênteses",
+			expr:       "2*(1d6+2)",
+			normalized: "2*(1d6+2)",
+			dice:       []DiceNode{{Count: 1, Sides: 6}},
+			hasError:   false,
+		},
+		{
+			name:       "Vários modificadores",
+			expr:       "1d20+5-2",
+			normalized: "1d20+5-2",
+			dice:       []DiceNode{{Count: 1, Sides: 20}},
+			hasError:   false,
+		},
+		{
+			name:       "Espaços, maiúsculas e dado implícito",
+			expr:       " D20 + 1D4 / 2 ",
+			normalized: "1d20+1d4/2",
+			dice:       []DiceNode{{Count: 1, Sides: 20}, {Count: 1, Sides: 4}},
+			hasError:   false,
+		},
+		{
+			name:       "Negação",
+			expr:       "-1d4+3",
+			normalized: "-1d4+3",
+			dice:       []DiceNode{{Count: 1, Sides: 4}},
+			hasError:   false,
+		},
+		{
+			name:       "Vantagem",
+			expr:       "2d20kh1+5",
+			normalized: "2d20kh1+5",
+			dice:       []DiceNode{{Count: 2, Sides: 20}},
+			hasError:   false,
+		},
+		{
+			name:       "Keep sem quantidade equivale a kh1",
+			expr:       "2d20k",
+			normalized: "2d20kh1",
+			dice:       []DiceNode{{Count: 2, Sides: 20}},
+			hasError:   false,
+		},
+		{
+			name:       "Geração de atributos",
+			expr:       "4d6dl1",
+			normalized: "4d6dl1",
+			dice:       []DiceNode{{Count: 4, Sides: 6}},
+			hasError:   false,
+		},
+		{
+			name:     "Keep maior que a quantidade de dados",
+			expr:     "2d20kh3",
+			hasError: true,
+		},
+		{
+			name:     "Drop de todos os dados",
+			expr:     "4d6dl4",
+			hasError: true,
+		},
+		{
+			name:     "Keep repetido",
+			expr:     "4d6kh3kl1",
+			hasError: true,
+		},
+		{
+			name:       "Explosão",
+			expr:       "1d6!",
+			normalized: "1d6!",
+			dice:       []DiceNode{{Count: 1, Sides: 6}},
+			hasError:   false,
+		},
+		{
+			name:       "Explosão composta com condição e limite",
+			expr:       "1d6!!>5l3",
+			normalized: "1d6!!>5l3",
+			dice:       []DiceNode{{Count: 1, Sides: 6}},
+			hasError:   false,
+		},
+		{
+			name:       "Rerrolagem",
+			expr:       "2d6r1",
+			normalized: "2d6r1",
+			dice:       []DiceNode{{Count: 2, Sides: 6}},
+			hasError:   false,
+		},
+		{
+			name:       "Rerrolagem única com keep",
+			expr:       "2d6ro<2kh1",
+			normalized: "2d6ro<2kh1",
+			dice:       []DiceNode{{Count: 2, Sides: 6}},
+			hasError:   false,
+		},
+		{
+			name:     "Explosão em todas as faces",
+			expr:     "1d6!>0",
+			hasError: true,
+		},
+		{
+			name:     "Rerrolagem em todas as faces",
+			expr:     "1d6r<7",
+			hasError: true,
+		},
+		{
+			name:     "Limite de explosão inválido",
+			expr:     "1d6!l0",
+			hasError: true,
+		},
+		{
+			name:     "Explosão repetida",
+			expr:     "1d6!!!",
+			hasError: true,
+		},
+		{
+			name:       "Parada de sucessos",
+			expr:       "8d10>=8",
+			normalized: "8d10>=8",
+			dice:       []DiceNode{{Count: 8, Sides: 10}},
+			hasError:   false,
+		},
+		{
+			name:       "Parada com falhas e regra 10-again",
+			expr:       "8d10a10>=8f1",
+			normalized: "8d10!>=10>=8f1",
+			dice:       []DiceNode{{Count: 8, Sides: 10}},
+			hasError:   false,
+		},
+		{
+			name:       "Parada com sucesso exato",
+			expr:       "5d6=6",
+			normalized: "5d6=6",
+			dice:       []DiceNode{{Count: 5, Sides: 6}},
+			hasError:   false,
+		},
+		{
+			name:       "Dados Fate",
+			expr:       "4dF+1",
+			normalized: "4dF+1",
+			dice:       []DiceNode{{Count: 4, Sides: 3}},
+			hasError:   false,
+		},
+		{
+			name:       "Percentual",
+			expr:       "d%",
+			normalized: "1d%",
+			dice:       []DiceNode{{Count: 1, Sides: 100}},
+			hasError:   false,
+		},
+		{
+			name:       "Percentual com bônus",
+			expr:       "1d%b2",
+			normalized: "1d%b2",
+			dice:       []DiceNode{{Count: 1, Sides: 100}},
+			hasError:   false,
+		},
+		{
+			name:       "Dado personalizado",
+			expr:       "2d[Boost]+1d[setback]",
+			normalized: "2d[boost]+1d[setback]",
+			dice:       []DiceNode{{Count: 2}, {Count: 1}},
+			hasError:   false,
+		},
+		{
+			name:     "Bônus fora de dado percentual",
+			expr:     "1d10b1",
+			hasError: true,
+		},
+		{
+			name:     "Muitos dados de bônus",
+			expr:     "1d%b3",
+			hasError: true,
+		},
+		{
+			name:     "Explosão em dado Fate",
+			expr:     "4dF!",
+			hasError: true,
+		},
+		{
+			name:     "Nome de dado inválido",
+			expr:     "1d[boo st]",
+			hasError: true,
+		},
+		{
+			name:     "Colchete não fechado",
+			expr:     "1d[boost",
+			hasError: true,
+		},
+		{
+			name:     "Falha sem limite de sucesso",
+			expr:     "8d10f1",
+			hasError: true,
+		},
+		{
+			name:     "Regra 'a' em todas as faces",
+			expr:     "8d10a1>=8",
+			hasError: true,
+		},
+		{
+			name:     "Limite de sucesso repetido",
+			expr:     "8d10>=8>=9",
+			hasError: true,
 		},
 		{
 			name:     "Expressão inválida",
 			expr:     "abc",
-			expected: DiceExpression{},
+			hasError: true,
+		},
+		{
+			name:     "Expressão vazia",
+			expr:     "",
+			hasError: true,
+		},
+		{
+			name:     "Operador sem operando",
+			expr:     "1d20+",
+			hasError: true,
+		},
+		{
+			name:     "Parêntese não fechado",
+			expr:     "2*(1d6+2",
+			hasError: true,
+		},
+		{
+			name:     "Termos sem operador",
+			expr:     "1d6 2",
 			hasError: true,
 		},
 		{
 			name:     "Dados zero",
 			expr:     "0d20",
-			expected: DiceExpression{},
 			hasError: true,
 		},
 		{
 			name:     "Faces inválidas",
 			expr:     "1d1",
-			expected: DiceExpression{},
 			hasError: true,
 		},
 		{
 			name:     "Muitos dados",
 			expr:     "101d20",
-			expected: DiceExpression{},
 			hasError: true,
 		},
 		{
 			name:     "Muitas faces",
 			expr:     "1d1001",
-			expected: DiceExpression{},
+			hasError: true,
+		},
+		{
+			name:     "Muitos dados no total",
+			expr:     "100d6+100d6+100d6+100d6+100d6+1d6",
 			hasError: true,
 		},
 	}
@@ -86,9 +314,14 @@ func TestParseExpression(t *testing.T) {
 				assert.Error(t, err)
 			} else {
 				assert.NoError(t, err)
-				assert.Equal(t, tt.expected.Count, result.Count)
-				assert.Equal(t, tt.expected.Sides, result.Sides)
-				assert.Equal(t, tt.expected.Modifier, result.Modifier)
+				assert.Equal(t, tt.normalized, result.String())
+
+				terms := result.DiceTerms()
+				assert.Len(t, terms, len(tt.dice))
+				for i, dice := range tt.dice {
+					assert.Equal(t, dice.Count, terms[i].Count)
+					assert.Equal(t, dice.Sides, terms[i].Sides)
+				}
 			}
 		})
 	}
@@ -121,6 +354,30 @@ func TestRoll(t *testing.T) {
 			minResult: 2,  // 3*1 - 1
 			maxResult: 23, // 3*8 - 1
 		},
+		{
+			name:      "1d8+1d6+4",
+			expr:      "1d8+1d6+4",
+			minResult: 6,  // 1 + 1 + 4
+			maxResult: 18, // 8 + 6 + 4
+		},
+		{
+			name:      "2*(1d6+2)",
+			expr:      "2*(1d6+2)",
+			minResult: 6,  // 2 * (1 + 2)
+			maxResult: 16, // 2 * (6 + 2)
+		},
+		{
+			name:      "1d20+5-2",
+			expr:      "1d20+5-2",
+			minResult: 4,  // 1 + 5 - 2
+			maxResult: 23, // 20 + 5 - 2
+		},
+		{
+			name:      "Divisão arredonda para baixo",
+			expr:      "(1d6+1)/2",
+			minResult: 1, // (1 + 1) / 2
+			maxResult: 3, // (6 + 1) / 2
+		},
 	}
 
 	for _, tt := range tests {
@@ -134,6 +391,357 @@ func TestRoll(t *testing.T) {
 	}
 }
 
+func TestRollTermBreakdown(t *testing.T) {
+	engine := NewRollEngine()
+
+	result, err := engine.Roll("1d8+1d6+4")
+	assert.NoError(t, err)
+	assert.Len(t, result.Terms, 3)
+	assert.Len(t, result.Dice, 2)
+
+	assert.Equal(t, models.RollTermDice, result.Terms[0].Type)
+	assert.Equal(t, "1d8", result.Terms[0].Expression)
+	assert.Equal(t, 8, result.Terms[0].Sides)
+	assert.Equal(t, models.RollTermDice, result.Terms[1].Type)
+	assert.Equal(t, "1d6", result.Terms[1].Expression)
+	assert.Equal(t, models.RollTermConstant, result.Terms[2].Type)
+	assert.Equal(t, 4, result.Terms[2].Value)
+
+	assert.Equal(t, result.Terms[0].Value+result.Terms[1].Value+4, result.Total)
+	assert.Equal(t, 4, result.Modifier)
+}
+
+func TestApplyKeep(t *testing.T) {
+	values := []int{3, 6, 1, 4}
+
+	tests := []struct {
+		name    string
+		keep    KeepModifier
+		dropped []bool
+	}{
+		{name: "kh1", keep: KeepModifier{Mode: KeepHighest, Count: 1}, dropped: []bool{true, false, true, true}},
+		{name: "kl1", keep: KeepModifier{Mode: KeepLowest, Count: 1}, dropped: []bool{true, true, false, true}},
+		{name: "dh1", keep: KeepModifier{Mode: DropHighest, Count: 1}, dropped: []bool{false, true, false, false}},
+		{name: "dl1", keep: KeepModifier{Mode: DropLowest, Count: 1}, dropped: []bool{false, false, true, false}},
+		{name: "kh3", keep: KeepModifier{Mode: KeepHighest, Count: 3}, dropped: []bool{false, false, true, false}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			results := make([]models.DieResult, len(values))
+			for i, value := range values {
+				results[i] = models.DieResult{Value: value}
+			}
+
+			applyKeep(results, &tt.keep)
+
+			for i, die := range results {
+				assert.Equal(t, tt.dropped[i], die.Dropped, "dado %d", i)
+			}
+		})
+	}
+}
+
+func TestRollKeepDrop(t *testing.T) {
+	engine := NewRollEngine()
+
+	for i := 0; i < 50; i++ {
+		result, err := engine.Roll("4d6dl1")
+		assert.NoError(t, err)
+		assert.Len(t, result.Terms, 1)
+
+		term := result.Terms[0]
+		assert.Len(t, term.Dice, 4)
+		assert.Len(t, term.KeptValues(), 3)
+		assert.Len(t, result.Dice, 3)
+
+		// O dado descartado não pode ser maior que nenhum dos mantidos
+		for _, die := range term.Dice {
+			if die.Dropped {
+				for _, kept := range term.KeptValues() {
+					assert.LessOrEqual(t, die.Value, kept)
+				}
+			}
+		}
+		assert.GreaterOrEqual(t, result.Total, 3)
+		assert.LessOrEqual(t, result.Total, 18)
+	}
+}
+
+func TestRollCriticalUsesKeptD20(t *testing.T) {
+	engine := NewRollEngine()
+
+	for i := 0; i < 200; i++ {
+		result, err := engine.Roll("2d20kl1")
+		assert.NoError(t, err)
+
+		kept := result.Terms[0].KeptValues()
+		assert.Len(t, kept, 1)
+		assert.Equal(t, kept[0] == 20, result.Critical)
+		assert.Equal(t, kept[0] == 1, result.Fumble)
+	}
+}
+
+func TestRollExploding(t *testing.T) {
+	engine := NewRollEngineWithSource(NewSeededSource(7))
+
+	for i := 0; i < 200; i++ {
+		result, err := engine.Roll("3d6!")
+		assert.NoError(t, err)
+
+		dice := result.Terms[0].Dice
+		for j, die := range dice {
+			// Todo 6 explode e gera um dado extra logo em seguida
+			assert.Equal(t, die.Value == 6, die.Exploded)
+			if die.Exploded {
+				assert.True(t, dice[j+1].Extra)
+			}
+		}
+		assert.Len(t, result.Dice, len(dice))
+	}
+}
+
+func TestRollExplodingLimit(t *testing.T) {
+	engine := NewRollEngineWithSource(NewSeededSource(7))
+
+	for i := 0; i < 200; i++ {
+		result, err := engine.Roll("1d2!l3")
+		assert.NoError(t, err)
+		assert.LessOrEqual(t, len(result.Terms[0].Dice), 4)
+	}
+}
+
+func TestRollCompounding(t *testing.T) {
+	engine := NewRollEngineWithSource(NewSeededSource(7))
+
+	for i := 0; i < 200; i++ {
+		result, err := engine.Roll("1d6!!")
+		assert.NoError(t, err)
+
+		dice := result.Terms[0].Dice
+		assert.Len(t, dice, 1)
+
+		die := dice[0]
+		if !die.Exploded {
+			assert.Empty(t, die.Rolls)
+			assert.Less(t, die.Value, 6)
+			continue
+		}
+
+		sum := 0
+		for j, roll := range die.Rolls {
+			sum += roll
+			if j < len(die.Rolls)-1 {
+				assert.Equal(t, 6, roll)
+			}
+		}
+		assert.Equal(t, sum, die.Value)
+		assert.Equal(t, die.Value, result.Total)
+	}
+}
+
+func TestRollReroll(t *testing.T) {
+	engine := NewRollEngineWithSource(NewSeededSource(7))
+
+	for i := 0; i < 200; i++ {
+		result, err := engine.Roll("2d6r<3")
+		assert.NoError(t, err)
+		for _, die := range result.Terms[0].Dice {
+			assert.Greater(t, die.Value, 2)
+			for _, discarded := range die.Rerolls {
+				assert.LessOrEqual(t, discarded, 2)
+			}
+		}
+
+		result, err = engine.Roll("2d6ro1")
+		assert.NoError(t, err)
+		for _, die := range result.Terms[0].Dice {
+			assert.LessOrEqual(t, len(die.Rerolls), 1)
+			if len(die.Rerolls) == 1 {
+				assert.Equal(t, 1, die.Rerolls[0])
+			}
+		}
+	}
+}
+
+func TestRollSuccessPool(t *testing.T) {
+	engine := NewRollEngineWithSource(NewSeededSource(7))
+
+	for i := 0; i < 200; i++ {
+		result, err := engine.Roll("8d10>=8f1+1")
+		assert.NoError(t, err)
+
+		term := result.Terms[0]
+		assert.Equal(t, models.RollTermPool, term.Type)
+
+		successes, failures := 0, 0
+		for _, die := range term.Dice {
+			assert.Equal(t, die.Value >= 8, die.Success)
+			assert.Equal(t, die.Value == 1, die.Failure)
+			if die.Success {
+				successes++
+			}
+			if die.Failure {
+				failures++
+			}
+		}
+
+		assert.Equal(t, successes, *result.Successes)
+		assert.Equal(t, failures, *result.Failures)
+		assert.Equal(t, successes == 0 && failures > 0, result.Botch)
+		assert.Equal(t, max(successes-failures, 0), term.Value)
+		assert.Equal(t, term.Value+1, result.Total)
+		assert.Equal(t, 1, result.Modifier)
+	}
+}
+
+func TestRollSuccessPoolAgain(t *testing.T) {
+	engine := NewRollEngineWithSource(NewSeededSource(7))
+
+	for i := 0; i < 200; i++ {
+		result, err := engine.Roll("5d10a10>=8")
+		assert.NoError(t, err)
+
+		dice := result.Terms[0].Dice
+		for j, die := range dice {
+			assert.Equal(t, die.Value == 10, die.Exploded)
+			if die.Exploded {
+				assert.True(t, dice[j+1].Extra)
+			}
+		}
+		assert.False(t, result.Botch)
+	}
+}
+
+func TestRollWithoutPool(t *testing.T) {
+	engine := NewRollEngine()
+
+	result, err := engine.Roll("2d6+1")
+	assert.NoError(t, err)
+	assert.Nil(t, result.Successes)
+	assert.Nil(t, result.Failures)
+	assert.False(t, result.Botch)
+}
+
+func TestRollFate(t *testing.T) {
+	engine := NewRollEngine()
+
+	for i := 0; i < 100; i++ {
+		result, err := engine.Roll("4dF")
+		assert.NoError(t, err)
+		assert.GreaterOrEqual(t, result.Total, -4)
+		assert.LessOrEqual(t, result.Total, 4)
+		for _, die := range result.Terms[0].Dice {
+			assert.Contains(t, []int{-1, 0, 1}, die.Value)
+		}
+		assert.Equal(t, 0, result.Modifier)
+	}
+}
+
+func TestRollPercentile(t *testing.T) {
+	engine := NewRollEngineWithSource(NewSeededSource(7))
+
+	for i := 0; i < 200; i++ {
+		result, err := engine.Roll("d%")
+		assert.NoError(t, err)
+		assert.GreaterOrEqual(t, result.Total, 1)
+		assert.LessOrEqual(t, result.Total, 100)
+		assert.Len(t, result.Terms[0].Dice[0].Tens, 1)
+
+		for _, expr := range []string{"d%b2", "d%p2"} {
+			result, err := engine.Roll(expr)
+			assert.NoError(t, err)
+
+			die := result.Terms[0].Dice[0]
+			assert.Len(t, die.Tens, 3)
+
+			// Todas as dezenas compartilham a mesma unidade
+			units := die.Value % 10
+			var candidates []int
+			for _, ten := range die.Tens {
+				value := ten + units
+				if value == 0 {
+					value = 100
+				}
+				candidates = append(candidates, value)
+			}
+
+			if expr == "d%b2" {
+				assert.Equal(t, slices.Min(candidates), die.Value)
+			} else {
+				assert.Equal(t, slices.Max(candidates), die.Value)
+			}
+		}
+	}
+}
+
+func TestRollCustomDice(t *testing.T) {
+	engine := NewRollEngine()
+	options := &RollOptions{
+		CustomDice: []models.CustomDie{
+			{Name: "boost", Faces: []models.DieFace{
+				{},
+				{Symbols: []string{"success"}},
+				{Symbols: []string{"success", "advantage"}},
+			}},
+			{Name: "bonus", Faces: []models.DieFace{{Value: 2}, {Value: 4}}},
+		},
+	}
+
+	for i := 0; i < 100; i++ {
+		result, err := engine.RollWithOptions("2d[boost]+1d[bonus]", options)
+		assert.NoError(t, err)
+		assert.Contains(t, []int{2, 4}, result.Total)
+
+		successes := 0
+		for _, die := range result.Terms[0].Dice {
+			if slices.Contains(die.Symbols, "success") {
+				successes++
+			}
+		}
+		assert.Equal(t, successes, result.Symbols["success"])
+		assert.Equal(t, result.Terms[0].Symbols["advantage"], result.Symbols["advantage"])
+	}
+
+	_, err := engine.Roll("1d[boost]")
+	assert.Error(t, err)
+}
+
+func TestValidateCustomDice(t *testing.T) {
+	valid := models.CustomDie{Name: "boost", Faces: []models.DieFace{{}, {Value: 1}}}
+	assert.NoError(t, ValidateCustomDice([]models.CustomDie{valid}))
+
+	assert.Error(t, ValidateCustomDice([]models.CustomDie{valid, valid}))
+	assert.Error(t, ValidateCustomDie(models.CustomDie{Name: "Boost", Faces: valid.Faces}))
+	assert.Error(t, ValidateCustomDie(models.CustomDie{Name: "boost", Faces: []models.DieFace{{}}}))
+	assert.Error(t, ValidateCustomDie(models.CustomDie{Name: "boost", Faces: []models.DieFace{{}, {Symbols: []string{""}}}}))
+}
+
+func TestRollDivisionByZero(t *testing.T) {
+	engine := NewRollEngine()
+
+	_, err := engine.Roll("1d6/(2-2)")
+	assert.Error(t, err)
+}
+
+func TestRollOverflow(t *testing.T) {
+	engine := NewRollEngine()
+
+	// 2^32 * 2^32 transbordaria para 0 se multiplicado antes da verificação
+	for _, expr := range []string{"(65536*65536)*(65536*65536)", "(65536*65536)*(65536*65536)+1d20", "-(65536*65536)*(65536*65536)"} {
+		_, err := engine.Roll(expr)
+		assert.ErrorContains(t, err, "resultado fora do intervalo permitido", expr)
+
+		_, err = engine.Analyze(expr, nil)
+		assert.ErrorContains(t, err, "resultado fora do intervalo permitido", expr)
+	}
+
+	// Produto no limite ainda é permitido
+	result, err := engine.Roll("(1024*1024)*(1024*1024)")
+	assert.NoError(t, err)
+	assert.Equal(t, 1<<40, result.Total)
+}
+
 func TestRollCriticalAndFumble(t *testing.T) {
 	engine := NewRollEngine()
 
@@ -173,35 +781,144 @@ func TestRollCriticalAndFumble(t *testing.T) {
 	}
 }
 
-func TestRollFromField(t *testing.T) {
-	engine := NewRollEngine()
-
-	// Dados de ficha de teste
-	sheetData := models.PlayerSheetData{
-		"attributes": map[string]interface{}{
-			"strength":  15,
-			"dexterity": 12,
-		},
-		"skills": map[string]interface{}{
-			"arcana":  8,
-			"stealth": "1d20+2",
-		},
-	}
+func TestRollCritRules(t *testing.T) {
+	engine := NewRollEngineWithSource(NewSeededSource(3))
+	intp := func(v int) *int { return &v }
 
 	tests := []struct {
-		name      string
-		fieldName string
-		hasError  bool
+		name     string
+		expr     string
+		options  *RollOptions
+		critical func(natural int) bool
+		fumble   func(natural int) bool
 	}{
 		{
-			name:      "Campo numérico existente",
-			fieldName: "attributes.strength",
-			hasError:  false,
+			name:     "Padrão d20",
+			expr:     "1d20+5",
+			critical: func(n int) bool { return n == 20 },
+			fumble:   func(n int) bool { return n == 1 },
 		},
 		{
-			name:      "Campo de expressão existente",
-			fieldName: "skills.stealth",
-			hasError:  false,
+			name:     "Padrão d20 com vantagem",
+			expr:     "2d20kh1",
+			critical: func(n int) bool { return n == 20 },
+			fumble:   func(n int) bool { return n == 1 },
+		},
+		{
+			name:     "Crítico aprimorado",
+			expr:     "1d20+7",
+			options:  &RollOptions{Critical: &models.CritRule{Dice: "1d20", Min: intp(19)}},
+			critical: func(n int) bool { return n >= 19 },
+			fumble:   func(n int) bool { return n == 1 },
+		},
+		{
+			name: "Percentual 01-05 e 96-00",
+			expr: "d%",
+			options: &RollOptions{
+				Critical: &models.CritRule{Dice: "d%", Max: intp(5)},
+				Fumble:   &models.CritRule{Dice: "1d100", Min: intp(96)},
+			},
+			critical: func(n int) bool { return n <= 5 },
+			fumble:   func(n int) bool { return n >= 96 },
+		},
+		{
+			name: "2d6",
+			expr: "2d6+1",
+			options: &RollOptions{
+				Critical: &models.CritRule{Dice: "2d6", Min: intp(12)},
+				Fumble:   &models.CritRule{Dice: "2d6", Max: intp(2)},
+			},
+			critical: func(n int) bool { return n == 12 },
+			fumble:   func(n int) bool { return n == 2 },
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			seenCritical, seenFumble := false, false
+			for i := 0; i < 2000; i++ {
+				result, err := engine.RollWithOptions(tt.expr, tt.options)
+				assert.NoError(t, err)
+
+				natural := 0
+				for _, value := range result.Terms[0].KeptValues() {
+					natural += value
+				}
+				assert.Equal(t, tt.critical(natural), result.Critical, "natural %d", natural)
+				assert.Equal(t, tt.fumble(natural), result.Fumble, "natural %d", natural)
+				seenCritical = seenCritical || result.Critical
+				seenFumble = seenFumble || result.Fumble
+			}
+			assert.True(t, seenCritical)
+			assert.True(t, seenFumble)
+		})
+	}
+
+	// Regras não se aplicam a termos ambíguos ou de outros dados
+	for _, expr := range []string{"1d20+1d20", "1d12", "3d6"} {
+		for i := 0; i < 200; i++ {
+			result, err := engine.Roll(expr)
+			assert.NoError(t, err)
+			assert.False(t, result.Critical, expr)
+			assert.False(t, result.Fumble, expr)
+		}
+	}
+}
+
+func TestValidateCritRule(t *testing.T) {
+	intp := func(v int) *int { return &v }
+
+	assert.NoError(t, ValidateCritRule(nil))
+	assert.NoError(t, ValidateCritRule(&DefaultCritical))
+	assert.NoError(t, ValidateCritRule(&DefaultFumble))
+	assert.NoError(t, ValidateCritRule(&models.CritRule{Dice: "d%", Min: intp(1), Max: intp(5)}))
+	assert.NoError(t, ValidateCritRule(&models.CritRule{Dice: "2d6", Min: intp(12)}))
+
+	assert.Error(t, ValidateCritRule(&models.CritRule{Dice: "1d20"}))
+	assert.Error(t, ValidateCritRule(&models.CritRule{Dice: "1d20", Min: intp(21)}))
+	assert.Error(t, ValidateCritRule(&models.CritRule{Dice: "2d6", Max: intp(1)}))
+	assert.Error(t, ValidateCritRule(&models.CritRule{Dice: "1d20", Min: intp(19), Max: intp(18)}))
+	assert.Error(t, ValidateCritRule(&models.CritRule{Dice: "1d20+5", Min: intp(20)}))
+	assert.Error(t, ValidateCritRule(&models.CritRule{Dice: "2d20kh1", Min: intp(20)}))
+	assert.Error(t, ValidateCritRule(&models.CritRule{Dice: "4dF", Min: intp(4)}))
+	assert.Error(t, ValidateCritRule(&models.CritRule{Dice: "abc", Min: intp(4)}))
+}
+
+func TestRollFromField(t *testing.T) {
+	engine := NewRollEngine()
+
+	// Dados de ficha de teste
+	sheetData := models.PlayerSheetData{
+		"attributes": map[string]interface{}{
+			"strength":  15,
+			"dexterity": 12,
+		},
+		"skills": map[string]interface{}{
+			"arcana":       8,
+			"stealth":      "1d20+2",
+			"sneak_attack": "1d8+2d6+3",
+		},
+	}
+
+	tests := []struct {
+		name      string
+		fieldName string
+		hasError  bool
+	}{
+		{
+			name:      "Campo numérico existente",
+			fieldName: "attributes.strength",
+			hasError:  false,
+		},
+		{
+			name:      "Campo de expressão existente",
+			fieldName: "skills.stealth",
+			hasError:  false,
+		},
+		{
+			name:      "Campo de expressão composta",
+			fieldName: "skills.sneak_attack",
+			hasError:  false,
 		},
 		{
 			name:      "Campo inexistente",
@@ -279,7 +996,834 @@ func TestEvaluateSuccess(t *testing.T) {
 	}
 }
 
+func TestEvaluateSuccessPool(t *testing.T) {
+	engine := NewRollEngine()
+
+	three, zero, one := 3, 0, 1
+
+	tests := []struct {
+		name       string
+		result     *models.RollDetails
+		difficulty int
+		expected   bool
+	}{
+		{
+			name:       "Sucessos suficientes",
+			result:     &models.RollDetails{Total: 3, Successes: &three, Failures: &zero},
+			difficulty: 3,
+			expected:   true,
+		},
+		{
+			name:       "Sucessos insuficientes",
+			result:     &models.RollDetails{Total: 3, Successes: &three, Failures: &zero},
+			difficulty: 4,
+			expected:   false,
+		},
+		{
+			name:       "Sem dificuldade e sem sucessos",
+			result:     &models.RollDetails{Total: 0, Successes: &zero, Failures: &zero},
+			difficulty: 0,
+			expected:   false,
+		},
+		{
+			name:       "Falha crítica",
+			result:     &models.RollDetails{Total: 0, Successes: &zero, Failures: &one, Botch: true},
+			difficulty: 0,
+			expected:   false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, engine.EvaluateSuccess(tt.result, tt.difficulty))
+		})
+	}
+}
+
+func TestEvaluateOutcome(t *testing.T) {
+	intp := func(v int) *int { return &v }
+
+	pbta := &models.OutcomeTable{Bands: []models.OutcomeBand{
+		{Name: "sucesso total", Min: intp(10), Success: true},
+		{Name: "sucesso parcial", Min: intp(7), Max: intp(9), Success: true},
+		{Name: "falha", Max: intp(6)},
+	}}
+	pf2e := &models.OutcomeTable{Basis: models.OutcomeBasisMargin, StepOnCritical: true, Bands: []models.OutcomeBand{
+		{Name: "sucesso crítico", Min: intp(10), Success: true},
+		{Name: "sucesso", Min: intp(0), Max: intp(9), Success: true},
+		{Name: "falha", Min: intp(-9), Max: intp(-1)},
+		{Name: "falha crítica", Max: intp(-10)},
+	}}
+	coc := &models.OutcomeTable{Basis: models.OutcomeBasisPercent, Bands: []models.OutcomeBand{
+		{Name: "extremo", Max: intp(20), Success: true},
+		{Name: "difícil", Max: intp(50), Success: true},
+		{Name: "regular", Max: intp(100), Success: true},
+		{Name: "falha"},
+	}}
+
+	tests := []struct {
+		name       string
+		details    *models.RollDetails
+		difficulty *int
+		table      *models.OutcomeTable
+		expected   string
+	}{
+		{name: "PbtA 10+", details: &models.RollDetails{Total: 11}, table: pbta, expected: "sucesso total"},
+		{name: "PbtA 7-9", details: &models.RollDetails{Total: 7}, table: pbta, expected: "sucesso parcial"},
+		{name: "PbtA 6-", details: &models.RollDetails{Total: 6}, table: pbta, expected: "falha"},
+		{name: "PF2e margem 10", details: &models.RollDetails{Total: 25}, difficulty: intp(15), table: pf2e, expected: "sucesso crítico"},
+		{name: "PF2e sucesso", details: &models.RollDetails{Total: 15}, difficulty: intp(15), table: pf2e, expected: "sucesso"},
+		{name: "PF2e 20 natural sobe um grau", details: &models.RollDetails{Total: 14, Critical: true}, difficulty: intp(15), table: pf2e, expected: "sucesso"},
+		{name: "PF2e 1 natural desce um grau", details: &models.RollDetails{Total: 16, Fumble: true}, difficulty: intp(15), table: pf2e, expected: "falha"},
+		{name: "PF2e já no pior grau", details: &models.RollDetails{Total: 1, Fumble: true}, difficulty: intp(15), table: pf2e, expected: "falha crítica"},
+		{name: "CoC extremo", details: &models.RollDetails{Total: 12}, difficulty: intp(60), table: coc, expected: "extremo"},
+		{name: "CoC difícil", details: &models.RollDetails{Total: 30}, difficulty: intp(60), table: coc, expected: "difícil"},
+		{name: "CoC regular", details: &models.RollDetails{Total: 60}, difficulty: intp(60), table: coc, expected: "regular"},
+		{name: "CoC falha", details: &models.RollDetails{Total: 61}, difficulty: intp(60), table: coc, expected: "falha"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			band, err := EvaluateOutcome(tt.details, tt.difficulty, tt.table)
+			assert.NoError(t, err)
+			if assert.NotNil(t, band) {
+				assert.Equal(t, tt.expected, band.Name)
+			}
+		})
+	}
+
+	band, err := EvaluateOutcome(&models.RollDetails{Total: 5}, nil, &models.OutcomeTable{Bands: []models.OutcomeBand{{Name: "alto", Min: intp(10)}}})
+	assert.NoError(t, err)
+	assert.Nil(t, band)
+
+	_, err = EvaluateOutcome(&models.RollDetails{Total: 5}, nil, pf2e)
+	assert.Error(t, err)
+	_, err = EvaluateOutcome(&models.RollDetails{Total: 5}, intp(0), coc)
+	assert.Error(t, err)
+}
+
+func TestRollWithOutcome(t *testing.T) {
+	engine := NewRollEngineWithSource(NewSeededSource(5))
+	intp := func(v int) *int { return &v }
+
+	tables := map[string]models.OutcomeTable{
+		"pbta": {Bands: []models.OutcomeBand{
+			{Name: "sucesso total", Min: intp(10), Success: true},
+			{Name: "sucesso parcial", Min: intp(7), Max: intp(9), Success: true},
+			{Name: "falha", Max: intp(6)},
+		}},
+	}
+
+	result, err := engine.RollWithOptions("8", &RollOptions{OutcomeTables: tables, Check: &models.RollCheck{OutcomeTable: "pbta"}})
+	assert.NoError(t, err)
+	if assert.NotNil(t, result.Outcome) {
+		assert.Equal(t, "sucesso parcial", result.Outcome.Name)
+		assert.True(t, result.Outcome.Success)
+	}
+
+	// Sem tabela, a dificuldade define sucesso ou falha
+	result, err = engine.RollWithOptions("10", &RollOptions{Check: &models.RollCheck{Difficulty: intp(10)}})
+	assert.NoError(t, err)
+	if assert.NotNil(t, result.Outcome) {
+		assert.Equal(t, OutcomeSuccess, result.Outcome.Name)
+		assert.Equal(t, 10, *result.Outcome.Difficulty)
+	}
+	result, err = engine.RollWithOptions("8", &RollOptions{Check: &models.RollCheck{Difficulty: intp(10)}})
+	assert.NoError(t, err)
+	if assert.NotNil(t, result.Outcome) {
+		assert.Equal(t, OutcomeFailure, result.Outcome.Name)
+		assert.False(t, result.Outcome.Success)
+	}
+
+	// A tabela "default" é usada quando só a dificuldade é informada
+	tables[DefaultOutcomeTable] = models.OutcomeTable{Basis: models.OutcomeBasisMargin, Bands: []models.OutcomeBand{
+		{Name: "passou", Min: intp(0), Success: true},
+		{Name: "não passou"},
+	}}
+	result, err = engine.RollWithOptions("5", &RollOptions{OutcomeTables: tables, Check: &models.RollCheck{Difficulty: intp(5)}})
+	assert.NoError(t, err)
+	if assert.NotNil(t, result.Outcome) {
+		assert.Equal(t, "passou", result.Outcome.Name)
+	}
+
+	// Sem teste pedido não há resultado nomeado
+	result, err = engine.RollWithOptions("4", &RollOptions{OutcomeTables: tables})
+	assert.NoError(t, err)
+	assert.Nil(t, result.Outcome)
+
+	_, err = engine.RollWithOptions("1d20", &RollOptions{Check: &models.RollCheck{OutcomeTable: "inexistente"}})
+	assert.EqualError(t, err, "tabela de resultados não definida: inexistente")
+}
+
+func TestValidateOutcomeTable(t *testing.T) {
+	intp := func(v int) *int { return &v }
+
+	assert.NoError(t, ValidateOutcomeTable(nil))
+	assert.NoError(t, ValidateOutcomeTable(&models.OutcomeTable{Bands: []models.OutcomeBand{{Name: "ok"}}}))
+
+	assert.Error(t, ValidateOutcomeTable(&models.OutcomeTable{Basis: "dobro", Bands: []models.OutcomeBand{{Name: "ok"}}}))
+	assert.Error(t, ValidateOutcomeTable(&models.OutcomeTable{}))
+	assert.Error(t, ValidateOutcomeTable(&models.OutcomeTable{Bands: []models.OutcomeBand{{Name: ""}}}))
+	assert.Error(t, ValidateOutcomeTable(&models.OutcomeTable{Bands: []models.OutcomeBand{{Name: "vazia", Min: intp(5), Max: intp(4)}}}))
+	assert.Error(t, ValidateOutcomeTables(map[string]models.OutcomeTable{"": {Bands: []models.OutcomeBand{{Name: "ok"}}}}))
+}
+
+func TestResolveContest(t *testing.T) {
+	tests := []struct {
+		name     string
+		attacker int
+		defender int
+		tieRule  string
+		winner   string
+		margin   int
+	}{
+		{name: "Atacante maior", attacker: 15, defender: 12, winner: models.ContestAttacker, margin: 3},
+		{name: "Defensor maior", attacker: 9, defender: 14, winner: models.ContestDefender, margin: -5},
+		{name: "Empate padrão", attacker: 10, defender: 10, winner: models.ContestDefender},
+		{name: "Empate favorece defensor", attacker: 10, defender: 10, tieRule: models.ContestTieRuleDefender, winner: models.ContestDefender},
+		{name: "Empate favorece atacante", attacker: 10, defender: 10, tieRule: models.ContestTieRuleAttacker, winner: models.ContestAttacker},
+		{name: "Empate sem vencedor", attacker: 10, defender: 10, tieRule: models.ContestTieRuleNone, winner: models.ContestTie},
+		{name: "Regra de empate não afeta vitória", attacker: 11, defender: 10, tieRule: models.ContestTieRuleNone, winner: models.ContestAttacker, margin: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			winner, margin, err := ResolveContest(&models.RollDetails{Total: tt.attacker}, &models.RollDetails{Total: tt.defender}, tt.tieRule)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.winner, winner)
+			assert.Equal(t, tt.margin, margin)
+		})
+	}
+
+	_, _, err := ResolveContest(&models.RollDetails{}, &models.RollDetails{}, "reroll")
+	assert.Error(t, err)
+}
+
+func TestSeededSourceIsDeterministic(t *testing.T) {
+	first := NewRollEngineWithSource(NewSeededSource(42))
+	second := NewRollEngineWithSource(NewSeededSource(42))
+
+	for i := 0; i < 50; i++ {
+		a, err := first.Roll("4d6kh3+1d8!")
+		assert.NoError(t, err)
+		b, err := second.Roll("4d6kh3+1d8!")
+		assert.NoError(t, err)
+		assert.Equal(t, a, b)
+	}
+}
+
+func TestCryptoSourceRange(t *testing.T) {
+	source := NewCryptoSource()
+	seen := make(map[int]bool)
+
+	for i := 0; i < 1000; i++ {
+		value := source.Intn(6)
+		assert.GreaterOrEqual(t, value, 0)
+		assert.Less(t, value, 6)
+		seen[value] = true
+	}
+	assert.Len(t, seen, 6)
+	assert.Equal(t, 0, source.Intn(1))
+}
+
+func TestFairSourceIsReproducible(t *testing.T) {
+	engine := NewRollEngine()
+	options := func(nonce int) *RollOptions {
+		return &RollOptions{Source: NewFairSource("server", "client", nonce)}
+	}
+
+	a, err := engine.RollWithOptions("4d6kh3+1d8!+2d%", options(1))
+	assert.NoError(t, err)
+	b, err := engine.RollWithOptions("4d6kh3+1d8!+2d%", options(1))
+	assert.NoError(t, err)
+	assert.Equal(t, a, b)
+
+	// Nonces diferentes produzem sequências diferentes
+	first, second := NewFairSource("server", "client", 1), NewFairSource("server", "client", 2)
+	same := true
+	for i := 0; i < 20; i++ {
+		if first.Intn(1000) != second.Intn(1000) {
+			same = false
+		}
+	}
+	assert.False(t, same)
+}
+
+func TestFairSourceAlgorithm(t *testing.T) {
+	// Primeiro valor = HMAC-SHA256("server", "client:3:0"), 8 primeiros bytes little-endian, módulo n
+	mac := hmac.New(sha256.New, []byte("server"))
+	mac.Write([]byte("client:3:0"))
+	block := mac.Sum(nil)
+	expected := int(binary.LittleEndian.Uint64(block[:8]) % 20)
+
+	assert.Equal(t, expected, NewFairSource("server", "client", 3).Intn(20))
+}
+
+func TestServerSeedCommitment(t *testing.T) {
+	seed, err := GenerateServerSeed()
+	assert.NoError(t, err)
+	assert.Len(t, seed, 64)
+
+	other, err := GenerateServerSeed()
+	assert.NoError(t, err)
+	assert.NotEqual(t, seed, other)
+
+	assert.Equal(t, "2cf24dba5fb0a30e26e83b2ac5b9e29e1b161e5c1fa7425e73043362938b9824", HashServerSeed("hello"))
+	assert.Len(t, HashServerSeed(seed), 64)
+}
+
+func TestAnalyzeExact(t *testing.T) {
+	engine := NewRollEngine()
+	options := &RollOptions{CustomDice: []models.CustomDie{
+		{Name: "boost", Faces: []models.DieFace{{}, {}, {Value: 1}, {Value: 1}, {Value: 2}, {Value: 2}}},
+	}}
+
+	tests := []struct {
+		expression string
+		min, max   int
+		mean       float64
+		checks     map[int]float64 // P(total = chave)
+	}{
+		{expression: "3d6+2", min: 5, max: 20, mean: 12.5, checks: map[int]float64{5: 1.0 / 216, 12: 27.0 / 216}},
+		{expression: "4d6kh3", min: 3, max: 18, mean: 15869.0 / 1296, checks: map[int]float64{18: 21.0 / 1296, 3: 1.0 / 1296}},
+		{expression: "2d20kl1", min: 1, max: 20, mean: 5.0 * 287 / 200, checks: map[int]float64{1: 39.0 / 400}},
+		{expression: "2d10>=8", min: 0, max: 2, mean: 0.6, checks: map[int]float64{0: 0.49, 1: 0.42, 2: 0.09}},
+		{expression: "1d10>=8f1", min: 0, max: 1, mean: 0.3, checks: map[int]float64{0: 0.7}},
+		{expression: "3d10kh2>=8", min: 0, max: 2, checks: map[int]float64{0: 0.343, 2: 0.216}},
+		{expression: "4dF", min: -4, max: 4, mean: 0, checks: map[int]float64{4: 1.0 / 81}},
+		{expression: "1d6ro1", min: 1, max: 6, mean: 141.0 / 36, checks: map[int]float64{1: 1.0 / 36, 6: 7.0 / 36}},
+		{expression: "d%b1", min: 1, max: 100, checks: map[int]float64{100: 1.0 / 1000}},
+		{expression: "1d6*2-1", min: 1, max: 11, mean: 6, checks: map[int]float64{2: 0, 3: 1.0 / 6}},
+		{expression: "1d6/2", min: 0, max: 3, mean: 1.5, checks: map[int]float64{0: 1.0 / 6, 3: 1.0 / 6}},
+		{expression: "-1d4", min: -4, max: -1, mean: -2.5},
+		{expression: "2d[boost]", min: 0, max: 4, mean: 2, checks: map[int]float64{4: 1.0 / 9}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.expression, func(t *testing.T) {
+			d, err := engine.Analyze(tt.expression, options)
+			assert.NoError(t, err)
+			assert.True(t, d.Exact)
+			assert.Zero(t, d.Samples)
+			assert.Equal(t, tt.min, d.Min)
+			assert.Equal(t, tt.max, d.Max)
+			if tt.mean != 0 || tt.expression == "4dF" {
+				assert.InDelta(t, tt.mean, d.Mean, 1e-9)
+			}
+			for total, p := range tt.checks {
+				assert.InDelta(t, p, d.Probability(total), 1e-9, "P(%d)", total)
+			}
+
+			sum := 0.0
+			for _, total := range d.Totals() {
+				sum += d.Probability(total)
+			}
+			assert.InDelta(t, 1, sum, 1e-9)
+		})
+	}
+}
+
+func TestAnalyzeAtLeast(t *testing.T) {
+	d, err := NewRollEngine().Analyze("3d6+2", nil)
+	assert.NoError(t, err)
+	assert.InDelta(t, 35.0/216, d.AtLeast(16), 1e-9)
+	assert.Equal(t, 1.0, d.AtLeast(5))
+	assert.Equal(t, 1.0, d.AtLeast(-10))
+	assert.Zero(t, d.AtLeast(21))
+	assert.InDelta(t, math.Sqrt(35.0/4), d.StdDev, 1e-9)
+}
+
+func TestAnalyzeLargeSpan(t *testing.T) {
+	engine := NewRollEngine()
+
+	// 100 mil totais possíveis: o histograma consulta AtLeast em cada um sem percorrer a distribuição
+	start := time.Now()
+	d, err := engine.Analyze("1d1000*100+1d100", nil)
+	require.NoError(t, err)
+	assert.True(t, d.Exact)
+	totals := d.Totals()
+	assert.Len(t, totals, 100_000)
+	for _, total := range totals {
+		d.AtLeast(total)
+	}
+	assert.Less(t, time.Since(start), 2*time.Second)
+
+	assert.Equal(t, 1.0, d.AtLeast(d.Min))
+	assert.InDelta(t, 1.0/100_000, d.AtLeast(d.Max), 1e-12)
+	assert.InDelta(t, 0.5, d.AtLeast(50_101), 1e-9)
+	assert.Zero(t, d.AtLeast(d.Max+1))
+}
+
+func TestAnalyzeMonteCarlo(t *testing.T) {
+	engine := NewRollEngine()
+
+	// Explosões não têm distribuição exata finita
+	d, err := engine.Analyze("1d6!", nil)
+	assert.NoError(t, err)
+	assert.False(t, d.Exact)
+	assert.Equal(t, analysisSamples, d.Samples)
+	assert.Greater(t, d.ErrorBound, 0.0)
+	assert.Less(t, d.ErrorBound, 0.01)
+	assert.Equal(t, 1, d.Min)
+	assert.InDelta(t, 4.2, d.Mean, 0.05)
+	assert.Zero(t, d.Probability(6))
+	assert.InDelta(t, 1.0/6, d.Probability(1), d.ErrorBound)
+
+	// Paradas com "again" também são estimadas
+	d, err = engine.Analyze("5d10>=8a10", nil)
+	assert.NoError(t, err)
+	assert.False(t, d.Exact)
+
+	// Resultado reproduzível
+	again, err := engine.Analyze("1d6!", nil)
+	assert.NoError(t, err)
+	assert.Equal(t, d.Exact, again.Exact)
+	first, _ := engine.Analyze("1d6!", nil)
+	assert.Equal(t, first.Mean, again.Mean)
+}
+
+func TestAnalyzeMonteCarloWork(t *testing.T) {
+	engine := NewRollEngine()
+
+	// Cada amostra pode rolar 100 dados com até 100 explosões: menos amostras, com limite de erro maior
+	d, err := engine.Analyze("100d100!", nil)
+	assert.NoError(t, err)
+	assert.False(t, d.Exact)
+	assert.Equal(t, maxSampleWork/sampleWork(mustParse(t, engine, "100d100!")), d.Samples)
+	assert.Less(t, d.Samples, analysisSamples)
+	assert.GreaterOrEqual(t, d.Samples, minSamples)
+	assert.Greater(t, d.ErrorBound, confidenceZScore*math.Sqrt(0.25/analysisSamples))
+
+	// Pior caso acima do orçamento mesmo com o mínimo de amostras
+	_, err = engine.Analyze("100d2!>1+100d2!>1+100d2!>1", nil)
+	assert.ErrorContains(t, err, "pesada demais")
+	_, err = engine.Analyze("100d6r<3!", nil)
+	assert.ErrorContains(t, err, "pesada demais")
+}
+
+func TestAnalyzeExactOnly(t *testing.T) {
+	engine := NewRollEngine()
+
+	d, err := engine.AnalyzeExact("3d6+2", nil, 1_000)
+	require.NoError(t, err)
+	assert.True(t, d.Exact)
+	assert.InDelta(t, 12.5, d.Mean, 1e-9)
+
+	// Sem Monte Carlo: explosões e expressões acima do limite de operações são erro
+	start := time.Now()
+	_, err = engine.AnalyzeExact("100d1000", nil, 1_000_000)
+	assert.ErrorIs(t, err, errNotExact)
+	_, err = engine.AnalyzeExact("1d6!", nil, 1_000_000)
+	assert.ErrorIs(t, err, errNotExact)
+	assert.Less(t, time.Since(start), 100*time.Millisecond)
+
+	_, err = engine.AnalyzeExact("1d", nil, 1_000)
+	assert.Error(t, err)
+}
+
+func TestAnalyzeRejectsWideSpanBeforeWork(t *testing.T) {
+	engine := NewRollEngine()
+
+	// Os limites da árvore já passam da amplitude máxima: nada é calculado nem amostrado
+	start := time.Now()
+	_, err := engine.Analyze("5d1000*5d1000", nil)
+	assert.ErrorContains(t, err, "resultado fora do intervalo permitido")
+	assert.Less(t, time.Since(start), 100*time.Millisecond)
+
+	// Subexpressão larga com total estreito: desiste do cálculo exato antes de combinar os operandos
+	start = time.Now()
+	d, err := engine.Analyze("(5d1000*5d1000)/1000", nil)
+	require.NoError(t, err)
+	assert.False(t, d.Exact)
+	assert.Less(t, time.Since(start), 2*time.Second)
+}
+
+func TestAnalyzeBounds(t *testing.T) {
+	engine := NewRollEngine()
+	an := &analyzer{customDice: map[string]models.CustomDie{
+		"boost": {Name: "boost", Faces: []models.DieFace{{Value: -1}, {Value: 0}, {Value: 2}}},
+	}}
+
+	tests := []struct {
+		expression string
+		lo, hi     float64
+	}{
+		{"5", 5, 5},
+		{"2d6+3", 5, 15},
+		{"1d20-1d6", -5, 19},
+		{"-(1d4)", -4, -1},
+		{"4dF", -4, 4},
+		{"2d%", 2, 200},
+		{"3d[boost]", -3, 6},
+		{"4d6kh3", 0, 24},
+		{"1d6!l2", 1, 18},
+		{"1d6!!l2", 1, 18},
+		{"5d10>=8a10", 0, 5 * (1 + maxExplosions)},
+		{"1d6*1d4", 1, 24},
+		{"(1d6-3)*1d4", -8, 12},
+		{"1d20/2", -0.5, 11},
+		{"1d20/(1d3-2)", -20, 20},
+		{"max(1d6, 1d8)", 1, 8},
+		{"min(1d6, 1d8)", 1, 6},
+		{"abs(1d6-4)", 0, 3},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.expression, func(t *testing.T) {
+			lo, hi := an.bounds(mustParse(t, engine, tt.expression))
+			assert.Equal(t, tt.lo, lo)
+			assert.Equal(t, tt.hi, hi)
+		})
+	}
+}
+
+func TestSampleWork(t *testing.T) {
+	engine := NewRollEngine()
+
+	tests := []struct {
+		expression string
+		work       int
+	}{
+		{"5", 0},
+		{"2d6+1d8", 3},
+		{"4dF", 4},
+		{"2d%", 4},
+		{"1d%b2", 4},
+		{"3d6ro1", 6},
+		{"1d6r1", 1 + maxRerolls},
+		{"2d6!", 2 * (1 + maxExplosions)},
+		{"2d6!l3", 2 * 4},
+		{"1d6!!", 1 + maxExplosions},
+		{"1d6r1!", (1 + maxRerolls) * (1 + maxExplosions)},
+		{"max(1d6!, -(2d4))", 1 + maxExplosions + 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.expression, func(t *testing.T) {
+			assert.Equal(t, tt.work, sampleWork(mustParse(t, engine, tt.expression)))
+		})
+	}
+}
+
+// mustParse retorna a raiz da expressão, que deve ser válida
+func mustParse(t *testing.T, engine *RollEngine, expression string) Node {
+	t.Helper()
+	expr, err := engine.ParseExpression(expression)
+	require.NoError(t, err)
+	return expr.Root
+}
+
+func TestAnalyzeErrors(t *testing.T) {
+	engine := NewRollEngine()
+
+	_, err := engine.Analyze("1d6/(1d2-1)", nil)
+	assert.Error(t, err)
+
+	_, err = engine.Analyze("2d[boost]", nil)
+	assert.Error(t, err)
+
+	_, err = engine.Analyze("1d", nil)
+	assert.Error(t, err)
+
+	// Divisor com zero impossível não é erro
+	_, err = engine.Analyze("1d6/1d2", nil)
+	assert.NoError(t, err)
+}
+
+func TestRollConcurrent(t *testing.T) {
+	for name, engine := range map[string]*RollEngine{
+		"crypto": NewRollEngine(),
+		"seeded": NewRollEngineWithSource(NewSeededSource(1)),
+	} {
+		t.Run(name, func(t *testing.T) {
+			var wg sync.WaitGroup
+			for g := 0; g < 8; g++ {
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					for i := 0; i < 200; i++ {
+						result, err := engine.Roll("2d20kh1+1d6!")
+						assert.NoError(t, err)
+						assert.GreaterOrEqual(t, result.Total, 2)
+					}
+				}()
+			}
+			wg.Wait()
+		})
+	}
+}
+
 // Benchmarks para testar performance
+func TestResolvePlaceholders(t *testing.T) {
+	engine := NewRollEngine()
+
+	sheetData := models.PlayerSheetData{
+		"attack_bonus": 5,
+		"attributes": map[string]interface{}{
+			"str_mod": float64(3),
+			"penalty": -2,
+		},
+		"weapon": map[string]interface{}{
+			"damage": "1d8",
+			"bonus":  "4",
+		},
+	}
+
+	tests := []struct {
+		name       string
+		expression string
+		expected   string
+		hasError   bool
+	}{
+		{"Sem referências", "1d20+5", "1d20+5", false},
+		{"Campo de primeiro nível", "1d20+{attack_bonus}", "1d20+5", false},
+		{"Campo aninhado", "1d8+{attributes.str_mod}", "1d8+3", false},
+		{"Número negativo", "1d20+{attributes.penalty}", "1d20+(-2)", false},
+		{"Expressão como texto", "{weapon.damage}+{attributes.str_mod}", "(1d8)+3", false},
+		{"Número como texto", "1d6+{ weapon.bonus }", "1d6+4", false},
+		{"Campo inexistente", "1d20+{attributes.wisdom}", "", true},
+		{"Referência vazia", "1d20+{}", "", true},
+		{"Objeto", "1d20+{attributes}", "", true},
+		{"Várias referências", "1d20+{attack_bonus}+{attributes.str_mod}", "1d20+5+3", false},
+		{"Campo calculado", "1d20+{derived.dex_mod}", "1d20+(floor((14-10)/2))", false},
+		{"Campo com referência", "{derived.attack}", "(1d20+(floor((14-10)/2))+5)", false},
+		{"Referência circular", "1d20+{loop.a}", "", true},
+	}
+
+	sheetData["abilities"] = map[string]interface{}{"dex": 14}
+	sheetData["derived"] = map[string]interface{}{
+		"dex_mod": "floor(({abilities.dex}-10)/2)",
+		"attack":  "1d20+{derived.dex_mod}+{attack_bonus}",
+	}
+	sheetData["loop"] = map[string]interface{}{"a": "{loop.b}", "b": "1+{loop.a}"}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resolved, _, err := engine.ResolvePlaceholders(sheetData, tt.expression)
+			if tt.hasError {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, resolved)
+		})
+	}
+}
+
+func TestResolvePlaceholdersReferences(t *testing.T) {
+	engine := NewRollEngine()
+
+	sheetData := models.PlayerSheetData{
+		"proficiency": 2,
+		"abilities":   map[string]interface{}{"dex_mod": 3},
+	}
+
+	resolved, references, err := engine.ResolvePlaceholders(sheetData, "1d20+{abilities.dex_mod}+{proficiency}+{proficiency}")
+	assert.NoError(t, err)
+	assert.Equal(t, "1d20+3+2+2", resolved)
+	assert.Equal(t, []models.SheetReference{
+		{Field: "abilities.dex_mod", Value: "3"},
+		{Field: "proficiency", Value: "2"},
+	}, references)
+}
+
+func TestRollExpressionWithSheet(t *testing.T) {
+	engine := NewRollEngineWithSource(NewSeededSource(1))
+
+	sheetData := models.PlayerSheetData{
+		"abilities": map[string]interface{}{"str": 16},
+	}
+
+	result, expression, err := engine.RollExpressionWithSheet(sheetData, "10+floor(({abilities.str}-10)/2)", nil)
+	assert.NoError(t, err)
+	assert.Equal(t, "10+floor((16-10)/2)", expression)
+	assert.Equal(t, 13, result.Total)
+	assert.Equal(t, "10+floor(({abilities.str}-10)/2)", result.SourceExpression)
+	assert.Equal(t, []models.SheetReference{{Field: "abilities.str", Value: "16"}}, result.References)
+
+	// Sem referências, a expressão é rolada como está
+	result, expression, err = engine.RollExpressionWithSheet(sheetData, "7", nil)
+	assert.NoError(t, err)
+	assert.Equal(t, "7", expression)
+	assert.Empty(t, result.SourceExpression)
+	assert.Nil(t, result.References)
+
+	_, _, err = engine.RollExpressionWithSheet(nil, "1d20+{abilities.str}", nil)
+	assert.Error(t, err)
+}
+
+func TestRollFromFieldWithReferences(t *testing.T) {
+	engine := NewRollEngine()
+
+	sheetData := models.PlayerSheetData{
+		"abilities": map[string]interface{}{"str": 16},
+		"skills":    map[string]interface{}{"athletics": "1d20+floor(({abilities.str}-10)/2)"},
+	}
+
+	result, expression, err := engine.RollFromField(sheetData, "skills.athletics")
+	assert.NoError(t, err)
+	assert.Equal(t, "1d20+floor((16-10)/2)", expression)
+	assert.Equal(t, 3, result.Modifier)
+	assert.Equal(t, "1d20+floor(({abilities.str}-10)/2)", result.SourceExpression)
+}
+
+func TestFunctions(t *testing.T) {
+	engine := NewRollEngine()
+
+	tests := []struct {
+		expression string
+		expected   int
+	}{
+		{"floor((15-10)/2)", 2},
+		{"floor((7-10)/2)", -2},
+		{"ceil(7/2)", 4},
+		{"ceil((7-10)/2)", -1},
+		{"round(7/2)", 4},
+		{"round(5/3)", 2},
+		{"round(-7/2)", -4},
+		{"floor(7)", 7},
+		{"abs(3-8)", 5},
+		{"min(4, 2, 9)", 2},
+		{"max(4, 2, 9)", 9},
+		{"MAX(1, 2)", 2},
+		{"10+max(floor((8-10)/2), 0)", 10},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.expression, func(t *testing.T) {
+			result, err := engine.Roll(tt.expression)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, result.Total)
+		})
+	}
+
+	for _, expression := range []string{"floor(1, 2)", "max(3)", "floor 3", "floor(", "ceil(1/0)", "sqrt(4)"} {
+		_, err := engine.Roll(expression)
+		assert.Error(t, err, expression)
+	}
+}
+
+func TestAnalyzeFunctions(t *testing.T) {
+	engine := NewRollEngine()
+
+	distribution, err := engine.Analyze("max(1d6, 3)", nil)
+	assert.NoError(t, err)
+	assert.Equal(t, 3, distribution.Min)
+	assert.Equal(t, 6, distribution.Max)
+	assert.InDelta(t, 0.5, distribution.Probability(3), 1e-9)
+
+	distribution, err = engine.Analyze("ceil(1d6/2)", nil)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, distribution.Min)
+	assert.Equal(t, 3, distribution.Max)
+	assert.InDelta(t, 1.0/3, distribution.Probability(2), 1e-9)
+}
+
+func TestExpandBatch(t *testing.T) {
+	tests := []struct {
+		name        string
+		expressions []string
+		expected    []string
+		hasError    bool
+	}{
+		{"Lista", []string{"1d20+5", "1d8+3"}, []string{"1d20+5", "1d8+3"}, false},
+		{"Repetição", []string{"3x 4d6kh3"}, []string{"4d6kh3", "4d6kh3", "4d6kh3"}, false},
+		{"Repetição sem espaço", []string{"2X1d20"}, []string{"1d20", "1d20"}, false},
+		{"Lista com repetição", []string{"1d20+5", "2x 1d6"}, []string{"1d20+5", "1d6", "1d6"}, false},
+		{"Expressão inválida segue no lote", []string{"1d0", "1d6"}, []string{"1d0", "1d6"}, false},
+		{"Repetição zero", []string{"0x 1d6"}, nil, true},
+		{"Repetições demais", []string{"21x 1d6"}, nil, true},
+		{"Lote grande demais", []string{"15x 1d6", "6x 1d8"}, nil, true},
+		{"Lote vazio", nil, nil, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			expanded, err := ExpandBatch(tt.expressions)
+			if tt.hasError {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, expanded)
+		})
+	}
+}
+
+func TestIsBatch(t *testing.T) {
+	assert.True(t, IsBatch("6x 4d6kh3", nil))
+	assert.True(t, IsBatch("", []string{"1d20"}))
+	assert.False(t, IsBatch("1d20+5", nil))
+	assert.False(t, IsBatch("max(1d6, 3)", nil))
+}
+
+func TestValidatePlaceholderExpression(t *testing.T) {
+	engine := NewRollEngine()
+
+	assert.NoError(t, engine.ValidatePlaceholderExpression("1d20+{attack_bonus}"))
+	assert.NoError(t, engine.ValidatePlaceholderExpression("1d8+{attributes.str_mod}+2"))
+	assert.NoError(t, engine.ValidatePlaceholderExpression("2d6"))
+	assert.Error(t, engine.ValidatePlaceholderExpression("1d20+{}"))
+	assert.Error(t, engine.ValidatePlaceholderExpression("1d20+{attack_bonus}+"))
+	assert.Error(t, engine.ValidatePlaceholderExpression("{attack_bonus}d"))
+}
+
+func TestIsSumExpression(t *testing.T) {
+	engine := NewRollEngine()
+	sheetData := models.PlayerSheetData{"pool": "{dice}d10>=8", "dice": 6, "attack": "1d20+{dice}"}
+
+	tests := []struct {
+		expression string
+		sum        bool
+	}{
+		{"1d20+5", true},
+		{"2d6+1d4", true},
+		{"d%b1", true},
+		{"{attack}+2", true},
+		{"8d10>=8", false},
+		{"{pool}", false},
+		{"4dF+2", false},
+		{"1d20+4dF", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.expression, func(t *testing.T) {
+			sum, err := engine.IsSumExpression(sheetData, tt.expression)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.sum, sum)
+		})
+	}
+
+	_, err := engine.IsSumExpression(nil, "1d20+{attack}")
+	assert.Error(t, err)
+}
+
+func TestChiSquare(t *testing.T) {
+	statistic, pValue := ChiSquare([]int{10, 10, 10, 10, 10, 10})
+	assert.Equal(t, 0.0, statistic)
+	assert.InDelta(t, 1.0, pValue, 1e-9)
+
+	// Um desvio de 2 em 20 lançamentos de moeda: qui-quadrado 0,8 com 1 grau de liberdade
+	statistic, pValue = ChiSquare([]int{12, 8})
+	assert.InDelta(t, 0.8, statistic, 1e-9)
+	assert.InDelta(t, 0.3711, pValue, 1e-4)
+
+	_, pValue = ChiSquare([]int{30, 10})
+	assert.InDelta(t, 0.0016, pValue, 1e-4)
+	statistic, pValue = ChiSquare([]int{20, 0})
+	assert.InDelta(t, 20.0, statistic, 1e-9)
+	assert.Less(t, pValue, 1e-4)
+
+	// d6 com 6 saindo demais: qui-quadrado 12 com 5 graus de liberdade
+	statistic, pValue = ChiSquare([]int{8, 8, 8, 8, 8, 20})
+	assert.InDelta(t, 12.0, statistic, 1e-9)
+	assert.InDelta(t, 0.0348, pValue, 1e-4)
+
+	statistic, pValue = ChiSquare(nil)
+	assert.Equal(t, 0.0, statistic)
+	assert.Equal(t, 1.0, pValue)
+}
+
 func BenchmarkParseExpression(b *testing.B) {
 	engine := NewRollEngine()
 	for i := 0; i < b.N; i++ {
@@ -307,3 +1851,18 @@ func BenchmarkRollFromField(b *testing.B) {
 		engine.RollFromField(sheetData, "attributes.strength")
 	}
 }
+
+func BenchmarkRollParallel(b *testing.B) {
+	for name, engine := range map[string]*RollEngine{
+		"crypto": NewRollEngine(),
+		"seeded": NewRollEngineWithSource(NewSeededSource(1)),
+	} {
+		b.Run(name, func(b *testing.B) {
+			b.RunParallel(func(pb *testing.PB) {
+				for pb.Next() {
+					engine.Roll("4d6kh3+1d20+5")
+				}
+			})
+		})
+	}
+}
@@ -0,0 +1,433 @@
+package roll
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/luizdequeiroz/rpg-backend/internal/app/models"
+)
+
+// maxMagnitude limita resultados intermediários para evitar overflow
+const maxMagnitude = 1 << 40
+
+// evaluator executa a rolagem de uma árvore sintática acumulando o detalhamento
+type evaluator struct {
+	source     RandomSource                // Fonte de aleatoriedade desta rolagem
+	customDice map[string]models.CustomDie // Dados personalizados disponíveis, por nome
+	dice       []int                       // Todos os dados rolados, na ordem
+	terms      []models.RollTerm           // Detalhamento por termo
+}
+
+// newEvaluator cria um avaliador com a fonte padrão e as definições da rolagem
+func newEvaluator(source RandomSource, options *RollOptions) *evaluator {
+	ev := &evaluator{source: source, customDice: customDiceByName(options)}
+	if options != nil && options.Source != nil {
+		ev.source = options.Source
+	}
+	return ev
+}
+
+// customDiceByName indexa os dados personalizados das opções; nomes repetidos prevalecem os últimos
+func customDiceByName(options *RollOptions) map[string]models.CustomDie {
+	customDice := make(map[string]models.CustomDie)
+	if options != nil {
+		for _, custom := range options.CustomDice {
+			customDice[strings.ToLower(custom.Name)] = custom
+		}
+	}
+	return customDice
+}
+
+// eval avalia um nó e retorna seu valor
+func (ev *evaluator) eval(n Node) (int, error) {
+	switch node := n.(type) {
+	case *NumberNode:
+		ev.terms = append(ev.terms, models.RollTerm{
+			Type:       models.RollTermConstant,
+			Expression: node.String(),
+			Value:      node.Value,
+		})
+		return node.Value, nil
+
+	case *DiceNode:
+		return ev.rollDice(node)
+
+	case *GroupNode:
+		return ev.eval(node.Inner)
+
+	case *NegateNode:
+		value, err := ev.eval(node.Operand)
+		if err != nil {
+			return 0, err
+		}
+		return -value, nil
+
+	case *BinaryNode:
+		left, err := ev.eval(node.Left)
+		if err != nil {
+			return 0, err
+		}
+		right, err := ev.eval(node.Right)
+		if err != nil {
+			return 0, err
+		}
+		return applyOperator(node.Op, left, right)
+
+	case *FuncNode:
+		return ev.evalFunc(node)
+	}
+
+	return 0, fmt.Errorf("nó de expressão desconhecido: %T", n)
+}
+
+// evalFunc avalia uma função. Funções de arredondamento aplicam-se à divisão do argumento
+// (e.g., "ceil(7/2)" = 4); sem divisão, o argumento já é inteiro e é retornado como está
+func (ev *evaluator) evalFunc(node *FuncNode) (int, error) {
+	if isRounding(node.Name) {
+		if div := divisionOf(node.Args[0]); div != nil {
+			left, err := ev.eval(div.Left)
+			if err != nil {
+				return 0, err
+			}
+			right, err := ev.eval(div.Right)
+			if err != nil {
+				return 0, err
+			}
+			return divide(left, right, node.Name)
+		}
+		return ev.eval(node.Args[0])
+	}
+
+	values := make([]int, len(node.Args))
+	for i, arg := range node.Args {
+		value, err := ev.eval(arg)
+		if err != nil {
+			return 0, err
+		}
+		values[i] = value
+	}
+	return applyFunction(node.Name, values)
+}
+
+// rollDice rola um termo de dados e registra o resultado
+func (ev *evaluator) rollDice(node *DiceNode) (int, error) {
+	var results []models.DieResult
+	switch node.Kind {
+	case DiceCustom:
+		custom, ok := ev.customDice[node.Custom]
+		if !ok {
+			return 0, fmt.Errorf("dado personalizado não definido: %s", node.Custom)
+		}
+		for i := 0; i < node.Count; i++ {
+			results = append(results, ev.rollCustom(custom))
+		}
+	case DiceFate:
+		for i := 0; i < node.Count; i++ {
+			results = append(results, models.DieResult{Value: ev.source.Intn(3) - 1})
+		}
+	case DicePercentile:
+		for i := 0; i < node.Count; i++ {
+			results = append(results, ev.rollPercentile(node.Tens))
+		}
+	default:
+		for i := 0; i < node.Count; i++ {
+			results = append(results, ev.rollDie(node)...)
+		}
+	}
+
+	if node.Keep != nil {
+		applyKeep(results, node.Keep)
+	}
+
+	term := models.RollTerm{
+		Type:       models.RollTermDice,
+		Expression: node.String(),
+		Dice:       results,
+	}
+	if node.Kind == DiceStandard || node.Kind == DicePercentile {
+		term.Sides = node.Sides
+	}
+
+	for _, value := range term.KeptValues() {
+		term.Value += value
+		ev.dice = append(ev.dice, value)
+	}
+	if node.Kind == DiceCustom {
+		countSymbols(&term)
+	}
+	if node.IsPool() {
+		countSuccesses(&term, node)
+	}
+	ev.terms = append(ev.terms, term)
+
+	return term.Value, nil
+}
+
+// rollPercentile rola um dado percentual como dezena + unidade.
+// Com bônus fica com a menor dezena e com penalidade, com a maior; "00" + "0" vale 100.
+func (ev *evaluator) rollPercentile(tens *TensModifier) models.DieResult {
+	units := ev.source.Intn(10)
+	rolls := 1
+	if tens != nil {
+		rolls += tens.Count
+	}
+
+	die := models.DieResult{}
+	for i := 0; i < rolls; i++ {
+		ten := ev.source.Intn(10) * 10
+		die.Tens = append(die.Tens, ten)
+
+		value := ten + units
+		if value == 0 {
+			value = 100
+		}
+
+		switch {
+		case i == 0:
+			die.Value = value
+		case tens.Bonus && value < die.Value:
+			die.Value = value
+		case !tens.Bonus && value > die.Value:
+			die.Value = value
+		}
+	}
+	return die
+}
+
+// rollCustom sorteia uma face de um dado personalizado
+func (ev *evaluator) rollCustom(custom models.CustomDie) models.DieResult {
+	face := custom.Faces[ev.source.Intn(len(custom.Faces))]
+	return models.DieResult{Value: face.Value, Symbols: face.Symbols}
+}
+
+// countSymbols soma os símbolos dos dados mantidos de um termo personalizado
+func countSymbols(term *models.RollTerm) {
+	for _, die := range term.Dice {
+		if die.Dropped {
+			continue
+		}
+		for _, symbol := range die.Symbols {
+			if term.Symbols == nil {
+				term.Symbols = make(map[string]int)
+			}
+			term.Symbols[symbol]++
+		}
+	}
+}
+
+// rollDie rola um dado aplicando rerrolagem e explosão.
+// Explosões comuns geram dados extras, que são retornados logo após o dado original.
+func (ev *evaluator) rollDie(node *DiceNode) []models.DieResult {
+	die := ev.rollWithReroll(node)
+	if node.Explode == nil {
+		return []models.DieResult{die}
+	}
+
+	trigger := node.Explode.trigger(node.Sides)
+
+	if node.Explode.Compound {
+		last := die.Value
+		for depth := 0; depth < node.Explode.Limit && trigger.Matches(last); depth++ {
+			if depth == 0 {
+				die.Rolls = []int{die.Value}
+			}
+			last = ev.source.Intn(node.Sides) + 1
+			die.Rolls = append(die.Rolls, last)
+			die.Value += last
+			die.Exploded = true
+		}
+		return []models.DieResult{die}
+	}
+
+	dice := []models.DieResult{die}
+	for depth := 0; depth < node.Explode.Limit && trigger.Matches(dice[len(dice)-1].Value); depth++ {
+		dice[len(dice)-1].Exploded = true
+		extra := ev.rollWithReroll(node)
+		extra.Extra = true
+		dice = append(dice, extra)
+	}
+	return dice
+}
+
+// rollWithReroll rola um dado aplicando a regra de rerrolagem, se houver
+func (ev *evaluator) rollWithReroll(node *DiceNode) models.DieResult {
+	die := models.DieResult{Value: ev.source.Intn(node.Sides) + 1}
+	if node.Reroll == nil {
+		return die
+	}
+
+	trigger := node.Reroll.trigger(node.Sides)
+	limit := maxRerolls
+	if node.Reroll.Once {
+		limit = 1
+	}
+
+	for attempts := 0; attempts < limit && trigger.Matches(die.Value); attempts++ {
+		die.Rerolls = append(die.Rerolls, die.Value)
+		die.Value = ev.source.Intn(node.Sides) + 1
+	}
+	return die
+}
+
+// countSuccesses transforma o termo em parada de sucessos.
+// Cada falha cancela um sucesso, sem deixar o valor do termo negativo.
+func countSuccesses(term *models.RollTerm, node *DiceNode) {
+	term.Type = models.RollTermPool
+	term.Successes = 0
+	term.Failures = 0
+
+	for i := range term.Dice {
+		die := &term.Dice[i]
+		if die.Dropped {
+			continue
+		}
+		if node.Success.Matches(die.Value) {
+			die.Success = true
+			term.Successes++
+		}
+		if node.Failure != nil && node.Failure.Matches(die.Value) {
+			die.Failure = true
+			term.Failures++
+		}
+	}
+
+	term.Value = term.Successes - term.Failures
+	if term.Value < 0 {
+		term.Value = 0
+	}
+}
+
+// applyKeep marca como descartados os dados que não atendem ao modificador
+func applyKeep(results []models.DieResult, keep *KeepModifier) {
+	order := make([]int, len(results))
+	for i := range order {
+		order[i] = i
+	}
+
+	// Ordenar índices do maior para o menor valor
+	sort.SliceStable(order, func(a, b int) bool {
+		return results[order[a]].Value > results[order[b]].Value
+	})
+
+	var dropped []int
+	switch keep.Mode {
+	case KeepHighest:
+		dropped = order[keep.Count:]
+	case KeepLowest:
+		dropped = order[:len(order)-keep.Count]
+	case DropHighest:
+		dropped = order[:keep.Count]
+	case DropLowest:
+		dropped = order[len(order)-keep.Count:]
+	}
+
+	for _, index := range dropped {
+		results[index].Dropped = true
+	}
+}
+
+// applyOperator aplica um operador aritmético.
+// A divisão arredonda para baixo, como é comum nos sistemas de RPG.
+func applyOperator(op byte, left, right int) (int, error) {
+	var result int
+
+	switch op {
+	case '+':
+		result = left + right
+	case '-':
+		result = left - right
+	case '*':
+		// Os operandos já estão dentro de maxMagnitude, mas o produto pode transbordar: verifica antes de multiplicar
+		if left != 0 && abs(right) > maxMagnitude/abs(left) {
+			return 0, errors.New("resultado fora do intervalo permitido")
+		}
+		result = left * right
+	case '/':
+		if right == 0 {
+			return 0, errors.New("divisão por zero")
+		}
+		result = floorDiv(left, right)
+	default:
+		return 0, fmt.Errorf("operador desconhecido: %c", op)
+	}
+
+	if result > maxMagnitude || result < -maxMagnitude {
+		return 0, errors.New("resultado fora do intervalo permitido")
+	}
+	return result, nil
+}
+
+// isRounding indica se a função arredonda uma divisão
+func isRounding(name string) bool {
+	return name == "floor" || name == "ceil" || name == "round"
+}
+
+// divisionOf retorna a divisão no topo do nó, ignorando parênteses, ou nil se não houver
+func divisionOf(n Node) *BinaryNode {
+	for {
+		switch node := n.(type) {
+		case *GroupNode:
+			n = node.Inner
+		case *BinaryNode:
+			if node.Op == '/' {
+				return node
+			}
+			return nil
+		default:
+			return nil
+		}
+	}
+}
+
+// divide realiza a divisão inteira com o arredondamento da função informada
+// ("floor", "ceil" ou "round", com metades arredondadas para longe de zero)
+func divide(left, right int, rounding string) (int, error) {
+	if right == 0 {
+		return 0, errors.New("divisão por zero")
+	}
+
+	switch rounding {
+	case "ceil":
+		return -floorDiv(-left, right), nil
+	case "round":
+		sign := 1
+		if (left < 0) != (right < 0) {
+			sign = -1
+		}
+		return sign * floorDiv(2*abs(left)+abs(right), 2*abs(right)), nil
+	}
+	return floorDiv(left, right), nil
+}
+
+// applyFunction aplica uma função que não é de arredondamento aos valores dos argumentos
+func applyFunction(name string, values []int) (int, error) {
+	switch name {
+	case "abs":
+		return abs(values[0]), nil
+	case "min":
+		return slices.Min(values), nil
+	case "max":
+		return slices.Max(values), nil
+	}
+	return 0, fmt.Errorf("função desconhecida: %s", name)
+}
+
+// abs retorna o valor absoluto
+func abs(value int) int {
+	if value < 0 {
+		return -value
+	}
+	return value
+}
+
+// floorDiv realiza divisão inteira arredondando em direção a -infinito
+func floorDiv(a, b int) int {
+	q := a / b
+	if (a%b != 0) && ((a < 0) != (b < 0)) {
+		q--
+	}
+	return q
+}
@@ -0,0 +1,97 @@
+package roll
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"math"
+	"sync"
+)
+
+// Tamanho em bytes das sementes geradas pelo servidor e, na falta de uma informada, pelo cliente
+const (
+	serverSeedBytes = 32
+	clientSeedBytes = 16
+)
+
+// GenerateServerSeed gera uma nova semente secreta do servidor, em hexadecimal
+func GenerateServerSeed() (string, error) {
+	return randomHex(serverSeedBytes)
+}
+
+// GenerateClientSeed gera uma semente de cliente para rolagens em que o jogador não informou uma
+func GenerateClientSeed() (string, error) {
+	return randomHex(clientSeedBytes)
+}
+
+// HashServerSeed calcula o compromisso (SHA-256 em hexadecimal) publicado antes da revelação da semente
+func HashServerSeed(serverSeed string) string {
+	sum := sha256.Sum256([]byte(serverSeed))
+	return hex.EncodeToString(sum[:])
+}
+
+// randomHex gera n bytes aleatórios codificados em hexadecimal
+func randomHex(n int) (string, error) {
+	buf := make([]byte, n)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("erro ao gerar semente: %w", err)
+	}
+	return hex.EncodeToString(buf), nil
+}
+
+// fairSource é uma fonte determinística derivada de semente do servidor, semente do cliente e nonce.
+// Os bytes são blocos HMAC-SHA256(server_seed, "client_seed:nonce:bloco"), consumidos em
+// inteiros de 64 bits little-endian com rejeição para evitar viés de módulo, de forma que
+// qualquer pessoa com as sementes reveladas consiga reproduzir os dados
+type fairSource struct {
+	mu         sync.Mutex
+	serverSeed []byte
+	clientSeed string
+	nonce      int
+	block      int
+	buf        []byte
+}
+
+// NewFairSource cria a fonte de uma rolagem verificável
+func NewFairSource(serverSeed, clientSeed string, nonce int) RandomSource {
+	return &fairSource{
+		serverSeed: []byte(serverSeed),
+		clientSeed: clientSeed,
+		nonce:      nonce,
+	}
+}
+
+// Intn sorteia um inteiro em [0, n) sem viés de módulo
+func (s *fairSource) Intn(n int) int {
+	if n <= 0 {
+		panic("roll: Intn com n <= 0")
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	bound := uint64(n)
+	limit := math.MaxUint64 - math.MaxUint64%bound
+	for {
+		value := s.next()
+		if value < limit {
+			return int(value % bound)
+		}
+	}
+}
+
+// next consome os próximos 8 bytes do fluxo, gerando um novo bloco quando necessário
+func (s *fairSource) next() uint64 {
+	if len(s.buf) < 8 {
+		mac := hmac.New(sha256.New, s.serverSeed)
+		fmt.Fprintf(mac, "%s:%d:%d", s.clientSeed, s.nonce, s.block)
+		s.buf = mac.Sum(nil)
+		s.block++
+	}
+	value := binary.LittleEndian.Uint64(s.buf[:8])
+	s.buf = s.buf[8:]
+	return value
+}
@@ -0,0 +1,174 @@
+package roll
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// tokenKind identifica o tipo de um token da expressão
+type tokenKind int
+
+const (
+	tokenEOF tokenKind = iota
+	tokenNumber
+	tokenKeyword
+	tokenPlus
+	tokenMinus
+	tokenStar
+	tokenSlash
+	tokenLParen
+	tokenRParen
+	tokenBang
+	tokenBangBang
+	tokenCompare
+	tokenPercent
+	tokenName
+	tokenFunc
+	tokenComma
+)
+
+// maxConstant limita o valor de constantes numéricas na expressão
+const maxConstant = 1000000
+
+// maxNameLength limita o tamanho do nome de um dado personalizado
+const maxNameLength = 30
+
+// keywords lista as palavras reconhecidas pelo tokenizador.
+// A busca é feita pelo maior prefixo, então palavras maiores devem vir antes.
+var keywords = []string{
+	"kh", "kl", "dh", "dl", "ro",
+	"k", "d", "r", "l", "f", "a", "b", "p",
+}
+
+// token representa um elemento léxico da expressão
+type token struct {
+	kind  tokenKind
+	text  string
+	value int
+	pos   int
+}
+
+// tokenize quebra a expressão em tokens
+func tokenize(expression string) ([]token, error) {
+	var tokens []token
+	runes := []rune(expression)
+
+	for i := 0; i < len(runes); {
+		ch := runes[i]
+
+		switch {
+		case unicode.IsSpace(ch):
+			i++
+
+		case unicode.IsDigit(ch):
+			start := i
+			for i < len(runes) && unicode.IsDigit(runes[i]) {
+				i++
+			}
+			text := string(runes[start:i])
+			value, err := strconv.Atoi(text)
+			if err != nil || value > maxConstant {
+				return nil, fmt.Errorf("número muito grande na posição %d: %s (máximo %d)", start+1, text, maxConstant)
+			}
+			tokens = append(tokens, token{kind: tokenNumber, text: text, value: value, pos: start})
+
+		case unicode.IsLetter(ch):
+			// Funções são palavras inteiras (e.g., "floor"); o resto são palavras-chave de dados
+			end := i
+			for end < len(runes) && unicode.IsLetter(runes[end]) {
+				end++
+			}
+			word := strings.ToLower(string(runes[i:end]))
+			if _, ok := functionArity[word]; ok {
+				tokens = append(tokens, token{kind: tokenFunc, text: word, pos: i})
+				i = end
+				continue
+			}
+
+			rest := strings.ToLower(string(runes[i:]))
+			matched := ""
+			for _, kw := range keywords {
+				if strings.HasPrefix(rest, kw) {
+					matched = kw
+					break
+				}
+			}
+			if matched == "" {
+				return nil, fmt.Errorf("termo desconhecido na posição %d: '%c'", i+1, ch)
+			}
+			tokens = append(tokens, token{kind: tokenKeyword, text: matched, pos: i})
+			i += len([]rune(matched))
+
+		case ch == '!':
+			if i+1 < len(runes) && runes[i+1] == '!' {
+				tokens = append(tokens, token{kind: tokenBangBang, text: "!!", pos: i})
+				i += 2
+			} else {
+				tokens = append(tokens, token{kind: tokenBang, text: "!", pos: i})
+				i++
+			}
+
+		case ch == '[':
+			end := i + 1
+			for end < len(runes) && runes[end] != ']' {
+				end++
+			}
+			if end == len(runes) {
+				return nil, fmt.Errorf("colchete não fechado na posição %d", i+1)
+			}
+			name := strings.ToLower(string(runes[i+1 : end]))
+			if !IsValidDieName(name) {
+				return nil, fmt.Errorf("nome de dado inválido na posição %d: '%s'", i+1, name)
+			}
+			tokens = append(tokens, token{kind: tokenName, text: name, pos: i})
+			i = end + 1
+
+		case ch == '>' || ch == '<' || ch == '=':
+			text := string(ch)
+			if ch != '=' && i+1 < len(runes) && runes[i+1] == '=' {
+				text += "="
+			}
+			tokens = append(tokens, token{kind: tokenCompare, text: text, pos: i})
+			i += len(text)
+
+		default:
+			kind, ok := symbolTokens[ch]
+			if !ok {
+				return nil, fmt.Errorf("caractere inesperado na posição %d: '%c'", i+1, ch)
+			}
+			tokens = append(tokens, token{kind: kind, text: string(ch), pos: i})
+			i++
+		}
+	}
+
+	tokens = append(tokens, token{kind: tokenEOF, pos: len(runes)})
+	return tokens, nil
+}
+
+// symbolTokens mapeia símbolos de um caractere para seus tokens
+var symbolTokens = map[rune]tokenKind{
+	'+': tokenPlus,
+	'-': tokenMinus,
+	'*': tokenStar,
+	'/': tokenSlash,
+	'(': tokenLParen,
+	')': tokenRParen,
+	'%': tokenPercent,
+	',': tokenComma,
+}
+
+// IsValidDieName verifica se o nome de um dado personalizado é válido
+// (letras minúsculas, dígitos, '_' e '-', com até maxNameLength caracteres)
+func IsValidDieName(name string) bool {
+	if name == "" || len(name) > maxNameLength {
+		return false
+	}
+	for _, ch := range name {
+		if !(ch >= 'a' && ch <= 'z') && !(ch >= '0' && ch <= '9') && ch != '_' && ch != '-' {
+			return false
+		}
+	}
+	return true
+}
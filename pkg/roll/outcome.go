@@ -0,0 +1,178 @@
+package roll
+
+import (
+	"fmt"
+
+	"github.com/luizdequeiroz/rpg-backend/internal/app/models"
+)
+
+// Limites das tabelas de resultados
+const (
+	maxOutcomeBands      = 20
+	maxOutcomeNameLength = 50
+)
+
+// DefaultOutcomeTable é o nome da tabela usada quando a rolagem informa apenas a dificuldade
+const DefaultOutcomeTable = "default"
+
+// Nomes dos resultados quando não há tabela declarada
+const (
+	OutcomeSuccess = "sucesso"
+	OutcomeFailure = "falha"
+	OutcomeBotch   = "falha crítica"
+)
+
+// ValidateOutcomeTable verifica se uma tabela de resultados é válida
+func ValidateOutcomeTable(table *models.OutcomeTable) error {
+	if table == nil {
+		return nil
+	}
+
+	switch table.Basis {
+	case "", models.OutcomeBasisTotal, models.OutcomeBasisMargin, models.OutcomeBasisPercent:
+	default:
+		return fmt.Errorf("base de resultados inválida: '%s' (use total, margin ou percent)", table.Basis)
+	}
+
+	if len(table.Bands) == 0 || len(table.Bands) > maxOutcomeBands {
+		return fmt.Errorf("tabela de resultados deve ter de 1 a %d faixas", maxOutcomeBands)
+	}
+	for i, band := range table.Bands {
+		if band.Name == "" || len(band.Name) > maxOutcomeNameLength {
+			return fmt.Errorf("faixa %d com nome inválido (1-%d caracteres)", i+1, maxOutcomeNameLength)
+		}
+		if band.Min != nil && band.Max != nil && *band.Min > *band.Max {
+			return fmt.Errorf("faixa '%s' com intervalo vazio: %d-%d", band.Name, *band.Min, *band.Max)
+		}
+	}
+	return nil
+}
+
+// ValidateOutcomeTables verifica as tabelas de resultados nomeadas de uma mesa ou template
+func ValidateOutcomeTables(tables map[string]models.OutcomeTable) error {
+	for name, table := range tables {
+		if name == "" || len(name) > maxOutcomeNameLength {
+			return fmt.Errorf("nome de tabela de resultados inválido: '%s'", name)
+		}
+		if err := ValidateOutcomeTable(&table); err != nil {
+			return fmt.Errorf("tabela '%s': %w", name, err)
+		}
+	}
+	return nil
+}
+
+// resolveOutcomeTable escolhe a tabela do teste: a informada na rolagem, a nomeada ou, havendo
+// apenas dificuldade, a tabela "default". Retorna nil quando o teste é um simples total >= dificuldade
+func resolveOutcomeTable(options *RollOptions) (*models.OutcomeTable, error) {
+	check := options.Check
+	if check.Outcomes != nil {
+		if err := ValidateOutcomeTable(check.Outcomes); err != nil {
+			return nil, err
+		}
+		return check.Outcomes, nil
+	}
+
+	if check.OutcomeTable != "" {
+		table, ok := options.OutcomeTables[check.OutcomeTable]
+		if !ok {
+			return nil, fmt.Errorf("tabela de resultados não definida: %s", check.OutcomeTable)
+		}
+		return &table, nil
+	}
+
+	if table, ok := options.OutcomeTables[DefaultOutcomeTable]; ok {
+		return &table, nil
+	}
+	return nil, nil
+}
+
+// applyOutcome calcula o resultado nomeado do teste pedido nas opções, se houver
+func (re *RollEngine) applyOutcome(details *models.RollDetails, options *RollOptions) error {
+	if options == nil || options.Check == nil {
+		return nil
+	}
+	check := options.Check
+	if check.Difficulty == nil && check.OutcomeTable == "" && check.Outcomes == nil {
+		return nil
+	}
+
+	table, err := resolveOutcomeTable(options)
+	if err != nil {
+		return err
+	}
+
+	if table == nil {
+		// Sem tabela: sucesso se total >= dificuldade, como em EvaluateSuccess
+		success := re.EvaluateSuccess(details, *check.Difficulty)
+		outcome := &models.RollOutcome{Name: OutcomeFailure, Success: success, Difficulty: check.Difficulty}
+		if success {
+			outcome.Name = OutcomeSuccess
+		} else if details.Botch {
+			outcome.Name = OutcomeBotch
+		}
+		details.Outcome = outcome
+		return nil
+	}
+
+	band, err := EvaluateOutcome(details, check.Difficulty, table)
+	if err != nil {
+		return err
+	}
+	if band != nil {
+		details.Outcome = &models.RollOutcome{Name: band.Name, Success: band.Success, Difficulty: check.Difficulty}
+	}
+	return nil
+}
+
+// EvaluateOutcome retorna a faixa da tabela em que a rolagem caiu, ou nil se nenhuma faixa contém o resultado.
+// As bases "margin" e "percent" exigem dificuldade; "percent" exige dificuldade positiva
+func EvaluateOutcome(result *models.RollDetails, difficulty *int, table *models.OutcomeTable) (*models.OutcomeBand, error) {
+	basis := table.Basis
+	if basis == "" {
+		basis = models.OutcomeBasisTotal
+	}
+	if basis != models.OutcomeBasisTotal && difficulty == nil {
+		return nil, fmt.Errorf("dificuldade obrigatória para resultados com base '%s'", basis)
+	}
+	if basis == models.OutcomeBasisPercent && *difficulty <= 0 {
+		return nil, fmt.Errorf("dificuldade deve ser positiva para resultados com base '%s'", basis)
+	}
+
+	index := -1
+	for i, band := range table.Bands {
+		if bandContains(band, result.Total, difficulty, basis) {
+			index = i
+			break
+		}
+	}
+	if index < 0 {
+		return nil, nil
+	}
+
+	// Faixas vão da melhor para a pior
+	if table.StepOnCritical {
+		if result.Critical && index > 0 {
+			index--
+		} else if result.Fumble && index < len(table.Bands)-1 {
+			index++
+		}
+	}
+	return &table.Bands[index], nil
+}
+
+// bandContains verifica se o total está na faixa segundo a base de comparação
+func bandContains(band models.OutcomeBand, total int, difficulty *int, basis string) bool {
+	switch basis {
+	case models.OutcomeBasisMargin:
+		margin := total - *difficulty
+		return (band.Min == nil || margin >= *band.Min) && (band.Max == nil || margin <= *band.Max)
+	case models.OutcomeBasisPercent:
+		// total/dificuldade em porcentagem, comparado sem arredondamento (e.g., max 20 = total <= dificuldade/5)
+		scaled := total * 100
+		if band.Min != nil && scaled < *band.Min*(*difficulty) {
+			return false
+		}
+		return band.Max == nil || scaled <= *band.Max*(*difficulty)
+	}
+	return (band.Min == nil || total >= *band.Min) && (band.Max == nil || total <= *band.Max)
+}
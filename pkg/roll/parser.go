@@ -0,0 +1,470 @@
+package roll
+
+import (
+	"fmt"
+)
+
+// Limites aceitos pelo parser
+const (
+	maxDiceCount = 100  // Máximo de dados por termo
+	minDiceSides = 2    // Mínimo de lados por dado
+	maxDiceSides = 1000 // Máximo de lados por dado
+	maxTotalDice = 500  // Máximo de dados somando todos os termos
+	maxExprDepth = 32   // Profundidade máxima de parênteses
+)
+
+// parser implementa uma análise descendente recursiva sobre os tokens.
+//
+// Gramática:
+//
+//	expr    := term (('+' | '-') term)*
+//	term    := unary (('*' | '/') unary)*
+//	unary   := ('+' | '-') unary | primary
+//	primary := NUMBER | dice | '(' expr ')' | func
+//	func    := ('floor' | 'ceil' | 'round' | 'abs' | 'min' | 'max') '(' expr (',' expr)* ')'
+//	dice    := [NUMBER] 'd' (NUMBER | 'F' | '%' | '[' NAME ']') modifier*
+//	modifier:= keep | reroll | explode | again | success | failure | tens
+//	keep    := ('kh' | 'kl' | 'dh' | 'dl' | 'k') [NUMBER]
+//	reroll  := ('r' | 'ro') [compare]
+//	explode := ('!' | '!!') [compare] ['l' NUMBER]
+//	again   := 'a' NUMBER
+//	success := ('=' | '>' | '>=' | '<' | '<=') NUMBER
+//	failure := 'f' compare
+//	tens    := ('b' | 'p') [NUMBER]
+//	compare := [('=' | '>' | '>=' | '<' | '<=')] NUMBER
+type parser struct {
+	tokens    []token
+	pos       int
+	depth     int
+	diceCount int
+}
+
+// parse analisa a expressão completa e retorna a árvore sintática
+func parse(expression string) (Node, error) {
+	tokens, err := tokenize(expression)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &parser{tokens: tokens}
+	if p.peek().kind == tokenEOF {
+		return nil, fmt.Errorf("expressão vazia")
+	}
+
+	root, err := p.parseExpr()
+	if err != nil {
+		return nil, err
+	}
+
+	if tok := p.peek(); tok.kind != tokenEOF {
+		return nil, fmt.Errorf("termo inesperado na posição %d: '%s'", tok.pos+1, tok.text)
+	}
+
+	return root, nil
+}
+
+// peek retorna o token atual sem consumi-lo
+func (p *parser) peek() token {
+	return p.tokens[p.pos]
+}
+
+// next consome e retorna o token atual
+func (p *parser) next() token {
+	tok := p.tokens[p.pos]
+	if tok.kind != tokenEOF {
+		p.pos++
+	}
+	return tok
+}
+
+// parseExpr trata soma e subtração
+func (p *parser) parseExpr() (Node, error) {
+	left, err := p.parseTerm()
+	if err != nil {
+		return nil, err
+	}
+
+	for {
+		tok := p.peek()
+		if tok.kind != tokenPlus && tok.kind != tokenMinus {
+			return left, nil
+		}
+		p.next()
+
+		right, err := p.parseTerm()
+		if err != nil {
+			return nil, err
+		}
+		left = &BinaryNode{Op: tok.text[0], Left: left, Right: right}
+	}
+}
+
+// parseTerm trata multiplicação e divisão
+func (p *parser) parseTerm() (Node, error) {
+	left, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+
+	for {
+		tok := p.peek()
+		if tok.kind != tokenStar && tok.kind != tokenSlash {
+			return left, nil
+		}
+		p.next()
+
+		right, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		left = &BinaryNode{Op: tok.text[0], Left: left, Right: right}
+	}
+}
+
+// parseUnary trata sinais antes de um termo
+func (p *parser) parseUnary() (Node, error) {
+	switch p.peek().kind {
+	case tokenPlus:
+		p.next()
+		return p.parseUnary()
+	case tokenMinus:
+		p.next()
+		operand, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return &NegateNode{Operand: operand}, nil
+	}
+	return p.parsePrimary()
+}
+
+// parsePrimary trata números, dados e subexpressões
+func (p *parser) parsePrimary() (Node, error) {
+	tok := p.peek()
+
+	switch {
+	case tok.kind == tokenNumber:
+		p.next()
+		if next := p.peek(); next.kind == tokenKeyword && next.text == "d" {
+			p.next()
+			return p.parseDice(tok.value)
+		}
+		return &NumberNode{Value: tok.value}, nil
+
+	case tok.kind == tokenKeyword && tok.text == "d":
+		p.next()
+		return p.parseDice(1)
+
+	case tok.kind == tokenLParen:
+		p.next()
+		p.depth++
+		if p.depth > maxExprDepth {
+			return nil, fmt.Errorf("expressão com parênteses demais (máximo %d níveis)", maxExprDepth)
+		}
+
+		inner, err := p.parseExpr()
+		if err != nil {
+			return nil, err
+		}
+
+		if closing := p.next(); closing.kind != tokenRParen {
+			return nil, fmt.Errorf("parêntese não fechado na posição %d", closing.pos+1)
+		}
+		p.depth--
+		return &GroupNode{Inner: inner}, nil
+
+	case tok.kind == tokenFunc:
+		p.next()
+		return p.parseFunc(tok)
+
+	case tok.kind == tokenEOF:
+		return nil, fmt.Errorf("expressão incompleta")
+	}
+
+	return nil, fmt.Errorf("termo inesperado na posição %d: '%s'", tok.pos+1, tok.text)
+}
+
+// parseFunc trata os argumentos de uma função após o nome
+func (p *parser) parseFunc(name token) (Node, error) {
+	if open := p.next(); open.kind != tokenLParen {
+		return nil, fmt.Errorf("'(' esperado após %s na posição %d", name.text, open.pos+1)
+	}
+	p.depth++
+	if p.depth > maxExprDepth {
+		return nil, fmt.Errorf("expressão com parênteses demais (máximo %d níveis)", maxExprDepth)
+	}
+
+	node := &FuncNode{Name: name.text}
+	for {
+		arg, err := p.parseExpr()
+		if err != nil {
+			return nil, err
+		}
+		node.Args = append(node.Args, arg)
+
+		if p.peek().kind != tokenComma {
+			break
+		}
+		p.next()
+	}
+
+	if closing := p.next(); closing.kind != tokenRParen {
+		return nil, fmt.Errorf("parêntese não fechado na posição %d", closing.pos+1)
+	}
+	p.depth--
+
+	arity := functionArity[node.Name]
+	if len(node.Args) < arity.min || len(node.Args) > arity.max {
+		if arity.min == arity.max {
+			return nil, fmt.Errorf("%s espera %d argumento(s), recebeu %d", node.Name, arity.min, len(node.Args))
+		}
+		return nil, fmt.Errorf("%s espera de %d a %d argumentos, recebeu %d", node.Name, arity.min, arity.max, len(node.Args))
+	}
+	return node, nil
+}
+
+// parseDice trata um termo de dados após o 'd'
+func (p *parser) parseDice(count int) (Node, error) {
+	if count < 1 || count > maxDiceCount {
+		return nil, fmt.Errorf("número de dados inválido: %d (1-%d)", count, maxDiceCount)
+	}
+
+	node := &DiceNode{Count: count}
+
+	tok := p.next()
+	switch {
+	case tok.kind == tokenNumber:
+		if tok.value < minDiceSides || tok.value > maxDiceSides {
+			return nil, fmt.Errorf("número de lados inválido: %d (%d-%d)", tok.value, minDiceSides, maxDiceSides)
+		}
+		node.Sides = tok.value
+	case tok.kind == tokenKeyword && tok.text == "f":
+		node.Kind = DiceFate
+		node.Sides = 3
+	case tok.kind == tokenPercent:
+		node.Kind = DicePercentile
+		node.Sides = 100
+	case tok.kind == tokenName:
+		node.Kind = DiceCustom
+		node.Custom = tok.text
+	default:
+		return nil, fmt.Errorf("número de lados esperado na posição %d", tok.pos+1)
+	}
+
+	p.diceCount += count
+	if p.diceCount > maxTotalDice {
+		return nil, fmt.Errorf("expressão com dados demais (máximo %d)", maxTotalDice)
+	}
+
+	if err := p.parseModifiers(node); err != nil {
+		return nil, err
+	}
+	if node.Failure != nil && node.Success == nil {
+		return nil, fmt.Errorf("limite de falha exige um limite de sucesso: %s", node.String())
+	}
+
+	return node, nil
+}
+
+// parseModifiers trata os modificadores que seguem um termo de dados
+func (p *parser) parseModifiers(node *DiceNode) error {
+	for {
+		tok := p.peek()
+
+		if tok.kind == tokenBang || tok.kind == tokenBangBang {
+			p.next()
+			if node.Kind != DiceStandard {
+				return fmt.Errorf("explosão não é suportada em %s", node.String())
+			}
+			if node.Explode != nil {
+				return fmt.Errorf("modificador de explosão repetido na posição %d", tok.pos+1)
+			}
+
+			explode := &ExplodeModifier{Compound: tok.kind == tokenBangBang, Limit: maxExplosions}
+			compare, err := p.optionalCompare()
+			if err != nil {
+				return err
+			}
+			explode.Compare = compare
+
+			if limit := p.peek(); limit.kind == tokenKeyword && limit.text == "l" {
+				p.next()
+				explode.Limit = p.optionalNumber(0)
+				if explode.Limit < 1 || explode.Limit > maxExplosions {
+					return fmt.Errorf("limite de explosão inválido: %d (1-%d)", explode.Limit, maxExplosions)
+				}
+			}
+
+			if matchesAllFaces(explode.trigger(node.Sides), node.Sides) {
+				return fmt.Errorf("explosão em todas as faces não é permitida: %s", explode.String())
+			}
+			node.Explode = explode
+			continue
+		}
+
+		if tok.kind == tokenCompare {
+			if node.Success != nil {
+				return fmt.Errorf("limite de sucesso repetido na posição %d", tok.pos+1)
+			}
+
+			compare, err := p.optionalCompare()
+			if err != nil {
+				return err
+			}
+			node.Success = compare
+			continue
+		}
+
+		if tok.kind != tokenKeyword {
+			return nil
+		}
+
+		switch tok.text {
+		case "r", "ro":
+			p.next()
+			if node.Kind != DiceStandard {
+				return fmt.Errorf("rerrolagem não é suportada em %s", node.String())
+			}
+			if node.Reroll != nil {
+				return fmt.Errorf("modificador de rerrolagem repetido na posição %d", tok.pos+1)
+			}
+
+			compare, err := p.optionalCompare()
+			if err != nil {
+				return err
+			}
+			reroll := &RerollModifier{Compare: compare, Once: tok.text == "ro"}
+
+			if !reroll.Once && matchesAllFaces(reroll.trigger(node.Sides), node.Sides) {
+				return fmt.Errorf("rerrolagem em todas as faces não é permitida: %s", reroll.String())
+			}
+			node.Reroll = reroll
+
+		case "a":
+			p.next()
+			if node.Kind != DiceStandard {
+				return fmt.Errorf("explosão não é suportada em %s", node.String())
+			}
+			if node.Explode != nil {
+				return fmt.Errorf("modificador de explosão repetido na posição %d", tok.pos+1)
+			}
+
+			again := p.next()
+			if again.kind != tokenNumber {
+				return fmt.Errorf("número esperado após 'a' na posição %d", again.pos+1)
+			}
+
+			// "a10" equivale a "!>=10" (regra "10-again")
+			explode := &ExplodeModifier{Compare: &ComparePoint{Op: ">=", Value: again.value}, Limit: maxExplosions}
+			if again.value > node.Sides || matchesAllFaces(explode.trigger(node.Sides), node.Sides) {
+				return fmt.Errorf("valor inválido para a regra 'a': %d (2-%d)", again.value, node.Sides)
+			}
+			node.Explode = explode
+
+		case "b", "p":
+			p.next()
+			if node.Kind != DicePercentile {
+				return fmt.Errorf("dados de bônus/penalidade exigem um dado percentual (d%%) na posição %d", tok.pos+1)
+			}
+			if node.Tens != nil {
+				return fmt.Errorf("modificador de bônus/penalidade repetido na posição %d", tok.pos+1)
+			}
+
+			amount := p.optionalNumber(1)
+			if amount < 1 || amount > maxExtraTens {
+				return fmt.Errorf("quantidade inválida para %s: %d (1-%d)", tok.text, amount, maxExtraTens)
+			}
+			node.Tens = &TensModifier{Bonus: tok.text == "b", Count: amount}
+
+		case "f":
+			p.next()
+			if node.Failure != nil {
+				return fmt.Errorf("limite de falha repetido na posição %d", tok.pos+1)
+			}
+
+			compare, err := p.optionalCompare()
+			if err != nil {
+				return err
+			}
+			if compare == nil {
+				return fmt.Errorf("condição esperada após 'f' na posição %d", tok.pos+2)
+			}
+			node.Failure = compare
+
+		case "k", KeepHighest, KeepLowest, DropHighest, DropLowest:
+			p.next()
+			if node.Keep != nil {
+				return fmt.Errorf("modificador de manter/descartar repetido na posição %d", tok.pos+1)
+			}
+
+			mode := tok.text
+			if mode == "k" {
+				mode = KeepHighest
+			}
+
+			amount := p.optionalNumber(1)
+			if err := validateKeep(mode, amount, node.Count); err != nil {
+				return err
+			}
+			node.Keep = &KeepModifier{Mode: mode, Count: amount}
+
+		default:
+			return nil
+		}
+	}
+}
+
+// optionalNumber consome um número se houver, retornando o valor padrão caso contrário
+func (p *parser) optionalNumber(fallback int) int {
+	if tok := p.peek(); tok.kind == tokenNumber {
+		p.next()
+		return tok.value
+	}
+	return fallback
+}
+
+// optionalCompare consome uma condição (e.g., "1", ">=5") se houver
+func (p *parser) optionalCompare() (*ComparePoint, error) {
+	tok := p.peek()
+
+	switch tok.kind {
+	case tokenNumber:
+		p.next()
+		return &ComparePoint{Op: "=", Value: tok.value}, nil
+
+	case tokenCompare:
+		p.next()
+		value := p.next()
+		if value.kind != tokenNumber {
+			return nil, fmt.Errorf("número esperado após '%s' na posição %d", tok.text, value.pos+1)
+		}
+		return &ComparePoint{Op: tok.text, Value: value.value}, nil
+	}
+
+	return nil, nil
+}
+
+// matchesAllFaces verifica se a condição é atendida por todas as faces do dado
+func matchesAllFaces(compare ComparePoint, sides int) bool {
+	for face := 1; face <= sides; face++ {
+		if !compare.Matches(face) {
+			return false
+		}
+	}
+	return true
+}
+
+// validateKeep verifica se a quantidade de dados mantidos/descartados é coerente
+func validateKeep(mode string, amount, count int) error {
+	switch mode {
+	case KeepHighest, KeepLowest:
+		if amount < 1 || amount > count {
+			return fmt.Errorf("quantidade inválida para %s: %d (1-%d)", mode, amount, count)
+		}
+	case DropHighest, DropLowest:
+		if amount < 1 || amount >= count {
+			return fmt.Errorf("quantidade inválida para %s: %d (1-%d)", mode, amount, count-1)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,198 @@
+package roll
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/luizdequeiroz/rpg-backend/internal/app/models"
+)
+
+// placeholderPattern reconhece referências a campos da ficha em expressões (e.g., "1d20+{attributes.strength}")
+var placeholderPattern = regexp.MustCompile(`\{([^{}]*)\}`)
+
+// HasPlaceholders informa se a expressão referencia campos da ficha
+func HasPlaceholders(expression string) bool {
+	return placeholderPattern.MatchString(expression)
+}
+
+// maxReferenceDepth limita referências encadeadas entre campos da ficha (e.g., {attack} = "1d20+{str_mod}")
+const maxReferenceDepth = 5
+
+// ResolvePlaceholders substitui cada referência {campo.aninhado} pelo valor do campo na ficha.
+// Números entram como constantes e textos como subexpressões (e.g., {weapon.damage} = "1d8" vira "(1d8)"),
+// que podem referenciar outros campos (e.g., {str_mod} = "floor(({abilities.str}-10)/2)").
+// Retorna também o trecho usado para cada campo referenciado diretamente na expressão
+func (re *RollEngine) ResolvePlaceholders(sheetData models.PlayerSheetData, expression string) (string, []models.SheetReference, error) {
+	resolver := &referenceResolver{engine: re, sheetData: sheetData}
+
+	var references []models.SheetReference
+	seen := make(map[string]bool)
+	resolved, err := replacePlaceholders(expression, func(path string) (string, error) {
+		value, err := resolver.value(path)
+		if err != nil {
+			return "", err
+		}
+		if !seen[path] {
+			seen[path] = true
+			references = append(references, models.SheetReference{Field: path, Value: value})
+		}
+		return value, nil
+	})
+	if err != nil {
+		return "", nil, err
+	}
+	return resolved, references, nil
+}
+
+// RollExpressionWithSheet resolve as referências à ficha e executa a rolagem da expressão resultante.
+// Retorna a expressão rolada; o detalhamento guarda a expressão original e os valores das referências
+func (re *RollEngine) RollExpressionWithSheet(sheetData models.PlayerSheetData, expression string, options *RollOptions) (*models.RollDetails, string, error) {
+	if !HasPlaceholders(expression) {
+		result, err := re.RollWithOptions(expression, options)
+		return result, expression, err
+	}
+	if sheetData == nil {
+		return nil, "", fmt.Errorf("expressão referencia campos da ficha, mas a rolagem não tem ficha: %s", expression)
+	}
+
+	resolved, references, err := re.ResolvePlaceholders(sheetData, expression)
+	if err != nil {
+		return nil, "", err
+	}
+
+	result, err := re.RollWithOptions(resolved, options)
+	if err != nil {
+		return nil, "", err
+	}
+	result.SourceExpression = expression
+	result.References = references
+	return result, resolved, nil
+}
+
+// IsSumExpression indica se a expressão, com as referências à ficha resolvidas, soma os dados (ver DiceExpression.IsSum)
+func (re *RollEngine) IsSumExpression(sheetData models.PlayerSheetData, expression string) (bool, error) {
+	if HasPlaceholders(expression) {
+		if sheetData == nil {
+			return false, fmt.Errorf("expressão referencia campos da ficha, mas a rolagem não tem ficha: %s", expression)
+		}
+		resolved, _, err := re.ResolvePlaceholders(sheetData, expression)
+		if err != nil {
+			return false, err
+		}
+		expression = resolved
+	}
+
+	parsed, err := re.ParseExpression(expression)
+	if err != nil {
+		return false, err
+	}
+	return parsed.IsSum(), nil
+}
+
+// referenceResolver resolve referências encadeadas, detectando ciclos entre campos
+type referenceResolver struct {
+	engine    *RollEngine
+	sheetData models.PlayerSheetData
+	stack     []string // Campos em resolução, do mais externo ao mais interno
+}
+
+// value retorna o trecho que substitui a referência ao campo
+func (r *referenceResolver) value(path string) (string, error) {
+	value, err := r.engine.getNestedValue(r.sheetData, path)
+	if err != nil {
+		return "", err
+	}
+
+	text, ok := value.(string)
+	if !ok || !HasPlaceholders(text) {
+		return placeholderValue(path, value)
+	}
+
+	if slices.Contains(r.stack, path) {
+		return "", fmt.Errorf("referência circular no campo '%s'", path)
+	}
+	if len(r.stack) >= maxReferenceDepth {
+		return "", fmt.Errorf("referências encadeadas demais no campo '%s' (máximo %d)", path, maxReferenceDepth)
+	}
+
+	r.stack = append(r.stack, path)
+	resolved, err := replacePlaceholders(text, r.value)
+	r.stack = r.stack[:len(r.stack)-1]
+	if err != nil {
+		return "", err
+	}
+	return "(" + strings.TrimSpace(resolved) + ")", nil
+}
+
+// ValidatePlaceholderExpression verifica a sintaxe de uma expressão com referências a campos,
+// substituindo cada referência por zero
+func (re *RollEngine) ValidatePlaceholderExpression(expression string) error {
+	resolved, err := replacePlaceholders(expression, func(string) (string, error) {
+		return "0", nil
+	})
+	if err != nil {
+		return err
+	}
+	_, err = re.ParseExpression(resolved)
+	return err
+}
+
+// replacePlaceholders substitui as referências da expressão pelos valores retornados por resolve
+func replacePlaceholders(expression string, resolve func(path string) (string, error)) (string, error) {
+	var resolveErr error
+	resolved := placeholderPattern.ReplaceAllStringFunc(expression, func(match string) string {
+		if resolveErr != nil {
+			return match
+		}
+		path := strings.TrimSpace(match[1 : len(match)-1])
+		if path == "" {
+			resolveErr = fmt.Errorf("referência vazia na expressão: %s", expression)
+			return match
+		}
+		value, err := resolve(path)
+		if err != nil {
+			resolveErr = err
+			return match
+		}
+		return value
+	})
+	if resolveErr != nil {
+		return "", resolveErr
+	}
+	return resolved, nil
+}
+
+// placeholderValue converte o valor de um campo da ficha para o trecho da expressão que o substitui
+func placeholderValue(path string, value interface{}) (string, error) {
+	var number int
+	switch v := value.(type) {
+	case float64:
+		if v != math.Trunc(v) {
+			return "", fmt.Errorf("campo '%s' não é um número inteiro: %v", path, v)
+		}
+		number = int(v)
+	case int:
+		number = v
+	case string:
+		text := strings.TrimSpace(v)
+		parsed, err := strconv.Atoi(text)
+		if err != nil {
+			if text == "" {
+				return "", fmt.Errorf("campo '%s' não é um valor de rolagem válido", path)
+			}
+			return "(" + text + ")", nil
+		}
+		number = parsed
+	default:
+		return "", fmt.Errorf("campo '%s' não é um valor de rolagem válido", path)
+	}
+
+	if number < 0 {
+		return fmt.Sprintf("(%d)", number), nil
+	}
+	return strconv.Itoa(number), nil
+}
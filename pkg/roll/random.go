@@ -0,0 +1,66 @@
+package roll
+
+import (
+	"crypto/rand"
+	"encoding/binary"
+	"math"
+	mathrand "math/rand"
+	"sync"
+)
+
+// RandomSource fornece números aleatórios para o motor de rolagem.
+// Implementações devem ser seguras para uso concorrente.
+type RandomSource interface {
+	// Intn retorna um inteiro uniforme no intervalo [0, n); n deve ser positivo
+	Intn(n int) int
+}
+
+// cryptoSource usa o gerador criptográfico do sistema operacional
+type cryptoSource struct{}
+
+// NewCryptoSource cria a fonte padrão, baseada em crypto/rand.
+// É segura para uso concorrente sem travas, pois crypto/rand já é.
+func NewCryptoSource() RandomSource {
+	return cryptoSource{}
+}
+
+// Intn sorteia um inteiro em [0, n) sem viés de módulo
+func (cryptoSource) Intn(n int) int {
+	if n <= 0 {
+		panic("roll: Intn com n <= 0")
+	}
+
+	bound := uint64(n)
+	// Descartar valores acima do maior múltiplo de n para manter a distribuição uniforme
+	limit := math.MaxUint64 - math.MaxUint64%bound
+
+	var buf [8]byte
+	for {
+		if _, err := rand.Read(buf[:]); err != nil {
+			panic("roll: falha ao ler do gerador criptográfico: " + err.Error())
+		}
+		value := binary.LittleEndian.Uint64(buf[:])
+		if value < limit {
+			return int(value % bound)
+		}
+	}
+}
+
+// seededSource é uma fonte determinística protegida por trava
+type seededSource struct {
+	mu   sync.Mutex
+	rand *mathrand.Rand
+}
+
+// NewSeededSource cria uma fonte determinística a partir de uma semente,
+// útil para testes e para reproduzir rolagens
+func NewSeededSource(seed int64) RandomSource {
+	return &seededSource{rand: mathrand.New(mathrand.NewSource(seed))}
+}
+
+// Intn sorteia um inteiro em [0, n)
+func (s *seededSource) Intn(n int) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.rand.Intn(n)
+}
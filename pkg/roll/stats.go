@@ -0,0 +1,89 @@
+package roll
+
+import (
+	"math"
+)
+
+// Parâmetros do cálculo da função gama incompleta
+const (
+	gammaMaxIterations = 500
+	gammaEpsilon       = 1e-14
+	gammaTiny          = 1e-300
+)
+
+// ChiSquare calcula a estatística qui-quadrado das contagens observadas de cada face contra uma
+// distribuição uniforme e o p-valor correspondente (chance de um dado justo desviar tanto ou mais).
+// Sem observações ou com menos de duas faces, retorna estatística 0 e p-valor 1
+func ChiSquare(counts []int) (statistic, pValue float64) {
+	total := 0
+	for _, count := range counts {
+		total += count
+	}
+	if total == 0 || len(counts) < 2 {
+		return 0, 1
+	}
+
+	expected := float64(total) / float64(len(counts))
+	for _, count := range counts {
+		diff := float64(count) - expected
+		statistic += diff * diff / expected
+	}
+
+	degrees := float64(len(counts) - 1)
+	return statistic, upperGamma(degrees/2, statistic/2)
+}
+
+// upperGamma calcula a função gama incompleta superior regularizada Q(a, x),
+// por série quando x < a+1 e por fração contínua nos demais casos
+func upperGamma(a, x float64) float64 {
+	if x <= 0 {
+		return 1
+	}
+	if x < a+1 {
+		return 1 - lowerGammaSeries(a, x)
+	}
+	return upperGammaFraction(a, x)
+}
+
+// lowerGammaSeries calcula P(a, x) pela expansão em série
+func lowerGammaSeries(a, x float64) float64 {
+	lgamma, _ := math.Lgamma(a)
+	term := 1 / a
+	sum := term
+	for n := 1; n <= gammaMaxIterations; n++ {
+		term *= x / (a + float64(n))
+		sum += term
+		if math.Abs(term) < math.Abs(sum)*gammaEpsilon {
+			break
+		}
+	}
+	return sum * math.Exp(-x+a*math.Log(x)-lgamma)
+}
+
+// upperGammaFraction calcula Q(a, x) pela fração contínua de Lentz
+func upperGammaFraction(a, x float64) float64 {
+	lgamma, _ := math.Lgamma(a)
+	b := x + 1 - a
+	c := 1 / gammaTiny
+	d := 1 / b
+	h := d
+	for n := 1; n <= gammaMaxIterations; n++ {
+		an := -float64(n) * (float64(n) - a)
+		b += 2
+		d = an*d + b
+		if math.Abs(d) < gammaTiny {
+			d = gammaTiny
+		}
+		c = b + an/c
+		if math.Abs(c) < gammaTiny {
+			c = gammaTiny
+		}
+		d = 1 / d
+		delta := d * c
+		h *= delta
+		if math.Abs(delta-1) < gammaEpsilon {
+			break
+		}
+	}
+	return math.Exp(-x+a*math.Log(x)-lgamma) * h
+}